@@ -1,11 +1,23 @@
 package main
 
 import (
+	"os"
+
 	"pets/internal"
 )
 
-// main is a main app endpoint
+// main is a main app endpoint. Use "pets migrate" subcommand to manage DB schema, "pets import" subcommand to import
+// pets from CSV or NDJSON file
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(internal.Migrate(os.Args[2:]))
+		case "import":
+			os.Exit(internal.Import(os.Args[2:]))
+		}
+	}
+
 	app := internal.NewApp()
 
 	app.Run()
@@ -1,10 +1,13 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -12,6 +15,7 @@ import (
 	"pets/internal/repository"
 	"pets/internal/server"
 	"pets/internal/service"
+	"pets/internal/storage"
 	"pets/pkg/logger"
 )
 
@@ -19,7 +23,11 @@ import (
 type App struct {
 	config     *config.Scheme
 	repository repository.IRepository
+	service    service.IService
 	server     *server.HttpServer
+	// stopJobs is used to stop background jobs, jobs is used to wait for them
+	stopJobs context.CancelFunc
+	jobs     sync.WaitGroup
 }
 
 // NewApp is used to get new App instance
@@ -30,15 +38,22 @@ func NewApp() *App {
 
 	a.repository = repository.NewRepository(a.config.DB)
 
-	srv := service.NewService(a.repository)
-	a.server = server.NewServer(a.config.Http, srv)
+	store := storage.NewBlobStore(a.config.Storage)
+
+	a.service = service.NewService(a.config.Service, a.repository, store)
+	a.server = server.NewServer(a.config.Http, a.service)
 
 	return a
 }
 
 // initConfig is used to init new config using viper. If no config in env vars will set default values
 func (a *App) initConfig() {
-	a.config = &config.Scheme{}
+	a.config = loadConfig()
+}
+
+// loadConfig is used to load config using viper. If no config in env vars will set default values
+func loadConfig() *config.Scheme {
+	conf := &config.Scheme{}
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -50,15 +65,23 @@ func (a *App) initConfig() {
 	viper.AutomaticEnv()
 	viper.AllowEmptyEnv(true)
 
-	if err := viper.Unmarshal(a.config); err != nil {
+	if err := viper.Unmarshal(conf); err != nil {
 		logger.Log().WithField("layer", "App").Fatalf("viper unmarshal config error: %v", err.Error())
 	}
 
 	logger.Log().WithField("layer", "App").Infof("config initialaized")
+
+	return conf
 }
 
 // Run is used to run app
 func (a *App) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopJobs = cancel
+
+	a.jobs.Add(1)
+	go a.purgeDeleted(ctx)
+
 	go a.server.ListenAndServ()
 
 	quit := make(chan os.Signal, 1)
@@ -68,8 +91,47 @@ func (a *App) Run() {
 	return
 }
 
+// purgeDeleted is a background job purging pets soft deleted more than config RetentionDays ago every PurgeInterval
+// until given context is done. Job is disabled if retention or interval is not positive
+func (a *App) purgeDeleted(ctx context.Context) {
+	defer a.jobs.Done()
+
+	days, interval := a.config.Service.RetentionDays, a.config.Service.PurgeInterval
+	if days <= 0 || interval <= 0 {
+		logger.Log().WithField("layer", "App-Purge").Infof("deleted pets purge is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		before := time.Now().AddDate(0, 0, -days)
+
+		n, err := a.service.PurgePets(ctx, before)
+		if err != nil {
+			logger.Log().WithField("layer", "App-Purge").Errorf("err purge deleted pets: %v", err.Error())
+		}
+
+		if n != 0 {
+			logger.Log().WithField("layer", "App-Purge").Infof("%v pets deleted before %v purged", n, before)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Stop is used to stop app
 func (a *App) Stop() {
+	if a.stopJobs != nil {
+		a.stopJobs()
+		a.jobs.Wait()
+	}
+
 	if a.repository != nil {
 		a.repository.Stop()
 	}
@@ -1,18 +1,54 @@
 package config
 
+import "time"
+
 // Scheme represents the application configuration scheme.
 type Scheme struct {
-	Env  string
-	DB   *DB
-	Http *Http
+	Env     string
+	DB      *DB
+	Http    *Http
+	Service *Service
+	Storage *Storage
 }
 
 // DB is service Data base connection params
 type DB struct {
+	// Driver is a DB driver name: "postgres", "sqlite" or "memory" to keep data in memory without DB
 	Driver string
-	Addr   string
+	// Addr is a DB connection string. For "sqlite" driver it is a DB file path or ":memory:"
+	Addr string
+	// Timeout is a max duration of a single DB query, e.g. "5s". 0 means no timeout
+	Timeout time.Duration
+	// AutoMigrate is used to apply embedded schema migrations on app start
+	AutoMigrate bool
+	// TxIsolation is an isolation level of transactions: "read_committed", "repeatable_read" or "serializable". Blank
+	// means the DB default
+	TxIsolation string
+	// TxRetries is a max number of retries of a transaction failed to serialize with concurrent ones
+	TxRetries int
 }
 
 type Http struct {
 	TCP string
 }
+
+// Service is service layer params
+type Service struct {
+	// CursorSecret is a key used to sign pagination cursors. If blank, random key is generated on start, so cursors
+	// can not be used after restart or with other app instances
+	CursorSecret string
+	// MaxPhotoSize is a max size of uploaded pet photo in bytes
+	MaxPhotoSize int64
+	// RetentionDays is a number of days soft deleted pets are kept before they are purged. 0 disables purging
+	RetentionDays int
+	// PurgeInterval is a period of deleted pets purge job runs, e.g. "1h"
+	PurgeInterval time.Duration
+}
+
+// Storage is blob storage params
+type Storage struct {
+	// Driver is a blob storage driver name: "fs" to keep blobs in local filesystem or "memory" to keep them in memory
+	Driver string
+	// Root is a directory blobs are saved to by "fs" driver
+	Root string
+}
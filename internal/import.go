@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pets/internal/model"
+	"pets/internal/repository"
+	"pets/internal/service"
+	"pets/pkg/logger"
+)
+
+// importUsage is a usage of import command
+const importUsage = "usage: pets import [-dry-run] [-format csv|ndjson] [-map column:field,...] [-report file] [-actor name] <file>"
+
+// Import is used to run pets import command with given args. Pets are read from the CSV or NDJSON file, "-" reads
+// stdin. Format is taken from the file extension if not given: .csv for CSV, .ndjson or .jsonl for NDJSON. Import
+// summary and rejected rows are printed, the rejected rows report is written to the -report file as CSV if given. The
+// report of rows read before an import error is printed too. Will return the process exit code, 1 if import failed
+func Import(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), importUsage); fs.PrintDefaults() }
+
+	dryRun := fs.Bool("dry-run", false, "only validate rows, pets are not added")
+	format := fs.String("format", "", "import format: csv or ndjson, taken from the file extension by default")
+	columns := fs.String("map", "", "comma-separated column:field mappings of columns not named as pet fields")
+	reportPath := fs.String("report", "", "file to write rejected rows report as CSV")
+	actor := fs.String("actor", "", "actor name recorded in the pet audit, system by default")
+
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		logger.Log().WithField("layer", "Import").Errorf(importUsage)
+		return 2
+	}
+
+	path := fs.Arg(0)
+	opts := &model.ImportOptions{Format: model.ImportFormat(*format), DryRun: *dryRun}
+
+	if opts.Format == "" {
+		opts.Format = importFormat(path)
+	}
+
+	if err := model.CheckActorName(*actor); err != nil {
+		logger.Log().WithField("layer", "Import").Errorf("err parse actor: %v", err.Error())
+		return 2
+	}
+
+	var mappings []string
+	if *columns != "" {
+		mappings = strings.Split(*columns, ",")
+	}
+
+	var err error
+	if opts.Columns, err = model.ParseImportColumns(mappings); err != nil {
+		logger.Log().WithField("layer", "Import").Errorf("err parse map: %v", err.Error())
+		return 2
+	}
+
+	// config and repository are loaded first, as they exit on error
+	conf := loadConfig()
+
+	rep := repository.NewRepository(conf.DB)
+	defer rep.Stop()
+
+	var r io.Reader = os.Stdin
+
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			logger.Log().WithField("layer", "Import").Errorf("err open file: %v", err.Error())
+			return 1
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	srv := service.NewService(conf.Service, rep, nil)
+
+	ctx := context.Background()
+	if *actor != "" {
+		ctx = model.WithActor(ctx, model.Actor{Name: *actor})
+	}
+
+	report, importErr := srv.ImportPets(ctx, r, opts)
+	if report == nil {
+		logger.Log().WithField("layer", "Import").Errorf("err import %v: %v", path, importErr.Error())
+		return 1
+	}
+
+	printImportReport(report)
+
+	if *reportPath != "" {
+		if err = writeImportReport(*reportPath, report); err != nil {
+			logger.Log().WithField("layer", "Import").Errorf("err write report: %v", err.Error())
+			return 1
+		}
+	}
+
+	if importErr != nil {
+		logger.Log().WithField("layer", "Import").Errorf("err import %v after line %v: %v", path, report.CommittedLine,
+			importErr.Error())
+		return 1
+	}
+
+	return 0
+}
+
+// printImportReport is used to print summary and rejected rows of given report
+func printImportReport(report *model.ImportReport) {
+	fmt.Printf("total: %v\nimported: %v\ncommitted line: %v\nrejected: %v\ndry run: %v\n", report.Total,
+		report.Imported, report.CommittedLine, report.Rejected, report.DryRun)
+
+	if len(report.IgnoredColumns) != 0 {
+		fmt.Printf("ignored columns: %v\n", strings.Join(report.IgnoredColumns, ", "))
+	}
+
+	for _, rej := range report.Rejects {
+		if rej.Field != "" {
+			fmt.Printf("line %v: %v: %v\n", rej.Line, rej.Field, rej.Reason)
+		} else {
+			fmt.Printf("line %v: %v\n", rej.Line, rej.Reason)
+		}
+	}
+
+	if report.RejectsTruncated {
+		fmt.Printf("only first %v rejects are listed\n", len(report.Rejects))
+	}
+}
+
+// importFormat is used to get import format by given file extension. CSV is the default format
+func importFormat(path string) model.ImportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return model.ImportNDJSON
+	}
+
+	return model.ImportCSV
+}
+
+// writeImportReport is used to write rejected rows of given report to the file with given path as CSV
+func writeImportReport(path string, report *model.ImportReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = report.WriteRejectsCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
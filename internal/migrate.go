@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+
+	"pets/internal/repository"
+	"pets/pkg/logger"
+)
+
+// migrateUsage is a usage of migrate command
+const migrateUsage = "usage: pets migrate up|down [N]|status|version"
+
+// Migrate is used to run schema migrations command with given args. Commands are:
+// "up" to apply all migrations, "down [N]" to roll back N migrations (1 by default),
+// "status" to print current and latest schema versions, "version" to print current schema version.
+// Will return the process exit code, 2 for wrong usage and 1 if the command failed
+func Migrate(args []string) int {
+	if len(args) == 0 {
+		logger.Log().WithField("layer", "Migrate").Errorf(migrateUsage)
+		return 2
+	}
+
+	steps := 1
+
+	switch args[0] {
+	case "up", "status", "version":
+	case "down":
+		if len(args) > 1 {
+			var err error
+
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				logger.Log().WithField("layer", "Migrate").Errorf("down steps should be positive number: %v", args[1])
+				return 2
+			}
+		}
+	default:
+		logger.Log().WithField("layer", "Migrate").Errorf(migrateUsage)
+		return 2
+	}
+
+	// config is loaded first, as it exits on error
+	conf := loadConfig()
+
+	if conf.DB == nil {
+		logger.Log().WithField("layer", "Migrate").Errorf("nil db config err")
+		return 1
+	}
+
+	if conf.DB.Driver == repository.MemoryDriver {
+		logger.Log().WithField("layer", "Migrate").Errorf("%v driver has no schema", conf.DB.Driver)
+		return 1
+	}
+
+	db, err := repository.OpenDB(conf.DB)
+	if err != nil {
+		logger.Log().WithField("layer", "Migrate").Errorf("err open db: %v", err.Error())
+		return 1
+	}
+	defer db.Close()
+
+	m, err := repository.NewMigrator(db.DB, conf.DB.Driver)
+	if err != nil {
+		logger.Log().WithField("layer", "Migrate").Errorf("err init migrator: %v", err.Error())
+		return 1
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down(steps)
+	case "status":
+		version, dirty, vErr := m.Version()
+		if vErr == nil {
+			fmt.Printf("version: %v\nlatest: %v\ndirty: %v\n", version, m.Latest(), dirty)
+		}
+
+		err = vErr
+	case "version":
+		version, _, vErr := m.Version()
+		if vErr == nil {
+			fmt.Println(version)
+		}
+
+		err = vErr
+	}
+
+	if err != nil {
+		logger.Log().WithField("layer", "Migrate").Errorf("err migrate %v: %v", args[0], err.Error())
+		return 1
+	}
+
+	logger.Log().WithField("layer", "Migrate").Infof("migrate %v done", args[0])
+
+	return 0
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+
+	"pets/internal/server/handlers/requests"
+)
+
+// ApplicationStatus is an adoption application status
+type ApplicationStatus string
+
+// Adoption application statuses
+const (
+	ApplicationSubmitted ApplicationStatus = "submitted"
+	ApplicationApproved  ApplicationStatus = "approved"
+	ApplicationRejected  ApplicationStatus = "rejected"
+	ApplicationCompleted ApplicationStatus = "completed"
+)
+
+// Valid is used to check that ApplicationStatus is one of known statuses
+func (s ApplicationStatus) Valid() bool {
+	switch s {
+	case ApplicationSubmitted, ApplicationApproved, ApplicationRejected, ApplicationCompleted:
+		return true
+	}
+
+	return false
+}
+
+// Application is an adoption application model struct
+type Application struct {
+	// ID is an application id
+	ID int `json:"id"`
+	// PetID is an id of the pet to adopt
+	PetID int `json:"pet_id" db:"pet_id"`
+	// Applicant is an applicant full name
+	Applicant string `json:"applicant"`
+	// Contact is an applicant email or phone. Can be blank
+	Contact string `json:"contact"`
+	// Notes is a free text applicant notes. Can be blank
+	Notes string `json:"notes"`
+	// Status is an application status, ApplicationSubmitted on submit
+	Status ApplicationStatus `json:"status"`
+	// Reason is a reason of the last application decision. Can be blank
+	Reason string `json:"reason"`
+	// CreatedAt is a date when application was submitted
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is a date of the last application decision. Can be nil
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ApplicationsFilter is a filter of adoption applications list. Blank fields are ignored
+type ApplicationsFilter struct {
+	// PetID is used to get applications for given pet only
+	PetID int
+	// Statuses is used to get applications in any of given statuses
+	Statuses []ApplicationStatus
+}
+
+// Transition is an append-only record of pet status change
+type Transition struct {
+	// ID is a transition id
+	ID int `json:"id"`
+	// PetID is an id of the pet changed
+	PetID int `json:"pet_id" db:"pet_id"`
+	// From is a pet status before the transition
+	From Status `json:"from" db:"from_status"`
+	// To is a pet status after the transition
+	To Status `json:"to" db:"to_status"`
+	// Reason is a free text transition reason. Can be blank
+	Reason string `json:"reason"`
+	// ApplicationID is an id of the adoption application caused the transition. Nil for other transitions
+	ApplicationID *int `json:"application_id" db:"application_id"`
+	// CreatedAt is a date of the transition
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// GetApplicationFromReq is used to get Application model for the pet with given ID from given
+// requests.ApplicationReq model
+func GetApplicationFromReq(petID int, req *requests.ApplicationReq) *Application {
+	return &Application{
+		PetID:     petID,
+		Applicant: req.Applicant,
+		Contact:   req.Contact,
+		Notes:     req.Notes,
+	}
+}
+
+// SetLocal is used to set local time format
+func (a *Application) SetLocal() {
+	a.CreatedAt = a.CreatedAt.Local()
+
+	if a.UpdatedAt != nil {
+		l := a.UpdatedAt.Local()
+		a.UpdatedAt = &l
+	}
+}
+
+// SetLocal is used to set local time format
+func (t *Transition) SetLocal() {
+	t.CreatedAt = t.CreatedAt.Local()
+}
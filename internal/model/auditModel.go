@@ -0,0 +1,268 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"pets/internal/server/handlers/requests"
+)
+
+// AuditAction is a kind of pet change recorded in the pet audit
+type AuditAction string
+
+// Audit actions
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditRevert  AuditAction = "revert"
+)
+
+// Valid is used to check that AuditAction is one of known actions
+func (a AuditAction) Valid() bool {
+	switch a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditRevert:
+		return true
+	}
+
+	return false
+}
+
+// SystemActor is an actor name of changes made without a request, e.g. by background jobs
+const SystemActor = "system"
+
+// AuditEntry is an append-only record of a pet change
+type AuditEntry struct {
+	// ID is an audit entry id
+	ID int `json:"id"`
+	// PetID is an id of the pet changed
+	PetID int `json:"pet_id" db:"pet_id"`
+	// Version is the pet version after the change
+	Version int `json:"version"`
+	// Action is a kind of the change
+	Action AuditAction `json:"action"`
+	// Actor is a name of the client made the change, SystemActor for changes made without a request
+	Actor string `json:"actor"`
+	// RequestID is an id of the request made the change. Blank for changes made without a request
+	RequestID string `json:"request_id" db:"request_id"`
+	// Diff is a map of changed pet fields to their values before and after the change
+	Diff Diff `json:"diff"`
+	// CreatedAt is a date of the change
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SetLocal is used to set local time format
+func (e *AuditEntry) SetLocal() {
+	e.CreatedAt = e.CreatedAt.Local()
+}
+
+// Change is a pet field values before and after the change. Values are JSON encoded as in requests.AddPetReq, null if
+// field had no value
+type Change struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Diff is a map of pet field names to their changes
+type Diff map[string]Change
+
+// auditNull is a JSON value of a field which has no value
+var auditNull = json.RawMessage("null")
+
+// NewDiff is used to get Diff of the pet fields changed by the client, status and deleted_at between given pets. Before
+// is nil for created pets. Will return error if pet fields can not be encoded
+func NewDiff(before *Pet, after *Pet) (Diff, error) {
+	from, err := auditValues(before)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := auditValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := Diff{}
+
+	for field, v := range to {
+		old, ok := from[field]
+		if !ok {
+			old = auditNull
+		}
+
+		if !bytes.Equal(old, v) {
+			diff[field] = Change{Before: old, After: v}
+		}
+	}
+
+	return diff, nil
+}
+
+// auditValues is used to get JSON encoded values of the audited pet fields. Will return empty map for nil pet
+func auditValues(p *Pet) (map[string]json.RawMessage, error) {
+	values := map[string]json.RawMessage{}
+	if p == nil {
+		return values, nil
+	}
+
+	b, err := json.Marshal(p.ToReq())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+
+	values["deleted_at"] = auditNull
+
+	if p.DeletedAt != nil {
+		if values["deleted_at"], err = json.Marshal(p.DeletedAt.UTC()); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// Value is implementing driver.Valuer interface. Diff is stored as a JSON string
+func (d Diff) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// Scan is implementing sql.Scanner interface
+func (d *Diff) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case []byte:
+		return json.Unmarshal(v, d)
+	}
+
+	return fmt.Errorf("can not scan %T to Diff", src)
+}
+
+// PetAtVersion is used to get the pet fields changed by the client as they were at given pet version, replaying
+// given audit entries of the pet. Status, owner and other fields changed by other routes are not set. Will return
+// error if entries have no pet creation at or before the version
+func PetAtVersion(entries []*AuditEntry, version int) (*Pet, error) {
+	sorted := make([]*AuditEntry, 0, len(entries))
+
+	for _, e := range entries {
+		if e.Version <= version {
+			sorted = append(sorted, e)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	if len(sorted) == 0 || sorted[0].Action != AuditCreate {
+		return nil, fmt.Errorf("no history of version %v", version)
+	}
+
+	values := map[string]json.RawMessage{}
+
+	for _, e := range sorted {
+		for field, c := range e.Diff {
+			values[field] = c.After
+		}
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &requests.AddPetReq{}
+	if err = json.Unmarshal(b, req); err != nil {
+		return nil, fmt.Errorf("can not decode version %v: %w", version, err)
+	}
+
+	return GetPetFromReq(req)
+}
+
+// AuditFilter is a filter of the pet audit entries. Zero fields are not used
+type AuditFilter struct {
+	// PetID is an id of the pet changed
+	PetID int
+	// Actor is a name of the client made the change
+	Actor string
+	// Action is a kind of the change
+	Action AuditAction
+	// RequestID is an id of the request made the change
+	RequestID string
+	// Since is an inclusive lower bound of the change date
+	Since *time.Time
+	// Until is an exclusive upper bound of the change date
+	Until *time.Time
+	// Limit is a max number of entries to return. 0 if number is not limited
+	Limit int
+	// Offset is a number of entries to skip
+	Offset int
+}
+
+// Actor is a client made the request and the request id, recorded in the pet audit
+type Actor struct {
+	// Name is a client name
+	Name string
+	// RequestID is an id of the request
+	RequestID string
+}
+
+// MaxActorName is a max length of Actor Name in characters
+const MaxActorName = 64
+
+// CheckActorName is used to check given client name can be recorded as Actor Name. Will return error if the name is
+// longer than MaxActorName or has not printable characters
+func CheckActorName(name string) error {
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("actor should be valid UTF-8")
+	}
+
+	if utf8.RuneCountInString(name) > MaxActorName {
+		return fmt.Errorf("actor cannot be longer than %v characters", MaxActorName)
+	}
+
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("actor cannot have not printable characters")
+		}
+	}
+
+	return nil
+}
+
+// actorKey is a context key of Actor
+type actorKey struct{}
+
+// WithActor is used to get a copy of given context with given Actor, changes made with the context are recorded in the
+// pet audit as made by the actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext is used to get Actor of given context. SystemActor without request id is returned if context has no
+// actor
+func ActorFromContext(ctx context.Context) Actor {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	if !ok || actor.Name == "" {
+		return Actor{Name: SystemActor, RequestID: actor.RequestID}
+	}
+
+	return actor
+}
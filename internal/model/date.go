@@ -0,0 +1,128 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is a Date format used in JSON, query params and DB
+const DateLayout = "2006-01-02"
+
+// Date is a calendar date without time of day. It is stored as UTC midnight
+type Date struct {
+	time.Time
+}
+
+// NewDate is used to get Date of given year, month and day
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate is used to get Date from given string in DateLayout format
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("should be a date in YYYY-MM-DD format")
+	}
+
+	return Date{Time: t}, nil
+}
+
+// String is implementing fmt.Stringer interface
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON is implementing json.Marshaler interface
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON is implementing json.Unmarshaler interface
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+// Value is implementing driver.Valuer interface. Date is stored as a DateLayout string, so it is compared the same
+// way by all DB drivers
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan is implementing sql.Scanner interface
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+		return nil
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	}
+
+	return fmt.Errorf("can not scan %T to Date", src)
+}
+
+// scanString is used to scan Date from a string starting with DateLayout date
+func (d *Date) scanString(s string) error {
+	if len(s) < len(DateLayout) {
+		return fmt.Errorf("can not scan %q to Date", s)
+	}
+
+	parsed, err := ParseDate(s[:len(DateLayout)])
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+// DateError is an error of parsing Date request field
+type DateError struct {
+	// Field is a request field name
+	Field string
+	// Err is a parse error
+	Err error
+}
+
+// Error is implementing error interface
+func (e *DateError) Error() string {
+	return fmt.Sprintf("invalid %v: %v", e.Field, e.Err.Error())
+}
+
+// Unwrap is used to match DateError with its parse error in errors.Is and errors.As
+func (e *DateError) Unwrap() error {
+	return e.Err
+}
+
+// parseReqDate is used to parse Date request field with given name. Will return nil if s is blank, *DateError if s is
+// malformed
+func parseReqDate(field string, s string) (*Date, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	d, err := ParseDate(s)
+	if err != nil {
+		return nil, &DateError{Field: field, Err: err}
+	}
+
+	return &d, nil
+}
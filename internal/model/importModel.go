@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportFormat is a format of pets import data
+type ImportFormat string
+
+// Pets import formats
+const (
+	// ImportCSV is a CSV format with a header row of column names
+	ImportCSV ImportFormat = "csv"
+	// ImportNDJSON is a newline delimited JSON format with a pet object per line
+	ImportNDJSON ImportFormat = "ndjson"
+)
+
+// ImportFields is a list of pet fields which can be imported. Names are the requests.AddPetReq JSON names
+var ImportFields = []string{"name", "species", "breed", "birth_date", "sex", "neutered", "weight", "color", "description", "status"}
+
+// MaxImportRejects is a max number of rejects listed in ImportReport, further rejected rows are only counted
+const MaxImportRejects = 1000
+
+// ImportOptions is a struct of pets import options
+type ImportOptions struct {
+	// Format is an import data format
+	Format ImportFormat
+	// DryRun is true if rows are only validated and pets are not added
+	DryRun bool
+	// Columns maps import column names or NDJSON keys to ImportFields. Columns named as ImportFields are mapped to
+	// them if they are not mapped, other columns are ignored
+	Columns map[string]string
+}
+
+// ImportReport is a result of pets import
+type ImportReport struct {
+	// Format is an import data format
+	Format ImportFormat `json:"format"`
+	// DryRun is true if rows were only validated
+	DryRun bool `json:"dry_run"`
+	// Total is a number of read rows
+	Total int `json:"total"`
+	// Imported is a number of added pets. Number of valid rows if DryRun is true
+	Imported int `json:"imported"`
+	// CommittedLine is a line number of the last row of the added pets, rows after it are not imported if the import
+	// failed
+	CommittedLine int `json:"committed_line"`
+	// Rejected is a number of rejected rows
+	Rejected int `json:"rejected"`
+	// IgnoredColumns is a list of CSV columns or NDJSON keys which are not mapped to pet fields
+	IgnoredColumns []string `json:"ignored_columns,omitempty"`
+	// Rejects is a list of rejected rows in data order, a row can have several rejects. Only first MaxImportRejects
+	// rejects are listed
+	Rejects []*ImportReject `json:"rejects"`
+	// RejectsTruncated is true if there are more than MaxImportRejects rejects
+	RejectsTruncated bool `json:"rejects_truncated,omitempty"`
+}
+
+// ImportReject is a rejected row of pets import
+type ImportReject struct {
+	// Line is a line number of the row in the import data, starting from 1
+	Line int `json:"line"`
+	// Field is a pet field the row is rejected for. Blank if the whole row is invalid
+	Field string `json:"field,omitempty"`
+	// Reason is a reason the row is rejected
+	Reason string `json:"reason"`
+}
+
+// Reject is used to add a rejected row to the report with given invalid fields and their reasons. Row with several
+// invalid fields has a reject per field. Rejects over MaxImportRejects are not listed, so the report size is bounded
+func (r *ImportReport) Reject(line int, rejects ...*ImportReject) {
+	r.Rejected++
+
+	for _, rej := range rejects {
+		if len(r.Rejects) == MaxImportRejects {
+			r.RejectsTruncated = true
+			return
+		}
+
+		rej.Line = line
+		r.Rejects = append(r.Rejects, rej)
+	}
+}
+
+// WriteRejectsCSV is used to write report rejected rows to given writer as CSV with line, field and reason columns
+func (r *ImportReport) WriteRejectsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"line", "field", "reason"}); err != nil {
+		return err
+	}
+
+	for _, rej := range r.Rejects {
+		if err := cw.Write([]string{strconv.Itoa(rej.Line), rej.Field, rej.Reason}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// ParseImportColumns is used to get ImportOptions Columns from given "column:field" mappings. Will return error if a
+// mapping has no colon or a blank part
+func ParseImportColumns(mappings []string) (map[string]string, error) {
+	columns := make(map[string]string, len(mappings))
+
+	for _, m := range mappings {
+		col, field, ok := strings.Cut(m, ":")
+		col, field = strings.TrimSpace(col), strings.TrimSpace(field)
+
+		if !ok || col == "" || field == "" {
+			return nil, fmt.Errorf("column mapping %q should be column:field", m)
+		}
+
+		columns[col] = field
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"time"
+
+	"pets/internal/server/handlers/requests"
+)
+
+// Vaccination is a pet vaccination record model struct
+type Vaccination struct {
+	// ID is a vaccination record id
+	ID int `json:"id"`
+	// PetID is an id of the vaccinated pet
+	PetID int `json:"pet_id" db:"pet_id"`
+	// Vaccine is a vaccine name
+	Vaccine string `json:"vaccine"`
+	// GivenOn is a date the shot was given
+	GivenOn Date `json:"given_on" db:"given_on"`
+	// DueOn is a date the next shot is due. Can be nil if no next shot is needed
+	DueOn *Date `json:"due_on" db:"due_on"`
+	// Vet is a veterinarian or clinic name. Can be blank
+	Vet string `json:"vet"`
+	// Notes is a free text notes. Can be blank
+	Notes string `json:"notes"`
+	// Attachment is a reference to an attached document. Can be blank
+	Attachment string `json:"attachment"`
+	// CreatedAt is a date when record was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is a date when record was updated last time. Can be nil
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DueVaccination is a latest pet vaccination with the next shot due
+type DueVaccination struct {
+	Vaccination
+	// PetName is a name of the vaccinated pet
+	PetName string `json:"pet_name" db:"pet_name"`
+	// Overdue is true if the next shot due date has passed
+	Overdue bool `json:"overdue" db:"-"`
+}
+
+// TreatmentKind is a kind of pet treatment record
+type TreatmentKind string
+
+// Treatment kinds
+const (
+	TreatmentVisit      TreatmentKind = "visit"
+	TreatmentMedication TreatmentKind = "medication"
+	TreatmentProcedure  TreatmentKind = "procedure"
+	TreatmentOther      TreatmentKind = "other"
+)
+
+// Valid is used to check that TreatmentKind is one of known kinds
+func (k TreatmentKind) Valid() bool {
+	switch k {
+	case TreatmentVisit, TreatmentMedication, TreatmentProcedure, TreatmentOther:
+		return true
+	}
+
+	return false
+}
+
+// Treatment is a pet treatment or vet visit record model struct
+type Treatment struct {
+	// ID is a treatment record id
+	ID int `json:"id"`
+	// PetID is an id of the treated pet
+	PetID int `json:"pet_id" db:"pet_id"`
+	// Kind is a treatment kind, TreatmentOther by default
+	Kind TreatmentKind `json:"kind"`
+	// Name is a treatment, medication or visit reason name
+	Name string `json:"name"`
+	// GivenOn is a date of the treatment or visit
+	GivenOn Date `json:"given_on" db:"given_on"`
+	// Vet is a veterinarian or clinic name. Can be blank
+	Vet string `json:"vet"`
+	// Notes is a free text notes. Can be blank
+	Notes string `json:"notes"`
+	// Attachment is a reference to an attached document. Can be blank
+	Attachment string `json:"attachment"`
+	// CreatedAt is a date when record was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is a date when record was updated last time. Can be nil
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// GetVaccinationFromReq is used to get Vaccination model for the pet with given ID from given requests.VaccinationReq
+// model. Will return *DateError if any date is malformed
+func GetVaccinationFromReq(petID int, req *requests.VaccinationReq) (*Vaccination, error) {
+	v := &Vaccination{
+		PetID:      petID,
+		Vaccine:    req.Vaccine,
+		Vet:        req.Vet,
+		Notes:      req.Notes,
+		Attachment: req.Attachment,
+	}
+
+	given, err := parseReqDate("given_on", req.GivenOn)
+	if err != nil {
+		return nil, err
+	}
+
+	if given != nil {
+		v.GivenOn = *given
+	}
+
+	v.DueOn, err = parseReqDate("due_on", req.DueOn)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetTreatmentFromReq is used to get Treatment model for the pet with given ID from given requests.TreatmentReq model.
+// Will return *DateError if date is malformed
+func GetTreatmentFromReq(petID int, req *requests.TreatmentReq) (*Treatment, error) {
+	t := &Treatment{
+		PetID:      petID,
+		Kind:       TreatmentKind(req.Kind),
+		Name:       req.Name,
+		Vet:        req.Vet,
+		Notes:      req.Notes,
+		Attachment: req.Attachment,
+	}
+
+	given, err := parseReqDate("given_on", req.GivenOn)
+	if err != nil {
+		return nil, err
+	}
+
+	if given != nil {
+		t.GivenOn = *given
+	}
+
+	return t, nil
+}
+
+// SetLocal is used to set local time format
+func (v *Vaccination) SetLocal() {
+	v.CreatedAt = v.CreatedAt.Local()
+
+	if v.UpdatedAt != nil {
+		l := v.UpdatedAt.Local()
+		v.UpdatedAt = &l
+	}
+}
+
+// SetLocal is used to set local time format
+func (t *Treatment) SetLocal() {
+	t.CreatedAt = t.CreatedAt.Local()
+
+	if t.UpdatedAt != nil {
+		l := t.UpdatedAt.Local()
+		t.UpdatedAt = &l
+	}
+}
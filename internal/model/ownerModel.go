@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"pets/internal/server/handlers/requests"
+)
+
+// Owner is a pet owner model struct
+type Owner struct {
+	// ID is an owner id
+	ID int `json:"id"`
+	// Name is an owner full name
+	Name string `json:"name"`
+	// Email is an owner contact email. Can be blank if Phone is set
+	Email string `json:"email"`
+	// Phone is an owner contact phone. Can be blank if Email is set
+	Phone string `json:"phone"`
+	// Address is an owner postal address. Can be blank
+	Address string `json:"address"`
+	// CreatedAt is a date when owner was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is a date when owner was updated. Can be nil
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Ownership is a record of pet ownership transfer
+type Ownership struct {
+	// ID is a transfer record id
+	ID int `json:"id"`
+	// PetID is a transferred pet id
+	PetID int `json:"pet_id" db:"pet_id"`
+	// FromOwnerID is a previous pet owner id. Nil if pet had no owner
+	FromOwnerID *int `json:"from_owner_id" db:"from_owner_id"`
+	// ToOwnerID is a new pet owner id. Nil if pet was released from the owner
+	ToOwnerID *int `json:"to_owner_id" db:"to_owner_id"`
+	// Note is a free text transfer note. Can be blank
+	Note string `json:"note"`
+	// TransferredAt is a date when pet was transferred
+	TransferredAt time.Time `json:"transferred_at" db:"transferred_at"`
+}
+
+// GetOwnerFromReq is used to get Owner model from given requests.OwnerReq model
+func GetOwnerFromReq(req *requests.OwnerReq) *Owner {
+	return &Owner{
+		Name:    req.Name,
+		Email:   req.Email,
+		Phone:   req.Phone,
+		Address: req.Address,
+	}
+}
+
+// SetLocal is used to set local time format
+func (o *Owner) SetLocal() {
+	o.CreatedAt = o.CreatedAt.Local()
+
+	if o.UpdatedAt != nil {
+		l := o.UpdatedAt.Local()
+		o.UpdatedAt = &l
+	}
+}
+
+// SetLocal is used to set local time format
+func (o *Ownership) SetLocal() {
+	o.TransferredAt = o.TransferredAt.Local()
+}
@@ -1,27 +1,361 @@
 package model
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"pets/internal/server/handlers/requests"
 )
 
+// Species is a pet species
+type Species string
+
+// Pet species
+const (
+	SpeciesDog     Species = "dog"
+	SpeciesCat     Species = "cat"
+	SpeciesRabbit  Species = "rabbit"
+	SpeciesBird    Species = "bird"
+	SpeciesRodent  Species = "rodent"
+	SpeciesReptile Species = "reptile"
+	SpeciesOther   Species = "other"
+)
+
+// Valid is used to check that Species is one of known species
+func (s Species) Valid() bool {
+	switch s {
+	case SpeciesDog, SpeciesCat, SpeciesRabbit, SpeciesBird, SpeciesRodent, SpeciesReptile, SpeciesOther:
+		return true
+	}
+
+	return false
+}
+
+// Sex is a pet sex
+type Sex string
+
+// Pet sexes
+const (
+	SexMale    Sex = "male"
+	SexFemale  Sex = "female"
+	SexUnknown Sex = "unknown"
+)
+
+// Valid is used to check that Sex is one of known sexes
+func (s Sex) Valid() bool {
+	switch s {
+	case SexMale, SexFemale, SexUnknown:
+		return true
+	}
+
+	return false
+}
+
+// Status is a pet lifecycle status in the shelter
+type Status string
+
+// Pet statuses
+const (
+	StatusAvailable Status = "available"
+	StatusPending   Status = "pending"
+	StatusReserved  Status = "reserved"
+	StatusAdopted   Status = "adopted"
+	StatusReturned  Status = "returned"
+	StatusArchived  Status = "archived"
+)
+
+// statusTransitions is a map of pet statuses to statuses pet can be moved to from them
+var statusTransitions = map[Status][]Status{
+	StatusAvailable: {StatusPending, StatusReserved, StatusArchived},
+	StatusPending:   {StatusAvailable, StatusArchived},
+	StatusReserved:  {StatusAvailable, StatusAdopted},
+	StatusAdopted:   {StatusReturned},
+	StatusReturned:  {StatusAvailable, StatusArchived},
+	StatusArchived:  {StatusAvailable},
+}
+
+// Valid is used to check that Status is one of known statuses
+func (s Status) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// CanTransition is used to check that pet can be moved from the status to given one
+func (s Status) CanTransition(to Status) bool {
+	for _, t := range statusTransitions[s] {
+		if t == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Adoption is true for statuses managed by the adoption workflow only: reserved, adopted and returned
+func (s Status) Adoption() bool {
+	return s == StatusReserved || s == StatusAdopted || s == StatusReturned
+}
+
 // Pet is a pet model struct
 type Pet struct {
 	// ID is a pet id
 	ID int `json:"id"`
 	// Name is a pet name
 	Name string `json:"name"`
+	// Species is a pet species, SpeciesOther by default
+	Species Species `json:"species"`
+	// Breed is a pet breed. Can be blank
+	Breed string `json:"breed"`
+	// BirthDate is a pet birth date. Can be nil if unknown
+	BirthDate *Date `json:"birth_date" db:"birth_date"`
+	// Age is a pet age computed from BirthDate. Nil if birth date is unknown
+	Age *Age `json:"age,omitempty" db:"-"`
+	// Sex is a pet sex, SexUnknown by default
+	Sex Sex `json:"sex"`
+	// Neutered is true if pet is neutered or spayed
+	Neutered bool `json:"neutered"`
+	// Weight is a pet weight in kilograms. Can be nil if unknown
+	Weight *float64 `json:"weight"`
+	// Color is a pet coat color. Can be blank
+	Color string `json:"color"`
+	// Description is a free text pet description. Can be blank
+	Description string `json:"description"`
+	// Status is a pet lifecycle status, StatusAvailable by default. Changed by transitions only, see Status.CanTransition
+	Status Status `json:"status"`
+	// OwnerID is a current pet owner id. Nil if pet has no owner. Changed by ownership transfer only
+	OwnerID *int `json:"owner_id" db:"owner_id"`
+	// PrimaryPhotoID is an id of the pet primary photo. Nil if pet has no photos. Changed by photo upload and delete only
+	PrimaryPhotoID *int `json:"-" db:"primary_photo_id"`
+	// PhotoURL is an API path of the pet primary photo content. Nil if pet has no photos
+	PhotoURL *string `json:"photo_url" db:"-"`
+	// Tags is a list of the pet tag names ordered by name. Changed by tags replacement only
+	Tags []string `json:"tags" db:"-"`
+	// Version is a pet version incremented on every pet change, starting from 1. It is used as the pet ETag
+	Version int `json:"version"`
 	// CreatedAt is a date when pet was created
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	// UpdatedAt is a date when pet was updated. Can be nil
 	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+	// DeletedAt is a date when pet was soft deleted. Nil if pet is not deleted. Changed by delete and restore only
+	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at"`
 }
 
-// GetPetFromReq is used to get Pet model from given requests.AddPetReq model
-func GetPetFromReq(req *requests.AddPetReq) *Pet {
-	return &Pet{
-		Name: req.Name,
+// Age is a pet age in full years and months
+type Age struct {
+	Years  int `json:"years"`
+	Months int `json:"months"`
+}
+
+// GetPetFromReq is used to get Pet model from given requests.AddPetReq model. Will return error if birth date is not
+// a date
+func GetPetFromReq(req *requests.AddPetReq) (*Pet, error) {
+	pet := &Pet{
+		Name:        req.Name,
+		Species:     Species(req.Species),
+		Breed:       req.Breed,
+		Sex:         Sex(req.Sex),
+		Neutered:    req.Neutered,
+		Weight:      req.Weight,
+		Color:       req.Color,
+		Description: req.Description,
+		Status:      Status(req.Status),
+	}
+
+	if req.BirthDate != "" {
+		d, err := ParseDate(req.BirthDate)
+		if err != nil {
+			return nil, err
+		}
+
+		pet.BirthDate = &d
+	}
+
+	return pet, nil
+}
+
+// ApplyUpdateReq is used to set fields given in requests.UpdateByIDReq to the Pet. Name is always set, other fields
+// are set only if they are given. Will return error if birth date is not a date
+func (p *Pet) ApplyUpdateReq(req *requests.UpdateByIDReq) error {
+	p.Name = req.Name
+
+	if req.BirthDate != nil {
+		if *req.BirthDate == "" {
+			p.BirthDate = nil
+		} else {
+			d, err := ParseDate(*req.BirthDate)
+			if err != nil {
+				return err
+			}
+
+			p.BirthDate = &d
+		}
+	}
+
+	if req.Species != nil {
+		p.Species = Species(*req.Species)
+	}
+
+	if req.Breed != nil {
+		p.Breed = *req.Breed
+	}
+
+	if req.Sex != nil {
+		p.Sex = Sex(*req.Sex)
+	}
+
+	if req.Neutered != nil {
+		p.Neutered = *req.Neutered
+	}
+
+	if req.Weight != nil {
+		p.Weight = req.Weight
+	}
+
+	if req.Color != nil {
+		p.Color = *req.Color
+	}
+
+	if req.Description != nil {
+		p.Description = *req.Description
+	}
+
+	if req.Status != nil {
+		p.Status = Status(*req.Status)
+	}
+
+	return nil
+}
+
+// ReplaceWithReq is used to replace the Pet fields changed by the client with the fields given in
+// requests.UpdateByIDReq. Fields not given are reset to their zero values, so SetDefaults sets defaults for them. Status
+// is kept unless it is given as it is changed by transitions. Will return error if birth date is not a date
+func (p *Pet) ReplaceWithReq(req *requests.UpdateByIDReq) error {
+	p.Species, p.Breed, p.BirthDate, p.Sex, p.Neutered = "", "", nil, "", false
+	p.Weight, p.Color, p.Description = nil, "", ""
+
+	return p.ApplyUpdateReq(req)
+}
+
+// PetFields is a list of Pet fields changed by the client, named as the DB columns. Status is not listed as it is
+// changed by transitions only
+var PetFields = []string{"name", "species", "breed", "birth_date", "sex", "neutered", "weight", "color", "description"}
+
+// ToReq is used to get requests.AddPetReq with the Pet fields changed by the client. It is a document JSON patches are
+// applied to
+func (p *Pet) ToReq() *requests.AddPetReq {
+	req := &requests.AddPetReq{
+		Name:        p.Name,
+		Species:     string(p.Species),
+		Breed:       p.Breed,
+		Sex:         string(p.Sex),
+		Neutered:    p.Neutered,
+		Weight:      p.Weight,
+		Color:       p.Color,
+		Description: p.Description,
+		Status:      string(p.Status),
+	}
+
+	if p.BirthDate != nil {
+		req.BirthDate = p.BirthDate.String()
+	}
+
+	return req
+}
+
+// Field is used to get value of given PetFields field. Will return false if the field is unknown
+func (p *Pet) Field(name string) (interface{}, bool) {
+	switch name {
+	case "name":
+		return p.Name, true
+	case "species":
+		return p.Species, true
+	case "breed":
+		return p.Breed, true
+	case "birth_date":
+		return p.BirthDate, true
+	case "sex":
+		return p.Sex, true
+	case "neutered":
+		return p.Neutered, true
+	case "weight":
+		return p.Weight, true
+	case "color":
+		return p.Color, true
+	case "description":
+		return p.Description, true
+	}
+
+	return nil, false
+}
+
+// CopyFields is used to set given PetFields fields of the Pet from given pet. Unknown fields are ignored
+func (p *Pet) CopyFields(from *Pet, fields []string) {
+	for _, f := range fields {
+		switch f {
+		case "name":
+			p.Name = from.Name
+		case "species":
+			p.Species = from.Species
+		case "breed":
+			p.Breed = from.Breed
+		case "birth_date":
+			p.BirthDate = from.BirthDate
+		case "sex":
+			p.Sex = from.Sex
+		case "neutered":
+			p.Neutered = from.Neutered
+		case "weight":
+			p.Weight = from.Weight
+		case "color":
+			p.Color = from.Color
+		case "description":
+			p.Description = from.Description
+		}
+	}
+}
+
+// PetPatch is a change of given fields of the pet
+type PetPatch struct {
+	// Pet is the changed pet, only Fields are saved. Version is checked if set
+	Pet *Pet
+	// Fields is a list of changed PetFields fields
+	Fields []string
+}
+
+// Changes is used to get PetFields fields which values differ in the Pet and given one. Values are compared by their
+// JSON representation, so equal dates and weights given by different pointers are not changes
+func (p *Pet) Changes(to *Pet) []string {
+	var fields []string
+
+	for _, f := range PetFields {
+		from, _ := p.Field(f)
+		v, _ := to.Field(f)
+
+		a, errA := json.Marshal(from)
+		b, errB := json.Marshal(v)
+
+		if errA != nil || errB != nil || !bytes.Equal(a, b) {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// SetDefaults is used to set default values of blank Species, Sex and Status
+func (p *Pet) SetDefaults() {
+	if p.Species == "" {
+		p.Species = SpeciesOther
+	}
+
+	if p.Sex == "" {
+		p.Sex = SexUnknown
+	}
+
+	if p.Status == "" {
+		p.Status = StatusAvailable
 	}
 }
 
@@ -33,4 +367,50 @@ func (p *Pet) SetLocal() {
 		l := p.UpdatedAt.Local()
 		p.UpdatedAt = &l
 	}
+
+	if p.DeletedAt != nil {
+		l := p.DeletedAt.Local()
+		p.DeletedAt = &l
+	}
+}
+
+// ETag is used to get the pet entity tag in HTTP ETag header format based on the pet version
+func (p *Pet) ETag() string {
+	return fmt.Sprintf(`"%v"`, p.Version)
+}
+
+// SetPhotoURL is used to set PhotoURL from PrimaryPhotoID
+func (p *Pet) SetPhotoURL() {
+	p.PhotoURL = nil
+
+	if p.PrimaryPhotoID != nil {
+		u := PhotoPath(p.ID, *p.PrimaryPhotoID)
+		p.PhotoURL = &u
+	}
+}
+
+// SetTags is used to set Tags to given tag names. Nil names are set as empty list, so tags are always encoded as JSON
+// array
+func (p *Pet) SetTags(names []string) {
+	if names == nil {
+		names = []string{}
+	}
+
+	p.Tags = names
+}
+
+// SetAge is used to compute pet Age from BirthDate at given time. Age is nil if birth date is unknown or in the future
+func (p *Pet) SetAge(now time.Time) {
+	p.Age = nil
+
+	if p.BirthDate == nil || p.BirthDate.After(now) {
+		return
+	}
+
+	months := (now.Year()-p.BirthDate.Year())*12 + int(now.Month()) - int(p.BirthDate.Month())
+	if now.Day() < p.BirthDate.Day() {
+		months--
+	}
+
+	p.Age = &Age{Years: months / 12, Months: months % 12}
 }
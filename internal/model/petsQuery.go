@@ -0,0 +1,183 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NameMatch is a PetsFilter name matching mode
+type NameMatch string
+
+const (
+	// NameExact is used to match pets with the same name
+	NameExact NameMatch = "exact"
+	// NamePrefix is used to match pets with name starting with given value
+	NamePrefix NameMatch = "prefix"
+	// NameContains is used to match pets with name containing given value
+	NameContains NameMatch = "contains"
+)
+
+// Sortable pets fields
+const (
+	SortID        = "id"
+	SortName      = "name"
+	SortCreatedAt = "created_at"
+)
+
+// sortable is a whitelist of fields pets can be sorted by
+var sortable = map[string]bool{
+	SortID:        true,
+	SortName:      true,
+	SortCreatedAt: true,
+}
+
+// PetsFilter is a filter of pets list. Blank fields are ignored
+type PetsFilter struct {
+	// Name is a pet name to match in case-insensitive way using NameMatch mode
+	Name string
+	// NameMatch is a Name matching mode. NameExact if blank
+	NameMatch NameMatch
+	// CreatedAfter is used to get pets created strictly after given time
+	CreatedAfter *time.Time
+	// CreatedBefore is used to get pets created strictly before given time
+	CreatedBefore *time.Time
+	// UpdatedSince is used to get pets updated at given time or later. Never updated pets are excluded
+	UpdatedSince *time.Time
+	// IDs is used to get pets with given IDs only
+	IDs []int
+	// Species is used to get pets of any of given species
+	Species []Species
+	// Statuses is used to get pets in any of given statuses
+	Statuses []Status
+	// Sex is used to get pets of given sex
+	Sex Sex
+	// Neutered is used to get neutered or not neutered pets
+	Neutered *bool
+	// Breed is a pet breed to match in case-insensitive way
+	Breed string
+	// Color is a pet color to match in case-insensitive way
+	Color string
+	// Description is used to get pets with description containing given value in case-insensitive way
+	Description string
+	// BornAfter is used to get pets born strictly after given date. Pets with unknown birth date are excluded
+	BornAfter *Date
+	// BornBefore is used to get pets born strictly before given date. Pets with unknown birth date are excluded
+	BornBefore *Date
+	// MinWeight is used to get pets weighing given kilograms or more. Pets with unknown weight are excluded
+	MinWeight *float64
+	// MaxWeight is used to get pets weighing given kilograms or less. Pets with unknown weight are excluded
+	MaxWeight *float64
+	// OwnerID is used to get pets of given owner only
+	OwnerID *int
+	// Tags is used to get pets having tags with given names using TagMatch mode
+	Tags []string
+	// TagMatch is a Tags matching mode. TagAny if blank
+	TagMatch TagMatch
+	// IncludeDeleted is used to get soft deleted pets too. Deleted pets are excluded by default
+	IncludeDeleted bool
+	// DeletedBefore is used to get pets soft deleted strictly before given time only
+	DeletedBefore *time.Time
+}
+
+// SortField is a pets sort field with direction
+type SortField struct {
+	// Field is one of sortable fields: SortID, SortName or SortCreatedAt
+	Field string
+	// Desc is true for descending order
+	Desc bool
+}
+
+// PetsKey is a keyset pagination position: values of sortable fields of the last seen pet
+type PetsKey struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PetsQuery is a typed query of pets list
+type PetsQuery struct {
+	// Filter is used to filter pets, total is counted with it
+	Filter PetsFilter
+	// Sort is a list of sort fields in priority order. Pets are sorted by ID ascending if blank
+	Sort []SortField
+	// Limit is a max number of pets to return. 0 limit will be ignored
+	Limit int
+	// Offset is a number of pets to skip. Ignored if After is set
+	Offset int
+	// After is used to get pets going after given position in Sort order, used for keyset pagination
+	After *PetsKey
+}
+
+// ParseSort is used to get sort fields from given string like "name,-created_at". Field with "-" prefix is sorted
+// descending. Will return error for unknown or repeated field
+func ParseSort(s string) ([]SortField, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	seen := make(map[string]bool)
+
+	for _, f := range strings.Split(s, ",") {
+		sf := SortField{Field: strings.TrimSpace(f)}
+
+		if strings.HasPrefix(sf.Field, "-") {
+			sf.Field = sf.Field[1:]
+			sf.Desc = true
+		}
+
+		if !sortable[sf.Field] {
+			return nil, fmt.Errorf("unknown sort field %q", sf.Field)
+		}
+
+		if seen[sf.Field] {
+			return nil, fmt.Errorf("repeated sort field %q", sf.Field)
+		}
+
+		seen[sf.Field] = true
+		fields = append(fields, sf)
+	}
+
+	return fields, nil
+}
+
+// SortString is used to get string of given sort fields in ParseSort format
+func SortString(fields []SortField) string {
+	s := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if f.Desc {
+			s = append(s, "-"+f.Field)
+		} else {
+			s = append(s, f.Field)
+		}
+	}
+
+	return strings.Join(s, ",")
+}
+
+// Sorting is used to get full PetsQuery sort order. ID is added as the last field if it is not sorted by, so pets
+// order is always unique
+func (q *PetsQuery) Sorting() []SortField {
+	fields := make([]SortField, 0, len(q.Sort)+1)
+
+	for _, f := range q.Sort {
+		fields = append(fields, f)
+
+		if f.Field == SortID {
+			return fields
+		}
+	}
+
+	return append(fields, SortField{Field: SortID})
+}
+
+// Key is used to get keyset pagination position of the pet
+func (p *Pet) Key() *PetsKey {
+	return &PetsKey{
+		ID:        p.ID,
+		Name:      p.Name,
+		CreatedAt: p.CreatedAt,
+	}
+}
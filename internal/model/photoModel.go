@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Photo is a pet photo model struct. Photo content and its thumbnail are kept in a blob store by BlobKey and ThumbKey
+type Photo struct {
+	// ID is a photo id
+	ID int `json:"id"`
+	// PetID is an id of the pet on the photo
+	PetID int `json:"pet_id" db:"pet_id"`
+	// BlobKey is a blob store key of the photo content
+	BlobKey string `json:"-" db:"blob_key"`
+	// ThumbKey is a blob store key of the photo thumbnail
+	ThumbKey string `json:"-" db:"thumb_key"`
+	// ContentType is a sniffed photo content type
+	ContentType string `json:"content_type" db:"content_type"`
+	// Size is a photo content size in bytes
+	Size int64 `json:"size"`
+	// Width is a photo width in pixels
+	Width int `json:"width"`
+	// Height is a photo height in pixels
+	Height int `json:"height"`
+	// Primary is true if the photo is the pet primary photo
+	Primary bool `json:"primary" db:"-"`
+	// URL is an API path of the photo content
+	URL string `json:"url" db:"-"`
+	// ThumbnailURL is an API path of the photo thumbnail
+	ThumbnailURL string `json:"thumbnail_url" db:"-"`
+	// CreatedAt is a date when photo was uploaded
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PhotoPath is used to get API path of the photo content with given pet ID and photo ID
+func PhotoPath(petID int, id int) string {
+	return fmt.Sprintf("/api/v1/pet/%v/photos/%v", petID, id)
+}
+
+// SetURLs is used to set photo URL, ThumbnailURL and Primary by given pet primary photo ID
+func (p *Photo) SetURLs(primaryID *int) {
+	p.URL = PhotoPath(p.PetID, p.ID)
+	p.ThumbnailURL = p.URL + "/thumbnail"
+	p.Primary = primaryID != nil && *primaryID == p.ID
+}
+
+// SetLocal is used to set local time format
+func (p *Photo) SetLocal() {
+	p.CreatedAt = p.CreatedAt.Local()
+}
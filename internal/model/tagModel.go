@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"pets/internal/server/handlers/requests"
+)
+
+// TagMatch is a PetsFilter tags matching mode
+type TagMatch string
+
+const (
+	// TagAny is used to match pets having any of given tags
+	TagAny TagMatch = "any"
+	// TagAll is used to match pets having all given tags
+	TagAll TagMatch = "all"
+)
+
+// Tag is a pet label model struct. Tags are assigned to pets by name
+type Tag struct {
+	// ID is a tag id
+	ID int `json:"id"`
+	// Name is a unique lower case tag name like "good-with-kids"
+	Name string `json:"name"`
+	// Category is a tag category used to group tags, e.g. "health". Can be blank
+	Category string `json:"category"`
+	// CreatedAt is a date when tag was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is a date when tag was updated. Can be nil
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// GetTagFromReq is used to get Tag model from given requests.TagReq model
+func GetTagFromReq(req *requests.TagReq) *Tag {
+	return &Tag{
+		Name:     req.Name,
+		Category: req.Category,
+	}
+}
+
+// SetLocal is used to set local time format
+func (t *Tag) SetLocal() {
+	t.CreatedAt = t.CreatedAt.Local()
+
+	if t.UpdatedAt != nil {
+		l := t.UpdatedAt.Local()
+		t.UpdatedAt = &l
+	}
+}
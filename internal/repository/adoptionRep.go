@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// applicationColumns is a list of adoption_applications table columns selected to model.Application
+const applicationColumns = `id, pet_id, applicant, contact, notes, status, reason, created_at, updated_at`
+
+// transitionColumns is a list of pet_status_history table columns selected to model.Transition
+const transitionColumns = `id, pet_id, from_status, to_status, reason, application_id, created_at`
+
+// GetApplications is used to get applications from the DB matching given filter ordered by ID
+func (r *Repository) GetApplications(ctx context.Context, filter *model.ApplicationsFilter) (apps []*model.Application, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := `SELECT ` + applicationColumns + ` FROM adoption_applications`
+
+	var where []string
+	var args []interface{}
+
+	if filter.PetID != 0 {
+		where = append(where, `pet_id = ?`)
+		args = append(args, filter.PetID)
+	}
+
+	if len(filter.Statuses) != 0 {
+		where = append(where, fmt.Sprintf(`status IN (%v)`, placeholders(len(filter.Statuses))))
+		for _, s := range filter.Statuses {
+			args = append(args, s)
+		}
+	}
+
+	if len(where) != 0 {
+		q = fmt.Sprintf("%v WHERE %v", q, strings.Join(where, " AND "))
+	}
+
+	err = r.db.SelectContext(ctx, &apps, r.db.Rebind(q+` ORDER BY id`), args...)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetApplications").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return apps, nil
+}
+
+// GetApplication is used to get application from the DB by given ID. Will return sql.ErrNoRows if application not
+// found
+func (r *Repository) GetApplication(ctx context.Context, id int) (app *model.Application, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	app = &model.Application{}
+
+	q := r.db.Rebind(`SELECT ` + applicationColumns + ` FROM adoption_applications WHERE id = ? LIMIT 1`)
+
+	err = r.db.GetContext(ctx, app, q, id)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetApplication").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// AddApplication is used to add new application to the DB. Fields id and created_at will be set automatically
+func (r *Repository) AddApplication(ctx context.Context, app *model.Application) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`INSERT INTO adoption_applications (pet_id, applicant, contact, notes, status, reason, created_at,
+		updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	app.CreatedAt = utcNow()
+
+	err := r.db.QueryRowContext(ctx, q, app.PetID, app.Applicant, app.Contact, app.Notes, app.Status, app.Reason,
+		app.CreatedAt, app.UpdatedAt).Scan(&app.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddApplication").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateApplication is used to update application status and reason if its status is still given from status. If
+// transition is not nil, the pet status transition is applied in the same transaction. Field updated_at will be set
+// automatically. Will return ErrStale if application or pet status was changed
+func (r *Repository) UpdateApplication(ctx context.Context, app *model.Application, from model.ApplicationStatus, transition *model.Transition) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateApplication").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	q := tx.Rebind(`UPDATE adoption_applications SET status = ?, reason = ?, updated_at = ? WHERE id = ? AND status = ?`)
+
+	now := utcNow()
+	app.UpdatedAt = &now
+
+	res, err := tx.ExecContext(ctx, q, app.Status, app.Reason, app.UpdatedAt, app.ID, from)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateApplication").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = stale(res); err != nil {
+		return err
+	}
+
+	if transition != nil {
+		if err = transitionPet(ctx, tx, transition); err != nil {
+			logger.Log().WithField("layer", "Repository-UpdateApplication").Errorf("err transition: %v", err.Error())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TransitionPet is used to change pet status from transition From to To if it is still From and to record the
+// transition in pet status history in one transaction. Fields id and created_at will be set automatically. Will return
+// ErrStale if pet status was changed or pet not found
+func (r *Repository) TransitionPet(ctx context.Context, transition *model.Transition) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-TransitionPet").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = transitionPet(ctx, tx, transition); err != nil {
+		logger.Log().WithField("layer", "Repository-TransitionPet").Errorf("err transition: %v", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTransitions is used to get status history of the pet with given ID from the DB, oldest transition first
+func (r *Repository) GetTransitions(ctx context.Context, petID int) (transitions []*model.Transition, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT ` + transitionColumns + ` FROM pet_status_history WHERE pet_id = ? ORDER BY id`)
+
+	err = r.db.SelectContext(ctx, &transitions, q, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetTransitions").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+// transitionPet is used to apply guarded pet status transition and record it in the pet status history and the pet
+// audit in given transaction
+func transitionPet(ctx context.Context, tx *txn, transition *model.Transition) error {
+	transition.CreatedAt = utcNow()
+
+	q := tx.Rebind(`UPDATE pets SET status = ?, updated_at = ?, version = version + 1 WHERE id = ? AND status = ?
+		RETURNING ` + petColumns)
+
+	after := &model.Pet{}
+
+	err := tx.GetContext(ctx, after, q, transition.To, transition.CreatedAt, transition.PetID, transition.From)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStale
+	}
+
+	if err != nil {
+		return err
+	}
+
+	before := *after
+	before.Status = transition.From
+
+	if err = audit(ctx, tx, model.AuditUpdate, &before, after); err != nil {
+		return err
+	}
+
+	q = tx.Rebind(`INSERT INTO pet_status_history (pet_id, from_status, to_status, reason, application_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	return tx.QueryRowContext(ctx, q, transition.PetID, transition.From, transition.To, transition.Reason,
+		transition.ApplicationID, transition.CreatedAt).Scan(&transition.ID)
+}
+
+// stale is used to check that given guarded query result affected rows. Will return ErrStale if no rows affected
+func stale(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrStale
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// auditColumns is a list of pet_audit table columns selected to model.AuditEntry
+const auditColumns = `id, pet_id, version, action, actor, request_id, diff, created_at`
+
+// GetAudit is used to get pet audit entries from the DB matching given filter, newest first. Pagination can be used by
+// setting filter limit and offset. Total is a number of entries matching the filter regardless of pagination
+func (r *Repository) GetAudit(ctx context.Context, filter *model.AuditFilter) (entries []*model.AuditEntry, total int, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	where, args := auditWhere(filter)
+
+	q := `SELECT ` + auditColumns + ` FROM pet_audit`
+	count := `SELECT COUNT(*) FROM pet_audit`
+
+	if len(where) != 0 {
+		q = fmt.Sprintf("%v WHERE %v", q, strings.Join(where, " AND "))
+		count = fmt.Sprintf("%v WHERE %v", count, strings.Join(where, " AND "))
+	}
+
+	q = r.db.Rebind(fmt.Sprintf("%v ORDER BY id DESC %v", q, r.limitOffset(filter.Limit, filter.Offset)))
+
+	err = r.db.SelectContext(ctx, &entries, q, args...)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetAudit").Errorf("err query: %v", err.Error())
+		return nil, 0, err
+	}
+
+	err = r.db.GetContext(ctx, &total, r.db.Rebind(count), args...)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetAudit").Errorf("err count query: %v", err.Error())
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// auditWhere is used to get WHERE conditions with "?" placeholders and their args for given filter
+func auditWhere(filter *model.AuditFilter) (where []string, args []interface{}) {
+	if filter.PetID != 0 {
+		where = append(where, `pet_id = ?`)
+		args = append(args, filter.PetID)
+	}
+
+	if filter.Actor != "" {
+		where = append(where, `actor = ?`)
+		args = append(args, filter.Actor)
+	}
+
+	if filter.Action != "" {
+		where = append(where, `action = ?`)
+		args = append(args, filter.Action)
+	}
+
+	if filter.RequestID != "" {
+		where = append(where, `request_id = ?`)
+		args = append(args, filter.RequestID)
+	}
+
+	if filter.Since != nil {
+		where = append(where, `created_at >= ?`)
+		args = append(args, filter.Since.UTC())
+	}
+
+	if filter.Until != nil {
+		where = append(where, `created_at < ?`)
+		args = append(args, filter.Until.UTC())
+	}
+
+	return where, args
+}
+
+// auditInsert is a multi-row pet_audit INSERT query prefix, rows values are appended with multiValues
+const auditInsert = `INSERT INTO pet_audit (pet_id, version, action, actor, request_id, diff, created_at) VALUES `
+
+// auditColumnsCount is a number of pet_audit columns set by auditInsert
+const auditColumnsCount = 7
+
+// audit is used to record the pet change made with given action in given transaction. Actor and request id are taken
+// from the context, see model.ActorFromContext. Updates which changed no audited fields are not recorded
+func audit(ctx context.Context, tx *txn, action model.AuditAction, before *model.Pet, after *model.Pet) error {
+	args, err := auditArgs(ctx, action, before, after)
+	if err != nil || args == nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(auditInsert+multiValues(1, auditColumnsCount)), args...)
+
+	return err
+}
+
+// auditArgs is used to get auditInsert args of the pet change made with given action. Will return nil args if the
+// change is not recorded
+func auditArgs(ctx context.Context, action model.AuditAction, before *model.Pet, after *model.Pet) ([]interface{}, error) {
+	diff, err := model.NewDiff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diff) == 0 && action == model.AuditUpdate {
+		return nil, nil
+	}
+
+	actor := model.ActorFromContext(ctx)
+
+	return []interface{}{after.ID, after.Version, action, actor.Name, actor.RequestID, diff, utcNow()}, nil
+}
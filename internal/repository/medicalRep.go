@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// vaccinationColumns is a list of vaccinations table columns selected to model.Vaccination
+const vaccinationColumns = `id, pet_id, vaccine, given_on, due_on, vet, notes, attachment, created_at, updated_at`
+
+// treatmentColumns is a list of treatments table columns selected to model.Treatment
+const treatmentColumns = `id, pet_id, kind, name, given_on, vet, notes, attachment, created_at, updated_at`
+
+// GetVaccinations is used to get vaccinations of the pet with given ID from the DB ordered by given_on
+func (r *Repository) GetVaccinations(ctx context.Context, petID int) (vaccinations []*model.Vaccination, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT ` + vaccinationColumns + ` FROM vaccinations WHERE pet_id = ? ORDER BY given_on, id`)
+
+	err = r.db.SelectContext(ctx, &vaccinations, q, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetVaccinations").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return vaccinations, nil
+}
+
+// GetVaccination is used to get vaccination of the pet with given pet ID from the DB by given ID. Will return
+// sql.ErrNoRows if vaccination not found
+func (r *Repository) GetVaccination(ctx context.Context, petID int, id int) (vaccination *model.Vaccination, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	vaccination = &model.Vaccination{}
+
+	q := r.db.Rebind(`SELECT ` + vaccinationColumns + ` FROM vaccinations WHERE id = ? AND pet_id = ? LIMIT 1`)
+
+	err = r.db.GetContext(ctx, vaccination, q, id, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetVaccination").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return vaccination, nil
+}
+
+// AddVaccination is used to add new vaccination to the DB. Fields id and created_at will be set automatically
+func (r *Repository) AddVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`INSERT INTO vaccinations (pet_id, vaccine, given_on, due_on, vet, notes, attachment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	vaccination.CreatedAt = utcNow()
+
+	err := r.db.QueryRowContext(ctx, q, vaccination.PetID, vaccination.Vaccine, vaccination.GivenOn, vaccination.DueOn,
+		vaccination.Vet, vaccination.Notes, vaccination.Attachment, vaccination.CreatedAt).Scan(&vaccination.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddVaccination").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateVaccination is used to update existing vaccination in the DB by given id and pet_id fields. Field updated_at
+// will be set automatically. Will return sql.ErrNoRows if vaccination not found
+func (r *Repository) UpdateVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`UPDATE vaccinations SET vaccine = ?, given_on = ?, due_on = ?, vet = ?, notes = ?, attachment = ?,
+		updated_at = ? WHERE id = ? AND pet_id = ?`)
+
+	now := utcNow()
+	vaccination.UpdatedAt = &now
+
+	res, err := r.db.ExecContext(ctx, q, vaccination.Vaccine, vaccination.GivenOn, vaccination.DueOn, vaccination.Vet,
+		vaccination.Notes, vaccination.Attachment, vaccination.UpdatedAt, vaccination.ID, vaccination.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateVaccination").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return affected(res)
+}
+
+// DeleteVaccination is used to delete vaccination from the DB by given id and pet_id fields. Will return sql.ErrNoRows
+// if vaccination not found
+func (r *Repository) DeleteVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`DELETE FROM vaccinations WHERE id = ? AND pet_id = ?`)
+
+	res, err := r.db.ExecContext(ctx, q, vaccination.ID, vaccination.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteVaccination").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return affected(res)
+}
+
+// GetDueVaccinations is used to get latest vaccinations of every pet and vaccine with the next shot due on or before
+// given date from the DB, earliest due first. Vaccinations followed by a later shot of the same vaccine and
+// vaccinations of deleted pets are skipped
+func (r *Repository) GetDueVaccinations(ctx context.Context, before model.Date) (vaccinations []*model.DueVaccination, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT v.id, v.pet_id, v.vaccine, v.given_on, v.due_on, v.vet, v.notes, v.attachment,
+		v.created_at, v.updated_at, p.name AS pet_name
+		FROM vaccinations v JOIN pets p ON p.id = v.pet_id
+		WHERE v.due_on IS NOT NULL AND v.due_on <= ? AND p.deleted_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM vaccinations l WHERE l.pet_id = v.pet_id AND l.vaccine = v.vaccine
+			AND (l.given_on > v.given_on OR (l.given_on = v.given_on AND l.id > v.id)))
+		ORDER BY v.due_on, v.id`)
+
+	err = r.db.SelectContext(ctx, &vaccinations, q, before)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetDueVaccinations").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return vaccinations, nil
+}
+
+// GetTreatments is used to get treatments of the pet with given ID from the DB ordered by given_on
+func (r *Repository) GetTreatments(ctx context.Context, petID int) (treatments []*model.Treatment, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT ` + treatmentColumns + ` FROM treatments WHERE pet_id = ? ORDER BY given_on, id`)
+
+	err = r.db.SelectContext(ctx, &treatments, q, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetTreatments").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return treatments, nil
+}
+
+// GetTreatment is used to get treatment of the pet with given pet ID from the DB by given ID. Will return
+// sql.ErrNoRows if treatment not found
+func (r *Repository) GetTreatment(ctx context.Context, petID int, id int) (treatment *model.Treatment, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	treatment = &model.Treatment{}
+
+	q := r.db.Rebind(`SELECT ` + treatmentColumns + ` FROM treatments WHERE id = ? AND pet_id = ? LIMIT 1`)
+
+	err = r.db.GetContext(ctx, treatment, q, id, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetTreatment").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return treatment, nil
+}
+
+// AddTreatment is used to add new treatment to the DB. Fields id and created_at will be set automatically
+func (r *Repository) AddTreatment(ctx context.Context, treatment *model.Treatment) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`INSERT INTO treatments (pet_id, kind, name, given_on, vet, notes, attachment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	treatment.CreatedAt = utcNow()
+
+	err := r.db.QueryRowContext(ctx, q, treatment.PetID, treatment.Kind, treatment.Name, treatment.GivenOn,
+		treatment.Vet, treatment.Notes, treatment.Attachment, treatment.CreatedAt).Scan(&treatment.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddTreatment").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTreatment is used to update existing treatment in the DB by given id and pet_id fields. Field updated_at will
+// be set automatically. Will return sql.ErrNoRows if treatment not found
+func (r *Repository) UpdateTreatment(ctx context.Context, treatment *model.Treatment) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`UPDATE treatments SET kind = ?, name = ?, given_on = ?, vet = ?, notes = ?, attachment = ?,
+		updated_at = ? WHERE id = ? AND pet_id = ?`)
+
+	now := utcNow()
+	treatment.UpdatedAt = &now
+
+	res, err := r.db.ExecContext(ctx, q, treatment.Kind, treatment.Name, treatment.GivenOn, treatment.Vet,
+		treatment.Notes, treatment.Attachment, treatment.UpdatedAt, treatment.ID, treatment.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateTreatment").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return affected(res)
+}
+
+// DeleteTreatment is used to delete treatment from the DB by given id and pet_id fields. Will return sql.ErrNoRows if
+// treatment not found
+func (r *Repository) DeleteTreatment(ctx context.Context, treatment *model.Treatment) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`DELETE FROM treatments WHERE id = ? AND pet_id = ?`)
+
+	res, err := r.db.ExecContext(ctx, q, treatment.ID, treatment.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteTreatment").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return affected(res)
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"pets/internal/model"
+)
+
+// GetApplications is used to get applications matching given filter ordered by ID
+func (r *MemoryRepository) GetApplications(ctx context.Context, filter *model.ApplicationsFilter) ([]*model.Application, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var apps []*model.Application
+
+	for _, a := range r.apps {
+		if filter.PetID != 0 && a.PetID != filter.PetID {
+			continue
+		}
+
+		if len(filter.Statuses) != 0 && !contains(filter.Statuses, a.Status) {
+			continue
+		}
+
+		apps = append(apps, copyApplication(a))
+	}
+
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].ID < apps[j].ID
+	})
+
+	return apps, nil
+}
+
+// GetApplication is used to get application by given ID. Will return sql.ErrNoRows if application not found
+func (r *MemoryRepository) GetApplication(ctx context.Context, id int) (*model.Application, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	app, ok := r.apps[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyApplication(app), nil
+}
+
+// AddApplication is used to add new application. Fields id and created_at will be set automatically
+func (r *MemoryRepository) AddApplication(ctx context.Context, app *model.Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.appSeq++
+
+	app.ID = r.appSeq
+	app.CreatedAt = time.Now()
+
+	r.apps[app.ID] = copyApplication(app)
+
+	return nil
+}
+
+// UpdateApplication is used to update application status and reason if its status is still given from status. If
+// transition is not nil, the pet status transition is applied too. Field updated_at will be set automatically. Will
+// return ErrStale if application or pet status was changed
+func (r *MemoryRepository) UpdateApplication(ctx context.Context, app *model.Application, from model.ApplicationStatus, transition *model.Transition) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.apps[app.ID]
+	if !ok || stored.Status != from {
+		return ErrStale
+	}
+
+	// pet is checked before any change, so nothing is changed if transition is stale
+	if transition != nil {
+		if pet, ok := r.pets[transition.PetID]; !ok || pet.Status != transition.From {
+			return ErrStale
+		}
+	}
+
+	now := time.Now()
+	app.UpdatedAt = &now
+
+	stored.Status = app.Status
+	stored.Reason = app.Reason
+	stored.UpdatedAt = &now
+
+	if transition != nil {
+		return r.transitionPet(ctx, transition)
+	}
+
+	return nil
+}
+
+// TransitionPet is used to change pet status from transition From to To if it is still From and to record the
+// transition in pet status history and the pet audit. Fields id and created_at will be set automatically. Will return ErrStale if pet
+// status was changed or pet not found
+func (r *MemoryRepository) TransitionPet(ctx context.Context, transition *model.Transition) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if pet, ok := r.pets[transition.PetID]; !ok || pet.Status != transition.From {
+		return ErrStale
+	}
+
+	return r.transitionPet(ctx, transition)
+}
+
+// GetTransitions is used to get status history of the pet with given ID, oldest transition first
+func (r *MemoryRepository) GetTransitions(ctx context.Context, petID int) ([]*model.Transition, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var transitions []*model.Transition
+
+	for _, t := range r.transitions {
+		if t.PetID == petID {
+			transitions = append(transitions, copyTransition(t))
+		}
+	}
+
+	return transitions, nil
+}
+
+// transitionPet is used to apply checked pet status transition and record it in the pet status history and the pet
+// audit. Lock should be held by caller
+func (r *MemoryRepository) transitionPet(ctx context.Context, transition *model.Transition) error {
+	r.transitionSeq++
+
+	transition.ID = r.transitionSeq
+	transition.CreatedAt = time.Now()
+
+	now := transition.CreatedAt
+
+	pet := r.pets[transition.PetID]
+	before := *pet
+
+	pet.Status = transition.To
+	pet.UpdatedAt = &now
+	pet.Version++
+
+	r.transitions = append(r.transitions, copyTransition(transition))
+
+	return r.record(ctx, model.AuditUpdate, &before, pet)
+}
+
+// copyApplication is used to get a copy of given application
+func copyApplication(app *model.Application) *model.Application {
+	c := *app
+
+	if app.UpdatedAt != nil {
+		u := *app.UpdatedAt
+		c.UpdatedAt = &u
+	}
+
+	return &c
+}
+
+// copyTransition is used to get a copy of given transition
+func copyTransition(t *model.Transition) *model.Transition {
+	c := *t
+
+	c.ApplicationID = copyID(t.ApplicationID)
+
+	return &c
+}
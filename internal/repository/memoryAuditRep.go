@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"pets/internal/model"
+)
+
+// GetAudit is used to get pet audit entries matching given filter, newest first. Pagination can be used by setting
+// filter limit and offset. Total is a number of entries matching the filter regardless of pagination
+func (r *MemoryRepository) GetAudit(ctx context.Context, filter *model.AuditFilter) ([]*model.AuditEntry, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*model.AuditEntry
+
+	for i := len(r.audit) - 1; i >= 0; i-- {
+		if matchAudit(r.audit[i], filter) {
+			entries = append(entries, copyAuditEntry(r.audit[i]))
+		}
+	}
+
+	total := len(entries)
+	offset := filter.Offset
+
+	if offset > len(entries) {
+		offset = len(entries)
+	}
+
+	entries = entries[offset:]
+
+	if filter.Limit > 0 && filter.Limit < len(entries) {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, total, nil
+}
+
+// matchAudit is used to check that given audit entry matches given filter
+func matchAudit(e *model.AuditEntry, filter *model.AuditFilter) bool {
+	switch {
+	case filter.PetID != 0 && e.PetID != filter.PetID,
+		filter.Actor != "" && e.Actor != filter.Actor,
+		filter.Action != "" && e.Action != filter.Action,
+		filter.RequestID != "" && e.RequestID != filter.RequestID,
+		filter.Since != nil && e.CreatedAt.Before(*filter.Since),
+		filter.Until != nil && !e.CreatedAt.Before(*filter.Until):
+		return false
+	}
+
+	return true
+}
+
+// record is used to record the pet change made with given action in the pet audit. Actor and request id are taken
+// from the context, see model.ActorFromContext. Updates which changed no audited fields are not recorded. Caller
+// should hold the lock
+func (r *MemoryRepository) record(ctx context.Context, action model.AuditAction, before *model.Pet, after *model.Pet) error {
+	diff, err := model.NewDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	if len(diff) == 0 && action == model.AuditUpdate {
+		return nil
+	}
+
+	actor := model.ActorFromContext(ctx)
+
+	r.auditSeq++
+
+	r.audit = append(r.audit, &model.AuditEntry{
+		ID:        r.auditSeq,
+		PetID:     after.ID,
+		Version:   after.Version,
+		Action:    action,
+		Actor:     actor.Name,
+		RequestID: actor.RequestID,
+		Diff:      diff,
+		CreatedAt: time.Now(),
+	})
+
+	return nil
+}
+
+// copyAuditEntry is used to get a copy of given audit entry, so stored entry can not be changed by the caller
+func copyAuditEntry(e *model.AuditEntry) *model.AuditEntry {
+	c := *e
+
+	c.Diff = make(model.Diff, len(e.Diff))
+	for field, change := range e.Diff {
+		c.Diff[field] = change
+	}
+
+	return &c
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"pets/internal/model"
+)
+
+// GetVaccinations is used to get vaccinations of the pet with given ID ordered by given_on
+func (r *MemoryRepository) GetVaccinations(ctx context.Context, petID int) ([]*model.Vaccination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var vaccinations []*model.Vaccination
+
+	for _, v := range r.vaccinations {
+		if v.PetID == petID {
+			vaccinations = append(vaccinations, copyVaccination(v))
+		}
+	}
+
+	sort.Slice(vaccinations, func(i, j int) bool {
+		return givenBefore(vaccinations[i].GivenOn, vaccinations[i].ID, vaccinations[j].GivenOn, vaccinations[j].ID)
+	})
+
+	return vaccinations, nil
+}
+
+// GetVaccination is used to get vaccination of the pet with given pet ID by given ID. Will return sql.ErrNoRows if
+// vaccination not found
+func (r *MemoryRepository) GetVaccination(ctx context.Context, petID int, id int) (*model.Vaccination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.vaccinations[id]
+	if !ok || v.PetID != petID {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyVaccination(v), nil
+}
+
+// AddVaccination is used to add new vaccination. Fields id and created_at will be set automatically
+func (r *MemoryRepository) AddVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.vaccinationSeq++
+
+	vaccination.ID = r.vaccinationSeq
+	vaccination.CreatedAt = time.Now()
+	vaccination.UpdatedAt = nil
+
+	r.vaccinations[vaccination.ID] = copyVaccination(vaccination)
+
+	return nil
+}
+
+// UpdateVaccination is used to update existing vaccination by given id and pet_id fields. Field updated_at will be set
+// automatically. Will return sql.ErrNoRows if vaccination not found
+func (r *MemoryRepository) UpdateVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.vaccinations[vaccination.ID]
+	if !ok || stored.PetID != vaccination.PetID {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now()
+	vaccination.UpdatedAt = &now
+	vaccination.CreatedAt = stored.CreatedAt
+
+	r.vaccinations[vaccination.ID] = copyVaccination(vaccination)
+
+	return nil
+}
+
+// DeleteVaccination is used to delete vaccination by given id and pet_id fields. Will return sql.ErrNoRows if
+// vaccination not found
+func (r *MemoryRepository) DeleteVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.vaccinations[vaccination.ID]
+	if !ok || stored.PetID != vaccination.PetID {
+		return sql.ErrNoRows
+	}
+
+	delete(r.vaccinations, vaccination.ID)
+
+	return nil
+}
+
+// GetDueVaccinations is used to get latest vaccinations of every pet and vaccine with the next shot due on or before
+// given date, earliest due first. Vaccinations followed by a later shot of the same vaccine and vaccinations of
+// deleted pets are skipped
+func (r *MemoryRepository) GetDueVaccinations(ctx context.Context, before model.Date) ([]*model.DueVaccination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type shot struct {
+		petID   int
+		vaccine string
+	}
+
+	latest := make(map[shot]*model.Vaccination)
+
+	for _, v := range r.vaccinations {
+		k := shot{petID: v.PetID, vaccine: v.Vaccine}
+
+		if l, ok := latest[k]; !ok || givenBefore(l.GivenOn, l.ID, v.GivenOn, v.ID) {
+			latest[k] = v
+		}
+	}
+
+	var due []*model.DueVaccination
+
+	for _, v := range latest {
+		pet, ok := r.pets[v.PetID]
+		if !ok || pet.DeletedAt != nil || v.DueOn == nil || v.DueOn.After(before.Time) {
+			continue
+		}
+
+		due = append(due, &model.DueVaccination{Vaccination: *copyVaccination(v), PetName: pet.Name})
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return givenBefore(*due[i].DueOn, due[i].ID, *due[j].DueOn, due[j].ID)
+	})
+
+	return due, nil
+}
+
+// GetTreatments is used to get treatments of the pet with given ID ordered by given_on
+func (r *MemoryRepository) GetTreatments(ctx context.Context, petID int) ([]*model.Treatment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var treatments []*model.Treatment
+
+	for _, t := range r.treatments {
+		if t.PetID == petID {
+			treatments = append(treatments, copyTreatment(t))
+		}
+	}
+
+	sort.Slice(treatments, func(i, j int) bool {
+		return givenBefore(treatments[i].GivenOn, treatments[i].ID, treatments[j].GivenOn, treatments[j].ID)
+	})
+
+	return treatments, nil
+}
+
+// GetTreatment is used to get treatment of the pet with given pet ID by given ID. Will return sql.ErrNoRows if
+// treatment not found
+func (r *MemoryRepository) GetTreatment(ctx context.Context, petID int, id int) (*model.Treatment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.treatments[id]
+	if !ok || t.PetID != petID {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyTreatment(t), nil
+}
+
+// AddTreatment is used to add new treatment. Fields id and created_at will be set automatically
+func (r *MemoryRepository) AddTreatment(ctx context.Context, treatment *model.Treatment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.treatmentSeq++
+
+	treatment.ID = r.treatmentSeq
+	treatment.CreatedAt = time.Now()
+	treatment.UpdatedAt = nil
+
+	r.treatments[treatment.ID] = copyTreatment(treatment)
+
+	return nil
+}
+
+// UpdateTreatment is used to update existing treatment by given id and pet_id fields. Field updated_at will be set
+// automatically. Will return sql.ErrNoRows if treatment not found
+func (r *MemoryRepository) UpdateTreatment(ctx context.Context, treatment *model.Treatment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.treatments[treatment.ID]
+	if !ok || stored.PetID != treatment.PetID {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now()
+	treatment.UpdatedAt = &now
+	treatment.CreatedAt = stored.CreatedAt
+
+	r.treatments[treatment.ID] = copyTreatment(treatment)
+
+	return nil
+}
+
+// DeleteTreatment is used to delete treatment by given id and pet_id fields. Will return sql.ErrNoRows if treatment
+// not found
+func (r *MemoryRepository) DeleteTreatment(ctx context.Context, treatment *model.Treatment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.treatments[treatment.ID]
+	if !ok || stored.PetID != treatment.PetID {
+		return sql.ErrNoRows
+	}
+
+	delete(r.treatments, treatment.ID)
+
+	return nil
+}
+
+// givenBefore is used to compare medical records by date and ID
+func givenBefore(a model.Date, aID int, b model.Date, bID int) bool {
+	if !a.Equal(b.Time) {
+		return a.Before(b.Time)
+	}
+
+	return aID < bID
+}
+
+// copyVaccination is used to get a copy of given vaccination
+func copyVaccination(v *model.Vaccination) *model.Vaccination {
+	c := *v
+
+	if v.DueOn != nil {
+		d := *v.DueOn
+		c.DueOn = &d
+	}
+
+	if v.UpdatedAt != nil {
+		u := *v.UpdatedAt
+		c.UpdatedAt = &u
+	}
+
+	return &c
+}
+
+// copyTreatment is used to get a copy of given treatment
+func copyTreatment(t *model.Treatment) *model.Treatment {
+	c := *t
+
+	if t.UpdatedAt != nil {
+		u := *t.UpdatedAt
+		c.UpdatedAt = &u
+	}
+
+	return &c
+}
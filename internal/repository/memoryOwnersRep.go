@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"pets/internal/model"
+)
+
+// GetOwners is used to get owners ordered by ID. 0 limit will be ignored. Total is a number of all owners regardless of
+// pagination
+func (r *MemoryRepository) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owners := make([]*model.Owner, 0, len(r.owners))
+	for _, o := range r.owners {
+		owners = append(owners, o)
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		return owners[i].ID < owners[j].ID
+	})
+
+	total := len(owners)
+
+	if offset > len(owners) {
+		offset = len(owners)
+	}
+
+	owners = owners[offset:]
+
+	if limit > 0 && limit < len(owners) {
+		owners = owners[:limit]
+	}
+
+	res := make([]*model.Owner, 0, len(owners))
+	for _, o := range owners {
+		res = append(res, copyOwner(o))
+	}
+
+	return res, total, nil
+}
+
+// GetOwner is used to get owner by given ID. Will return sql.ErrNoRows if owner not found
+func (r *MemoryRepository) GetOwner(ctx context.Context, id int) (*model.Owner, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owner, ok := r.owners[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyOwner(owner), nil
+}
+
+// AddOwner is used to add new owner. Fields id and created_at will be set automatically
+func (r *MemoryRepository) AddOwner(ctx context.Context, owner *model.Owner) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ownerSeq++
+
+	owner.ID = r.ownerSeq
+	owner.CreatedAt = time.Now()
+
+	r.owners[owner.ID] = copyOwner(owner)
+
+	return nil
+}
+
+// UpdateOwner is used to update existing owner by given id field. Field updated_at will be set automatically. Will
+// return sql.ErrNoRows if owner not found
+func (r *MemoryRepository) UpdateOwner(ctx context.Context, owner *model.Owner) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	owner.UpdatedAt = &now
+
+	stored, ok := r.owners[owner.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	upd := copyOwner(owner)
+	upd.CreatedAt = stored.CreatedAt
+
+	r.owners[owner.ID] = upd
+
+	return nil
+}
+
+// DeleteOwner is used to delete owner by given id. Pets of the owner are left without owner, ownership history keeps
+// the transfers with blank owner. Will return sql.ErrNoRows if owner not found
+func (r *MemoryRepository) DeleteOwner(ctx context.Context, owner *model.Owner) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.owners[owner.ID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.owners, owner.ID)
+
+	for _, p := range r.pets {
+		if p.OwnerID != nil && *p.OwnerID == owner.ID {
+			p.OwnerID = nil
+			p.Version++
+		}
+	}
+
+	for _, o := range r.ownership {
+		if o.FromOwnerID != nil && *o.FromOwnerID == owner.ID {
+			o.FromOwnerID = nil
+		}
+
+		if o.ToOwnerID != nil && *o.ToOwnerID == owner.ID {
+			o.ToOwnerID = nil
+		}
+	}
+
+	return nil
+}
+
+// TransferPet is used to set pet owner to transfer ToOwnerID and record the transfer in ownership history. Fields id,
+// from_owner_id and transferred_at will be set automatically. Will return sql.ErrNoRows if pet not found
+func (r *MemoryRepository) TransferPet(ctx context.Context, transfer *model.Ownership) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pet, ok := r.pets[transfer.PetID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	r.ownershipSeq++
+
+	now := time.Now()
+
+	transfer.ID = r.ownershipSeq
+	transfer.FromOwnerID = copyID(pet.OwnerID)
+	transfer.TransferredAt = now
+
+	pet.OwnerID = copyID(transfer.ToOwnerID)
+	pet.UpdatedAt = &now
+	pet.Version++
+
+	r.ownership = append(r.ownership, copyOwnership(transfer))
+
+	return nil
+}
+
+// GetOwnership is used to get ownership history of the pet with given ID, oldest transfer first
+func (r *MemoryRepository) GetOwnership(ctx context.Context, petID int) ([]*model.Ownership, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var history []*model.Ownership
+
+	for _, o := range r.ownership {
+		if o.PetID == petID {
+			history = append(history, copyOwnership(o))
+		}
+	}
+
+	return history, nil
+}
+
+// copyOwner is used to get a copy of given owner, so stored owners can not be changed outside the repository
+func copyOwner(owner *model.Owner) *model.Owner {
+	c := *owner
+
+	if owner.UpdatedAt != nil {
+		u := *owner.UpdatedAt
+		c.UpdatedAt = &u
+	}
+
+	return &c
+}
+
+// copyOwnership is used to get a copy of given ownership transfer
+func copyOwnership(o *model.Ownership) *model.Ownership {
+	c := *o
+
+	c.FromOwnerID = copyID(o.FromOwnerID)
+	c.ToOwnerID = copyID(o.ToOwnerID)
+
+	return &c
+}
+
+// copyID is used to get a copy of given optional ID
+func copyID(id *int) *int {
+	if id == nil {
+		return nil
+	}
+
+	c := *id
+
+	return &c
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"pets/internal/model"
+)
+
+// GetPhotos is used to get photos of the pet with given ID ordered by ID
+func (r *MemoryRepository) GetPhotos(ctx context.Context, petID int) ([]*model.Photo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var photos []*model.Photo
+
+	for _, p := range r.photos {
+		if p.PetID == petID {
+			photos = append(photos, copyPhoto(p))
+		}
+	}
+
+	sort.Slice(photos, func(i, j int) bool {
+		return photos[i].ID < photos[j].ID
+	})
+
+	return photos, nil
+}
+
+// GetPhoto is used to get photo of the pet with given pet ID by given ID. Will return sql.ErrNoRows if photo not found
+func (r *MemoryRepository) GetPhoto(ctx context.Context, petID int, id int) (*model.Photo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.photos[id]
+	if !ok || p.PetID != petID {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyPhoto(p), nil
+}
+
+// AddPhoto is used to add new photo and to set it as the pet primary photo if primary is true or the pet has no
+// primary photo. Fields id and created_at will be set automatically
+func (r *MemoryRepository) AddPhoto(ctx context.Context, photo *model.Photo, primary bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.photoSeq++
+
+	photo.ID = r.photoSeq
+	photo.CreatedAt = time.Now()
+
+	r.photos[photo.ID] = copyPhoto(photo)
+
+	if pet, ok := r.pets[photo.PetID]; ok && (primary || pet.PrimaryPhotoID == nil) {
+		id := photo.ID
+		pet.PrimaryPhotoID = &id
+		pet.Version++
+	}
+
+	return nil
+}
+
+// DeletePhoto is used to delete photo by given id and pet_id fields. If it was the pet primary photo, the latest
+// remaining photo becomes primary. Will return sql.ErrNoRows if photo not found
+func (r *MemoryRepository) DeletePhoto(ctx context.Context, photo *model.Photo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.photos[photo.ID]
+	if !ok || stored.PetID != photo.PetID {
+		return sql.ErrNoRows
+	}
+
+	delete(r.photos, photo.ID)
+
+	pet, ok := r.pets[photo.PetID]
+	if !ok || pet.PrimaryPhotoID == nil || *pet.PrimaryPhotoID != photo.ID {
+		return nil
+	}
+
+	pet.PrimaryPhotoID = nil
+	pet.Version++
+
+	for id, p := range r.photos {
+		if p.PetID == photo.PetID && (pet.PrimaryPhotoID == nil || id > *pet.PrimaryPhotoID) {
+			latest := id
+			pet.PrimaryPhotoID = &latest
+		}
+	}
+
+	return nil
+}
+
+// SetPrimaryPhoto is used to set photo with given id and pet_id fields as the pet primary photo. Will return
+// sql.ErrNoRows if photo not found
+func (r *MemoryRepository) SetPrimaryPhoto(ctx context.Context, photo *model.Photo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.photos[photo.ID]
+	if !ok || stored.PetID != photo.PetID {
+		return sql.ErrNoRows
+	}
+
+	pet, ok := r.pets[photo.PetID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	id := photo.ID
+	pet.PrimaryPhotoID = &id
+	pet.Version++
+
+	return nil
+}
+
+// copyPhoto is used to get a copy of given photo
+func copyPhoto(p *model.Photo) *model.Photo {
+	c := *p
+
+	// primary flag and URLs are computed by the service, they are not stored
+	c.Primary = false
+	c.URL = ""
+	c.ThumbnailURL = ""
+
+	return &c
+}
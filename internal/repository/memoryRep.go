@@ -0,0 +1,676 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// MemoryDriver is a config.DB Driver value used to select MemoryRepository
+const MemoryDriver = "memory"
+
+// MemoryRepository is an in-memory repository struct, implements IRepository interface. It is safe for concurrent use
+// and keeps the same semantics as Repository, so it can be used for demos, local development and tests without DB
+type MemoryRepository struct {
+	mu sync.RWMutex
+	memoryData
+}
+
+// memoryData is MemoryRepository stored data. It is copied by MemoryRepository.WithTx, so the transaction changes are
+// made to the copy
+type memoryData struct {
+	// seq is a last given pet ID
+	seq  int
+	pets map[int]*model.Pet
+	// ownerSeq is a last given owner ID
+	ownerSeq int
+	owners   map[int]*model.Owner
+	// ownershipSeq is a last given ownership transfer ID
+	ownershipSeq int
+	// ownership is a pets ownership history in transfers order
+	ownership []*model.Ownership
+	// appSeq is a last given adoption application ID
+	appSeq int
+	apps   map[int]*model.Application
+	// transitionSeq is a last given pet status transition ID
+	transitionSeq int
+	// transitions is a pets status history in transitions order
+	transitions []*model.Transition
+	// vaccinationSeq is a last given vaccination ID
+	vaccinationSeq int
+	vaccinations   map[int]*model.Vaccination
+	// treatmentSeq is a last given treatment ID
+	treatmentSeq int
+	treatments   map[int]*model.Treatment
+	// photoSeq is a last given photo ID
+	photoSeq int
+	photos   map[int]*model.Photo
+	// tagSeq is a last given tag ID
+	tagSeq int
+	tags   map[int]*model.Tag
+	// petTags is a map of pet IDs to IDs of their tags
+	petTags map[int][]int
+	// auditSeq is a last given pet audit entry ID
+	auditSeq int
+	// audit is a pet audit in changes order
+	audit []*model.AuditEntry
+}
+
+// NewMemoryRepository is used to get new empty MemoryRepository instance
+func NewMemoryRepository() IRepository {
+	logger.Log().WithField("layer", "MemoryRepository-Init").Infof("in-memory repository created")
+
+	return &MemoryRepository{
+		memoryData: memoryData{
+			pets:         make(map[int]*model.Pet),
+			owners:       make(map[int]*model.Owner),
+			apps:         make(map[int]*model.Application),
+			vaccinations: make(map[int]*model.Vaccination),
+			treatments:   make(map[int]*model.Treatment),
+			photos:       make(map[int]*model.Photo),
+			tags:         make(map[int]*model.Tag),
+			petTags:      make(map[int][]int),
+		},
+	}
+}
+
+// GetPet is used to get not deleted pet with its tags by given ID. Will return sql.ErrNoRows if pet not found or
+// deleted
+func (r *MemoryRepository) GetPet(ctx context.Context, id int) (*model.Pet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pet, ok := r.pets[id]
+	if !ok || pet.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	res := copyPet(pet)
+	res.Tags = r.tagNames(pet.ID)
+
+	return res, nil
+}
+
+// GetPets is used to get pets with their tags matching given query filter in query sort order. Soft deleted pets are
+// excluded unless filter IncludeDeleted or DeletedBefore is set. Pagination can be used by setting query limit and
+// offset or keyset After position. Total is a number of pets matching the filter regardless of pagination
+func (r *MemoryRepository) GetPets(ctx context.Context, query *model.PetsQuery) ([]*model.Pet, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorting := query.Sorting()
+
+	pets := make([]*model.Pet, 0, len(r.pets))
+	for _, p := range r.pets {
+		if matchPet(p, &query.Filter) && r.matchTags(p.ID, &query.Filter) {
+			pets = append(pets, p)
+		}
+	}
+
+	sort.Slice(pets, func(i, j int) bool {
+		return comparePets(pets[i], pets[j], sorting) < 0
+	})
+
+	total := len(pets)
+	offset := query.Offset
+
+	if query.After != nil {
+		after := &model.Pet{ID: query.After.ID, Name: query.After.Name, CreatedAt: query.After.CreatedAt}
+
+		// first position going after the key in sort order
+		offset = sort.Search(len(pets), func(i int) bool {
+			return comparePets(pets[i], after, sorting) > 0
+		})
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset >= total {
+		return nil, total, nil
+	}
+
+	pets = pets[offset:]
+	if query.Limit > 0 && query.Limit < len(pets) {
+		pets = pets[:query.Limit]
+	}
+
+	res := make([]*model.Pet, 0, len(pets))
+	for _, p := range pets {
+		c := copyPet(p)
+		c.Tags = r.tagNames(p.ID)
+
+		res = append(res, c)
+	}
+
+	return res, total, nil
+}
+
+// ExportPets is implementing IRepository.ExportPets function. Matching pets are copied before the function is called,
+// so the repository is not locked while the function runs
+func (r *MemoryRepository) ExportPets(ctx context.Context, query *model.PetsQuery, fn func(pet *model.Pet) error) error {
+	all := *query
+	all.Limit, all.Offset, all.After = 0, 0, nil
+
+	pets, _, err := r.GetPets(ctx, &all)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pets {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if err = fn(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchPet is used to check that given pet matches given filter
+func matchPet(pet *model.Pet, filter *model.PetsFilter) bool {
+	if filter.Name != "" {
+		name, val := strings.ToLower(pet.Name), strings.ToLower(filter.Name)
+
+		switch filter.NameMatch {
+		case model.NamePrefix:
+			if !strings.HasPrefix(name, val) {
+				return false
+			}
+		case model.NameContains:
+			if !strings.Contains(name, val) {
+				return false
+			}
+		default:
+			if name != val {
+				return false
+			}
+		}
+	}
+
+	if filter.CreatedAfter != nil && !pet.CreatedAt.After(*filter.CreatedAfter) {
+		return false
+	}
+
+	if filter.CreatedBefore != nil && !pet.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+
+	if filter.UpdatedSince != nil && (pet.UpdatedAt == nil || pet.UpdatedAt.Before(*filter.UpdatedSince)) {
+		return false
+	}
+
+	if len(filter.IDs) != 0 && !contains(filter.IDs, pet.ID) {
+		return false
+	}
+
+	if len(filter.Species) != 0 && !contains(filter.Species, pet.Species) {
+		return false
+	}
+
+	if len(filter.Statuses) != 0 && !contains(filter.Statuses, pet.Status) {
+		return false
+	}
+
+	if filter.Sex != "" && pet.Sex != filter.Sex {
+		return false
+	}
+
+	if filter.Neutered != nil && pet.Neutered != *filter.Neutered {
+		return false
+	}
+
+	if filter.Breed != "" && !strings.EqualFold(pet.Breed, filter.Breed) {
+		return false
+	}
+
+	if filter.Color != "" && !strings.EqualFold(pet.Color, filter.Color) {
+		return false
+	}
+
+	if filter.Description != "" && !strings.Contains(strings.ToLower(pet.Description), strings.ToLower(filter.Description)) {
+		return false
+	}
+
+	if filter.BornAfter != nil && (pet.BirthDate == nil || !pet.BirthDate.After(filter.BornAfter.Time)) {
+		return false
+	}
+
+	if filter.BornBefore != nil && (pet.BirthDate == nil || !pet.BirthDate.Before(filter.BornBefore.Time)) {
+		return false
+	}
+
+	if filter.MinWeight != nil && (pet.Weight == nil || *pet.Weight < *filter.MinWeight) {
+		return false
+	}
+
+	if filter.MaxWeight != nil && (pet.Weight == nil || *pet.Weight > *filter.MaxWeight) {
+		return false
+	}
+
+	if filter.OwnerID != nil && (pet.OwnerID == nil || *pet.OwnerID != *filter.OwnerID) {
+		return false
+	}
+
+	switch {
+	case filter.DeletedBefore != nil:
+		return pet.DeletedAt != nil && pet.DeletedAt.Before(*filter.DeletedBefore)
+	case !filter.IncludeDeleted:
+		return pet.DeletedAt == nil
+	}
+
+	return true
+}
+
+// contains is used to check that given slice contains given value
+func contains[T comparable](s []T, v T) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// comparePets is used to compare given pets by given sort fields. Will return negative value if a goes before b, 0 if
+// they are equal and positive value if a goes after b. Names are compared lowercased as on SQL repositories
+func comparePets(a *model.Pet, b *model.Pet, sorting []model.SortField) int {
+	for _, f := range sorting {
+		var c int
+
+		switch f.Field {
+		case model.SortName:
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case model.SortCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		default:
+			c = a.ID - b.ID
+		}
+
+		if f.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// AddPet is used to add new pet. Fields id, version and created_at will be set automatically
+func (r *MemoryRepository) AddPet(ctx context.Context, pet *model.Pet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// owner is set by TransferPet only, primary photo by photos functions only
+	stored := copyPet(pet)
+	stored.ID = r.seq + 1
+	stored.Version = 1
+	stored.CreatedAt = time.Now()
+	stored.OwnerID = nil
+	stored.PrimaryPhotoID = nil
+	stored.DeletedAt = nil
+
+	if err := r.record(ctx, model.AuditCreate, nil, stored); err != nil {
+		return err
+	}
+
+	r.seq++
+	r.pets[stored.ID] = stored
+
+	pet.ID = stored.ID
+	pet.Version = stored.Version
+	pet.CreatedAt = stored.CreatedAt
+
+	return nil
+}
+
+// AddPets is used to add given pets in one transaction as AddPet does. Fields id, version and created_at of every pet
+// will be set automatically
+func (r *MemoryRepository) AddPets(ctx context.Context, pets []*model.Pet) error {
+	return r.WithTx(ctx, func(tx IRepository) error {
+		for _, p := range pets {
+			if err := tx.AddPet(ctx, p); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdatePet is used to update existing pet by given id filed. All fields except owner, status, primary photo and
+// created_at will be updated, updated_at will be set automatically and version incremented. If version field is set,
+// pet is updated only if it is the stored version. Will return ErrStale if the stored version differs, sql.ErrNoRows
+// if pet not found or deleted
+func (r *MemoryRepository) UpdatePet(ctx context.Context, pet *model.Pet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.writablePet(pet)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	upd := copyPet(pet)
+	upd.UpdatedAt = &now
+	upd.Version = stored.Version + 1
+	upd.CreatedAt = stored.CreatedAt
+	upd.OwnerID = stored.OwnerID
+	upd.Status = stored.Status
+	upd.PrimaryPhotoID = stored.PrimaryPhotoID
+	upd.DeletedAt = nil
+
+	if err = r.record(ctx, model.AuditUpdate, stored, upd); err != nil {
+		return err
+	}
+
+	r.pets[pet.ID] = upd
+
+	pet.UpdatedAt = copyTime(upd.UpdatedAt)
+	pet.Version = upd.Version
+
+	return nil
+}
+
+// PatchPet is used to update only given fields of existing pet by given id field, fields are named as in
+// model.PetFields. Field updated_at will be set automatically and version incremented, version is checked as in
+// UpdatePet. Will return error if any field is unknown, ErrStale if the stored version differs, sql.ErrNoRows if pet
+// not found or deleted
+func (r *MemoryRepository) PatchPet(ctx context.Context, pet *model.Pet, fields []string) error {
+	return r.patchPet(ctx, pet, fields, model.AuditUpdate)
+}
+
+// RevertPet is used to update given fields of existing pet as PatchPet, the change is recorded in the pet audit as a
+// revert
+func (r *MemoryRepository) RevertPet(ctx context.Context, pet *model.Pet, fields []string) error {
+	return r.patchPet(ctx, pet, fields, model.AuditRevert)
+}
+
+// patchPet is used to update only given fields of existing pet and record the change with given audit action
+func (r *MemoryRepository) patchPet(ctx context.Context, pet *model.Pet, fields []string, action model.AuditAction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if !contains(model.PetFields, f) {
+			return fmt.Errorf("unknown pet field %q", f)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.writablePet(pet)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	upd := copyPet(stored)
+	upd.CopyFields(copyPet(pet), fields)
+	upd.UpdatedAt = &now
+	upd.Version = stored.Version + 1
+
+	if err = r.record(ctx, action, stored, upd); err != nil {
+		return err
+	}
+
+	r.pets[pet.ID] = upd
+
+	pet.UpdatedAt = copyTime(upd.UpdatedAt)
+	pet.Version = upd.Version
+
+	return nil
+}
+
+// DeletePet is used to soft delete pet by given id. Fields deleted_at and updated_at will be set automatically and
+// version incremented, pet records are kept until the pet is purged. If version field is set, pet is deleted only if it
+// is the stored version. Will return ErrStale if the stored version differs, sql.ErrNoRows if pet not found or already
+// deleted
+func (r *MemoryRepository) DeletePet(ctx context.Context, pet *model.Pet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.writablePet(pet)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	upd := copyPet(stored)
+	upd.DeletedAt = &now
+	upd.UpdatedAt = copyTime(&now)
+	upd.Version++
+
+	if err = r.record(ctx, model.AuditDelete, stored, upd); err != nil {
+		return err
+	}
+
+	r.pets[pet.ID] = upd
+
+	pet.DeletedAt = copyTime(upd.DeletedAt)
+	pet.UpdatedAt = copyTime(upd.UpdatedAt)
+	pet.Version = upd.Version
+
+	return nil
+}
+
+// writablePet is used to get stored not deleted pet by given pet id, checking its version if given pet version is
+// set. Will return ErrStale if the stored version differs, sql.ErrNoRows if pet not found or deleted. Caller should
+// hold the lock
+func (r *MemoryRepository) writablePet(pet *model.Pet) (*model.Pet, error) {
+	stored, ok := r.pets[pet.ID]
+	if !ok || stored.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	if pet.Version != 0 && pet.Version != stored.Version {
+		return nil, ErrStale
+	}
+
+	return stored, nil
+}
+
+// RestorePet is used to restore soft deleted pet by given id. Field updated_at will be set automatically and version
+// incremented. Will return sql.ErrNoRows if pet not found or not deleted
+func (r *MemoryRepository) RestorePet(ctx context.Context, pet *model.Pet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.pets[pet.ID]
+	if !ok || stored.DeletedAt == nil {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now()
+
+	upd := copyPet(stored)
+	upd.DeletedAt = nil
+	upd.UpdatedAt = &now
+	upd.Version++
+
+	if err := r.record(ctx, model.AuditRestore, stored, upd); err != nil {
+		return err
+	}
+
+	r.pets[pet.ID] = upd
+
+	pet.DeletedAt = nil
+	pet.UpdatedAt = copyTime(upd.UpdatedAt)
+	pet.Version = upd.Version
+
+	return nil
+}
+
+// PurgePet is used to hard delete soft deleted pet by given id with its ownership history, adoption applications,
+// status history, medical records, photos and tags. Will return sql.ErrNoRows if pet not found or not deleted
+func (r *MemoryRepository) PurgePet(ctx context.Context, pet *model.Pet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.pets[pet.ID]
+	if !ok || stored.DeletedAt == nil {
+		return sql.ErrNoRows
+	}
+
+	delete(r.pets, pet.ID)
+
+	// ownership history is deleted with the pet
+	history := r.ownership[:0]
+	for _, o := range r.ownership {
+		if o.PetID != pet.ID {
+			history = append(history, o)
+		}
+	}
+
+	r.ownership = history
+
+	// adoption applications and status history are deleted with the pet
+	for id, a := range r.apps {
+		if a.PetID == pet.ID {
+			delete(r.apps, id)
+		}
+	}
+
+	transitions := r.transitions[:0]
+	for _, t := range r.transitions {
+		if t.PetID != pet.ID {
+			transitions = append(transitions, t)
+		}
+	}
+
+	r.transitions = transitions
+
+	// medical records are deleted with the pet
+	for id, v := range r.vaccinations {
+		if v.PetID == pet.ID {
+			delete(r.vaccinations, id)
+		}
+	}
+
+	for id, t := range r.treatments {
+		if t.PetID == pet.ID {
+			delete(r.treatments, id)
+		}
+	}
+
+	// photos and tags are deleted with the pet
+	for id, p := range r.photos {
+		if p.PetID == pet.ID {
+			delete(r.photos, id)
+		}
+	}
+
+	delete(r.petTags, pet.ID)
+
+	return nil
+}
+
+// Stop is implementing IRepository.Stop function. In-memory data is dropped
+func (r *MemoryRepository) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pets = make(map[int]*model.Pet)
+	r.owners = make(map[int]*model.Owner)
+	r.ownership = nil
+	r.apps = make(map[int]*model.Application)
+	r.transitions = nil
+	r.vaccinations = make(map[int]*model.Vaccination)
+	r.treatments = make(map[int]*model.Treatment)
+	r.photos = make(map[int]*model.Photo)
+	r.tags = make(map[int]*model.Tag)
+	r.petTags = make(map[int][]int)
+
+	logger.Log().WithField("layer", "MemoryRepository-Stop").Infof("in-memory repository stopped")
+}
+
+// copyPet is used to get a copy of given pet, so stored pets can not be changed outside the repository
+func copyPet(pet *model.Pet) *model.Pet {
+	c := *pet
+
+	if pet.UpdatedAt != nil {
+		u := *pet.UpdatedAt
+		c.UpdatedAt = &u
+	}
+
+	if pet.BirthDate != nil {
+		d := *pet.BirthDate
+		c.BirthDate = &d
+	}
+
+	if pet.Weight != nil {
+		w := *pet.Weight
+		c.Weight = &w
+	}
+
+	c.OwnerID = copyID(pet.OwnerID)
+	c.PrimaryPhotoID = copyID(pet.PrimaryPhotoID)
+	c.DeletedAt = copyTime(pet.DeletedAt)
+
+	// age and photo URL are computed by the service, tags are kept separately, they are not stored
+	c.Age = nil
+	c.PhotoURL = nil
+	c.Tags = nil
+
+	return &c
+}
+
+// copyTime is used to get a copy of given time pointer
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	c := *t
+
+	return &c
+}
@@ -0,0 +1,14 @@
+package repository_test
+
+import (
+	"testing"
+
+	"pets/internal/repository"
+	"pets/internal/repository/repotest"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.IRepository {
+		return repository.NewMemoryRepository()
+	})
+}
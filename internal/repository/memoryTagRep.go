@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"pets/internal/model"
+)
+
+// GetTags is used to get tags ordered by name. Blank category will be ignored, otherwise only tags of given category
+// are returned
+func (r *MemoryRepository) GetTags(ctx context.Context, category string) ([]*model.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tags []*model.Tag
+
+	for _, t := range r.tags {
+		if category == "" || t.Category == category {
+			tags = append(tags, copyTag(t))
+		}
+	}
+
+	sortTags(tags)
+
+	return tags, nil
+}
+
+// GetTagsByNames is used to get tags with given names ordered by name. Unknown names are skipped
+func (r *MemoryRepository) GetTagsByNames(ctx context.Context, names []string) ([]*model.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tags []*model.Tag
+
+	for _, t := range r.tags {
+		if contains(names, t.Name) {
+			tags = append(tags, copyTag(t))
+		}
+	}
+
+	sortTags(tags)
+
+	return tags, nil
+}
+
+// GetTag is used to get tag by given ID. Will return sql.ErrNoRows if tag not found
+func (r *MemoryRepository) GetTag(ctx context.Context, id int) (*model.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tags[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyTag(t), nil
+}
+
+// AddTag is used to add new tag. Fields id and created_at will be set automatically
+func (r *MemoryRepository) AddTag(ctx context.Context, tag *model.Tag) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tagSeq++
+
+	tag.ID = r.tagSeq
+	tag.CreatedAt = time.Now()
+	tag.UpdatedAt = nil
+
+	r.tags[tag.ID] = copyTag(tag)
+
+	return nil
+}
+
+// UpdateTag is used to update existing tag by given id field. Field updated_at will be set automatically, versions of
+// pets having the tag are incremented. Will return sql.ErrNoRows if tag not found
+func (r *MemoryRepository) UpdateTag(ctx context.Context, tag *model.Tag) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tags[tag.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now()
+	tag.UpdatedAt = &now
+	tag.CreatedAt = stored.CreatedAt
+
+	r.tags[tag.ID] = copyTag(tag)
+	r.touchTagged(tag.ID)
+
+	return nil
+}
+
+// DeleteTag is used to delete tag by given id and to remove it from all pets, their versions are incremented. Will
+// return sql.ErrNoRows if tag not found
+func (r *MemoryRepository) DeleteTag(ctx context.Context, tag *model.Tag) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tags[tag.ID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.tags, tag.ID)
+	r.touchTagged(tag.ID)
+
+	for petID, ids := range r.petTags {
+		kept := make([]int, 0, len(ids))
+		for _, id := range ids {
+			if id != tag.ID {
+				kept = append(kept, id)
+			}
+		}
+
+		r.petTags[petID] = kept
+	}
+
+	return nil
+}
+
+// SetPetTags is used to replace tags of the pet with given ID by tags with given names, the pet version is incremented.
+// Unknown names are skipped. Will return sql.ErrNoRows if pet not found or deleted
+func (r *MemoryRepository) SetPetTags(ctx context.Context, petID int, names []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pet, ok := r.pets[petID]
+	if !ok || pet.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+
+	pet.Version++
+
+	var ids []int
+
+	for id, t := range r.tags {
+		if contains(names, t.Name) {
+			ids = append(ids, id)
+		}
+	}
+
+	r.petTags[petID] = ids
+
+	return nil
+}
+
+// touchTagged is used to increment versions of pets having the tag with given ID. Caller should hold the lock
+func (r *MemoryRepository) touchTagged(tagID int) {
+	for petID, ids := range r.petTags {
+		if p, ok := r.pets[petID]; ok && contains(ids, tagID) {
+			p.Version++
+		}
+	}
+}
+
+// tagNames is used to get tag names of the pet with given ID ordered by name. Caller should hold the lock
+func (r *MemoryRepository) tagNames(petID int) []string {
+	var names []string
+
+	for _, id := range r.petTags[petID] {
+		if t, ok := r.tags[id]; ok {
+			names = append(names, t.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// matchTags is used to check that the pet with given ID has filter tags in filter TagMatch mode. Caller should hold
+// the lock
+func (r *MemoryRepository) matchTags(petID int, filter *model.PetsFilter) bool {
+	if len(filter.Tags) == 0 {
+		return true
+	}
+
+	names := r.tagNames(petID)
+
+	for _, t := range filter.Tags {
+		found := contains(names, t)
+
+		if filter.TagMatch == model.TagAll && !found {
+			return false
+		}
+
+		if filter.TagMatch != model.TagAll && found {
+			return true
+		}
+	}
+
+	return filter.TagMatch == model.TagAll
+}
+
+// sortTags is used to sort given tags by name
+func sortTags(tags []*model.Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// copyTag is used to get a copy of given tag
+func copyTag(t *model.Tag) *model.Tag {
+	c := *t
+
+	if t.UpdatedAt != nil {
+		u := *t.UpdatedAt
+		c.UpdatedAt = &u
+	}
+
+	return &c
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"slices"
+)
+
+// WithTx is implementing IRepository.WithTx function. Transactions are serializable: the repository is locked while
+// given function runs, the function changes are made to a copy of the data which replaces the data on commit. Function
+// should use the given transaction repository only, calls to the locked repository will block
+func (r *MemoryRepository) WithTx(ctx context.Context, fn func(tx IRepository) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &MemoryRepository{memoryData: r.memoryData.copy()}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	r.memoryData = tx.memoryData
+
+	return nil
+}
+
+// copy is used to get a deep copy of the data, so changes of the copy do not change the data
+func (d *memoryData) copy() memoryData {
+	c := *d
+
+	c.pets = copyMap(d.pets, copyPet)
+	c.owners = copyMap(d.owners, copyOwner)
+	c.ownership = copySlice(d.ownership, copyOwnership)
+	c.apps = copyMap(d.apps, copyApplication)
+	c.transitions = copySlice(d.transitions, copyTransition)
+	c.vaccinations = copyMap(d.vaccinations, copyVaccination)
+	c.treatments = copyMap(d.treatments, copyTreatment)
+	c.photos = copyMap(d.photos, copyPhoto)
+	c.tags = copyMap(d.tags, copyTag)
+	c.petTags = copyMap(d.petTags, slices.Clone[[]int])
+	c.audit = copySlice(d.audit, copyAuditEntry)
+
+	return c
+}
+
+// copyMap is used to get a copy of given map with values copied by given function
+func copyMap[V any](m map[int]V, copyValue func(V) V) map[int]V {
+	c := make(map[int]V, len(m))
+
+	for k, v := range m {
+		c[k] = copyValue(v)
+	}
+
+	return c
+}
+
+// copySlice is used to get a copy of given slice with values copied by given function
+func copySlice[V any](s []V, copyValue func(V) V) []V {
+	if s == nil {
+		return nil
+	}
+
+	c := make([]V, len(s))
+
+	for i, v := range s {
+		c[i] = copyValue(v)
+	}
+
+	return c
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"pets/migrations"
+	"pets/pkg/logger"
+)
+
+// migrationDirs is a map of driver name to the migrations.FS dir with driver migrations
+var migrationDirs = map[string]string{
+	PostgresDriver: "postgres",
+	SQLiteDriver:   "sqlite",
+}
+
+// Migrator is used to apply embedded migrations.FS schema migrations to the DB. Applied versions are stored in
+// schema_migrations table, compatible with migrate/migrate CLI
+type Migrator struct {
+	m      *migrate.Migrate
+	driver string
+	// latest is the latest embedded migration version
+	latest uint
+}
+
+// NewMigrator is used to get new Migrator instance working on given DB connection. Migrator.Close should be called
+// when migrations are done, given DB connection is not closed by Migrator
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	dir, ok := migrationDirs[driver]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for %v driver", driver)
+	}
+
+	src, err := iofs.New(migrations.FS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("err open migrations: %w", err)
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbDriver database.Driver
+
+	switch driver {
+	case PostgresDriver:
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("err get db conn: %w", err)
+		}
+
+		dbDriver, err = postgres.WithConnection(context.Background(), conn, &postgres.Config{})
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("err init migrations: %w", err)
+		}
+	case SQLiteDriver:
+		dbDriver, err = sqlite.WithInstance(db, &sqlite.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("err init migrations: %w", err)
+		}
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driver, dbDriver)
+	if err != nil {
+		return nil, fmt.Errorf("err init migrations: %w", err)
+	}
+
+	return &Migrator{
+		m:      m,
+		driver: driver,
+		latest: latest,
+	}, nil
+}
+
+// Up is used to apply all not applied migrations
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// Down is used to roll back given number of applied migrations. If less migrations applied, all of them will be
+// rolled back
+func (m *Migrator) Down(steps int) error {
+	err := m.m.Steps(-steps)
+
+	var short migrate.ErrShortLimit
+	if err == nil || errors.Is(err, migrate.ErrNoChange) || errors.Is(err, os.ErrNotExist) || errors.As(err, &short) {
+		return nil
+	}
+
+	return err
+}
+
+// Version is used to get current DB schema version. Will return 0 version if no migrations applied. Dirty is true if
+// the last migration failed
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// Latest is used to get the latest embedded migration version
+func (m *Migrator) Latest() uint {
+	return m.latest
+}
+
+// Check is used to check that DB schema is migrated to the latest version and is not dirty
+func (m *Migrator) Check() error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("err get schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("schema version %v is dirty, fix the DB and force the version", version)
+	}
+
+	if version < m.latest {
+		return fmt.Errorf("schema version %v is behind %v, run migrations", version, m.latest)
+	}
+
+	return nil
+}
+
+// Close is used to release Migrator resources. DB connection given to NewMigrator is not closed
+func (m *Migrator) Close() {
+	// sqlite migrate driver closes the whole DB, it holds no own resources
+	if m.driver == SQLiteDriver {
+		return
+	}
+
+	if _, err := m.m.Close(); err != nil {
+		logger.Log().WithField("layer", "Migrator-Close").Warningf("err closing migrator: %v", err.Error())
+	}
+}
+
+// latestVersion is used to get the latest migration version from given source
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("err read migrations: %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("err read migrations: %w", err)
+		}
+
+		version = next
+	}
+}
@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/repository"
+)
+
+func TestMigrator_SQLite(t *testing.T) {
+	db, err := sqlx.Open(repository.SQLiteDriver, ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	m, err := repository.NewMigrator(db.DB, repository.SQLiteDriver)
+	require.NoError(t, err)
+	defer m.Close()
+
+	require.Greater(t, m.Latest(), uint(0))
+
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.Equal(t, uint(0), version)
+	require.False(t, dirty)
+	require.Error(t, m.Check())
+
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Up())
+
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	require.Equal(t, m.Latest(), version)
+	require.NoError(t, m.Check())
+
+	require.NoError(t, m.Down(100))
+
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	require.Equal(t, uint(0), version)
+
+	require.NoError(t, m.Down(1))
+}
+
+func TestNewMigrator_UnknownDriver(t *testing.T) {
+	_, err := repository.NewMigrator(nil, "mysql")
+	require.Error(t, err)
+}
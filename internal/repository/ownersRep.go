@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// ownerColumns is a list of owners table columns selected to model.Owner
+const ownerColumns = `id, name, email, phone, address, created_at, updated_at`
+
+// ownershipColumns is a list of pet_ownership table columns selected to model.Ownership
+const ownershipColumns = `id, pet_id, from_owner_id, to_owner_id, note, transferred_at`
+
+// GetOwners is used to get owners ordered by ID. 0 limit will be ignored. Total is a number of all owners regardless of
+// pagination
+func (r *Repository) GetOwners(ctx context.Context, limit int, offset int) (owners []*model.Owner, total int, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT ` + ownerColumns + ` FROM owners ORDER BY id ` + r.limitOffset(limit, offset))
+
+	err = r.db.SelectContext(ctx, &owners, q)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetOwners").Errorf("err query: %v", err.Error())
+		return nil, 0, err
+	}
+
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM owners`)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetOwners").Errorf("err count query: %v", err.Error())
+		return nil, 0, err
+	}
+
+	return owners, total, nil
+}
+
+// GetOwner is used to get owner by given ID. Will return sql.ErrNoRows if owner not found
+func (r *Repository) GetOwner(ctx context.Context, id int) (owner *model.Owner, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	owner = &model.Owner{}
+
+	q := r.db.Rebind(`SELECT ` + ownerColumns + ` FROM owners WHERE id = ? LIMIT 1`)
+
+	err = r.db.GetContext(ctx, owner, q, id)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetOwner").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return owner, nil
+}
+
+// AddOwner is used to add new owner to the DB. Fields id and created_at will be set automatically
+func (r *Repository) AddOwner(ctx context.Context, owner *model.Owner) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`INSERT INTO owners (name, email, phone, address, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`)
+
+	owner.CreatedAt = utcNow()
+
+	err := r.db.QueryRowContext(ctx, q, owner.Name, owner.Email, owner.Phone, owner.Address, owner.CreatedAt,
+		owner.UpdatedAt).Scan(&owner.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddOwner").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateOwner is used to update existing owner in the DB by given id field. Field updated_at will be set
+// automatically. Will return sql.ErrNoRows if owner not found
+func (r *Repository) UpdateOwner(ctx context.Context, owner *model.Owner) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`UPDATE owners SET name = ?, email = ?, phone = ?, address = ?, updated_at = ? WHERE id = ?`)
+
+	now := utcNow()
+	owner.UpdatedAt = &now
+
+	res, err := r.db.ExecContext(ctx, q, owner.Name, owner.Email, owner.Phone, owner.Address, owner.UpdatedAt, owner.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateOwner").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return affected(res)
+}
+
+// DeleteOwner is used to delete owner from the DB by given id. Pets of the owner are left without owner and their
+// versions are incremented, ownership history keeps the transfers with blank owner. Will return sql.ErrNoRows if owner
+// not found
+func (r *Repository) DeleteOwner(ctx context.Context, owner *model.Owner) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteOwner").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	// pets.owner_id is not a foreign key on SQLite, so it is cleared here on both drivers
+	q := tx.Rebind(`UPDATE pets SET owner_id = NULL, version = version + 1 WHERE owner_id = ?`)
+
+	if _, err = tx.ExecContext(ctx, q, owner.ID); err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteOwner").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM owners WHERE id = ?`), owner.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteOwner").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = affected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TransferPet is used to set pet owner to transfer ToOwnerID and record the transfer in ownership history in one
+// transaction. Fields id, from_owner_id and transferred_at will be set automatically. Will return sql.ErrNoRows if pet
+// not found
+func (r *Repository) TransferPet(ctx context.Context, transfer *model.Ownership) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-TransferPet").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, &transfer.FromOwnerID, tx.Rebind(`SELECT owner_id FROM pets WHERE id = ?`), transfer.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-TransferPet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	transfer.TransferredAt = utcNow()
+
+	q := tx.Rebind(`UPDATE pets SET owner_id = ?, updated_at = ?, version = version + 1 WHERE id = ?`)
+
+	if _, err = tx.ExecContext(ctx, q, transfer.ToOwnerID, transfer.TransferredAt, transfer.PetID); err != nil {
+		logger.Log().WithField("layer", "Repository-TransferPet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	q = tx.Rebind(`INSERT INTO pet_ownership (pet_id, from_owner_id, to_owner_id, note, transferred_at)
+		VALUES (?, ?, ?, ?, ?) RETURNING id`)
+
+	err = tx.QueryRowContext(ctx, q, transfer.PetID, transfer.FromOwnerID, transfer.ToOwnerID, transfer.Note,
+		transfer.TransferredAt).Scan(&transfer.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-TransferPet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetOwnership is used to get ownership history of the pet with given ID, oldest transfer first
+func (r *Repository) GetOwnership(ctx context.Context, petID int) (history []*model.Ownership, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT ` + ownershipColumns + ` FROM pet_ownership WHERE pet_id = ? ORDER BY id`)
+
+	err = r.db.SelectContext(ctx, &history, q, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetOwnership").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -1,100 +1,796 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
-	"time"
 
 	"pets/internal/model"
 	"pets/pkg/logger"
 )
 
-// GetPet is used to get pet from DB by given ID
-func (r *Repository) GetPet(id int) (pet *model.Pet, err error) {
+// petColumns is a list of pets table columns selected to model.Pet
+const petColumns = `id, name, species, breed, birth_date, sex, neutered, weight, color, description, status, owner_id,
+	primary_photo_id, version, created_at, updated_at, deleted_at`
+
+// GetPet is used to get not deleted pet from DB with its tags by given ID
+func (r *Repository) GetPet(ctx context.Context, id int) (pet *model.Pet, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	pet = &model.Pet{}
 
-	q := `SELECT * FROM pets WHERE id = $1 LIMIT 1`
+	q := r.db.Rebind(`SELECT ` + petColumns + ` FROM pets WHERE id = ? AND deleted_at IS NULL LIMIT 1`)
 
-	err = r.db.QueryRow(q, id).Scan(&pet.ID, &pet.Name, &pet.CreatedAt, &pet.UpdatedAt)
+	err = r.db.GetContext(ctx, pet, q, id)
 	if err != nil {
 		logger.Log().WithField("layer", "Repository-GetPet").Errorf("err query: %v", err.Error())
 		return nil, err
 	}
 
+	if err = r.loadTags(ctx, pet); err != nil {
+		logger.Log().WithField("layer", "Repository-GetPet").Errorf("err tags query: %v", err.Error())
+		return nil, err
+	}
+
 	return pet, nil
 }
 
-// GetPets is used to get pet from DB. Pagination can be used by setting limit and offset values. Order should be
-// "asc" or "desc" in any register, all other values will be ignored. 0 limit will be ignored.
-func (r *Repository) GetPets(limit int, offset int, order string) (pets []*model.Pet, err error) {
-	q := `SELECT id, name, created_at, updated_at	 FROM pets`
+// sortColumns is a map of model sortable fields to pets table columns
+var sortColumns = map[string]string{
+	model.SortID:        "id",
+	model.SortName:      "name",
+	model.SortCreatedAt: "created_at",
+}
 
-	if strings.ToLower(order) == "asc" {
-		q = fmt.Sprintf("%v ORDER BY id ASC", q)
+// sortExpr is used to get ORDER BY expression of given sortable field and the placeholder expression its keyset value
+// is compared with. Names are compared lowercased byte by byte on both drivers, so pages do not depend on the database
+// locale. SQLite lower() folds ASCII letters only
+func (r *Repository) sortExpr(field string) (col string, placeholder string) {
+	if field != model.SortName {
+		return sortColumns[field], "?"
 	}
 
-	if strings.ToLower(order) == "desc" {
-		q = fmt.Sprintf("%v ORDER BY id DESC", q)
+	if r.driver == SQLiteDriver {
+		return "lower(name)", "lower(?)"
 	}
 
-	if limit != 0 {
-		q = fmt.Sprintf("%v LIMIT %v", q, limit)
+	return `lower(name) COLLATE "C"`, "lower(?)"
+}
+
+// GetPets is used to get pets from DB with their tags matching given query filter in query sort order. Soft deleted pets
+// are excluded unless filter IncludeDeleted or DeletedBefore is set. Pagination can be used by setting query limit and
+// offset or keyset After position. Total is a number of pets matching the filter regardless of pagination
+func (r *Repository) GetPets(ctx context.Context, query *model.PetsQuery) (pets []*model.Pet, total int, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	where, args := petsWhere(&query.Filter)
+	q := `SELECT ` + petColumns + ` FROM pets`
+	offset := query.Offset
+
+	if query.After != nil {
+		keyset, keyArgs := r.petsKeyset(query)
+
+		where = append(where, keyset)
+		args = append(args, keyArgs...)
+		offset = 0
+	}
+
+	if len(where) != 0 {
+		q = fmt.Sprintf("%v WHERE %v", q, strings.Join(where, " AND "))
 	}
 
-	q = fmt.Sprintf("%v OFFSET %v", q, offset)
+	q = r.db.Rebind(fmt.Sprintf("%v ORDER BY %v %v", q, r.petsOrderBy(query), r.limitOffset(query.Limit, offset)))
 
-	err = r.db.Select(&pets, q)
+	err = r.db.SelectContext(ctx, &pets, q, args...)
 	if err != nil {
 		logger.Log().WithField("layer", "Repository-GetPets").Errorf("err query: %v", err.Error())
-		return nil, err
+		return nil, 0, err
+	}
+
+	if err = r.loadTags(ctx, pets...); err != nil {
+		logger.Log().WithField("layer", "Repository-GetPets").Errorf("err tags query: %v", err.Error())
+		return nil, 0, err
+	}
+
+	total, err = r.countPets(ctx, &query.Filter)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetPets").Errorf("err count query: %v", err.Error())
+		return nil, 0, err
+	}
+
+	return pets, total, nil
+}
+
+// countPets is used to get number of pets in the DB matching given filter
+func (r *Repository) countPets(ctx context.Context, filter *model.PetsFilter) (total int, err error) {
+	q := `SELECT COUNT(*) FROM pets`
+
+	where, args := petsWhere(filter)
+	if len(where) != 0 {
+		q = fmt.Sprintf("%v WHERE %v", q, strings.Join(where, " AND "))
+	}
+
+	err = r.db.GetContext(ctx, &total, r.db.Rebind(q), args...)
+
+	return total, err
+}
+
+// exportRow is a pet row of ExportPets query with the pet tag names aggregated by the query
+type exportRow struct {
+	model.Pet
+	// TagNames is a comma-separated list of the pet tag names. Nil if pet has no tags
+	TagNames *string `db:"tag_names"`
+}
+
+// pet is used to get the row pet with its tags split from the tag names sorted
+func (row *exportRow) pet() *model.Pet {
+	if row.TagNames != nil {
+		row.Tags = strings.Split(*row.TagNames, ",")
+		sort.Strings(row.Tags)
+	}
+
+	return &row.Pet
+}
+
+// exportBatch is a number of pets read by one ExportPets query on SQLite
+const exportBatch = 500
+
+// ExportPets is implementing IRepository.ExportPets function. Tags are aggregated by the query, so the connection is
+// not used by other queries while the cursor is open. The query is not limited by Repository timeout, only by ctx.
+// SQLite has a single connection, so pets are read there in keyset paged batches limited by Repository timeout and
+// the connection is released while the function is called
+func (r *Repository) ExportPets(ctx context.Context, query *model.PetsQuery, fn func(pet *model.Pet) error) error {
+	// pagination is ignored
+	query = &model.PetsQuery{Filter: query.Filter, Sort: query.Sort}
+
+	if r.driver == SQLiteDriver {
+		return r.exportPetsBatches(ctx, query, fn)
+	}
+
+	q, args := r.exportQuery(query)
+
+	rows, err := r.db.QueryxContext(ctx, r.db.Rebind(fmt.Sprintf("%v ORDER BY %v", q, r.petsOrderBy(query))), args...)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-ExportPets").Errorf("err query: %v", err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := &exportRow{}
+		if err = rows.StructScan(row); err != nil {
+			logger.Log().WithField("layer", "Repository-ExportPets").Errorf("err scan: %v", err.Error())
+			return err
+		}
+
+		if err = fn(row.pet()); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// exportPetsBatches is used to call given function for every pet matching given query as ExportPets does, reading
+// exportBatch pets per query after the last read pet position in query sort order
+func (r *Repository) exportPetsBatches(ctx context.Context, query *model.PetsQuery, fn func(pet *model.Pet) error) error {
+	for {
+		rows, err := r.exportPage(ctx, query)
+		if err != nil {
+			logger.Log().WithField("layer", "Repository-ExportPets").Errorf("err query: %v", err.Error())
+			return err
+		}
+
+		for _, row := range rows {
+			if err = fn(row.pet()); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < exportBatch {
+			return nil
+		}
+
+		query.After = rows[len(rows)-1].Key()
 	}
+}
+
+// exportPage is used to get exportBatch export rows matching given query after its After position if it is set
+func (r *Repository) exportPage(ctx context.Context, query *model.PetsQuery) (rows []*exportRow, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q, args := r.exportQuery(query)
+	q = r.db.Rebind(fmt.Sprintf("%v ORDER BY %v LIMIT %v", q, r.petsOrderBy(query), exportBatch))
+
+	err = r.db.SelectContext(ctx, &rows, q, args...)
 
-	return pets, nil
+	return rows, err
 }
 
-// AddPet is used to add new pet to the DB. Only "name" field will be used. Fields id and created_at will be set automatically
-func (r *Repository) AddPet(pet *model.Pet) error {
-	q := `INSERT INTO pets (name, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id`
+// exportQuery is used to get ExportPets query without ORDER BY clause and its args for given query filter and keyset
+// After position if it is set
+func (r *Repository) exportQuery(query *model.PetsQuery) (string, []interface{}) {
+	agg := "string_agg(t.name, ',')"
+	if r.driver == SQLiteDriver {
+		agg = "group_concat(t.name, ',')"
+	}
+
+	where, args := petsWhere(&query.Filter)
+
+	if query.After != nil {
+		keyset, keyArgs := r.petsKeyset(query)
+
+		where = append(where, keyset)
+		args = append(args, keyArgs...)
+	}
+	q := fmt.Sprintf(`SELECT %v, (SELECT %v FROM pet_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.pet_id = pets.id)
+		AS tag_names FROM pets`, petColumns, agg)
+
+	if len(where) != 0 {
+		q = fmt.Sprintf("%v WHERE %v", q, strings.Join(where, " AND "))
+	}
+
+	return q, args
+}
+
+// AddPet is used to add new pet to the DB and record it in the pet audit. Fields id, version and created_at will be
+// set automatically
+func (r *Repository) AddPet(ctx context.Context, pet *model.Pet) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddPet").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	q := tx.Rebind(`INSERT INTO pets (name, species, breed, birth_date, sex, neutered, weight, color, description, status,
+		created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, version`)
 
-	pet.CreatedAt = time.Now()
+	pet.CreatedAt = utcNow()
 
-	err := r.db.QueryRow(q, pet.Name, pet.CreatedAt, pet.UpdatedAt).Scan(&pet.ID)
+	err = tx.QueryRowContext(ctx, q, pet.Name, pet.Species, pet.Breed, pet.BirthDate, pet.Sex, pet.Neutered, pet.Weight,
+		pet.Color, pet.Description, pet.Status, pet.CreatedAt, pet.UpdatedAt).Scan(&pet.ID, &pet.Version)
 	if err != nil {
 		logger.Log().WithField("layer", "Repository-AddPet").Errorf("err query: %v", err.Error())
 		return err
 	}
 
+	if err = audit(ctx, tx, model.AuditCreate, nil, pet); err != nil {
+		logger.Log().WithField("layer", "Repository-AddPet").Errorf("err audit: %v", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// petsBatch is a max number of pets inserted by a single multi-row INSERT, it keeps query params count under the DB
+// limits
+const petsBatch = 500
+
+// petInsertColumnsCount is a number of pets columns set by AddPets
+const petInsertColumnsCount = 12
+
+// AddPets is used to add given pets to the DB with multi-row INSERTs in one transaction and record them in the pet
+// audit. Fields id, version and created_at of every pet will be set automatically
+func (r *Repository) AddPets(ctx context.Context, pets []*model.Pet) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddPets").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(pets); start += petsBatch {
+		batch := pets[start:min(start+petsBatch, len(pets))]
+
+		if err = insertPets(ctx, tx, batch); err != nil {
+			logger.Log().WithField("layer", "Repository-AddPets").Errorf("err query: %v", err.Error())
+			return err
+		}
+
+		rows := make([]interface{}, 0, len(batch)*auditColumnsCount)
+
+		for _, p := range batch {
+			args, err := auditArgs(ctx, model.AuditCreate, nil, p)
+			if err != nil {
+				logger.Log().WithField("layer", "Repository-AddPets").Errorf("err audit: %v", err.Error())
+				return err
+			}
+
+			rows = append(rows, args...)
+		}
+
+		q := tx.Rebind(auditInsert + multiValues(len(batch), auditColumnsCount))
+
+		if _, err = tx.ExecContext(ctx, q, rows...); err != nil {
+			logger.Log().WithField("layer", "Repository-AddPets").Errorf("err audit: %v", err.Error())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertPets is used to insert given pets with a single multi-row INSERT and set their ids, versions and created_at.
+// IDs are given in rows order, so returned rows are matched to the pets by the ID order
+func insertPets(ctx context.Context, tx *txn, pets []*model.Pet) error {
+	now := utcNow()
+	args := make([]interface{}, 0, len(pets)*petInsertColumnsCount)
+
+	for _, p := range pets {
+		p.CreatedAt = now
+		args = append(args, p.Name, p.Species, p.Breed, p.BirthDate, p.Sex, p.Neutered, p.Weight, p.Color, p.Description,
+			p.Status, p.CreatedAt, p.UpdatedAt)
+	}
+
+	q := tx.Rebind(`INSERT INTO pets (name, species, breed, birth_date, sex, neutered, weight, color, description, status,
+		created_at, updated_at) VALUES ` + multiValues(len(pets), petInsertColumnsCount) + ` RETURNING id, version`)
+
+	var inserted []struct {
+		ID      int
+		Version int
+	}
+
+	if err := tx.SelectContext(ctx, &inserted, q, args...); err != nil {
+		return err
+	}
+
+	if len(inserted) != len(pets) {
+		return fmt.Errorf("inserted %v pets of %v", len(inserted), len(pets))
+	}
+
+	sort.Slice(inserted, func(i, j int) bool {
+		return inserted[i].ID < inserted[j].ID
+	})
+
+	for i, p := range pets {
+		p.ID = inserted[i].ID
+		p.Version = inserted[i].Version
+	}
+
 	return nil
 }
 
-// UpdatePet is used to update existing pet to the DB by given id filed. Only "name" field will be used. Fields id and
-// updated_at will be set automatically
-func (r *Repository) UpdatePet(pet *model.Pet) error {
-	q := `UPDATE pets SET name = $1, updated_at = $2 WHERE id=$3`
+// multiValues is used to get VALUES list of given number of rows with given number of "?" placeholders each
+func multiValues(rows int, columns int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
+
+// UpdatePet is used to update existing pet to the DB by given id filed. All fields except owner, status, primary photo
+// and created_at will be updated, updated_at will be set automatically and version incremented. If version field is
+// set, pet is updated only if it is the stored version. Will return ErrStale if the stored version differs,
+// sql.ErrNoRows if pet not found or deleted
+func (r *Repository) UpdatePet(ctx context.Context, pet *model.Pet) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	now := utcNow()
+
+	set := `name = ?, species = ?, breed = ?, birth_date = ?, sex = ?, neutered = ?, weight = ?, color = ?, description = ?,
+		updated_at = ?`
+	args := []interface{}{pet.Name, pet.Species, pet.Breed, pet.BirthDate, pet.Sex, pet.Neutered, pet.Weight, pet.Color,
+		pet.Description, now}
+
+	if err := r.writePet(ctx, pet, model.AuditUpdate, set, args); err != nil {
+		logger.Log().WithField("layer", "Repository-UpdatePet").Errorf("err query: %v", err.Error())
+		return err
+	}
 
-	now := time.Now()
 	pet.UpdatedAt = &now
 
-	_, err := r.db.Exec(q, pet.Name, pet.UpdatedAt, pet.ID)
+	return nil
+}
+
+// PatchPet is used to update only given fields of existing pet in the DB by given id field, fields are named as in
+// model.PetFields. Field updated_at will be set automatically and version incremented, version is checked as in
+// UpdatePet. Will return error if any field is unknown, ErrStale if the stored version differs, sql.ErrNoRows if pet
+// not found or deleted
+func (r *Repository) PatchPet(ctx context.Context, pet *model.Pet, fields []string) error {
+	if err := r.patchPet(ctx, pet, fields, model.AuditUpdate); err != nil {
+		logger.Log().WithField("layer", "Repository-PatchPet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RevertPet is used to update given fields of existing pet in the DB as PatchPet, the change is recorded in the pet
+// audit as a revert
+func (r *Repository) RevertPet(ctx context.Context, pet *model.Pet, fields []string) error {
+	if err := r.patchPet(ctx, pet, fields, model.AuditRevert); err != nil {
+		logger.Log().WithField("layer", "Repository-RevertPet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// patchPet is used to update only given fields of existing pet and record the change with given audit action
+func (r *Repository) patchPet(ctx context.Context, pet *model.Pet, fields []string, action model.AuditAction) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	now := utcNow()
+
+	set := ``
+	args := make([]interface{}, 0, len(fields)+1)
+
+	for _, f := range fields {
+		v, ok := pet.Field(f)
+		if !ok {
+			return fmt.Errorf("unknown pet field %q", f)
+		}
+
+		// field names are checked above, so they are safe to use as columns
+		set += f + ` = ?, `
+		args = append(args, v)
+	}
+
+	set += `updated_at = ?`
+	args = append(args, now)
+
+	if err := r.writePet(ctx, pet, action, set, args); err != nil {
+		return err
+	}
+
+	pet.UpdatedAt = &now
+
+	return nil
+}
+
+// DeletePet is used to soft delete pet in the DB by given id. Fields deleted_at and updated_at will be set
+// automatically and version incremented, pet records are kept until the pet is purged. If version field is set, pet is
+// deleted only if it is the stored version. Will return ErrStale if the stored version differs, sql.ErrNoRows if pet
+// not found or already deleted
+func (r *Repository) DeletePet(ctx context.Context, pet *model.Pet) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	now := utcNow()
+
+	if err := r.writePet(ctx, pet, model.AuditDelete, `deleted_at = ?, updated_at = ?`, []interface{}{now, now}); err != nil {
+		logger.Log().WithField("layer", "Repository-DeletePet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	pet.DeletedAt = &now
+	pet.UpdatedAt = &now
+
+	return nil
+}
+
+// RestorePet is used to restore soft deleted pet in the DB by given id. Field updated_at will be set automatically and
+// version incremented. Will return sql.ErrNoRows if pet not found or not deleted
+func (r *Repository) RestorePet(ctx context.Context, pet *model.Pet) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	now := utcNow()
+
+	if err := r.writePet(ctx, pet, model.AuditRestore, `deleted_at = NULL, updated_at = ?`, []interface{}{now}); err != nil {
+		logger.Log().WithField("layer", "Repository-RestorePet").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	pet.DeletedAt = nil
+	pet.UpdatedAt = &now
+
+	return nil
+}
+
+// writePet is used to change the pet by given SET clause with given args in a transaction and to record the change
+// with given audit action. Restored pet should be deleted, other ones not deleted. The pet row is locked by incrementing
+// its version first, guarded by the pet version if it is set, so the audit diff is made from the exact stored pet. New
+// version is set to given pet. Will return ErrStale if the stored version differs, sql.ErrNoRows if pet not found
+func (r *Repository) writePet(ctx context.Context, pet *model.Pet, action model.AuditAction, set string, args []interface{}) error {
+	tx, err := r.begin(ctx)
 	if err != nil {
-		logger.Log().WithField("layer", "Repository-UpdatePet").Errorf("err query: %v", err.Error())
 		return err
 	}
+	defer tx.Rollback()
+
+	deleted := `deleted_at IS NULL`
+	if action == model.AuditRestore {
+		deleted = `deleted_at IS NOT NULL`
+	}
+
+	q := `UPDATE pets SET version = version + 1 WHERE id = ? AND ` + deleted
+	lockArgs := []interface{}{pet.ID}
+
+	if pet.Version != 0 {
+		q += ` AND version = ?`
+		lockArgs = append(lockArgs, pet.Version)
+	}
+
+	before := &model.Pet{}
+
+	err = tx.GetContext(ctx, before, tx.Rebind(q+` RETURNING `+petColumns), lockArgs...)
+	if errors.Is(err, sql.ErrNoRows) && pet.Version != 0 {
+		var n int
+
+		// the guard failed either as pet was changed or as it does not exist
+		q = tx.Rebind(`SELECT COUNT(*) FROM pets WHERE id = ? AND ` + deleted)
+		if err = tx.GetContext(ctx, &n, q, pet.ID); err == nil && n != 0 {
+			err = ErrStale
+		} else if err == nil {
+			err = sql.ErrNoRows
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	after := &model.Pet{}
+
+	q = tx.Rebind(`UPDATE pets SET ` + set + ` WHERE id = ? RETURNING ` + petColumns)
+	if err = tx.GetContext(ctx, after, q, append(args, pet.ID)...); err != nil {
+		return err
+	}
+
+	if err = audit(ctx, tx, action, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	pet.Version = after.Version
 
 	return nil
 }
 
-// DeletePet is used to delete pet from the DB by given id
-func (r *Repository) DeletePet(pet *model.Pet) error {
-	q := `DELETE FROM pets WHERE id=$1`
+// PurgePet is used to hard delete soft deleted pet from the DB by given id with its ownership history, adoption
+// applications, status history, medical records, photos and tags. Will return sql.ErrNoRows if pet not found or not
+// deleted
+func (r *Repository) PurgePet(ctx context.Context, pet *model.Pet) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	pet.CreatedAt = time.Now()
+	q := r.db.Rebind(`DELETE FROM pets WHERE id = ? AND deleted_at IS NOT NULL`)
 
-	_, err := r.db.Exec(q, pet.ID)
+	res, err := r.db.ExecContext(ctx, q, pet.ID)
 	if err != nil {
-		logger.Log().WithField("layer", "Repository-DeletePet").Errorf("err query: %v", err.Error())
+		logger.Log().WithField("layer", "Repository-PurgePet").Errorf("err query: %v", err.Error())
 		return err
 	}
 
+	return affected(res)
+}
+
+// affected is used to check that given query result affected rows. Will return sql.ErrNoRows if no rows affected
+func affected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
+
+// limitOffset is used to get LIMIT and OFFSET clause for the Repository driver. 0 limit will be ignored. SQLite does
+// not accept OFFSET without LIMIT, so -1 limit is used there
+func (r *Repository) limitOffset(limit int, offset int) string {
+	if limit > 0 {
+		return fmt.Sprintf("LIMIT %v OFFSET %v", limit, offset)
+	}
+
+	if r.driver == SQLiteDriver {
+		return fmt.Sprintf("LIMIT -1 OFFSET %v", offset)
+	}
+
+	return fmt.Sprintf("OFFSET %v", offset)
+}
+
+// petsWhere is used to get WHERE conditions with "?" placeholders and their args for given filter
+func petsWhere(filter *model.PetsFilter) (where []string, args []interface{}) {
+	if filter.Name != "" {
+		switch filter.NameMatch {
+		case model.NamePrefix:
+			where = append(where, `LOWER(name) LIKE ? ESCAPE '\'`)
+			args = append(args, escapeLike(strings.ToLower(filter.Name))+"%")
+		case model.NameContains:
+			where = append(where, `LOWER(name) LIKE ? ESCAPE '\'`)
+			args = append(args, "%"+escapeLike(strings.ToLower(filter.Name))+"%")
+		default:
+			where = append(where, `LOWER(name) = ?`)
+			args = append(args, strings.ToLower(filter.Name))
+		}
+	}
+
+	if filter.CreatedAfter != nil {
+		where = append(where, `created_at > ?`)
+		args = append(args, filter.CreatedAfter.UTC())
+	}
+
+	if filter.CreatedBefore != nil {
+		where = append(where, `created_at < ?`)
+		args = append(args, filter.CreatedBefore.UTC())
+	}
+
+	if filter.UpdatedSince != nil {
+		where = append(where, `updated_at >= ?`)
+		args = append(args, filter.UpdatedSince.UTC())
+	}
+
+	if len(filter.IDs) != 0 {
+		where = append(where, fmt.Sprintf(`id IN (%v)`, placeholders(len(filter.IDs))))
+		for _, id := range filter.IDs {
+			args = append(args, id)
+		}
+	}
+
+	if len(filter.Species) != 0 {
+		where = append(where, fmt.Sprintf(`species IN (%v)`, placeholders(len(filter.Species))))
+		for _, s := range filter.Species {
+			args = append(args, s)
+		}
+	}
+
+	if len(filter.Statuses) != 0 {
+		where = append(where, fmt.Sprintf(`status IN (%v)`, placeholders(len(filter.Statuses))))
+		for _, s := range filter.Statuses {
+			args = append(args, s)
+		}
+	}
+
+	if filter.Sex != "" {
+		where = append(where, `sex = ?`)
+		args = append(args, filter.Sex)
+	}
+
+	if filter.Neutered != nil {
+		where = append(where, `neutered = ?`)
+		args = append(args, *filter.Neutered)
+	}
+
+	if filter.Breed != "" {
+		where = append(where, `LOWER(breed) = ?`)
+		args = append(args, strings.ToLower(filter.Breed))
+	}
+
+	if filter.Color != "" {
+		where = append(where, `LOWER(color) = ?`)
+		args = append(args, strings.ToLower(filter.Color))
+	}
+
+	if filter.Description != "" {
+		where = append(where, `LOWER(description) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(filter.Description))+"%")
+	}
+
+	if filter.BornAfter != nil {
+		where = append(where, `birth_date > ?`)
+		args = append(args, *filter.BornAfter)
+	}
+
+	if filter.BornBefore != nil {
+		where = append(where, `birth_date < ?`)
+		args = append(args, *filter.BornBefore)
+	}
+
+	if filter.MinWeight != nil {
+		where = append(where, `weight >= ?`)
+		args = append(args, *filter.MinWeight)
+	}
+
+	if filter.MaxWeight != nil {
+		where = append(where, `weight <= ?`)
+		args = append(args, *filter.MaxWeight)
+	}
+
+	if filter.OwnerID != nil {
+		where = append(where, `owner_id = ?`)
+		args = append(args, *filter.OwnerID)
+	}
+
+	switch {
+	case filter.DeletedBefore != nil:
+		where = append(where, `deleted_at < ?`)
+		args = append(args, filter.DeletedBefore.UTC())
+	case !filter.IncludeDeleted:
+		where = append(where, `deleted_at IS NULL`)
+	}
+
+	if len(filter.Tags) != 0 {
+		tags := fmt.Sprintf(`id IN (SELECT pt.pet_id FROM pet_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name IN (%v)`,
+			placeholders(len(filter.Tags)))
+
+		// every given tag should be found for the pet
+		if filter.TagMatch == model.TagAll {
+			tags += fmt.Sprintf(` GROUP BY pt.pet_id HAVING COUNT(DISTINCT t.id) = %v`, len(filter.Tags))
+		}
+
+		where = append(where, tags+`)`)
+		args = append(args, stringArgs(filter.Tags)...)
+	}
+
+	return where, args
+}
+
+// placeholders is used to get n comma separated "?" placeholders
+func placeholders(n int) string {
+	return "?" + strings.Repeat(", ?", n-1)
+}
+
+// petsKeyset is used to get WHERE condition with "?" placeholders and its args selecting pets going after query After
+// position in query sort order. For sort fields f1, f2 it is (f1 > v1) OR (f1 = v1 AND f2 > v2), "<" is used for
+// descending fields
+func (r *Repository) petsKeyset(query *model.PetsQuery) (string, []interface{}) {
+	var or []string
+	var args []interface{}
+
+	var eq []string
+	var eqArgs []interface{}
+
+	for _, f := range query.Sorting() {
+		op := ">"
+		if f.Desc {
+			op = "<"
+		}
+
+		col, ph := r.sortExpr(f.Field)
+		val := keyValue(query.After, f.Field)
+
+		or = append(or, "("+strings.Join(append(eq, fmt.Sprintf("%v %v %v", col, op, ph)), " AND ")+")")
+		args = append(append(args, eqArgs...), val)
+
+		eq = append(eq, fmt.Sprintf("%v = %v", col, ph))
+		eqArgs = append(eqArgs, val)
+	}
+
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+// petsOrderBy is used to get ORDER BY expression for given query sort order
+func (r *Repository) petsOrderBy(query *model.PetsQuery) string {
+	var order []string
+
+	for _, f := range query.Sorting() {
+		dir := "ASC"
+		if f.Desc {
+			dir = "DESC"
+		}
+
+		col, _ := r.sortExpr(f.Field)
+		order = append(order, fmt.Sprintf("%v %v", col, dir))
+	}
+
+	return strings.Join(order, ", ")
+}
+
+// keyValue is used to get value of given sortable field from keyset position
+func keyValue(key *model.PetsKey, field string) interface{} {
+	switch field {
+	case model.SortName:
+		return key.Name
+	case model.SortCreatedAt:
+		return key.CreatedAt.UTC()
+	default:
+		return key.ID
+	}
+}
+
+// escapeLike is used to escape LIKE pattern special chars in given string with "\"
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
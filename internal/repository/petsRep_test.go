@@ -0,0 +1,38 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/config"
+	"pets/internal/repository"
+	"pets/internal/repository/repotest"
+)
+
+// testDBAddrEnv is an env var with Postgres DSN used to run repository tests. Tests are skipped if it is blank.
+// Migrations will be applied and all tables will be truncated
+const testDBAddrEnv = "PETS_TEST_DB_ADDR"
+
+func TestRepository(t *testing.T) {
+	addr := os.Getenv(testDBAddrEnv)
+	if addr == "" {
+		t.Skipf("%v is not set", testDBAddrEnv)
+	}
+
+	repotest.Run(t, func(t *testing.T) repository.IRepository {
+		rep := repository.NewRepository(&config.DB{Driver: repository.PostgresDriver, Addr: addr, AutoMigrate: true})
+
+		db, err := sqlx.Open(repository.PostgresDriver, addr)
+		require.NoError(t, err)
+		defer db.Close()
+
+		_, err = db.Exec(`TRUNCATE pets, owners, pet_ownership, adoption_applications, pet_status_history, vaccinations, treatments,
+			pet_photos, tags, pet_tags, pet_audit RESTART IDENTITY`)
+		require.NoError(t, err)
+
+		return rep
+	})
+}
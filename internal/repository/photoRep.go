@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// photoColumns is a list of pet_photos table columns selected to model.Photo
+const photoColumns = `id, pet_id, blob_key, thumb_key, content_type, size, width, height, created_at`
+
+// GetPhotos is used to get photos of the pet with given ID from the DB ordered by ID
+func (r *Repository) GetPhotos(ctx context.Context, petID int) (photos []*model.Photo, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`SELECT ` + photoColumns + ` FROM pet_photos WHERE pet_id = ? ORDER BY id`)
+
+	err = r.db.SelectContext(ctx, &photos, q, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetPhotos").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return photos, nil
+}
+
+// GetPhoto is used to get photo of the pet with given pet ID from the DB by given ID. Will return sql.ErrNoRows if
+// photo not found
+func (r *Repository) GetPhoto(ctx context.Context, petID int, id int) (photo *model.Photo, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	photo = &model.Photo{}
+
+	q := r.db.Rebind(`SELECT ` + photoColumns + ` FROM pet_photos WHERE id = ? AND pet_id = ? LIMIT 1`)
+
+	err = r.db.GetContext(ctx, photo, q, id, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetPhoto").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+// AddPhoto is used to add new photo to the DB and to set it as the pet primary photo if primary is true or the pet has
+// no primary photo in one transaction. Fields id and created_at will be set automatically
+func (r *Repository) AddPhoto(ctx context.Context, photo *model.Photo, primary bool) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddPhoto").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	q := tx.Rebind(`INSERT INTO pet_photos (pet_id, blob_key, thumb_key, content_type, size, width, height, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+
+	photo.CreatedAt = utcNow()
+
+	err = tx.QueryRowContext(ctx, q, photo.PetID, photo.BlobKey, photo.ThumbKey, photo.ContentType, photo.Size,
+		photo.Width, photo.Height, photo.CreatedAt).Scan(&photo.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddPhoto").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	q = `UPDATE pets SET primary_photo_id = ?, version = version + 1 WHERE id = ?`
+	if !primary {
+		q += ` AND primary_photo_id IS NULL`
+	}
+
+	if _, err = tx.ExecContext(ctx, tx.Rebind(q), photo.ID, photo.PetID); err != nil {
+		logger.Log().WithField("layer", "Repository-AddPhoto").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeletePhoto is used to delete photo from the DB by given id and pet_id fields. If it was the pet primary photo, the
+// latest remaining photo becomes primary in the same transaction. Will return sql.ErrNoRows if photo not found
+func (r *Repository) DeletePhoto(ctx context.Context, photo *model.Photo) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeletePhoto").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM pet_photos WHERE id = ? AND pet_id = ?`), photo.ID, photo.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeletePhoto").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = affected(res); err != nil {
+		return err
+	}
+
+	q := tx.Rebind(`UPDATE pets SET primary_photo_id = (SELECT MAX(id) FROM pet_photos WHERE pet_id = ?),
+		version = version + 1 WHERE id = ? AND primary_photo_id = ?`)
+
+	if _, err = tx.ExecContext(ctx, q, photo.PetID, photo.PetID, photo.ID); err != nil {
+		logger.Log().WithField("layer", "Repository-DeletePhoto").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetPrimaryPhoto is used to set photo with given id and pet_id fields as the pet primary photo in the DB. Will return
+// sql.ErrNoRows if photo not found
+func (r *Repository) SetPrimaryPhoto(ctx context.Context, photo *model.Photo) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`UPDATE pets SET primary_photo_id = ?, version = version + 1 WHERE id = ?
+		AND EXISTS (SELECT 1 FROM pet_photos WHERE id = ? AND pet_id = ?)`)
+
+	res, err := r.db.ExecContext(ctx, q, photo.ID, photo.PetID, photo.ID, photo.PetID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-SetPrimaryPhoto").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return affected(res)
+}
@@ -1,8 +1,16 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 
 	"pets/internal/config"
 	"pets/internal/model"
@@ -11,34 +19,234 @@ import (
 
 // IRepository is a repository layer interface
 type IRepository interface {
-	// GetPets is used to get pet from DB. Pagination can be used by setting limit and offset values. Order should be
-	// "asc" or "desc" in any register, all other values will be ignored. 0 limit will be ignored.
-	GetPets(limit int, offset int, order string) (pets []*model.Pet, err error)
-	// GetPet is used to get pet from DB by given ID
-	GetPet(id int) (pet *model.Pet, err error)
-	// AddPet is used to add new pet to the DB. Only "name" field will be used. Fields id and created_at will be set automatically
-	AddPet(pet *model.Pet) error
-	// UpdatePet is used to update existing pet to the DB by given id filed. Only "name" field will be used. Fields id and
-	// updated_at will be set automatically
-	UpdatePet(pet *model.Pet) error
-	// DeletePet is used to delete pet from the DB by given id
-	DeletePet(pet *model.Pet) error
+	IOwnerRepository
+	IAdoptionRepository
+	IMedicalRepository
+	IPhotoRepository
+	ITagRepository
+	IAuditRepository
+
+	// GetPets is used to get pets from DB with their tags matching given query filter in query sort order. Soft
+	// deleted pets are excluded unless filter IncludeDeleted or DeletedBefore is set. Pagination can be used by
+	// setting query limit and offset or keyset After position. Total is a number of pets matching the filter
+	// regardless of pagination
+	GetPets(ctx context.Context, query *model.PetsQuery) (pets []*model.Pet, total int, err error)
+	// ExportPets is used to call given function for every pet with its tags matching given query filter in query sort
+	// order, pagination is ignored. Pets are read from a DB cursor one by one, on SQLite in batches, so memory usage
+	// does not depend on the number of pets. Iteration is stopped if the function returns error, the error is returned
+	ExportPets(ctx context.Context, query *model.PetsQuery, fn func(pet *model.Pet) error) error
+	// GetPet is used to get not deleted pet from DB with its tags by given ID
+	GetPet(ctx context.Context, id int) (pet *model.Pet, err error)
+	// AddPet is used to add new pet to the DB. Owner and tags are not set, use TransferPet and SetPetTags. Fields id,
+	// version and created_at will be set automatically. Every pet change increments its version. AddPet, UpdatePet,
+	// PatchPet, RevertPet, DeletePet and RestorePet record the change in the pet audit in the same transaction
+	AddPet(ctx context.Context, pet *model.Pet) error
+	// AddPets is used to add given pets to the DB in one transaction as AddPet does, using multi-row inserts instead of
+	// a query per pet. Fields id, version and created_at of every pet will be set automatically
+	AddPets(ctx context.Context, pets []*model.Pet) error
+	// UpdatePet is used to update existing pet to the DB by given id filed. All fields except owner, status, primary
+	// photo and created_at will be updated, updated_at will be set automatically and version incremented. If version
+	// field is set, pet is updated only if it is the stored version. Will return ErrStale if the stored version
+	// differs, sql.ErrNoRows if pet not found or deleted
+	UpdatePet(ctx context.Context, pet *model.Pet) error
+	// PatchPet is used to update only given fields of existing pet in the DB by given id field, fields are named as in
+	// model.PetFields. Field updated_at will be set automatically and version incremented, version is checked as in
+	// UpdatePet. Will return error if any field is unknown, ErrStale if the stored version differs, sql.ErrNoRows if pet
+	// not found or deleted
+	PatchPet(ctx context.Context, pet *model.Pet, fields []string) error
+	// RevertPet is used to update given fields of existing pet as PatchPet, the change is recorded in the pet audit as a
+	// revert
+	RevertPet(ctx context.Context, pet *model.Pet, fields []string) error
+	// DeletePet is used to soft delete pet in the DB by given id. Fields deleted_at and updated_at will be set
+	// automatically and version incremented, pet records are kept until the pet is purged. If version field is set,
+	// pet is deleted only if it is the stored version. Will return ErrStale if the stored version differs,
+	// sql.ErrNoRows if pet not found or already deleted
+	DeletePet(ctx context.Context, pet *model.Pet) error
+	// RestorePet is used to restore soft deleted pet in the DB by given id. Field updated_at will be set
+	// automatically. Will return sql.ErrNoRows if pet not found or not deleted
+	RestorePet(ctx context.Context, pet *model.Pet) error
+	// PurgePet is used to hard delete soft deleted pet from the DB by given id with its ownership history, adoption
+	// applications, status history, medical records, photos and tags. Will return sql.ErrNoRows if pet not found or
+	// not deleted
+	PurgePet(ctx context.Context, pet *model.Pet) error
+	// WithTx is used to run given function in a transaction. Function gets IRepository bound to the transaction, all
+	// its changes are committed if function returns nil and rolled back if it returns error or panics, the panic is
+	// passed on. Whole transaction is retried on serialization failures up to the configured number of times, so the
+	// function should have no side effects except the repository calls. WithTx of IRepository bound to a transaction
+	// runs given function in a nested transaction, which changes are rolled back alone
+	WithTx(ctx context.Context, fn func(tx IRepository) error) error
 	// Stop is used to stop repository work
 	Stop()
 }
 
-// Repository is a repository struct, implements IRepository interface
+// IAdoptionRepository is a repository layer interface of adoption applications and pet status transitions
+type IAdoptionRepository interface {
+	// GetApplications is used to get applications matching given filter ordered by ID
+	GetApplications(ctx context.Context, filter *model.ApplicationsFilter) (apps []*model.Application, err error)
+	// GetApplication is used to get application by given ID. Will return sql.ErrNoRows if application not found
+	GetApplication(ctx context.Context, id int) (app *model.Application, err error)
+	// AddApplication is used to add new application. Fields id and created_at will be set automatically
+	AddApplication(ctx context.Context, app *model.Application) error
+	// UpdateApplication is used to update application status and reason if its status is still given from status.
+	// If transition is not nil, the pet status transition is applied in the same transaction as TransitionPet does.
+	// Field updated_at will be set automatically. Will return ErrStale if application or pet status was changed
+	UpdateApplication(ctx context.Context, app *model.Application, from model.ApplicationStatus, transition *model.Transition) error
+	// TransitionPet is used to change pet status from transition From to To if it is still From and to record the
+	// transition in pet status history and the status change in the pet audit in one transaction. Fields id and
+	// created_at will be set automatically. Will return ErrStale if pet status was changed or pet not found
+	TransitionPet(ctx context.Context, transition *model.Transition) error
+	// GetTransitions is used to get status history of the pet with given ID, oldest transition first
+	GetTransitions(ctx context.Context, petID int) (transitions []*model.Transition, err error)
+}
+
+// IMedicalRepository is a repository layer interface of pet vaccinations and treatments
+type IMedicalRepository interface {
+	// GetVaccinations is used to get vaccinations of the pet with given ID ordered by given_on
+	GetVaccinations(ctx context.Context, petID int) (vaccinations []*model.Vaccination, err error)
+	// GetVaccination is used to get vaccination of the pet with given pet ID by given ID. Will return sql.ErrNoRows if
+	// vaccination not found
+	GetVaccination(ctx context.Context, petID int, id int) (vaccination *model.Vaccination, err error)
+	// AddVaccination is used to add new vaccination. Fields id and created_at will be set automatically
+	AddVaccination(ctx context.Context, vaccination *model.Vaccination) error
+	// UpdateVaccination is used to update existing vaccination by given id and pet_id fields. Field updated_at will be
+	// set automatically. Will return sql.ErrNoRows if vaccination not found
+	UpdateVaccination(ctx context.Context, vaccination *model.Vaccination) error
+	// DeleteVaccination is used to delete vaccination by given id and pet_id fields. Will return sql.ErrNoRows if
+	// vaccination not found
+	DeleteVaccination(ctx context.Context, vaccination *model.Vaccination) error
+	// GetDueVaccinations is used to get latest vaccinations of every not deleted pet and vaccine with the next shot
+	// due on or before given date, earliest due first
+	GetDueVaccinations(ctx context.Context, before model.Date) (vaccinations []*model.DueVaccination, err error)
+
+	// GetTreatments is used to get treatments of the pet with given ID ordered by given_on
+	GetTreatments(ctx context.Context, petID int) (treatments []*model.Treatment, err error)
+	// GetTreatment is used to get treatment of the pet with given pet ID by given ID. Will return sql.ErrNoRows if
+	// treatment not found
+	GetTreatment(ctx context.Context, petID int, id int) (treatment *model.Treatment, err error)
+	// AddTreatment is used to add new treatment. Fields id and created_at will be set automatically
+	AddTreatment(ctx context.Context, treatment *model.Treatment) error
+	// UpdateTreatment is used to update existing treatment by given id and pet_id fields. Field updated_at will be set
+	// automatically. Will return sql.ErrNoRows if treatment not found
+	UpdateTreatment(ctx context.Context, treatment *model.Treatment) error
+	// DeleteTreatment is used to delete treatment by given id and pet_id fields. Will return sql.ErrNoRows if treatment
+	// not found
+	DeleteTreatment(ctx context.Context, treatment *model.Treatment) error
+}
+
+// IPhotoRepository is a repository layer interface of pet photos. Photo content is not kept in the repository, only
+// its blob store keys
+type IPhotoRepository interface {
+	// GetPhotos is used to get photos of the pet with given ID ordered by ID
+	GetPhotos(ctx context.Context, petID int) (photos []*model.Photo, err error)
+	// GetPhoto is used to get photo of the pet with given pet ID by given ID. Will return sql.ErrNoRows if photo not
+	// found
+	GetPhoto(ctx context.Context, petID int, id int) (photo *model.Photo, err error)
+	// AddPhoto is used to add new photo. The photo is made the pet primary photo if primary is true or the pet has no
+	// primary photo. Fields id and created_at will be set automatically
+	AddPhoto(ctx context.Context, photo *model.Photo, primary bool) error
+	// DeletePhoto is used to delete photo by given id and pet_id fields. If it was the pet primary photo, the latest
+	// remaining photo becomes primary. Will return sql.ErrNoRows if photo not found
+	DeletePhoto(ctx context.Context, photo *model.Photo) error
+	// SetPrimaryPhoto is used to make photo with given id and pet_id fields the pet primary photo. Will return
+	// sql.ErrNoRows if photo not found
+	SetPrimaryPhoto(ctx context.Context, photo *model.Photo) error
+}
+
+// ITagRepository is a repository layer interface of tags and pet tags
+type ITagRepository interface {
+	// GetTags is used to get tags ordered by name. Blank category will be ignored, otherwise only tags of given
+	// category are returned
+	GetTags(ctx context.Context, category string) (tags []*model.Tag, err error)
+	// GetTagsByNames is used to get tags with given names ordered by name. Unknown names are skipped
+	GetTagsByNames(ctx context.Context, names []string) (tags []*model.Tag, err error)
+	// GetTag is used to get tag by given ID. Will return sql.ErrNoRows if tag not found
+	GetTag(ctx context.Context, id int) (tag *model.Tag, err error)
+	// AddTag is used to add new tag. Fields id and created_at will be set automatically
+	AddTag(ctx context.Context, tag *model.Tag) error
+	// UpdateTag is used to update existing tag by given id field. Field updated_at will be set automatically. Will
+	// return sql.ErrNoRows if tag not found
+	UpdateTag(ctx context.Context, tag *model.Tag) error
+	// DeleteTag is used to delete tag by given id and to remove it from all pets. Will return sql.ErrNoRows if tag not
+	// found
+	DeleteTag(ctx context.Context, tag *model.Tag) error
+	// SetPetTags is used to replace tags of the pet with given ID by tags with given names in one transaction. Unknown
+	// names are skipped. Will return sql.ErrNoRows if pet not found
+	SetPetTags(ctx context.Context, petID int, names []string) error
+}
+
+// IAuditRepository is a repository layer interface of the pet audit
+type IAuditRepository interface {
+	// GetAudit is used to get pet audit entries matching given filter, newest first. Pagination can be used by setting
+	// filter limit and offset. Total is a number of entries matching the filter regardless of pagination. Entries are
+	// kept after the pet is purged
+	GetAudit(ctx context.Context, filter *model.AuditFilter) (entries []*model.AuditEntry, total int, err error)
+}
+
+// ErrStale is returned if a record was changed concurrently and a guarded update was not applied
+var ErrStale = errors.New("record was changed concurrently")
+
+// IOwnerRepository is a repository layer interface of owners and pet ownership
+type IOwnerRepository interface {
+	// GetOwners is used to get owners ordered by ID. 0 limit will be ignored. Total is a number of all owners regardless
+	// of pagination
+	GetOwners(ctx context.Context, limit int, offset int) (owners []*model.Owner, total int, err error)
+	// GetOwner is used to get owner by given ID. Will return sql.ErrNoRows if owner not found
+	GetOwner(ctx context.Context, id int) (owner *model.Owner, err error)
+	// AddOwner is used to add new owner. Fields id and created_at will be set automatically
+	AddOwner(ctx context.Context, owner *model.Owner) error
+	// UpdateOwner is used to update existing owner by given id field. Field updated_at will be set automatically. Will
+	// return sql.ErrNoRows if owner not found
+	UpdateOwner(ctx context.Context, owner *model.Owner) error
+	// DeleteOwner is used to delete owner by given id. Pets of the owner are left without owner, ownership history
+	// keeps the transfers with blank owner. Will return sql.ErrNoRows if owner not found
+	DeleteOwner(ctx context.Context, owner *model.Owner) error
+	// TransferPet is used to set pet owner to transfer ToOwnerID and record the transfer in ownership history in one
+	// transaction. Fields id, from_owner_id and transferred_at will be set automatically. Will return sql.ErrNoRows if
+	// pet not found
+	TransferPet(ctx context.Context, transfer *model.Ownership) error
+	// GetOwnership is used to get ownership history of the pet with given ID, oldest transfer first
+	GetOwnership(ctx context.Context, petID int) (history []*model.Ownership, err error)
+}
+
+const (
+	// PostgresDriver is a config.DB Driver value used to connect to Postgres
+	PostgresDriver = "postgres"
+	// SQLiteDriver is a config.DB Driver value used to connect to SQLite file or ":memory:" DB with the pure Go driver,
+	// so the app needs no cgo
+	SQLiteDriver = "sqlite"
+)
+
+// Repository is a repository struct, implements IRepository interface. Queries are written with "?" placeholders and
+// rebound to the DB driver bind type, so the same Repository is used for Postgres and SQLite
 type Repository struct {
-	db *sqlx.DB
+	// db is a DB handle queries run on, conn or the transaction of Repository bound to a transaction
+	db     queryer
+	conn   *sqlx.DB
+	driver string
+	// timeout is a max duration of a single DB query. 0 timeout will be ignored
+	timeout time.Duration
+	// isolation is an isolation level of transactions
+	isolation sql.IsolationLevel
+	// retries is a max number of retries of a transaction failed to serialize
+	retries int
+	// tx is a transaction Repository is bound to. Nil if Repository is not bound to a transaction
+	tx *sqlx.Tx
+	// savepoints is a number of savepoints created in the transaction, it is used to name nested transactions
+	savepoints *int
 }
 
-// NewRepository is used to get new Repository instance
+// NewRepository is used to get new IRepository instance. MemoryRepository will be used for MemoryDriver, otherwise
+// Repository connected to the DB with given driver
 func NewRepository(conf *config.DB) IRepository {
 	if conf == nil {
 		logger.Log().WithField("layer", "Repository-Init").Fatalf("nil config err")
 	}
 
-	db, err := sqlx.Open(conf.Driver, conf.Addr)
+	if conf.Driver == MemoryDriver {
+		return NewMemoryRepository()
+	}
+
+	db, err := OpenDB(conf)
 	if err != nil {
 		logger.Log().WithField("layer", "Repository-Init").Fatalf("err open db: %v", err.Error())
 	}
@@ -51,18 +259,103 @@ func NewRepository(conf *config.DB) IRepository {
 
 	logger.Log().WithField("layer", "Repository-Init").Infof("ping db ok")
 
+	if err = migrateOnStart(db, conf); err != nil {
+		logger.Log().WithField("layer", "Repository-Init").Fatalf("err db schema: %v", err.Error())
+	}
+
+	logger.Log().WithField("layer", "Repository-Init").Infof("db schema ok")
+
+	isolation, err := ParseIsolation(conf.TxIsolation)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-Init").Fatalf("err tx isolation: %v", err.Error())
+	}
+
 	return &Repository{
-		db: db,
+		db:        db,
+		conn:      db,
+		driver:    conf.Driver,
+		timeout:   conf.Timeout,
+		isolation: isolation,
+		retries:   conf.TxRetries,
+	}
+}
+
+// OpenDB is used to open the DB with given config driver and address. SQLite is opened with sqliteDSN params through a
+// single connection, so the server and the migrate command use the same connection settings
+func OpenDB(conf *config.DB) (*sqlx.DB, error) {
+	addr := conf.Addr
+	if conf.Driver == SQLiteDriver {
+		addr = sqliteDSN(addr)
+	}
+
+	db, err := sqlx.Open(conf.Driver, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Driver == SQLiteDriver {
+		// SQLite allows single writer and every ":memory:" connection is a separate DB
+		db.SetMaxOpenConns(1)
 	}
+
+	return db, nil
+}
+
+// sqliteDSN is used to get SQLite connection string of given DB file path or ":memory:" with the driver params. Times
+// are written in the SQLite text format, so stored and bound times are compared as strings in the same format. Foreign
+// keys are enforced, so ON DELETE clauses of the migrations are applied as on Postgres
+func sqliteDSN(addr string) string {
+	sep := "?"
+	if strings.Contains(addr, "?") {
+		sep = "&"
+	}
+
+	return addr + sep + "_time_format=sqlite&_pragma=foreign_keys(1)"
 }
 
 // Stop is implementing IRepository.Stop function. It will close the DB connection and log error if occurred
 func (r *Repository) Stop() {
-	if r.db != nil {
-		if err := r.db.Close(); err != nil {
+	if r.conn != nil {
+		if err := r.conn.Close(); err != nil {
 			logger.Log().WithField("layer", "Repository-Stop").Warningf("err closing db: %v", err.Error())
 		} else {
 			logger.Log().WithField("layer", "Repository-Stop").Infof("db closed")
 		}
 	}
 }
+
+// migrateOnStart is used to apply migrations if conf.AutoMigrate is set and to check that DB schema is not behind the
+// embedded migrations
+func migrateOnStart(db *sqlx.DB, conf *config.DB) error {
+	m, err := NewMigrator(db.DB, conf.Driver)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if conf.AutoMigrate {
+		if err = m.Up(); err != nil {
+			return fmt.Errorf("err migrate up: %w", err)
+		}
+
+		logger.Log().WithField("layer", "Repository-Init").Infof("migrations applied")
+	}
+
+	return m.Check()
+}
+
+// withTimeout is used to get child context limited by Repository timeout. If timeout is not set will return given
+// context with no-op cancel func
+func (r *Repository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+// utcNow is used to get current time in UTC. Timestamps are stored without time zone and SQLite compares them as
+// formatted strings, so every time written or bound as a query arg is converted to UTC
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
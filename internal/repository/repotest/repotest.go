@@ -0,0 +1,1782 @@
+// Package repotest is a conformance test suite for repository.IRepository implementations. Every implementation
+// should pass Run to be used by the service layer.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/repository"
+)
+
+// Factory is used to get new empty repository.IRepository instance for a single test. Repository will be stopped
+// when the test finishes
+type Factory func(t *testing.T) repository.IRepository
+
+// Run is used to run all conformance tests against repositories created by given Factory
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, rep repository.IRepository)
+	}{
+		{name: "AddPet", test: testAddPet},
+		{name: "AddPets", test: testAddPets},
+		{name: "GetPet", test: testGetPet},
+		{name: "GetPetNotFound", test: testGetPetNotFound},
+		{name: "GetPetsEmpty", test: testGetPetsEmpty},
+		{name: "GetPetsOrder", test: testGetPetsOrder},
+		{name: "GetPetsSort", test: testGetPetsSort},
+		{name: "GetPetsSortCase", test: testGetPetsSortCase},
+		{name: "GetPetsPagination", test: testGetPetsPagination},
+		{name: "GetPetsAfter", test: testGetPetsAfter},
+		{name: "GetPetsFilterName", test: testGetPetsFilterName},
+		{name: "GetPetsFilterDates", test: testGetPetsFilterDates},
+		{name: "GetPetsFilterIDs", test: testGetPetsFilterIDs},
+		{name: "GetPetsFilterDetails", test: testGetPetsFilterDetails},
+		{name: "PetDetails", test: testPetDetails},
+		{name: "UpdatePet", test: testUpdatePet},
+		{name: "PatchPet", test: testPatchPet},
+		{name: "DeletePet", test: testDeletePet},
+		{name: "RestorePet", test: testRestorePet},
+		{name: "PurgePet", test: testPurgePet},
+		{name: "PetVersion", test: testPetVersion},
+		{name: "Audit", test: testAudit},
+		{name: "GetAuditFilter", test: testGetAuditFilter},
+		{name: "CanceledContext", test: testCanceledContext},
+		{name: "WithTx", test: testWithTx},
+		{name: "WithTxRollback", test: testWithTxRollback},
+		{name: "WithTxNested", test: testWithTxNested},
+		{name: "Owner", test: testOwner},
+		{name: "GetOwners", test: testGetOwners},
+		{name: "TransferPet", test: testTransferPet},
+		{name: "DeleteOwner", test: testDeleteOwner},
+		{name: "Application", test: testApplication},
+		{name: "TransitionPet", test: testTransitionPet},
+		{name: "TransitionPetAudit", test: testTransitionPetAudit},
+		{name: "UpdateApplicationStale", test: testUpdateApplicationStale},
+		{name: "Vaccination", test: testVaccination},
+		{name: "GetDueVaccinations", test: testGetDueVaccinations},
+		{name: "Treatment", test: testTreatment},
+		{name: "Photo", test: testPhoto},
+		{name: "Tag", test: testTag},
+		{name: "PetTags", test: testPetTags},
+		{name: "GetPetsFilterTags", test: testGetPetsFilterTags},
+		{name: "ExportPets", test: testExportPets},
+		{name: "ExportPetsPaused", test: testExportPetsPaused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := factory(t)
+			t.Cleanup(rep.Stop)
+
+			tt.test(t, rep)
+		})
+	}
+}
+
+// testAddPet checks that AddPet sets increasing IDs and created_at, leaving updated_at blank
+func testAddPet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	first := &model.Pet{Name: "Velho"}
+	require.NoError(t, rep.AddPet(ctx, first))
+	require.Greater(t, first.ID, 0)
+	require.False(t, first.CreatedAt.IsZero())
+	require.Nil(t, first.UpdatedAt)
+
+	second := &model.Pet{Name: "Melho"}
+	require.NoError(t, rep.AddPet(ctx, second))
+	require.Greater(t, second.ID, first.ID)
+}
+
+// testAddPets checks that AddPets sets IDs in the pets order, stores pet fields and records every pet in the audit.
+// Number of pets is more than a single multi-row insert
+func testAddPets(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	weight := 4.5
+	pets := make([]*model.Pet, 600)
+
+	for i := range pets {
+		pets[i] = &model.Pet{Name: fmt.Sprintf("Pet %v", i), Species: model.SpeciesCat, Status: model.StatusAvailable}
+	}
+
+	pets[1].Breed = "siamese"
+	pets[1].Weight = &weight
+
+	require.NoError(t, rep.AddPets(ctx, pets))
+
+	for i, p := range pets {
+		require.Greater(t, p.ID, 0)
+		require.Equal(t, 1, p.Version)
+		require.False(t, p.CreatedAt.IsZero())
+
+		if i > 0 {
+			require.Greater(t, p.ID, pets[i-1].ID)
+		}
+	}
+
+	stored, err := rep.GetPet(ctx, pets[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, "Pet 1", stored.Name)
+	require.Equal(t, "siamese", stored.Breed)
+	require.Equal(t, weight, *stored.Weight)
+
+	_, total, err := rep.GetPets(ctx, &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, len(pets), total)
+
+	entries, total, err := rep.GetAudit(ctx, &model.AuditFilter{Action: model.AuditCreate, Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, len(pets), total)
+	require.Equal(t, pets[len(pets)-1].ID, entries[0].PetID)
+
+	require.NoError(t, rep.AddPets(ctx, nil))
+}
+
+// testGetPet checks that GetPet returns added pet
+func testGetPet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pet := &model.Pet{Name: "Velho"}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, pet.ID, res.ID)
+	require.Equal(t, pet.Name, res.Name)
+	require.False(t, res.CreatedAt.IsZero())
+	require.Nil(t, res.UpdatedAt)
+}
+
+// testGetPetNotFound checks that GetPet returns sql.ErrNoRows for unknown ID
+func testGetPetNotFound(t *testing.T, rep repository.IRepository) {
+	res, err := rep.GetPet(context.Background(), 1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Nil(t, res)
+}
+
+// testGetPetsEmpty checks that GetPets returns no pets, 0 total and no error for empty repository
+func testGetPetsEmpty(t *testing.T, rep repository.IRepository) {
+	res, total, err := rep.GetPets(context.Background(), &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Empty(t, res)
+	require.Equal(t, 0, total)
+}
+
+// testGetPetsOrder checks that GetPets orders pets by ID ascending by default and by given ID direction
+func testGetPetsOrder(t *testing.T, rep repository.IRepository) {
+	ids := addPets(t, rep, 3)
+
+	res, _, err := rep.GetPets(context.Background(), &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, ids, petIDs(res))
+
+	res, _, err = rep.GetPets(context.Background(), &model.PetsQuery{Sort: []model.SortField{{Field: model.SortID, Desc: true}}})
+	require.NoError(t, err)
+	require.Equal(t, []int{ids[2], ids[1], ids[0]}, petIDs(res))
+}
+
+// testGetPetsSort checks GetPets multi-field sorting with ID as a tie-breaker
+func testGetPetsSort(t *testing.T, rep repository.IRepository) {
+	ids := addNamedPets(t, rep, "Bob", "Alice", "Bob", "Carl")
+
+	tests := []struct {
+		name    string
+		sort    string
+		wantIDs []int
+	}{
+		{name: "name", sort: "name", wantIDs: []int{ids[1], ids[0], ids[2], ids[3]}},
+		{name: "name desc", sort: "-name", wantIDs: []int{ids[3], ids[0], ids[2], ids[1]}},
+		{name: "name and id desc", sort: "name,-id", wantIDs: []int{ids[1], ids[2], ids[0], ids[3]}},
+		{name: "name and created_at desc", sort: "name,-created_at", wantIDs: []int{ids[1], ids[2], ids[0], ids[3]}},
+		{name: "created_at desc", sort: "-created_at", wantIDs: []int{ids[3], ids[2], ids[1], ids[0]}},
+		{name: "id before name", sort: "id,name", wantIDs: ids},
+	}
+	for _, tt := range tests {
+		sorting, err := model.ParseSort(tt.sort)
+		require.NoError(t, err, tt.name)
+
+		res, _, err := rep.GetPets(context.Background(), &model.PetsQuery{Sort: sorting})
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.wantIDs, petIDs(res), tt.name)
+	}
+}
+
+// testGetPetsSortCase checks that GetPets sorts and pages names case-insensitively with ID as a tie-breaker
+func testGetPetsSortCase(t *testing.T, rep repository.IRepository) {
+	ids := addNamedPets(t, rep, "bob", "Alice", "Bob", "carl")
+	pets := getPets(t, rep, ids)
+	byName := []model.SortField{{Field: model.SortName}}
+
+	res, _, err := rep.GetPets(context.Background(), &model.PetsQuery{Sort: byName})
+	require.NoError(t, err)
+	require.Equal(t, []int{ids[1], ids[0], ids[2], ids[3]}, petIDs(res))
+
+	res, _, err = rep.GetPets(context.Background(), &model.PetsQuery{After: pets[0].Key(), Sort: byName})
+	require.NoError(t, err)
+	require.Equal(t, []int{ids[2], ids[3]}, petIDs(res))
+}
+
+// testGetPetsPagination checks GetPets limit and offset semantics and that total does not depend on them
+func testGetPetsPagination(t *testing.T, rep repository.IRepository) {
+	ids := addPets(t, rep, 5)
+	desc := []model.SortField{{Field: model.SortID, Desc: true}}
+
+	tests := []struct {
+		name    string
+		limit   int
+		offset  int
+		sort    []model.SortField
+		wantIDs []int
+	}{
+		{name: "no limit", wantIDs: ids},
+		{name: "limit", limit: 2, wantIDs: ids[:2]},
+		{name: "offset", offset: 3, wantIDs: ids[3:]},
+		{name: "limit and offset", limit: 2, offset: 1, wantIDs: ids[1:3]},
+		{name: "limit and offset desc", limit: 2, offset: 1, sort: desc, wantIDs: []int{ids[3], ids[2]}},
+		{name: "limit over total", limit: 10, offset: 4, wantIDs: ids[4:]},
+		{name: "offset over total", limit: 2, offset: 5},
+	}
+	for _, tt := range tests {
+		res, total, err := rep.GetPets(context.Background(), &model.PetsQuery{Limit: tt.limit, Offset: tt.offset, Sort: tt.sort})
+		require.NoError(t, err, tt.name)
+		require.Equal(t, len(ids), total, tt.name)
+		require.Equal(t, len(tt.wantIDs), len(res), tt.name)
+
+		if len(tt.wantIDs) != 0 {
+			require.Equal(t, tt.wantIDs, petIDs(res), tt.name)
+		}
+	}
+}
+
+// testGetPetsAfter checks GetPets keyset semantics in different sort orders, offset is ignored with keyset
+func testGetPetsAfter(t *testing.T, rep repository.IRepository) {
+	ids := addNamedPets(t, rep, "Bob", "Alice", "Bob", "Carl", "Alice")
+	pets := getPets(t, rep, ids)
+
+	desc := []model.SortField{{Field: model.SortID, Desc: true}}
+	byName := []model.SortField{{Field: model.SortName}, {Field: model.SortCreatedAt, Desc: true}}
+
+	tests := []struct {
+		name    string
+		after   *model.Pet
+		limit   int
+		sort    []model.SortField
+		wantIDs []int
+	}{
+		{name: "after first", after: pets[0], limit: 2, wantIDs: ids[1:3]},
+		{name: "after last", after: pets[4], limit: 2},
+		{name: "after desc", after: pets[3], sort: desc, wantIDs: []int{ids[2], ids[1], ids[0]}},
+		{name: "after last desc", after: pets[0], limit: 2, sort: desc},
+		{name: "after by name", after: pets[1], sort: byName, wantIDs: []int{ids[2], ids[0], ids[3]}},
+		{name: "after by name equal names", after: pets[2], limit: 1, sort: byName, wantIDs: []int{ids[0]}},
+	}
+	for _, tt := range tests {
+		q := &model.PetsQuery{After: tt.after.Key(), Limit: tt.limit, Offset: 1, Sort: tt.sort}
+
+		res, total, err := rep.GetPets(context.Background(), q)
+		require.NoError(t, err, tt.name)
+		require.Equal(t, len(ids), total, tt.name)
+		require.Equal(t, len(tt.wantIDs), len(res), tt.name)
+
+		if len(tt.wantIDs) != 0 {
+			require.Equal(t, tt.wantIDs, petIDs(res), tt.name)
+		}
+	}
+
+	// deleted pet used as a keyset position does not break pagination
+	require.NoError(t, rep.DeletePet(context.Background(), &model.Pet{ID: ids[1]}))
+
+	res, _, err := rep.GetPets(context.Background(), &model.PetsQuery{After: pets[1].Key()})
+	require.NoError(t, err)
+	require.Equal(t, ids[2:], petIDs(res))
+}
+
+// testGetPetsFilterName checks case-insensitive name filters and that total is counted with the filter
+func testGetPetsFilterName(t *testing.T, rep repository.IRepository) {
+	ids := addNamedPets(t, rep, "Velho", "velhovsky", "Melho", "Old_Velho", "Old%")
+
+	tests := []struct {
+		name    string
+		filter  model.PetsFilter
+		wantIDs []int
+	}{
+		{name: "exact", filter: model.PetsFilter{Name: "VELHO"}, wantIDs: ids[:1]},
+		{name: "exact explicit", filter: model.PetsFilter{Name: "velho", NameMatch: model.NameExact}, wantIDs: ids[:1]},
+		{name: "prefix", filter: model.PetsFilter{Name: "Velho", NameMatch: model.NamePrefix}, wantIDs: ids[:2]},
+		{name: "contains", filter: model.PetsFilter{Name: "ELH", NameMatch: model.NameContains}, wantIDs: ids[:4]},
+		{name: "escaped underscore", filter: model.PetsFilter{Name: "d_v", NameMatch: model.NameContains}, wantIDs: ids[3:4]},
+		{name: "escaped percent", filter: model.PetsFilter{Name: "old%", NameMatch: model.NamePrefix}, wantIDs: ids[4:]},
+		{name: "no match", filter: model.PetsFilter{Name: "Velh"}},
+	}
+	for _, tt := range tests {
+		res, total, err := rep.GetPets(context.Background(), &model.PetsQuery{Filter: tt.filter})
+		require.NoError(t, err, tt.name)
+		require.Equal(t, len(tt.wantIDs), total, tt.name)
+		require.Equal(t, len(tt.wantIDs), len(res), tt.name)
+
+		if len(tt.wantIDs) != 0 {
+			require.Equal(t, tt.wantIDs, petIDs(res), tt.name)
+		}
+	}
+}
+
+// testGetPetsFilterDates checks created_at and updated_at filters
+func testGetPetsFilterDates(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addPets(t, rep, 4)
+	require.NoError(t, rep.UpdatePet(ctx, &model.Pet{ID: ids[2], Name: "Updated"}))
+	require.NoError(t, rep.UpdatePet(ctx, &model.Pet{ID: ids[3], Name: "Updated"}))
+
+	pets := getPets(t, rep, ids)
+	zoned := pets[1].CreatedAt.In(time.FixedZone("UTC+5", 5*60*60))
+
+	tests := []struct {
+		name    string
+		filter  model.PetsFilter
+		wantIDs []int
+	}{
+		{name: "created after", filter: model.PetsFilter{CreatedAfter: &pets[1].CreatedAt}, wantIDs: ids[2:]},
+		{name: "created after other zone", filter: model.PetsFilter{CreatedAfter: &zoned}, wantIDs: ids[2:]},
+		{name: "created before other zone", filter: model.PetsFilter{CreatedBefore: &zoned}, wantIDs: ids[:1]},
+		{name: "created before", filter: model.PetsFilter{CreatedBefore: &pets[1].CreatedAt}, wantIDs: ids[:1]},
+		{
+			name:    "created between",
+			filter:  model.PetsFilter{CreatedAfter: &pets[0].CreatedAt, CreatedBefore: &pets[3].CreatedAt},
+			wantIDs: ids[1:3],
+		},
+		{name: "updated since", filter: model.PetsFilter{UpdatedSince: pets[2].UpdatedAt}, wantIDs: ids[2:]},
+		{name: "updated since last", filter: model.PetsFilter{UpdatedSince: pets[3].UpdatedAt}, wantIDs: ids[3:]},
+	}
+	for _, tt := range tests {
+		res, total, err := rep.GetPets(ctx, &model.PetsQuery{Filter: tt.filter})
+		require.NoError(t, err, tt.name)
+		require.Equal(t, len(tt.wantIDs), total, tt.name)
+		require.Equal(t, tt.wantIDs, petIDs(res), tt.name)
+	}
+}
+
+// testGetPetsFilterIDs checks ID list filter combined with pagination
+func testGetPetsFilterIDs(t *testing.T, rep repository.IRepository) {
+	ids := addPets(t, rep, 5)
+
+	q := &model.PetsQuery{Filter: model.PetsFilter{IDs: []int{ids[4], ids[1], ids[3], 100500}}, Limit: 2, Offset: 1}
+
+	res, total, err := rep.GetPets(context.Background(), q)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, []int{ids[3], ids[4]}, petIDs(res))
+}
+
+// testGetPetsFilterDetails checks species, status, sex, neutered, breed, color, description, birth date and weight
+// filters
+func testGetPetsFilterDetails(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	light, heavy := 3.5, 25.0
+	born2019, born2021 := model.NewDate(2019, time.March, 10), model.NewDate(2021, time.July, 1)
+
+	pets := []*model.Pet{
+		{Name: "Rex", Species: model.SpeciesDog, Breed: "Beagle", Sex: model.SexMale, Neutered: true, Weight: &heavy,
+			BirthDate: &born2019, Color: "tricolor", Description: "Loves long walks", Status: model.StatusAvailable},
+		{Name: "Murka", Species: model.SpeciesCat, Breed: "Siamese", Sex: model.SexFemale, Weight: &light,
+			BirthDate: &born2021, Color: "cream", Description: "Quiet and shy", Status: model.StatusAdopted},
+		{Name: "Bun", Species: model.SpeciesRabbit, Sex: model.SexUnknown, Status: model.StatusPending},
+	}
+
+	for _, p := range pets {
+		require.NoError(t, rep.AddPet(ctx, p))
+	}
+
+	yes, no := true, false
+	weight := 10.0
+	date := model.NewDate(2020, time.January, 1)
+
+	tests := []struct {
+		name   string
+		filter model.PetsFilter
+		want   []int
+	}{
+		{name: "species", filter: model.PetsFilter{Species: []model.Species{model.SpeciesCat, model.SpeciesRabbit}}, want: []int{pets[1].ID, pets[2].ID}},
+		{name: "status", filter: model.PetsFilter{Statuses: []model.Status{model.StatusAvailable}}, want: []int{pets[0].ID}},
+		{name: "sex", filter: model.PetsFilter{Sex: model.SexFemale}, want: []int{pets[1].ID}},
+		{name: "neutered", filter: model.PetsFilter{Neutered: &yes}, want: []int{pets[0].ID}},
+		{name: "not neutered", filter: model.PetsFilter{Neutered: &no}, want: []int{pets[1].ID, pets[2].ID}},
+		{name: "breed", filter: model.PetsFilter{Breed: "beagle"}, want: []int{pets[0].ID}},
+		{name: "color", filter: model.PetsFilter{Color: "CREAM"}, want: []int{pets[1].ID}},
+		{name: "description", filter: model.PetsFilter{Description: "long"}, want: []int{pets[0].ID}},
+		{name: "born after", filter: model.PetsFilter{BornAfter: &date}, want: []int{pets[1].ID}},
+		{name: "born before", filter: model.PetsFilter{BornBefore: &date}, want: []int{pets[0].ID}},
+		{name: "min weight", filter: model.PetsFilter{MinWeight: &weight}, want: []int{pets[0].ID}},
+		{name: "max weight", filter: model.PetsFilter{MaxWeight: &weight}, want: []int{pets[1].ID}},
+		{name: "combined", filter: model.PetsFilter{Species: []model.Species{model.SpeciesDog}, Sex: model.SexFemale}, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, total, err := rep.GetPets(ctx, &model.PetsQuery{Filter: tt.filter})
+			require.NoError(t, err)
+			require.Equal(t, len(tt.want), total)
+			require.Equal(t, tt.want, petIDs(res))
+		})
+	}
+}
+
+// testPetDetails checks that all pet fields are stored by AddPet and UpdatePet, unknown birth date and weight are
+// kept nil, status is not changed by UpdatePet
+func testPetDetails(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	weight := 12.5
+	birthDate := model.NewDate(2020, time.May, 1)
+
+	pet := &model.Pet{Name: "Velho", Species: model.SpeciesDog, Breed: "beagle", BirthDate: &birthDate,
+		Sex: model.SexMale, Neutered: true, Weight: &weight, Color: "tricolor", Description: "friendly",
+		Status: model.StatusPending}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.SpeciesDog, res.Species)
+	require.Equal(t, "beagle", res.Breed)
+	require.NotNil(t, res.BirthDate)
+	require.Equal(t, "2020-05-01", res.BirthDate.String())
+	require.Equal(t, model.SexMale, res.Sex)
+	require.True(t, res.Neutered)
+	require.NotNil(t, res.Weight)
+	require.Equal(t, 12.5, *res.Weight)
+	require.Equal(t, "tricolor", res.Color)
+	require.Equal(t, "friendly", res.Description)
+	require.Equal(t, model.StatusPending, res.Status)
+
+	res.BirthDate = nil
+	res.Weight = nil
+	res.Neutered = false
+	res.Status = model.StatusAdopted
+	require.NoError(t, rep.UpdatePet(ctx, res))
+
+	res, err = rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Nil(t, res.BirthDate)
+	require.Nil(t, res.Weight)
+	require.False(t, res.Neutered)
+	require.Equal(t, model.StatusPending, res.Status)
+	require.Equal(t, "beagle", res.Breed)
+}
+
+// testUpdatePet checks that UpdatePet changes name and sets updated_at keeping created_at, sql.ErrNoRows is returned for
+// unknown ID
+func testUpdatePet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pet := &model.Pet{Name: "Velho"}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	before, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+
+	upd := &model.Pet{ID: pet.ID, Name: "Melho"}
+	require.NoError(t, rep.UpdatePet(ctx, upd))
+	require.NotNil(t, upd.UpdatedAt)
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Melho", res.Name)
+	require.NotNil(t, res.UpdatedAt)
+	require.True(t, before.CreatedAt.Equal(res.CreatedAt))
+
+	err = rep.UpdatePet(ctx, &model.Pet{ID: pet.ID + 1, Name: "Melho"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// testPatchPet checks that only given pet fields are updated
+func testPatchPet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	weight := 4.5
+	birthDate := model.NewDate(2020, time.March, 1)
+
+	pet := &model.Pet{Name: "Velho", Species: model.SpeciesCat, Breed: "Siamese", Color: "white", Weight: &weight}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	patch := &model.Pet{ID: pet.ID, Name: "Ignored", Species: model.SpeciesDog, BirthDate: &birthDate, Color: "black"}
+	require.NoError(t, rep.PatchPet(ctx, patch, []string{"birth_date", "color", "weight"}))
+	require.Equal(t, 2, patch.Version)
+	require.NotNil(t, patch.UpdatedAt)
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Velho", res.Name)
+	require.Equal(t, model.SpeciesCat, res.Species)
+	require.Equal(t, "Siamese", res.Breed)
+	require.Equal(t, "black", res.Color)
+	require.Nil(t, res.Weight)
+	require.NotNil(t, res.BirthDate)
+	require.Equal(t, "2020-03-01", res.BirthDate.String())
+	require.Equal(t, 2, res.Version)
+
+	require.Error(t, rep.PatchPet(ctx, &model.Pet{ID: pet.ID}, []string{"id"}))
+	require.ErrorIs(t, rep.PatchPet(ctx, &model.Pet{ID: pet.ID, Version: 1}, []string{"name"}), repository.ErrStale)
+	require.ErrorIs(t, rep.PatchPet(ctx, &model.Pet{ID: 100500}, []string{"name"}), sql.ErrNoRows)
+
+	res, err = rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Velho", res.Name)
+	require.Equal(t, 2, res.Version)
+}
+
+// testDeletePet checks that deleted pet can not be found and can not be deleted again
+func testDeletePet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addPets(t, rep, 2)
+
+	require.NoError(t, rep.DeletePet(ctx, &model.Pet{ID: ids[0]}))
+
+	_, err := rep.GetPet(ctx, ids[0])
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	res, total, err := rep.GetPets(ctx, &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, ids[1:], petIDs(res))
+	require.Equal(t, 1, total)
+
+	err = rep.DeletePet(ctx, &model.Pet{ID: ids[0]})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// testRestorePet checks that soft deleted pet is listed with IncludeDeleted only, can not be changed and is visible
+// again after restore
+func testRestorePet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addPets(t, rep, 2)
+	addTags(t, rep, "senior")
+
+	pet := &model.Pet{ID: ids[0]}
+	require.NoError(t, rep.DeletePet(ctx, pet))
+	require.NotNil(t, pet.DeletedAt)
+
+	res, total, err := rep.GetPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{IncludeDeleted: true}})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, ids, petIDs(res))
+	require.NotNil(t, res[0].DeletedAt)
+	require.Nil(t, res[1].DeletedAt)
+
+	// deleted pet can not be updated or tagged
+	require.ErrorIs(t, rep.UpdatePet(ctx, &model.Pet{ID: ids[0], Name: "Renamed"}), sql.ErrNoRows)
+	require.ErrorIs(t, rep.SetPetTags(ctx, ids[0], []string{"senior"}), sql.ErrNoRows)
+
+	// only deleted pets can be restored
+	require.ErrorIs(t, rep.RestorePet(ctx, &model.Pet{ID: ids[1]}), sql.ErrNoRows)
+	require.ErrorIs(t, rep.RestorePet(ctx, &model.Pet{ID: 100500}), sql.ErrNoRows)
+
+	require.NoError(t, rep.RestorePet(ctx, pet))
+	require.Nil(t, pet.DeletedAt)
+	require.ErrorIs(t, rep.RestorePet(ctx, pet), sql.ErrNoRows)
+
+	restored, err := rep.GetPet(ctx, ids[0])
+	require.NoError(t, err)
+	require.Equal(t, "Pet0", restored.Name)
+	require.Nil(t, restored.DeletedAt)
+	require.NotNil(t, restored.UpdatedAt)
+
+	res, total, err = rep.GetPets(ctx, &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, ids, petIDs(res))
+}
+
+// testPetVersion checks that pet version is incremented on every pet write and that writes with stale version fail
+func testPetVersion(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pet := &model.Pet{Name: "Velho"}
+	require.NoError(t, rep.AddPet(ctx, pet))
+	require.Equal(t, 1, pet.Version)
+	addTags(t, rep, "senior")
+
+	// unconditional update
+	upd := &model.Pet{ID: pet.ID, Name: "Melho"}
+	require.NoError(t, rep.UpdatePet(ctx, upd))
+	require.Equal(t, 2, upd.Version)
+
+	// conditional update
+	upd = &model.Pet{ID: pet.ID, Name: "Zelho", Version: 2}
+	require.NoError(t, rep.UpdatePet(ctx, upd))
+	require.Equal(t, 3, upd.Version)
+
+	require.NoError(t, rep.SetPetTags(ctx, pet.ID, []string{"senior"}))
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Zelho", res.Name)
+	require.Equal(t, 4, res.Version)
+
+	// stale writes are rejected and change nothing
+	require.ErrorIs(t, rep.UpdatePet(ctx, &model.Pet{ID: pet.ID, Name: "Stale", Version: 3}), repository.ErrStale)
+	require.ErrorIs(t, rep.DeletePet(ctx, &model.Pet{ID: pet.ID, Version: 3}), repository.ErrStale)
+	require.ErrorIs(t, rep.UpdatePet(ctx, &model.Pet{ID: 100500, Name: "Stale", Version: 3}), sql.ErrNoRows)
+
+	res, err = rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Zelho", res.Name)
+	require.Equal(t, 4, res.Version)
+
+	del := &model.Pet{ID: pet.ID, Version: 4}
+	require.NoError(t, rep.DeletePet(ctx, del))
+	require.Equal(t, 5, del.Version)
+
+	require.NoError(t, rep.RestorePet(ctx, del))
+	require.Equal(t, 6, del.Version)
+}
+
+// testAudit checks that every pet write is recorded in the pet audit with the actor and the changed fields only
+func testAudit(t *testing.T, rep repository.IRepository) {
+	ctx := model.WithActor(context.Background(), model.Actor{Name: "alice", RequestID: "req-1"})
+
+	pet := &model.Pet{Name: "Velho", Species: model.SpeciesCat, Status: model.StatusAvailable}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	upd := &model.Pet{ID: pet.ID, Name: "Melho", Species: model.SpeciesCat}
+	require.NoError(t, rep.UpdatePet(ctx, upd))
+
+	// update without changes is not recorded
+	require.NoError(t, rep.UpdatePet(ctx, &model.Pet{ID: pet.ID, Name: "Melho", Species: model.SpeciesCat}))
+
+	require.NoError(t, rep.PatchPet(context.Background(), &model.Pet{ID: pet.ID, Breed: "Siamese"}, []string{"breed"}))
+	require.NoError(t, rep.RevertPet(ctx, &model.Pet{ID: pet.ID, Name: "Velho"}, []string{"name"}))
+	require.NoError(t, rep.DeletePet(ctx, &model.Pet{ID: pet.ID}))
+	require.NoError(t, rep.RestorePet(ctx, &model.Pet{ID: pet.ID}))
+
+	// failed writes are not recorded
+	require.ErrorIs(t, rep.UpdatePet(ctx, &model.Pet{ID: pet.ID, Name: "Stale", Version: 1}), repository.ErrStale)
+
+	entries, total, err := rep.GetAudit(ctx, &model.AuditFilter{PetID: pet.ID})
+	require.NoError(t, err)
+	require.Equal(t, 6, total)
+	require.Len(t, entries, 6)
+
+	var actions []model.AuditAction
+	for _, e := range entries {
+		require.Equal(t, pet.ID, e.PetID)
+		require.False(t, e.CreatedAt.IsZero())
+		actions = append(actions, e.Action)
+	}
+
+	// newest first
+	require.Equal(t, []model.AuditAction{model.AuditRestore, model.AuditDelete, model.AuditRevert, model.AuditUpdate,
+		model.AuditUpdate, model.AuditCreate}, actions)
+	require.Equal(t, []int{7, 6, 5, 4, 2, 1}, []int{entries[0].Version, entries[1].Version, entries[2].Version,
+		entries[3].Version, entries[4].Version, entries[5].Version})
+
+	created := entries[5]
+	require.Equal(t, "alice", created.Actor)
+	require.Equal(t, "req-1", created.RequestID)
+	require.Equal(t, model.Change{Before: json.RawMessage("null"), After: json.RawMessage(`"Velho"`)}, created.Diff["name"])
+	require.Equal(t, json.RawMessage(`"available"`), created.Diff["status"].After)
+
+	renamed := entries[4]
+	require.Equal(t, model.Diff{"name": {Before: json.RawMessage(`"Velho"`), After: json.RawMessage(`"Melho"`)}}, renamed.Diff)
+
+	patched := entries[3]
+	require.Equal(t, model.SystemActor, patched.Actor)
+	require.Empty(t, patched.RequestID)
+	require.Equal(t, model.Diff{"breed": {Before: json.RawMessage(`""`), After: json.RawMessage(`"Siamese"`)}}, patched.Diff)
+
+	deleted := entries[1]
+	require.Len(t, deleted.Diff, 1)
+	require.Equal(t, json.RawMessage("null"), deleted.Diff["deleted_at"].Before)
+
+	restored := entries[0]
+	require.Len(t, restored.Diff, 1)
+	require.Equal(t, json.RawMessage("null"), restored.Diff["deleted_at"].After)
+
+	// the pet can be rebuilt from its history
+	res, err := model.PetAtVersion(entries, 4)
+	require.NoError(t, err)
+	require.Equal(t, "Melho", res.Name)
+	require.Equal(t, "Siamese", res.Breed)
+}
+
+// testGetAuditFilter checks pet audit filters and pagination
+func testGetAuditFilter(t *testing.T, rep repository.IRepository) {
+	alice := model.WithActor(context.Background(), model.Actor{Name: "alice", RequestID: "req-1"})
+	bob := model.WithActor(context.Background(), model.Actor{Name: "bob", RequestID: "req-2"})
+
+	first := &model.Pet{Name: "Velho"}
+	require.NoError(t, rep.AddPet(alice, first))
+
+	second := &model.Pet{Name: "Melho"}
+	require.NoError(t, rep.AddPet(bob, second))
+	require.NoError(t, rep.UpdatePet(bob, &model.Pet{ID: first.ID, Name: "Zelho"}))
+	require.NoError(t, rep.DeletePet(alice, &model.Pet{ID: second.ID}))
+
+	all, total, err := rep.GetAudit(alice, &model.AuditFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		filter    model.AuditFilter
+		wantIDs   []int
+		wantTotal int
+	}{
+		{name: "pet", filter: model.AuditFilter{PetID: first.ID}, wantIDs: []int{all[1].ID, all[3].ID}, wantTotal: 2},
+		{name: "actor", filter: model.AuditFilter{Actor: "bob"}, wantIDs: []int{all[1].ID, all[2].ID}, wantTotal: 2},
+		{name: "action", filter: model.AuditFilter{Action: model.AuditDelete}, wantIDs: []int{all[0].ID}, wantTotal: 1},
+		{name: "request", filter: model.AuditFilter{RequestID: "req-1"}, wantIDs: []int{all[0].ID, all[3].ID}, wantTotal: 2},
+		{name: "since", filter: model.AuditFilter{Since: &all[1].CreatedAt}, wantIDs: []int{all[0].ID, all[1].ID}, wantTotal: 2},
+		{name: "until", filter: model.AuditFilter{Until: &all[2].CreatedAt}, wantIDs: []int{all[3].ID}, wantTotal: 1},
+		{name: "future", filter: model.AuditFilter{Since: &future}, wantTotal: 0},
+		{name: "limit and offset", filter: model.AuditFilter{Limit: 2, Offset: 1}, wantIDs: []int{all[1].ID, all[2].ID}, wantTotal: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, total, err := rep.GetAudit(alice, &tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTotal, total)
+
+			var ids []int
+			for _, e := range res {
+				ids = append(ids, e.ID)
+			}
+
+			require.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+// testPurgePet checks DeletedBefore filter, that only soft deleted pets can be purged and their records are deleted
+func testPurgePet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addPets(t, rep, 3)
+
+	require.NoError(t, rep.AddVaccination(ctx, &model.Vaccination{PetID: ids[0], Vaccine: "rabies",
+		GivenOn: model.NewDate(2023, time.March, 1)}))
+
+	require.NoError(t, rep.DeletePet(ctx, &model.Pet{ID: ids[0]}))
+	require.NoError(t, rep.DeletePet(ctx, &model.Pet{ID: ids[1]}))
+
+	before := time.Now().Add(time.Second)
+
+	res, total, err := rep.GetPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{DeletedBefore: &before}})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, ids[:2], petIDs(res))
+
+	past := time.Now().Add(-time.Hour)
+
+	res, _, err = rep.GetPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{DeletedBefore: &past}})
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	require.ErrorIs(t, rep.PurgePet(ctx, &model.Pet{ID: ids[2]}), sql.ErrNoRows)
+	require.NoError(t, rep.PurgePet(ctx, &model.Pet{ID: ids[0]}))
+	require.ErrorIs(t, rep.PurgePet(ctx, &model.Pet{ID: ids[0]}), sql.ErrNoRows)
+
+	vaccinations, err := rep.GetVaccinations(ctx, ids[0])
+	require.NoError(t, err)
+	require.Empty(t, vaccinations)
+
+	// purged pet can not be restored
+	require.ErrorIs(t, rep.RestorePet(ctx, &model.Pet{ID: ids[0]}), sql.ErrNoRows)
+
+	res, total, err = rep.GetPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{IncludeDeleted: true}})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, ids[1:], petIDs(res))
+}
+
+// testCanceledContext checks that methods fail with canceled context
+func testCanceledContext(t *testing.T, rep repository.IRepository) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Error(t, rep.AddPet(ctx, &model.Pet{Name: "Velho"}))
+
+	_, _, err := rep.GetPets(ctx, &model.PetsQuery{})
+	require.Error(t, err)
+}
+
+// testWithTx checks that changes made in a transaction are visible in it and are committed together, including tags
+// and the pet audit
+func testWithTx(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	require.NoError(t, rep.AddTag(ctx, &model.Tag{Name: "senior"}))
+
+	pet := &model.Pet{Name: "Velho"}
+
+	err := rep.WithTx(ctx, func(tx repository.IRepository) error {
+		if err := tx.AddPet(ctx, pet); err != nil {
+			return err
+		}
+
+		if err := tx.SetPetTags(ctx, pet.ID, []string{"senior"}); err != nil {
+			return err
+		}
+
+		stored, err := tx.GetPet(ctx, pet.ID)
+		if err != nil {
+			return err
+		}
+
+		require.Equal(t, []string{"senior"}, stored.Tags)
+
+		stored.Name = "Bobik"
+
+		return tx.UpdatePet(ctx, stored)
+	})
+	require.NoError(t, err)
+
+	stored, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Bobik", stored.Name)
+	require.Equal(t, []string{"senior"}, stored.Tags)
+
+	_, total, err := rep.GetAudit(ctx, &model.AuditFilter{PetID: pet.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+}
+
+// testWithTxRollback checks that changes are rolled back if the function returns error or panics and the panic is
+// passed on
+func testWithTxRollback(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+	pets := addPets(t, rep, 1)
+	errFailed := errors.New("failed")
+
+	err := rep.WithTx(ctx, func(tx repository.IRepository) error {
+		require.NoError(t, tx.AddPet(ctx, &model.Pet{Name: "Bobik"}))
+		require.NoError(t, tx.DeletePet(ctx, &model.Pet{ID: pets[0]}))
+
+		return fmt.Errorf("wrapped: %w", errFailed)
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	require.PanicsWithValue(t, "failed", func() {
+		_ = rep.WithTx(ctx, func(tx repository.IRepository) error {
+			require.NoError(t, tx.DeletePet(ctx, &model.Pet{ID: pets[0]}))
+			panic("failed")
+		})
+	})
+
+	res, total, err := rep.GetPets(ctx, &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, pets, petIDs(res))
+
+	_, total, err = rep.GetAudit(ctx, &model.AuditFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	// repository is usable after the rollback
+	require.NoError(t, rep.WithTx(ctx, func(tx repository.IRepository) error {
+		return tx.DeletePet(ctx, &model.Pet{ID: pets[0]})
+	}))
+
+	_, err = rep.GetPet(ctx, pets[0])
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// testWithTxNested checks that a failed nested transaction rolls back its own changes only
+func testWithTxNested(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	outer := &model.Pet{Name: "Velho"}
+	inner := &model.Pet{Name: "Bobik"}
+
+	err := rep.WithTx(ctx, func(tx repository.IRepository) error {
+		if err := tx.AddPet(ctx, outer); err != nil {
+			return err
+		}
+
+		err := tx.WithTx(ctx, func(tx repository.IRepository) error {
+			require.NoError(t, tx.AddPet(ctx, inner))
+			return errFailed
+		})
+		require.ErrorIs(t, err, errFailed)
+
+		// failed repository method rolls back its own changes, the transaction can be used after it
+		require.ErrorIs(t, tx.UpdatePet(ctx, &model.Pet{ID: outer.ID + 100, Name: "Nobody"}), sql.ErrNoRows)
+
+		return tx.WithTx(ctx, func(tx repository.IRepository) error {
+			outer.Name = "Renamed"
+			return tx.UpdatePet(ctx, outer)
+		})
+	})
+	require.NoError(t, err)
+
+	res, total, err := rep.GetPets(ctx, &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "Renamed", res[0].Name)
+}
+
+// testOwner checks owner add, get, update and delete, sql.ErrNoRows is returned for unknown ID
+func testOwner(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	owner := &model.Owner{Name: "John Smith", Email: "john@example.com", Phone: "+1 555 0100", Address: "1 Main St"}
+	require.NoError(t, rep.AddOwner(ctx, owner))
+	require.Greater(t, owner.ID, 0)
+	require.False(t, owner.CreatedAt.IsZero())
+
+	res, err := rep.GetOwner(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, "John Smith", res.Name)
+	require.Equal(t, "john@example.com", res.Email)
+	require.Equal(t, "+1 555 0100", res.Phone)
+	require.Equal(t, "1 Main St", res.Address)
+	require.Nil(t, res.UpdatedAt)
+
+	res.Email = "smith@example.com"
+	require.NoError(t, rep.UpdateOwner(ctx, res))
+
+	res, err = rep.GetOwner(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, "smith@example.com", res.Email)
+	require.NotNil(t, res.UpdatedAt)
+
+	require.ErrorIs(t, rep.UpdateOwner(ctx, &model.Owner{ID: owner.ID + 1, Name: "Jane"}), sql.ErrNoRows)
+
+	require.NoError(t, rep.DeleteOwner(ctx, owner))
+	require.ErrorIs(t, rep.DeleteOwner(ctx, owner), sql.ErrNoRows)
+
+	_, err = rep.GetOwner(ctx, owner.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// testGetOwners checks that GetOwners orders owners by ID and counts total regardless of pagination
+func testGetOwners(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addOwners(t, rep, 3)
+
+	res, total, err := rep.GetOwners(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, ids, ownerIDs(res))
+
+	res, total, err = rep.GetOwners(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, ids[1:2], ownerIDs(res))
+
+	res, total, err = rep.GetOwners(ctx, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, ids[2:], ownerIDs(res))
+}
+
+// testTransferPet checks that TransferPet sets pet owner and records history, UpdatePet keeps the owner and pets can
+// be filtered by owner
+func testTransferPet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	owners := addOwners(t, rep, 2)
+	pets := addPets(t, rep, 2)
+
+	first := &model.Ownership{PetID: pets[0], ToOwnerID: &owners[0], Note: "adopted"}
+	require.NoError(t, rep.TransferPet(ctx, first))
+	require.Greater(t, first.ID, 0)
+	require.Nil(t, first.FromOwnerID)
+	require.False(t, first.TransferredAt.IsZero())
+
+	second := &model.Ownership{PetID: pets[0], ToOwnerID: &owners[1]}
+	require.NoError(t, rep.TransferPet(ctx, second))
+	require.NotNil(t, second.FromOwnerID)
+	require.Equal(t, owners[0], *second.FromOwnerID)
+
+	pet, err := rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.NotNil(t, pet.OwnerID)
+	require.Equal(t, owners[1], *pet.OwnerID)
+
+	pet.Name = "Renamed"
+	pet.OwnerID = nil
+	require.NoError(t, rep.UpdatePet(ctx, pet))
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.NotNil(t, pet.OwnerID)
+	require.Equal(t, owners[1], *pet.OwnerID)
+
+	res, total, err := rep.GetPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{OwnerID: &owners[1]}})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, []int{pets[0]}, petIDs(res))
+
+	history, err := rep.GetOwnership(ctx, pets[0])
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, first.ID, history[0].ID)
+	require.Equal(t, "adopted", history[0].Note)
+	require.Equal(t, second.ID, history[1].ID)
+	require.Equal(t, owners[1], *history[1].ToOwnerID)
+
+	history, err = rep.GetOwnership(ctx, pets[1])
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	err = rep.TransferPet(ctx, &model.Ownership{PetID: pets[1] + 1, ToOwnerID: &owners[0]})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	purgePet(t, rep, pets[0])
+
+	history, err = rep.GetOwnership(ctx, pets[0])
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
+
+// testDeleteOwner checks that pets of deleted owner are left without owner and history keeps blank owner
+func testDeleteOwner(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	owners := addOwners(t, rep, 1)
+	pets := addPets(t, rep, 1)
+
+	require.NoError(t, rep.TransferPet(ctx, &model.Ownership{PetID: pets[0], ToOwnerID: &owners[0]}))
+	require.NoError(t, rep.DeleteOwner(ctx, &model.Owner{ID: owners[0]}))
+
+	pet, err := rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Nil(t, pet.OwnerID)
+
+	history, err := rep.GetOwnership(ctx, pets[0])
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Nil(t, history[0].ToOwnerID)
+}
+
+// testApplication checks application add, get and filter, UpdateApplication changes application and pet status and
+// records the transition
+func testApplication(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	var pets []int
+
+	for _, name := range []string{"Velho", "Melho"} {
+		pet := &model.Pet{Name: name, Status: model.StatusAvailable}
+		require.NoError(t, rep.AddPet(ctx, pet))
+
+		pets = append(pets, pet.ID)
+	}
+
+	first := &model.Application{PetID: pets[0], Applicant: "John", Contact: "john@example.com", Notes: "has a yard",
+		Status: model.ApplicationSubmitted}
+	require.NoError(t, rep.AddApplication(ctx, first))
+	require.Greater(t, first.ID, 0)
+	require.False(t, first.CreatedAt.IsZero())
+
+	second := &model.Application{PetID: pets[1], Applicant: "Jane", Status: model.ApplicationSubmitted}
+	require.NoError(t, rep.AddApplication(ctx, second))
+
+	res, err := rep.GetApplication(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "John", res.Applicant)
+	require.Equal(t, "john@example.com", res.Contact)
+	require.Equal(t, "has a yard", res.Notes)
+	require.Equal(t, model.ApplicationSubmitted, res.Status)
+	require.Nil(t, res.UpdatedAt)
+
+	_, err = rep.GetApplication(ctx, second.ID+1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	res.Status = model.ApplicationApproved
+	res.Reason = "good fit"
+	transition := &model.Transition{PetID: pets[0], From: model.StatusAvailable, To: model.StatusReserved,
+		ApplicationID: &res.ID}
+	require.NoError(t, rep.UpdateApplication(ctx, res, model.ApplicationSubmitted, transition))
+	require.Greater(t, transition.ID, 0)
+
+	res, err = rep.GetApplication(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.ApplicationApproved, res.Status)
+	require.Equal(t, "good fit", res.Reason)
+	require.NotNil(t, res.UpdatedAt)
+
+	pet, err := rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, model.StatusReserved, pet.Status)
+
+	apps, err := rep.GetApplications(ctx, &model.ApplicationsFilter{})
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+
+	apps, err = rep.GetApplications(ctx, &model.ApplicationsFilter{Statuses: []model.ApplicationStatus{model.ApplicationSubmitted}})
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, second.ID, apps[0].ID)
+
+	apps, err = rep.GetApplications(ctx, &model.ApplicationsFilter{PetID: pets[0]})
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, first.ID, apps[0].ID)
+
+	purgePet(t, rep, pets[0])
+
+	apps, err = rep.GetApplications(ctx, &model.ApplicationsFilter{PetID: pets[0]})
+	require.NoError(t, err)
+	require.Empty(t, apps)
+}
+
+// testTransitionPet checks that TransitionPet changes pet status and records history, ErrStale is returned if pet has
+// other status or not found
+func testTransitionPet(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pet := &model.Pet{Name: "Velho", Status: model.StatusAdopted}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	returned := &model.Transition{PetID: pet.ID, From: model.StatusAdopted, To: model.StatusReturned, Reason: "allergy"}
+	require.NoError(t, rep.TransitionPet(ctx, returned))
+	require.Greater(t, returned.ID, 0)
+	require.False(t, returned.CreatedAt.IsZero())
+
+	err := rep.TransitionPet(ctx, &model.Transition{PetID: pet.ID, From: model.StatusAdopted, To: model.StatusReturned})
+	require.ErrorIs(t, err, repository.ErrStale)
+
+	err = rep.TransitionPet(ctx, &model.Transition{PetID: pet.ID + 1, From: model.StatusAdopted, To: model.StatusReturned})
+	require.ErrorIs(t, err, repository.ErrStale)
+
+	require.NoError(t, rep.TransitionPet(ctx, &model.Transition{PetID: pet.ID, From: model.StatusReturned, To: model.StatusAvailable}))
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusAvailable, res.Status)
+	require.NotNil(t, res.UpdatedAt)
+
+	history, err := rep.GetTransitions(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, model.StatusAdopted, history[0].From)
+	require.Equal(t, model.StatusReturned, history[0].To)
+	require.Equal(t, "allergy", history[0].Reason)
+	require.Nil(t, history[0].ApplicationID)
+	require.Equal(t, model.StatusAvailable, history[1].To)
+}
+
+// testTransitionPetAudit checks that a status change made by PUT, which updates the pet and then applies the transition,
+// is recorded in the pet audit with the status diff and the new version. Stale transitions are not recorded
+func testTransitionPetAudit(t *testing.T, rep repository.IRepository) {
+	ctx := model.WithActor(context.Background(), model.Actor{Name: "alice", RequestID: "req-1"})
+
+	pet := &model.Pet{Name: "Velho", Species: model.SpeciesCat, Status: model.StatusAvailable}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	require.NoError(t, rep.UpdatePet(ctx, &model.Pet{ID: pet.ID, Name: "Velho", Species: model.SpeciesCat, Status: model.StatusArchived}))
+	require.NoError(t, rep.TransitionPet(ctx, &model.Transition{PetID: pet.ID, From: model.StatusAvailable, To: model.StatusArchived}))
+
+	err := rep.TransitionPet(ctx, &model.Transition{PetID: pet.ID, From: model.StatusAvailable, To: model.StatusArchived})
+	require.ErrorIs(t, err, repository.ErrStale)
+
+	res, err := rep.GetPet(ctx, pet.ID)
+	require.NoError(t, err)
+
+	entries, total, err := rep.GetAudit(ctx, &model.AuditFilter{PetID: pet.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+
+	archived := entries[0]
+	require.Equal(t, model.AuditUpdate, archived.Action)
+	require.Equal(t, res.Version, archived.Version)
+	require.Equal(t, "alice", archived.Actor)
+	require.Equal(t, "req-1", archived.RequestID)
+	require.Equal(t, model.Diff{"status": {Before: json.RawMessage(`"available"`), After: json.RawMessage(`"archived"`)}},
+		archived.Diff)
+}
+
+// testUpdateApplicationStale checks that UpdateApplication changes nothing if application or pet status was changed
+func testUpdateApplicationStale(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pet := &model.Pet{Name: "Velho", Status: model.StatusPending}
+	require.NoError(t, rep.AddPet(ctx, pet))
+
+	app := &model.Application{PetID: pet.ID, Applicant: "John", Status: model.ApplicationSubmitted}
+	require.NoError(t, rep.AddApplication(ctx, app))
+
+	app.Status = model.ApplicationApproved
+	err := rep.UpdateApplication(ctx, app, model.ApplicationApproved, nil)
+	require.ErrorIs(t, err, repository.ErrStale)
+
+	transition := &model.Transition{PetID: pet.ID, From: model.StatusAvailable, To: model.StatusReserved}
+	err = rep.UpdateApplication(ctx, app, model.ApplicationSubmitted, transition)
+	require.ErrorIs(t, err, repository.ErrStale)
+
+	res, err := rep.GetApplication(ctx, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.ApplicationSubmitted, res.Status)
+
+	history, err := rep.GetTransitions(ctx, pet.ID)
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
+
+func testVaccination(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pets := addPets(t, rep, 2)
+	due := model.NewDate(2024, time.March, 1)
+
+	v := &model.Vaccination{PetID: pets[0], Vaccine: "rabies", GivenOn: model.NewDate(2023, time.March, 1), DueOn: &due,
+		Vet: "Dr. Smith"}
+	require.NoError(t, rep.AddVaccination(ctx, v))
+	require.NotZero(t, v.ID)
+
+	res, err := rep.GetVaccination(ctx, pets[0], v.ID)
+	require.NoError(t, err)
+	require.Equal(t, "rabies", res.Vaccine)
+	require.Equal(t, v.GivenOn.String(), res.GivenOn.String())
+	require.Equal(t, due.String(), res.DueOn.String())
+	require.Nil(t, res.UpdatedAt)
+
+	_, err = rep.GetVaccination(ctx, pets[1], v.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	earlier := &model.Vaccination{PetID: pets[0], Vaccine: "distemper", GivenOn: model.NewDate(2022, time.May, 1)}
+	require.NoError(t, rep.AddVaccination(ctx, earlier))
+
+	list, err := rep.GetVaccinations(ctx, pets[0])
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, earlier.ID, list[0].ID)
+	require.Nil(t, list[0].DueOn)
+
+	v.Notes = "booster"
+	v.DueOn = nil
+	require.NoError(t, rep.UpdateVaccination(ctx, v))
+
+	res, err = rep.GetVaccination(ctx, pets[0], v.ID)
+	require.NoError(t, err)
+	require.Equal(t, "booster", res.Notes)
+	require.Nil(t, res.DueOn)
+	require.NotNil(t, res.UpdatedAt)
+
+	require.ErrorIs(t, rep.UpdateVaccination(ctx, &model.Vaccination{ID: v.ID, PetID: pets[1]}), sql.ErrNoRows)
+	require.ErrorIs(t, rep.DeleteVaccination(ctx, &model.Vaccination{ID: v.ID, PetID: pets[1]}), sql.ErrNoRows)
+
+	require.NoError(t, rep.DeleteVaccination(ctx, v))
+	require.ErrorIs(t, rep.DeleteVaccination(ctx, v), sql.ErrNoRows)
+
+	purgePet(t, rep, pets[0])
+
+	list, err = rep.GetVaccinations(ctx, pets[0])
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func testGetDueVaccinations(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pets := addNamedPets(t, rep, "Velho", "Melho")
+
+	date := func(month time.Month, day int) *model.Date {
+		d := model.NewDate(2024, month, day)
+		return &d
+	}
+
+	vaccinations := []*model.Vaccination{
+		// superseded by the later rabies shot
+		{PetID: pets[0], Vaccine: "rabies", GivenOn: *date(time.January, 1), DueOn: date(time.February, 1)},
+		{PetID: pets[0], Vaccine: "rabies", GivenOn: *date(time.February, 1), DueOn: date(time.May, 1)},
+		{PetID: pets[0], Vaccine: "distemper", GivenOn: *date(time.January, 1), DueOn: date(time.March, 1)},
+		{PetID: pets[1], Vaccine: "rabies", GivenOn: *date(time.January, 1), DueOn: date(time.April, 1)},
+		{PetID: pets[1], Vaccine: "distemper", GivenOn: *date(time.January, 1)},
+	}
+
+	for _, v := range vaccinations {
+		require.NoError(t, rep.AddVaccination(ctx, v))
+	}
+
+	tests := []struct {
+		name    string
+		before  *model.Date
+		wantIDs []int
+	}{
+		{name: "none due", before: date(time.February, 15)},
+		{name: "due on date", before: date(time.March, 1), wantIDs: []int{vaccinations[2].ID}},
+		{name: "all due", before: date(time.December, 1), wantIDs: []int{vaccinations[2].ID, vaccinations[3].ID, vaccinations[1].ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := rep.GetDueVaccinations(ctx, *tt.before)
+			require.NoError(t, err)
+
+			ids := make([]int, 0, len(res))
+			for _, v := range res {
+				ids = append(ids, v.ID)
+			}
+
+			if tt.wantIDs == nil {
+				require.Empty(t, ids)
+			} else {
+				require.Equal(t, tt.wantIDs, ids)
+			}
+		})
+	}
+
+	res, err := rep.GetDueVaccinations(ctx, *date(time.April, 1))
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, "Melho", res[1].PetName)
+	require.Equal(t, "rabies", res[1].Vaccine)
+
+	// vaccinations of deleted pets are skipped
+	require.NoError(t, rep.DeletePet(ctx, &model.Pet{ID: pets[1]}))
+
+	res, err = rep.GetDueVaccinations(ctx, *date(time.April, 1))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "Velho", res[0].PetName)
+}
+
+func testTreatment(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pets := addPets(t, rep, 2)
+
+	tr := &model.Treatment{PetID: pets[0], Kind: model.TreatmentVisit, Name: "checkup", GivenOn: model.NewDate(2023, time.June, 1)}
+	require.NoError(t, rep.AddTreatment(ctx, tr))
+	require.NotZero(t, tr.ID)
+
+	res, err := rep.GetTreatment(ctx, pets[0], tr.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.TreatmentVisit, res.Kind)
+	require.Equal(t, tr.GivenOn.String(), res.GivenOn.String())
+
+	_, err = rep.GetTreatment(ctx, pets[1], tr.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	tr.Kind = model.TreatmentMedication
+	tr.Name = "antibiotics"
+	require.NoError(t, rep.UpdateTreatment(ctx, tr))
+
+	list, err := rep.GetTreatments(ctx, pets[0])
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "antibiotics", list[0].Name)
+	require.NotNil(t, list[0].UpdatedAt)
+
+	require.ErrorIs(t, rep.UpdateTreatment(ctx, &model.Treatment{ID: tr.ID, PetID: pets[1]}), sql.ErrNoRows)
+
+	require.NoError(t, rep.DeleteTreatment(ctx, tr))
+	require.ErrorIs(t, rep.DeleteTreatment(ctx, tr), sql.ErrNoRows)
+
+	list, err = rep.GetTreatments(ctx, pets[0])
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func testPhoto(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pets := addPets(t, rep, 2)
+
+	// the first photo becomes primary, the second one does not
+	first := &model.Photo{PetID: pets[0], BlobKey: "a", ThumbKey: "a_thumb", ContentType: "image/png", Size: 10, Width: 2, Height: 1}
+	require.NoError(t, rep.AddPhoto(ctx, first, false))
+	require.NotZero(t, first.ID)
+
+	second := &model.Photo{PetID: pets[0], BlobKey: "b", ThumbKey: "b_thumb", ContentType: "image/jpeg", Size: 20, Width: 1, Height: 2}
+	require.NoError(t, rep.AddPhoto(ctx, second, false))
+
+	pet, err := rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, first.ID, *pet.PrimaryPhotoID)
+
+	res, err := rep.GetPhoto(ctx, pets[0], second.ID)
+	require.NoError(t, err)
+	require.Equal(t, "b", res.BlobKey)
+	require.Equal(t, "b_thumb", res.ThumbKey)
+	require.Equal(t, "image/jpeg", res.ContentType)
+	require.Equal(t, int64(20), res.Size)
+	require.Equal(t, 2, res.Height)
+
+	_, err = rep.GetPhoto(ctx, pets[1], second.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// pet update keeps the primary photo
+	pet.Name = "Renamed"
+	pet.PrimaryPhotoID = nil
+	require.NoError(t, rep.UpdatePet(ctx, pet))
+
+	require.NoError(t, rep.SetPrimaryPhoto(ctx, second))
+	require.ErrorIs(t, rep.SetPrimaryPhoto(ctx, &model.Photo{ID: second.ID, PetID: pets[1]}), sql.ErrNoRows)
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, "Renamed", pet.Name)
+	require.Equal(t, second.ID, *pet.PrimaryPhotoID)
+
+	// the latest remaining photo becomes primary
+	third := &model.Photo{PetID: pets[0], BlobKey: "c", ThumbKey: "c_thumb", ContentType: "image/gif", Size: 30, Width: 1, Height: 1}
+	require.NoError(t, rep.AddPhoto(ctx, third, false))
+	require.NoError(t, rep.SetPrimaryPhoto(ctx, first))
+	require.NoError(t, rep.DeletePhoto(ctx, first))
+	require.ErrorIs(t, rep.DeletePhoto(ctx, first), sql.ErrNoRows)
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, third.ID, *pet.PrimaryPhotoID)
+
+	// deleting not primary photo keeps the primary one
+	require.NoError(t, rep.DeletePhoto(ctx, second))
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, third.ID, *pet.PrimaryPhotoID)
+
+	require.NoError(t, rep.DeletePhoto(ctx, third))
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Nil(t, pet.PrimaryPhotoID)
+
+	// primary flag replaces existing primary photo
+	require.NoError(t, rep.AddPhoto(ctx, first, false))
+	require.NoError(t, rep.AddPhoto(ctx, second, true))
+
+	list, err := rep.GetPhotos(ctx, pets[0])
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, first.ID, list[0].ID)
+	require.Equal(t, second.ID, list[1].ID)
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, second.ID, *pet.PrimaryPhotoID)
+
+	// photos are deleted with the purged pet
+	purgePet(t, rep, pet.ID)
+
+	list, err = rep.GetPhotos(ctx, pets[0])
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+// testTag checks tag CRUD, ordering by name and category filter
+func testTag(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	senior := &model.Tag{Name: "senior", Category: "age"}
+	require.NoError(t, rep.AddTag(ctx, senior))
+	require.NotZero(t, senior.ID)
+	require.False(t, senior.CreatedAt.IsZero())
+
+	kids := &model.Tag{Name: "good-with-kids", Category: "behavior"}
+	require.NoError(t, rep.AddTag(ctx, kids))
+	require.Greater(t, kids.ID, senior.ID)
+
+	res, err := rep.GetTag(ctx, senior.ID)
+	require.NoError(t, err)
+	require.Equal(t, "senior", res.Name)
+	require.Equal(t, "age", res.Category)
+	require.Nil(t, res.UpdatedAt)
+
+	_, err = rep.GetTag(ctx, 100500)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	list, err := rep.GetTags(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "good-with-kids", list[0].Name)
+	require.Equal(t, "senior", list[1].Name)
+
+	list, err = rep.GetTags(ctx, "age")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, senior.ID, list[0].ID)
+
+	list, err = rep.GetTagsByNames(ctx, []string{"senior", "unknown"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, senior.ID, list[0].ID)
+
+	senior.Name = "elderly"
+	require.NoError(t, rep.UpdateTag(ctx, senior))
+	require.ErrorIs(t, rep.UpdateTag(ctx, &model.Tag{ID: 100500, Name: "none"}), sql.ErrNoRows)
+
+	res, err = rep.GetTag(ctx, senior.ID)
+	require.NoError(t, err)
+	require.Equal(t, "elderly", res.Name)
+	require.NotNil(t, res.UpdatedAt)
+
+	require.NoError(t, rep.DeleteTag(ctx, senior))
+	require.ErrorIs(t, rep.DeleteTag(ctx, senior), sql.ErrNoRows)
+
+	_, err = rep.GetTag(ctx, senior.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// testPetTags checks that SetPetTags replaces pet tags, skips unknown names and that tags are removed with the pet
+// or the tag
+func testPetTags(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	pets := addPets(t, rep, 2)
+	addTags(t, rep, "senior", "special-needs", "good-with-kids")
+
+	require.NoError(t, rep.SetPetTags(ctx, pets[0], []string{"senior", "special-needs", "unknown"}))
+	require.ErrorIs(t, rep.SetPetTags(ctx, 100500, []string{"senior"}), sql.ErrNoRows)
+
+	pet, err := rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"senior", "special-needs"}, pet.Tags)
+
+	// tag set is replaced
+	require.NoError(t, rep.SetPetTags(ctx, pets[0], []string{"good-with-kids", "senior"}))
+	require.NoError(t, rep.SetPetTags(ctx, pets[1], []string{"senior"}))
+
+	res, _, err := rep.GetPets(ctx, &model.PetsQuery{})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, []string{"good-with-kids", "senior"}, res[0].Tags)
+	require.Equal(t, []string{"senior"}, res[1].Tags)
+
+	// deleted tag is removed from pets
+	tags, err := rep.GetTagsByNames(ctx, []string{"senior"})
+	require.NoError(t, err)
+	require.NoError(t, rep.DeleteTag(ctx, tags[0]))
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"good-with-kids"}, pet.Tags)
+
+	// empty list removes all tags
+	require.NoError(t, rep.SetPetTags(ctx, pets[0], nil))
+
+	pet, err = rep.GetPet(ctx, pets[0])
+	require.NoError(t, err)
+	require.Empty(t, pet.Tags)
+
+	// tags are deleted with the purged pet
+	require.NoError(t, rep.SetPetTags(ctx, pets[0], []string{"good-with-kids"}))
+	purgePet(t, rep, pet.ID)
+
+	filter := model.PetsFilter{Tags: []string{"good-with-kids"}, IncludeDeleted: true}
+
+	res, _, err = rep.GetPets(ctx, &model.PetsQuery{Filter: filter})
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
+
+// testGetPetsFilterTags checks tag filter in any and all match modes
+func testGetPetsFilterTags(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addPets(t, rep, 4)
+	addTags(t, rep, "senior", "special-needs", "good-with-kids")
+
+	require.NoError(t, rep.SetPetTags(ctx, ids[0], []string{"senior", "special-needs"}))
+	require.NoError(t, rep.SetPetTags(ctx, ids[1], []string{"senior"}))
+	require.NoError(t, rep.SetPetTags(ctx, ids[2], []string{"special-needs", "good-with-kids"}))
+
+	tests := []struct {
+		name   string
+		filter model.PetsFilter
+		want   []int
+	}{
+		{name: "any by default", filter: model.PetsFilter{Tags: []string{"senior", "good-with-kids"}}, want: []int{ids[0], ids[1], ids[2]}},
+		{name: "any", filter: model.PetsFilter{Tags: []string{"special-needs"}, TagMatch: model.TagAny}, want: []int{ids[0], ids[2]}},
+		{name: "all", filter: model.PetsFilter{Tags: []string{"senior", "special-needs"}, TagMatch: model.TagAll}, want: []int{ids[0]}},
+		{name: "all single", filter: model.PetsFilter{Tags: []string{"senior"}, TagMatch: model.TagAll}, want: []int{ids[0], ids[1]}},
+		{name: "unknown", filter: model.PetsFilter{Tags: []string{"unknown"}}, want: []int{}},
+		{name: "all with unknown", filter: model.PetsFilter{Tags: []string{"senior", "unknown"}, TagMatch: model.TagAll}, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, total, err := rep.GetPets(ctx, &model.PetsQuery{Filter: tt.filter})
+			require.NoError(t, err)
+			require.Equal(t, len(tt.want), total)
+			require.Equal(t, tt.want, petIDs(res))
+		})
+	}
+}
+
+// testExportPets checks that ExportPets calls the function for every matching pet with its tags in sort order ignoring
+// pagination, and stops on the function error
+func testExportPets(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	ids := addNamedPets(t, rep, "Velho", "Bobik", "Murka")
+	addTags(t, rep, "senior", "good-with-kids")
+
+	require.NoError(t, rep.SetPetTags(ctx, ids[0], []string{"senior", "good-with-kids"}))
+	require.NoError(t, rep.DeletePet(ctx, &model.Pet{ID: ids[2]}))
+
+	var res []*model.Pet
+
+	query := &model.PetsQuery{Sort: []model.SortField{{Field: model.SortName}}, Limit: 1, Offset: 1}
+
+	err := rep.ExportPets(ctx, query, func(pet *model.Pet) error {
+		res = append(res, pet)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []int{ids[1], ids[0]}, petIDs(res))
+	require.Empty(t, res[0].Tags)
+	require.Equal(t, []string{"good-with-kids", "senior"}, res[1].Tags)
+
+	stop := errors.New("stop")
+	calls := 0
+
+	err = rep.ExportPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{IncludeDeleted: true}}, func(pet *model.Pet) error {
+		calls++
+		return stop
+	})
+
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, calls)
+}
+
+// testExportPetsPaused checks that other queries are not blocked while the export function is running and that pets
+// with equal sort fields are exported once in many pages
+func testExportPetsPaused(t *testing.T, rep repository.IRepository) {
+	ctx := context.Background()
+
+	names := []string{"bob", "Alice", "Bob"}
+	pets := make([]*model.Pet, 1200)
+
+	for i := range pets {
+		pets[i] = &model.Pet{Name: names[i%len(names)]}
+	}
+
+	require.NoError(t, rep.AddPets(ctx, pets))
+
+	var bobs, alices []int
+	for _, pet := range pets {
+		if pet.Name == "Alice" {
+			alices = append(alices, pet.ID)
+		} else {
+			bobs = append(bobs, pet.ID)
+		}
+	}
+
+	var res []*model.Pet
+
+	query := &model.PetsQuery{Sort: []model.SortField{{Field: model.SortName, Desc: true}}}
+
+	err := rep.ExportPets(ctx, query, func(pet *model.Pet) error {
+		res = append(res, pet)
+
+		getCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		_, err := rep.GetPet(getCtx, pet.ID)
+
+		return err
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, append(bobs, alices...), petIDs(res))
+}
+
+// addTags is used to add tags with given names
+func addTags(t *testing.T, rep repository.IRepository, names ...string) {
+	for _, name := range names {
+		require.NoError(t, rep.AddTag(context.Background(), &model.Tag{Name: name}))
+	}
+}
+
+// purgePet is used to soft delete and then purge the pet with given ID
+func purgePet(t *testing.T, rep repository.IRepository, id int) {
+	require.NoError(t, rep.DeletePet(context.Background(), &model.Pet{ID: id}))
+	require.NoError(t, rep.PurgePet(context.Background(), &model.Pet{ID: id}))
+}
+
+// addPets is used to add n pets and get their IDs in adding order
+func addPets(t *testing.T, rep repository.IRepository, n int) []int {
+	ids := make([]int, 0, n)
+
+	for i := 0; i < n; i++ {
+		pet := &model.Pet{Name: fmt.Sprintf("Pet%v", i)}
+		require.NoError(t, rep.AddPet(context.Background(), pet))
+
+		ids = append(ids, pet.ID)
+	}
+
+	return ids
+}
+
+// addNamedPets is used to add pets with given names and get their IDs in adding order
+func addNamedPets(t *testing.T, rep repository.IRepository, names ...string) []int {
+	ids := make([]int, 0, len(names))
+
+	for _, name := range names {
+		pet := &model.Pet{Name: name}
+		require.NoError(t, rep.AddPet(context.Background(), pet))
+
+		ids = append(ids, pet.ID)
+	}
+
+	return ids
+}
+
+// getPets is used to get stored pets by given IDs
+func getPets(t *testing.T, rep repository.IRepository, ids []int) []*model.Pet {
+	pets := make([]*model.Pet, 0, len(ids))
+
+	for _, id := range ids {
+		pet, err := rep.GetPet(context.Background(), id)
+		require.NoError(t, err)
+
+		pets = append(pets, pet)
+	}
+
+	return pets
+}
+
+// petIDs is used to get IDs of given pets
+func petIDs(pets []*model.Pet) []int {
+	ids := make([]int, 0, len(pets))
+
+	for _, p := range pets {
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
+
+// addOwners is used to add n owners and get their IDs in adding order
+func addOwners(t *testing.T, rep repository.IRepository, n int) []int {
+	ids := make([]int, 0, n)
+
+	for i := 0; i < n; i++ {
+		owner := &model.Owner{Name: fmt.Sprintf("Owner%v", i), Email: fmt.Sprintf("owner%v@example.com", i)}
+		require.NoError(t, rep.AddOwner(context.Background(), owner))
+
+		ids = append(ids, owner.ID)
+	}
+
+	return ids
+}
+
+// ownerIDs is used to get IDs of given owners
+func ownerIDs(owners []*model.Owner) []int {
+	ids := make([]int, 0, len(owners))
+
+	for _, o := range owners {
+		ids = append(ids, o.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"pets/internal/config"
+	"pets/internal/repository"
+	"pets/internal/repository/repotest"
+)
+
+func TestRepository_SQLite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.IRepository {
+		return repository.NewRepository(&config.DB{Driver: repository.SQLiteDriver, Addr: ":memory:", AutoMigrate: true})
+	})
+}
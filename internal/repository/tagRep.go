@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// tagColumns is a list of tags table columns selected to model.Tag
+const tagColumns = `id, name, category, created_at, updated_at`
+
+// GetTags is used to get tags from DB ordered by name. Blank category will be ignored, otherwise only tags of given
+// category are returned
+func (r *Repository) GetTags(ctx context.Context, category string) (tags []*model.Tag, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := `SELECT ` + tagColumns + ` FROM tags`
+	var args []interface{}
+
+	if category != "" {
+		q += ` WHERE category = ?`
+		args = append(args, category)
+	}
+
+	err = r.db.SelectContext(ctx, &tags, r.db.Rebind(q+` ORDER BY name`), args...)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetTags").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetTagsByNames is used to get tags with given names from DB ordered by name. Unknown names are skipped
+func (r *Repository) GetTagsByNames(ctx context.Context, names []string) (tags []*model.Tag, err error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(fmt.Sprintf(`SELECT `+tagColumns+` FROM tags WHERE name IN (%v) ORDER BY name`,
+		placeholders(len(names))))
+
+	err = r.db.SelectContext(ctx, &tags, q, stringArgs(names)...)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetTagsByNames").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetTag is used to get tag from DB by given ID. Will return sql.ErrNoRows if tag not found
+func (r *Repository) GetTag(ctx context.Context, id int) (tag *model.Tag, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tag = &model.Tag{}
+
+	err = r.db.GetContext(ctx, tag, r.db.Rebind(`SELECT `+tagColumns+` FROM tags WHERE id = ? LIMIT 1`), id)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-GetTag").Errorf("err query: %v", err.Error())
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// AddTag is used to add new tag to the DB. Fields id and created_at will be set automatically
+func (r *Repository) AddTag(ctx context.Context, tag *model.Tag) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	q := r.db.Rebind(`INSERT INTO tags (name, category, created_at) VALUES (?, ?, ?) RETURNING id`)
+
+	tag.CreatedAt = utcNow()
+
+	err := r.db.QueryRowContext(ctx, q, tag.Name, tag.Category, tag.CreatedAt).Scan(&tag.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-AddTag").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTag is used to update existing tag in the DB by given id field. Field updated_at will be set automatically,
+// versions of pets having the tag are incremented. Will return sql.ErrNoRows if tag not found
+func (r *Repository) UpdateTag(ctx context.Context, tag *model.Tag) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateTag").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	now := utcNow()
+
+	q := tx.Rebind(`UPDATE tags SET name = ?, category = ?, updated_at = ? WHERE id = ?`)
+
+	res, err := tx.ExecContext(ctx, q, tag.Name, tag.Category, now, tag.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateTag").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = affected(res); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, tx.Rebind(taggedPetsVersion), tag.ID); err != nil {
+		logger.Log().WithField("layer", "Repository-UpdateTag").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	tag.UpdatedAt = &now
+
+	return nil
+}
+
+// DeleteTag is used to delete tag from the DB by given id with its pet assignments. Versions of pets having the tag
+// are incremented. Will return sql.ErrNoRows if tag not found
+func (r *Repository) DeleteTag(ctx context.Context, tag *model.Tag) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteTag").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, tx.Rebind(taggedPetsVersion), tag.ID); err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteTag").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM tags WHERE id = ?`), tag.ID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-DeleteTag").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = affected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// taggedPetsVersion is a query incrementing versions of pets having the tag with given ID, as their tags are changed
+const taggedPetsVersion = `UPDATE pets SET version = version + 1 WHERE id IN (SELECT pet_id FROM pet_tags WHERE tag_id = ?)`
+
+// SetPetTags is used to replace tags of the pet with given ID in the DB by tags with given names in one transaction,
+// the pet version is incremented. Unknown names are skipped. Will return sql.ErrNoRows if pet not found or deleted
+func (r *Repository) SetPetTags(ctx context.Context, petID int, names []string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-SetPetTags").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	q := tx.Rebind(`UPDATE pets SET version = version + 1 WHERE id = ? AND deleted_at IS NULL`)
+
+	res, err := tx.ExecContext(ctx, q, petID)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-SetPetTags").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if err = affected(res); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, tx.Rebind(`DELETE FROM pet_tags WHERE pet_id = ?`), petID); err != nil {
+		logger.Log().WithField("layer", "Repository-SetPetTags").Errorf("err query: %v", err.Error())
+		return err
+	}
+
+	if len(names) != 0 {
+		q = tx.Rebind(fmt.Sprintf(`INSERT INTO pet_tags (pet_id, tag_id) SELECT ?, id FROM tags WHERE name IN (%v)`,
+			placeholders(len(names))))
+
+		if _, err = tx.ExecContext(ctx, q, append([]interface{}{petID}, stringArgs(names)...)...); err != nil {
+			logger.Log().WithField("layer", "Repository-SetPetTags").Errorf("err query: %v", err.Error())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// petTag is a pet tag name selected from pet_tags table
+type petTag struct {
+	PetID int    `db:"pet_id"`
+	Name  string `db:"name"`
+}
+
+// loadTags is used to set tags of given pets from the DB ordered by name
+func (r *Repository) loadTags(ctx context.Context, pets ...*model.Pet) error {
+	if len(pets) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Pet, len(pets))
+	args := make([]interface{}, 0, len(pets))
+
+	for _, p := range pets {
+		byID[p.ID] = p
+		args = append(args, p.ID)
+	}
+
+	q := r.db.Rebind(fmt.Sprintf(`SELECT pt.pet_id, t.name FROM pet_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.pet_id IN (%v) ORDER BY t.name`, placeholders(len(pets))))
+
+	var tags []petTag
+
+	if err := r.db.SelectContext(ctx, &tags, q, args...); err != nil {
+		return err
+	}
+
+	for _, t := range tags {
+		p := byID[t.PetID]
+		p.Tags = append(p.Tags, t.Name)
+	}
+
+	return nil
+}
+
+// stringArgs is used to get query args from given strings
+func stringArgs(s []string) []interface{} {
+	args := make([]interface{}, 0, len(s))
+	for _, v := range s {
+		args = append(args, v)
+	}
+
+	return args
+}
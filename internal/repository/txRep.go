@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"pets/pkg/logger"
+)
+
+// queryer is a DB handle queries are run on, implemented by *sqlx.DB and *sqlx.Tx
+type queryer interface {
+	Rebind(query string) string
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// txRetryDelay is a delay before the first retry of a transaction failed to serialize, every next retry waits longer
+const txRetryDelay = 20 * time.Millisecond
+
+// Postgres error codes of transactions failed to serialize with concurrent ones
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// txn is a transaction of Repository methods. If Repository is bound to a transaction, txn is a savepoint of it, so
+// the method changes are committed or rolled back with the bound transaction
+type txn struct {
+	*sqlx.Tx
+	// savepoint is a savepoint name. Blank if txn is a DB transaction
+	savepoint string
+	// done is true if the savepoint is released or rolled back
+	done bool
+}
+
+// begin is used to begin a transaction with Repository isolation level or a savepoint if Repository is bound to a
+// transaction
+func (r *Repository) begin(ctx context.Context) (*txn, error) {
+	if r.tx == nil {
+		tx, err := r.conn.BeginTxx(ctx, &sql.TxOptions{Isolation: r.isolation})
+		if err != nil {
+			return nil, err
+		}
+
+		return &txn{Tx: tx}, nil
+	}
+
+	*r.savepoints++
+	t := &txn{Tx: r.tx, savepoint: fmt.Sprintf("sp_%v", *r.savepoints)}
+
+	if _, err := r.tx.ExecContext(ctx, "SAVEPOINT "+t.savepoint); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Commit is used to commit the transaction or to release the savepoint
+func (t *txn) Commit() error {
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+
+	t.done = true
+
+	_, err := t.Exec("RELEASE SAVEPOINT " + t.savepoint)
+
+	return err
+}
+
+// Rollback is used to roll back the transaction or the changes made after the savepoint. It is a no-op if the
+// transaction is already committed or rolled back
+func (t *txn) Rollback() error {
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+
+	if t.done {
+		return nil
+	}
+
+	t.done = true
+
+	if _, err := t.Exec("ROLLBACK TO SAVEPOINT " + t.savepoint); err != nil {
+		return err
+	}
+
+	_, err := t.Exec("RELEASE SAVEPOINT " + t.savepoint)
+
+	return err
+}
+
+// WithTx is implementing IRepository.WithTx function. Nested transactions are savepoints of the bound transaction and
+// are not retried, the whole transaction is retried instead
+func (r *Repository) WithTx(ctx context.Context, fn func(tx IRepository) error) error {
+	for attempt := 0; ; attempt++ {
+		err := r.runTx(ctx, fn)
+		if err == nil || r.tx != nil || attempt >= r.retries || !serializationFailure(err) {
+			return err
+		}
+
+		logger.Log().WithField("layer", "Repository-WithTx").Warningf("retry %v of tx: %v", attempt+1, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * txRetryDelay):
+		}
+	}
+}
+
+// runTx is used to run given function in a single transaction attempt. Deferred rollback is a no-op after commit and
+// rolls back the transaction if the function panics
+func (r *Repository) runTx(ctx context.Context, fn func(tx IRepository) error) error {
+	t, err := r.begin(ctx)
+	if err != nil {
+		logger.Log().WithField("layer", "Repository-WithTx").Errorf("err begin tx: %v", err.Error())
+		return err
+	}
+	defer t.Rollback()
+
+	bound := &Repository{
+		db:         t.Tx,
+		driver:     r.driver,
+		timeout:    r.timeout,
+		isolation:  r.isolation,
+		retries:    r.retries,
+		tx:         t.Tx,
+		savepoints: r.savepoints,
+	}
+
+	if bound.savepoints == nil {
+		bound.savepoints = new(int)
+	}
+
+	if err = fn(bound); err != nil {
+		return err
+	}
+
+	return t.Commit()
+}
+
+// serializationFailure is used to check if given error is returned because the Postgres transaction could not be
+// serialized with concurrent ones, so it can be retried. SQLite transactions are not retried, they run one at a time
+// on the single SQLite connection
+func serializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+	}
+
+	return false
+}
+
+// ParseIsolation is used to get sql.IsolationLevel by given config.DB TxIsolation name. Blank name is the DB default
+// level
+func ParseIsolation(name string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "default":
+		return sql.LevelDefault, nil
+	case "read_committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable_read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	}
+
+	return 0, fmt.Errorf("unknown isolation level %q", name)
+}
@@ -0,0 +1,128 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/config"
+	"pets/internal/model"
+	"pets/internal/repository"
+)
+
+func TestRepository_WithTxRetry(t *testing.T) {
+	errSerialization := &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+	errDeadlock := &pq.Error{Code: "40P01", Message: "deadlock detected"}
+
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "check serialization failure retried",
+			failures:  2,
+			err:       fmt.Errorf("wrapped: %w", errSerialization),
+			wantCalls: 3,
+		},
+		{
+			name:      "check deadlock retried",
+			failures:  1,
+			err:       errDeadlock,
+			wantCalls: 2,
+		},
+		{
+			name:      "check retries exceeded",
+			failures:  3,
+			err:       errSerialization,
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "check other pq error not retried",
+			failures:  1,
+			err:       &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "check other error not retried",
+			failures:  1,
+			err:       errors.New("failed"),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := repository.NewRepository(&config.DB{Driver: repository.SQLiteDriver, Addr: ":memory:", AutoMigrate: true, TxRetries: 2})
+			defer rep.Stop()
+
+			ctx := context.Background()
+			calls := 0
+
+			err := rep.WithTx(ctx, func(tx repository.IRepository) error {
+				calls++
+
+				if err := tx.AddPet(ctx, &model.Pet{Name: "Velho"}); err != nil {
+					return err
+				}
+
+				if calls <= tt.failures {
+					return tt.err
+				}
+
+				return nil
+			})
+
+			require.Equal(t, tt.wantCalls, calls)
+
+			_, total, getErr := rep.GetPets(ctx, &model.PetsQuery{})
+			require.NoError(t, getErr)
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, tt.err)
+				require.Equal(t, 0, total)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, 1, total)
+		})
+	}
+}
+
+func TestParseIsolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    sql.IsolationLevel
+		wantErr bool
+	}{
+		{name: "", want: sql.LevelDefault},
+		{name: "read_committed", want: sql.LevelReadCommitted},
+		{name: "Repeatable_Read", want: sql.LevelRepeatableRead},
+		{name: "serializable", want: sql.LevelSerializable},
+		{name: "snapshot", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run("check "+tt.name, func(t *testing.T) {
+			got, err := repository.ParseIsolation(tt.name)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
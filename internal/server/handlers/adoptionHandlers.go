@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/pkg/logger"
+)
+
+// SubmitApplication is a handler func for POST /pet/{id}/applications route
+// Will return submitted application ID in responses.AddApplicationResp format
+// Will return 400 status if no request.Body provided, application fields are invalid or ID in path is less than 0
+// Will return 404 status if pet not found
+// Will return 409 status if pet is not available
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) SubmitApplication() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-SubmitApplication").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.ApplicationReq{}
+
+		if err = decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-SubmitApplication").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"applicant":string}`))
+			return
+		}
+
+		appID, err := h.srv.SubmitApplication(request.Context(), model.GetApplicationFromReq(id, req))
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusCreated, &responses.AddApplicationResp{ID: appID})
+	}
+}
+
+// GetPetApplications is a handler func for GET /pet/{id}/applications route
+// Will return pet applications in responses.GetApplicationsResp format, oldest first
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetPetApplications() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetPetApplications").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetPetApplications(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writeApplications(writer, request, res)
+	}
+}
+
+// GetApplications is a handler func for GET /applications route. Applications can be filtered by query params status
+// (comma separated list) and pet_id
+// Will return applications in responses.GetApplicationsResp format, oldest first
+// Will return 400 status if query params are invalid
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetApplications() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		filter := &model.ApplicationsFilter{}
+
+		for _, s := range queryList(request, "status") {
+			filter.Statuses = append(filter.Statuses, model.ApplicationStatus(s))
+		}
+
+		if v := request.URL.Query().Get("pet_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil || id <= 0 {
+				writeError(writer, request, invalidParam("pet_id", "invalid pet_id: should be a number more than 0"))
+				return
+			}
+
+			filter.PetID = id
+		}
+
+		res, err := h.srv.GetApplications(request.Context(), filter)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writeApplications(writer, request, res)
+	}
+}
+
+// GetApplication is a handler func for GET /applications/{id} route
+// Will return application in model.Application format if application found
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if application not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetApplication() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetApplication").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetApplication(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// ApproveApplication is a handler func for POST /applications/{id}/approve route. Optional body is
+// requests.DecisionReq
+// Will return approved application in model.Application format
+// Will return 400 status if body or ID in path are invalid
+// Will return 404 status if application not found
+// Will return 409 status if application is not submitted or pet is not available
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) ApproveApplication() http.HandlerFunc {
+	return h.decideApplication("Handlers-ApproveApplication", h.srv.ApproveApplication)
+}
+
+// RejectApplication is a handler func for POST /applications/{id}/reject route. Optional body is
+// requests.DecisionReq
+// Will return rejected application in model.Application format
+// Will return 400 status if body or ID in path are invalid
+// Will return 404 status if application not found
+// Will return 409 status if application is already rejected or completed
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) RejectApplication() http.HandlerFunc {
+	return h.decideApplication("Handlers-RejectApplication", h.srv.RejectApplication)
+}
+
+// CompleteApplication is a handler func for POST /applications/{id}/complete route. Optional body is
+// requests.DecisionReq
+// Will return completed application in model.Application format
+// Will return 400 status if body or ID in path are invalid
+// Will return 404 status if application not found
+// Will return 409 status if application is not approved
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) CompleteApplication() http.HandlerFunc {
+	return h.decideApplication("Handlers-CompleteApplication", h.srv.CompleteApplication)
+}
+
+// ReturnPet is a handler func for POST /pet/{id}/return route. Optional body is requests.DecisionReq
+// Will return recorded transition in model.Transition format
+// Will return 400 status if body or ID in path are invalid
+// Will return 404 status if pet not found
+// Will return 409 status if pet is not adopted
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) ReturnPet() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ReturnPet").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		reason, err := getDecisionReason(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ReturnPet").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"reason":string} or no body`))
+			return
+		}
+
+		res, err := h.srv.ReturnPet(request.Context(), id, reason)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// GetTransitions is a handler func for GET /pet/{id}/transitions route
+// Will return pet status history in responses.GetTransitionsResp format, oldest transition first
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetTransitions() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetTransitions").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetTransitions(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Transition{}
+		}
+
+		render(writer, request, http.StatusOK, &responses.GetTransitionsResp{Transitions: res})
+	}
+}
+
+// decideApplication is used to get handler func applying given service decision to the application with path ID
+func (h *Handlers) decideApplication(layer string, decide func(ctx context.Context, id int, reason string) (*model.Application, error)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", layer).Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		reason, err := getDecisionReason(request)
+		if err != nil {
+			logger.Log().WithField("layer", layer).Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"reason":string} or no body`))
+			return
+		}
+
+		res, err := decide(request.Context(), id, reason)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// getDecisionReason is used to get reason from optional requests.DecisionReq body. Empty body gives blank reason
+func getDecisionReason(request *http.Request) (string, error) {
+	req := &requests.DecisionReq{}
+
+	if err := decode(request, req); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return req.Reason, nil
+}
+
+// writeApplications is used to write given applications in responses.GetApplicationsResp format
+func writeApplications(writer http.ResponseWriter, request *http.Request, apps []*model.Application) {
+	if apps == nil {
+		apps = []*model.Application{}
+	}
+
+	render(writer, request, http.StatusOK, &responses.GetApplicationsResp{Applications: apps})
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_SubmitApplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		req  *requests.ApplicationReq
+
+		goToSev bool
+		id      int
+		srvErr  error
+
+		wantBody   *responses.AddApplicationResp
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 201",
+			req:        &requests.ApplicationReq{Applicant: "John", Notes: "has a garden"},
+			goToSev:    true,
+			id:         3,
+			wantBody:   &responses.AddApplicationResp{ID: 3},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 400 no body",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"applicant":string}`,
+		},
+		{
+			name:       "check 409 pet reserved",
+			req:        &requests.ApplicationReq{Applicant: "John"},
+			goToSev:    true,
+			srvErr:     service.NewConflictError("pet 1 is reserved, only available pets can be adopted"),
+			wantStatus: http.StatusConflict,
+			wantErr:    "pet 1 is reserved, only available pets can be adopted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			submitApplication := h.SubmitApplication()
+
+			res := httptest.NewRecorder()
+			var b []byte
+
+			if tt.req != nil {
+				b, _ = json.Marshal(tt.req)
+			}
+
+			req, _ := http.NewRequest("POST", "/pet/1/applications", bytes.NewReader(b))
+			req = withPathID(req, "1")
+
+			if tt.goToSev {
+				srvMock.EXPECT().SubmitApplication(gomock.Any(), model.GetApplicationFromReq(1, tt.req)).Return(tt.id, tt.srvErr)
+			}
+
+			submitApplication.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_ApproveApplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		body string
+
+		goToSev bool
+		reason  string
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200 no body",
+			goToSev:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 reason",
+			body:       `{"reason":"home visit passed"}`,
+			goToSev:    true,
+			reason:     "home visit passed",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong body",
+			body:       `{"reason":`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"reason":string} or no body`,
+		},
+		{
+			name:       "check 409 not submitted",
+			goToSev:    true,
+			srvErr:     service.NewConflictError("application 2 is rejected, cannot be approved"),
+			wantStatus: http.StatusConflict,
+			wantErr:    "application 2 is rejected, cannot be approved",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			approveApplication := h.ApproveApplication()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/applications/2/approve", bytes.NewBufferString(tt.body))
+			req = withPathID(req, "2")
+
+			app := &model.Application{ID: 2, PetID: 1, Status: model.ApplicationApproved, Reason: tt.reason}
+
+			if tt.goToSev {
+				if tt.srvErr != nil {
+					srvMock.EXPECT().ApproveApplication(gomock.Any(), 2, tt.reason).Return(nil, tt.srvErr)
+				} else {
+					srvMock.EXPECT().ApproveApplication(gomock.Any(), 2, tt.reason).Return(app, nil)
+				}
+			}
+
+			approveApplication.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(app)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_GetApplications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		url  string
+
+		goToSev bool
+		filter  *model.ApplicationsFilter
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:    "check 200 filter",
+			url:     "/applications?status=submitted,approved&pet_id=1",
+			goToSev: true,
+			filter: &model.ApplicationsFilter{
+				PetID:    1,
+				Statuses: []model.ApplicationStatus{model.ApplicationSubmitted, model.ApplicationApproved},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 no filter",
+			url:        "/applications",
+			goToSev:    true,
+			filter:     &model.ApplicationsFilter{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong pet",
+			url:        "/applications?pet_id=cat",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid pet_id: should be a number more than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getApplications := h.GetApplications()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+
+			if tt.goToSev {
+				srvMock.EXPECT().GetApplications(gomock.Any(), tt.filter).Return(nil, nil)
+			}
+
+			getApplications.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(&responses.GetApplicationsResp{Applications: []*model.Application{}})
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
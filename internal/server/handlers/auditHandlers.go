@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/pkg/logger"
+)
+
+// ActorHeader is a request header with the client name recorded in the pet audit. The name is advisory, it is not
+// authenticated
+const ActorHeader = "X-Actor"
+
+// anonymousActor is an actor name recorded in the pet audit for requests without ActorHeader
+const anonymousActor = "anonymous"
+
+// Actor is a middleware used to set model.Actor of the request context from ActorHeader and the request id, so pet
+// changes are recorded in the pet audit with them. The request id is returned in X-Request-Id response header. Will
+// return 400 status if ActorHeader is longer than model.MaxActorName or has not printable characters
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		reqID := middleware.GetReqID(request.Context())
+		writer.Header().Set(middleware.RequestIDHeader, reqID)
+
+		name := strings.TrimSpace(request.Header.Get(ActorHeader))
+		if name == "" {
+			name = anonymousActor
+		}
+
+		if err := model.CheckActorName(name); err != nil {
+			logger.Log().WithField("layer", "Handlers-Actor").Warningf("wrong actor: %v", err.Error())
+			writeError(writer, request, invalidParam(ActorHeader, err.Error()))
+			return
+		}
+
+		ctx := model.WithActor(request.Context(), model.Actor{Name: name, RequestID: reqID})
+
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// GetAudit is a handler func for GET /audit route
+// Will return pet audit entries in responses.GetAuditResp format with pagination metadata and Link header, newest
+// first. Entries can be filtered by pet_id, actor, action, request_id, since and until query params
+// Will return 400 status if filter params are invalid
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetAudit() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		filter, err := getAuditFilter(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetAudit").Warningf("wrong query: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if v := request.URL.Query().Get("pet_id"); v != "" {
+			if filter.PetID, err = strconv.Atoi(v); err != nil || filter.PetID < 1 {
+				writeError(writer, request, invalidParam("pet_id", fmt.Sprintf("invalid pet_id %q", v)))
+				return
+			}
+		}
+
+		h.writeAudit(writer, request, filter, "Handlers-GetAudit")
+	}
+}
+
+// GetPetHistory is a handler func for GET /pet/{id}/history route
+// Will return the pet audit entries in responses.GetAuditResp format with pagination metadata and Link header, newest
+// first. Entries can be filtered as in GET /audit route, pet_id query param is ignored. History of deleted and purged
+// pets is kept
+// Will return 400 status if ID in path is not a number or less than 0 or filter params are invalid
+// Will return 404 status if pet not found and has no history
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetPetHistory() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetPetHistory").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		filter, err := getAuditFilter(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetPetHistory").Warningf("wrong query: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		filter.PetID = id
+
+		h.writeAudit(writer, request, filter, "Handlers-GetPetHistory")
+	}
+}
+
+// RevertPet is a handler func for POST /pet/{id}/revert route. Fields changed by the client are set back to their
+// values at the version given in requests.RevertReq body, status, owner, tags and photos are kept. The revert is
+// recorded in the pet history as a new version
+// Will return reverted pet in model.Pet format with ETag header
+// Will return 400 status if no request.Body provided, version is out of range or ID in path is less than 0
+// Will return 404 status if pet not found or the version is not in the pet history
+// Will return 412 status if If-Match header does not match the pet ETag
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) RevertPet() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-RevertPet").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.RevertReq{}
+
+		if err = decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-RevertPet").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"version":int}`))
+			return
+		}
+
+		stored, err := h.srv.GetPet(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		version, err := ifMatchVersion(request, stored)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.RevertPet(request.Context(), &model.Pet{ID: id, Version: version}, req.Version)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if etag, err := petETag(request, res); err == nil {
+			writer.Header().Set("ETag", etag)
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// writeAudit is used to get audit entries matching given filter and write them in responses.GetAuditResp format.
+// Errors are logged with given layer
+func (h *Handlers) writeAudit(writer http.ResponseWriter, request *http.Request, filter *model.AuditFilter, layer string) {
+	res, total, err := h.srv.GetAudit(request.Context(), filter)
+	if err != nil {
+		writeError(writer, request, err)
+		return
+	}
+
+	if res == nil {
+		res = []*model.AuditEntry{}
+	}
+
+	resp := &responses.GetAuditResp{
+		Entries: res,
+		Total:   total,
+	}
+
+	setAuditPagination(writer, request, resp, filter.Limit, filter.Offset)
+
+	render(writer, request, http.StatusOK, resp)
+}
+
+// getAuditFilter is used to get model.AuditFilter from audit query params except pet_id. Not convertable limit and
+// offset are ignored as in other list routes, invalid times will return service.ErrValidation kind error
+func getAuditFilter(request *http.Request) (*model.AuditFilter, error) {
+	values := request.URL.Query()
+
+	filter := &model.AuditFilter{
+		Actor:     values.Get("actor"),
+		Action:    model.AuditAction(values.Get("action")),
+		RequestID: values.Get("request_id"),
+	}
+
+	filter.Limit, filter.Offset = pageBounds(queryInt(request, "limit"), queryInt(request, "offset"))
+
+	var err error
+
+	if filter.Since, err = queryTime(request, "since"); err != nil {
+		return nil, err
+	}
+
+	if filter.Until, err = queryTime(request, "until"); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_GetAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	since := time.Date(2023, time.November, 26, 10, 0, 0, 0, time.UTC)
+	entries := []*model.AuditEntry{{ID: 2, PetID: 1, Version: 2, Action: model.AuditUpdate, Actor: "alice"}}
+
+	tests := []struct {
+		name   string
+		url    string
+		filter *model.AuditFilter
+
+		srvEntries []*model.AuditEntry
+		srvTotal   int
+		srvErr     error
+
+		wantStatus int
+		wantErr    string
+		wantResp   *responses.GetAuditResp
+		wantLink   string
+	}{
+		{
+			name:       "check 200 with filters",
+			url:        "/audit?pet_id=1&actor=alice&action=update&request_id=abc&since=2023-11-26T10:00:00Z&limit=1",
+			filter:     &model.AuditFilter{PetID: 1, Actor: "alice", Action: model.AuditUpdate, RequestID: "abc", Since: &since, Limit: 1},
+			srvEntries: entries,
+			srvTotal:   2,
+			wantStatus: http.StatusOK,
+			wantResp: &responses.GetAuditResp{
+				Entries: entries,
+				Total:   2,
+				Limit:   1,
+				HasMore: true,
+				Next:    "/audit?action=update&actor=alice&limit=1&offset=1&pet_id=1&request_id=abc&since=2023-11-26T10%3A00%3A00Z",
+			},
+			wantLink: `</audit?action=update&actor=alice&limit=1&offset=1&pet_id=1&request_id=abc&since=2023-11-26T10%3A00%3A00Z>; rel="next"`,
+		},
+		{
+			name:       "check 200 empty",
+			url:        "/audit",
+			filter:     &model.AuditFilter{},
+			wantStatus: http.StatusOK,
+			wantResp:   &responses.GetAuditResp{Entries: []*model.AuditEntry{}},
+		},
+		{
+			name:       "check 400 pet_id",
+			url:        "/audit?pet_id=dog",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `invalid pet_id "dog"`,
+		},
+		{
+			name:       "check 400 since",
+			url:        "/audit?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid since: should be RFC 3339 time",
+		},
+		{
+			name:       "check 400 action",
+			url:        "/audit?action=rename",
+			filter:     &model.AuditFilter{Action: "rename"},
+			srvErr:     service.NewValidationError("invalid audit filter", service.FieldError{Field: "action", Message: `unknown action "rename"`}),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid audit filter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getAudit := h.GetAudit()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+
+			if tt.filter != nil {
+				srvMock.EXPECT().GetAudit(gomock.Any(), tt.filter).Return(tt.srvEntries, tt.srvTotal, tt.srvErr)
+			}
+
+			getAudit.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			want := httptest.NewRecorder()
+			json.NewEncoder(want).Encode(tt.wantResp)
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, want.Body.String(), res.Body.String())
+			require.Equal(t, tt.wantLink, res.Header().Get("Link"))
+		})
+	}
+}
+
+func TestHandlers_GetPetHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	entries := []*model.AuditEntry{{ID: 1, PetID: 1, Version: 1, Action: model.AuditCreate, Actor: "alice"}}
+
+	tests := []struct {
+		name string
+		id   string
+		url  string
+
+		goToSev bool
+		srvErr  error
+
+		wantFilter *model.AuditFilter
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			url:        "/pet/1/history?pet_id=2&action=create",
+			goToSev:    true,
+			wantFilter: &model.AuditFilter{PetID: 1, Action: model.AuditCreate},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 0 id",
+			id:         "0",
+			url:        "/pet/0/history",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 404 not exist",
+			id:         "1",
+			url:        "/pet/1/history",
+			goToSev:    true,
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantFilter: &model.AuditFilter{PetID: 1},
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getHistory := h.GetPetHistory()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			req = withPathID(req, tt.id)
+
+			if tt.goToSev {
+				var found []*model.AuditEntry
+				if tt.srvErr == nil {
+					found = entries
+				}
+
+				srvMock.EXPECT().GetAudit(gomock.Any(), tt.wantFilter).Return(found, len(found), tt.srvErr)
+			}
+
+			getHistory.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			want := httptest.NewRecorder()
+			json.NewEncoder(want).Encode(&responses.GetAuditResp{Entries: entries, Total: 1})
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, want.Body.String(), res.Body.String())
+		})
+	}
+}
+
+func TestHandlers_RevertPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	stored := &model.Pet{ID: 1, Name: "Bobik", Version: 2, Tags: []string{}}
+	reverted := &model.Pet{ID: 1, Name: "Velho", Version: 3, Tags: []string{}}
+
+	tests := []struct {
+		name    string
+		id      string
+		body    string
+		ifMatch string
+
+		goToGet     bool
+		goToSev     bool
+		wantTo      int
+		wantVersion int
+		srvErr      error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			body:       `{"version":1}`,
+			goToGet:    true,
+			goToSev:    true,
+			wantTo:     1,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "check 200 with If-Match",
+			id:          "1",
+			body:        `{"version":1}`,
+			ifMatch:     `"2"`,
+			goToGet:     true,
+			goToSev:     true,
+			wantTo:      1,
+			wantVersion: 2,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:       "check 400 0 id",
+			id:         "0",
+			body:       `{"version":1}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 400 no body",
+			id:         "1",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"version":int}`,
+		},
+		{
+			name:       "check 400 version out of range",
+			id:         "1",
+			body:       `{"version":5}`,
+			goToGet:    true,
+			goToSev:    true,
+			wantTo:     5,
+			srvErr:     service.NewValidationError("invalid version", service.FieldError{Field: "version", Message: "should be from 1 to 2"}),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid version",
+		},
+		{
+			name:       "check 412 If-Match",
+			id:         "1",
+			body:       `{"version":1}`,
+			ifMatch:    `"1"`,
+			goToGet:    true,
+			wantStatus: http.StatusPreconditionFailed,
+			wantErr:    `pet 1 does not match If-Match, current ETag is "2"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			revertPet := h.RevertPet()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/pet/"+tt.id+"/revert", bytes.NewBufferString(tt.body))
+			req = withPathID(req, tt.id)
+
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			if tt.goToGet {
+				srvMock.EXPECT().GetPet(gomock.Any(), 1).Return(stored, nil)
+			}
+
+			if tt.goToSev {
+				var pet *model.Pet
+				if tt.srvErr == nil {
+					pet = reverted
+				}
+
+				srvMock.EXPECT().RevertPet(gomock.Any(), &model.Pet{ID: 1, Version: tt.wantVersion}, tt.wantTo).
+					Return(pet, tt.srvErr)
+			}
+
+			revertPet.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			want := httptest.NewRecorder()
+			json.NewEncoder(want).Encode(reverted)
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, want.Body.String(), res.Body.String())
+			require.Equal(t, `"3"`, res.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+
+		wantActor  string
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check anonymous",
+			wantActor:  "anonymous",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check trimmed",
+			header:     " Alice Smith ",
+			wantActor:  "Alice Smith",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check max length",
+			header:     strings.Repeat("é", model.MaxActorName),
+			wantActor:  strings.Repeat("é", model.MaxActorName),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 too long",
+			header:     strings.Repeat("a", model.MaxActorName+1),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "actor cannot be longer than 64 characters",
+		},
+		{
+			name:       "check 400 control character",
+			header:     "alice\tbob",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "actor cannot have not printable characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model.Actor
+
+			next := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				got = model.ActorFromContext(request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pet", nil)
+			req.Header.Set(ActorHeader, tt.header)
+			res := httptest.NewRecorder()
+
+			middleware.RequestID(Actor(next)).ServeHTTP(res, req)
+
+			require.NotEmpty(t, res.Header().Get(middleware.RequestIDHeader))
+
+			if tt.wantErr != "" {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, tt.wantActor, got.Name)
+			require.Equal(t, res.Header().Get(middleware.RequestIDHeader), got.RequestID)
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	"pets/pkg/logger"
+)
+
+// Batch modes accepted in mode query param of /pet/batch routes
+const (
+	// BatchAtomic is a mode applying all batch items or none of them
+	BatchAtomic = "atomic"
+	// BatchBestEffort is a mode applying every valid batch item
+	BatchBestEffort = "best_effort"
+)
+
+// CreatePets is a handler func for POST /pet/batch route. Pets are given in requests.BatchAddPetsReq format, mode
+// query param is BatchAtomic by default
+// Will return per-item results in responses.BatchResp format with added pet IDs
+// Will return 201 status if all pets are added, 207 status if some pets of best effort batch are not added
+// Will return 400 status if no request.Body provided, mode is unknown or batch is empty, status of the first failed
+// pet if atomic batch is not added, other pets get 424 status
+// Will return 413 status if there are more than service.MaxBatchSize pets
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) CreatePets() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := &requests.BatchAddPetsReq{}
+
+		mode, err := batchMode(request, req)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreatePets").Warningf("wrong batch: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		pets := make([]*model.Pet, len(req.Items))
+
+		prepare := func(i int) error {
+			if req.Items[i] == nil {
+				return invalidParam("items", `provide item params {"name":string}`)
+			}
+
+			if pets[i], err = model.GetPetFromReq(req.Items[i]); err != nil {
+				return invalidParam("birth_date", err.Error())
+			}
+
+			return nil
+		}
+
+		apply := func(idx []int) ([]error, error) {
+			valid := make([]*model.Pet, len(idx))
+			for j, i := range idx {
+				valid[j] = pets[i]
+			}
+
+			return h.srv.AddPets(request.Context(), valid, mode == BatchAtomic)
+		}
+
+		errs, err := applyBatch(len(pets), mode == BatchAtomic, prepare, apply)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		ids := make([]int, len(pets))
+		for i, p := range pets {
+			if p != nil && errs[i] == nil {
+				ids[i] = p.ID
+			}
+		}
+
+		writeBatch(writer, request, mode, ids, errs, http.StatusCreated)
+	}
+}
+
+// PatchPets is a handler func for PATCH /pet/batch route. Pet patches are given in requests.BatchPatchPetsReq format
+// as RFC 7396 JSON Merge Patches, mode query param is BatchAtomic by default. Pet is patched only if it is the item
+// version if the version is given
+// Will return per-item results in responses.BatchResp format
+// Will return 200 status if all pets are patched, 207 status if some pets of best effort batch are not patched
+// Will return 400 status if no request.Body provided, mode is unknown or batch is empty, status of the first failed
+// pet if atomic batch is not patched, other pets get 424 status
+// Will return 413 status if there are more than service.MaxBatchSize pets
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) PatchPets() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := &requests.BatchPatchPetsReq{}
+
+		mode, err := batchMode(request, req)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-PatchPets").Warningf("wrong batch: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		ids := make([]int, len(req.Items))
+		patches := make([]*model.PetPatch, len(req.Items))
+
+		prepare := func(i int) error {
+			item := req.Items[i]
+			if item == nil || item.ID < 1 {
+				return invalidParam("id", "id should be a number more than 0")
+			}
+
+			ids[i] = item.ID
+
+			if len(item.Patch) == 0 {
+				return invalidParam("patch", "provide pet patch")
+			}
+
+			stored, err := h.srv.GetPet(request.Context(), item.ID)
+			if err != nil {
+				return err
+			}
+
+			patched, err := applyPetPatch(MergePatchContentType, item.Patch, stored)
+			if err != nil {
+				return err
+			}
+
+			patched.Version = item.Version
+			patches[i] = &model.PetPatch{Pet: patched, Fields: stored.Changes(patched)}
+
+			return nil
+		}
+
+		apply := func(idx []int) ([]error, error) {
+			valid := make([]*model.PetPatch, len(idx))
+			for j, i := range idx {
+				valid[j] = patches[i]
+			}
+
+			return h.srv.PatchPets(request.Context(), valid, mode == BatchAtomic)
+		}
+
+		errs, err := applyBatch(len(req.Items), mode == BatchAtomic, prepare, apply)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writeBatch(writer, request, mode, ids, errs, http.StatusOK)
+	}
+}
+
+// DeletePets is a handler func for DELETE /pet/batch route. Pets are given in requests.BatchDeletePetsReq format, mode
+// query param is BatchAtomic by default. Pet is deleted only if it is the item version if the version is given
+// Will return per-item results in responses.BatchResp format
+// Will return 200 status if all pets are deleted, 207 status if some pets of best effort batch are not deleted
+// Will return 400 status if no request.Body provided, mode is unknown or batch is empty, status of the first failed
+// pet if atomic batch is not deleted, other pets get 424 status
+// Will return 413 status if there are more than service.MaxBatchSize pets
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeletePets() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := &requests.BatchDeletePetsReq{}
+
+		mode, err := batchMode(request, req)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeletePets").Warningf("wrong batch: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		ids := make([]int, len(req.Items))
+		pets := make([]*model.Pet, len(req.Items))
+
+		prepare := func(i int) error {
+			item := req.Items[i]
+			if item == nil || item.ID < 1 {
+				return invalidParam("id", "id should be a number more than 0")
+			}
+
+			ids[i] = item.ID
+			pets[i] = &model.Pet{ID: item.ID, Version: item.Version}
+
+			return nil
+		}
+
+		apply := func(idx []int) ([]error, error) {
+			valid := make([]*model.Pet, len(idx))
+			for j, i := range idx {
+				valid[j] = pets[i]
+			}
+
+			return h.srv.DeletePets(request.Context(), valid, mode == BatchAtomic)
+		}
+
+		errs, err := applyBatch(len(req.Items), mode == BatchAtomic, prepare, apply)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writeBatch(writer, request, mode, ids, errs, http.StatusOK)
+	}
+}
+
+// batchMode is used to get batch mode query param and to decode the request body to given batch request. Will return
+// service.ErrValidation kind error if mode is unknown or body is invalid, service.CheckBatchSize errors if body has no
+// items or too many items
+func batchMode(request *http.Request, req interface{ Len() int }) (string, error) {
+	mode := request.URL.Query().Get("mode")
+
+	switch mode {
+	case "":
+		mode = BatchAtomic
+	case BatchAtomic, BatchBestEffort:
+	default:
+		return "", invalidParam("mode", fmt.Sprintf("unknown mode %q, should be %v or %v", mode, BatchAtomic, BatchBestEffort))
+	}
+
+	if err := decode(request, req); err != nil {
+		return "", bodyError(err, `provide body params {"items":[...]}`)
+	}
+
+	return mode, service.CheckBatchSize(req.Len())
+}
+
+// applyBatch is used to prepare n batch items with given prepare function and to apply prepared items with given
+// apply function getting their indexes. If atomic batch has items failed to prepare, nothing is applied and other
+// items get service.ErrNotApplied kind errors. Function will return item errors in items order, nil for applied items
+func applyBatch(n int, atomic bool, prepare func(i int) error, apply func(idx []int) ([]error, error)) ([]error, error) {
+	errs := make([]error, n)
+	idx := make([]int, 0, n)
+
+	for i := range errs {
+		if errs[i] = prepare(i); errs[i] == nil {
+			idx = append(idx, i)
+		}
+	}
+
+	if len(idx) != n && atomic {
+		for _, i := range idx {
+			errs[i] = service.NewNotAppliedError()
+		}
+
+		return errs, nil
+	}
+
+	if len(idx) == 0 {
+		return errs, nil
+	}
+
+	res, err := apply(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range idx {
+		errs[i] = res[j]
+	}
+
+	return errs, nil
+}
+
+// writeBatch is used to write batch item results in responses.BatchResp format with given success status if all items
+// are applied. Failed atomic batch is written with the first failed item status, best effort batch with 207 status
+func writeBatch(writer http.ResponseWriter, request *http.Request, mode string, ids []int, errs []error, success int) {
+	resp := &responses.BatchResp{Mode: mode, Items: make([]*responses.BatchItemResp, len(errs))}
+	status := success
+
+	for i, err := range errs {
+		item := &responses.BatchItemResp{Index: i, ID: ids[i], Status: success}
+
+		if err != nil {
+			item.Error = newProblem(request, err)
+			item.Status = item.Error.Status
+		}
+
+		switch {
+		case err == nil:
+			resp.Succeeded++
+		case mode == BatchBestEffort:
+			resp.Failed++
+			status = http.StatusMultiStatus
+		default:
+			resp.Failed++
+
+			if status == success && item.Status != http.StatusFailedDependency {
+				status = item.Status
+			}
+		}
+
+		resp.Items[i] = item
+	}
+
+	render(writer, request, status, resp)
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_CreatePets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+
+		srvCalled bool
+		srvErrs   []error
+		srvErr    error
+
+		wantStatus   int
+		wantErr      string
+		wantStatuses []int
+		wantIDs      []int
+	}{
+		{
+			name:         "check 201",
+			url:          "/pet/batch",
+			body:         `{"items":[{"name":"Velho"},{"name":"Bobik"}]}`,
+			srvCalled:    true,
+			srvErrs:      []error{nil, nil},
+			wantStatus:   http.StatusCreated,
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated},
+			wantIDs:      []int{1, 2},
+		},
+		{
+			name:         "check atomic failed",
+			url:          "/pet/batch",
+			body:         `{"items":[{"name":"Velho"},{"name":"Bobik"}]}`,
+			srvCalled:    true,
+			srvErrs:      []error{service.NewNotAppliedError(), service.NewValidationError("invalid pet")},
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []int{http.StatusFailedDependency, http.StatusBadRequest},
+			wantIDs:      []int{0, 0},
+		},
+		{
+			name:         "check atomic invalid birth date",
+			url:          "/pet/batch?mode=atomic",
+			body:         `{"items":[{"name":"Velho"},{"name":"Bobik","birth_date":"yesterday"}]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []int{http.StatusFailedDependency, http.StatusBadRequest},
+			wantIDs:      []int{0, 0},
+		},
+		{
+			name:         "check 207 best effort",
+			url:          "/pet/batch?mode=best_effort",
+			body:         `{"items":[{"name":"Velho"},{"name":"Bobik","birth_date":"yesterday"}]}`,
+			srvCalled:    true,
+			srvErrs:      []error{nil},
+			wantStatus:   http.StatusMultiStatus,
+			wantStatuses: []int{http.StatusCreated, http.StatusBadRequest},
+			wantIDs:      []int{1, 0},
+		},
+		{
+			name:       "check 400 mode",
+			url:        "/pet/batch?mode=some",
+			body:       `{"items":[{"name":"Velho"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown mode "some", should be atomic or best_effort`,
+		},
+		{
+			name:       "check 400 body",
+			url:        "/pet/batch",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"items":[...]}`,
+		},
+		{
+			name:       "check 400 empty",
+			url:        "/pet/batch",
+			body:       `{"items":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "batch is empty",
+		},
+		{
+			name:       "check 503",
+			url:        "/pet/batch",
+			body:       `{"items":[{"name":"Velho"}]}`,
+			srvCalled:  true,
+			srvErr:     service.NewUnavailableError(context.DeadlineExceeded),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErr:    "storage is unavailable, try again later",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+
+			if tt.srvCalled {
+				srvMock.EXPECT().AddPets(gomock.Any(), gomock.Any(), tt.url != "/pet/batch?mode=best_effort").
+					DoAndReturn(func(_ context.Context, pets []*model.Pet, _ bool) ([]error, error) {
+						for i, p := range pets {
+							p.ID = i + 1
+						}
+
+						return tt.srvErrs, tt.srvErr
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString(tt.body))
+			res := httptest.NewRecorder()
+			h.CreatePets().ServeHTTP(res, req)
+
+			if tt.wantErr != "" {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			requireBatch(t, res, tt.wantStatuses, tt.wantIDs)
+		})
+	}
+}
+
+func TestHandlers_PatchPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	stored := &model.Pet{ID: 1, Version: 2, Name: "Velho", Species: model.SpeciesDog, Status: model.StatusAvailable}
+
+	tests := []struct {
+		name string
+		body string
+
+		getErr    error
+		srvCalled bool
+		srvErrs   []error
+
+		wantStatus   int
+		wantStatuses []int
+	}{
+		{
+			name:         "check 200",
+			body:         `{"items":[{"id":1,"version":2,"patch":{"name":"Bobik"}}]}`,
+			srvCalled:    true,
+			srvErrs:      []error{nil},
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusOK},
+		},
+		{
+			name:         "check 412 stale version",
+			body:         `{"items":[{"id":1,"version":1,"patch":{"name":"Bobik"}}]}`,
+			srvCalled:    true,
+			srvErrs:      []error{service.NewPreconditionFailedError("pet 1 was changed, get it again")},
+			wantStatus:   http.StatusPreconditionFailed,
+			wantStatuses: []int{http.StatusPreconditionFailed},
+		},
+		{
+			name:         "check 404",
+			body:         `{"items":[{"id":1,"patch":{"name":"Bobik"}}]}`,
+			getErr:       service.NewNotFoundError("pet 1 not found"),
+			wantStatus:   http.StatusNotFound,
+			wantStatuses: []int{http.StatusNotFound},
+		},
+		{
+			name:         "check 400 id",
+			body:         `{"items":[{"patch":{"name":"Bobik"}}]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []int{http.StatusBadRequest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+
+			if tt.body != `{"items":[{"patch":{"name":"Bobik"}}]}` {
+				pet := *stored
+				srvMock.EXPECT().GetPet(gomock.Any(), 1).Return(&pet, tt.getErr)
+			}
+
+			if tt.srvCalled {
+				srvMock.EXPECT().PatchPets(gomock.Any(), gomock.Any(), true).
+					DoAndReturn(func(_ context.Context, patches []*model.PetPatch, _ bool) ([]error, error) {
+						require.Len(t, patches, 1)
+						require.Equal(t, "Bobik", patches[0].Pet.Name)
+						require.Equal(t, []string{"name"}, patches[0].Fields)
+						return tt.srvErrs, nil
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/pet/batch", bytes.NewBufferString(tt.body))
+			res := httptest.NewRecorder()
+			h.PatchPets().ServeHTTP(res, req)
+
+			require.Equal(t, tt.wantStatus, res.Code)
+
+			wantIDs := []int{1}
+			if tt.wantStatus == http.StatusBadRequest {
+				wantIDs = []int{0}
+			}
+
+			requireBatch(t, res, tt.wantStatuses, wantIDs)
+		})
+	}
+}
+
+func TestHandlers_DeletePets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+
+		srvPets []*model.Pet
+		srvErrs []error
+
+		wantStatus   int
+		wantStatuses []int
+		wantIDs      []int
+	}{
+		{
+			name:         "check 200",
+			url:          "/pet/batch",
+			body:         `{"items":[{"id":1,"version":2},{"id":2}]}`,
+			srvPets:      []*model.Pet{{ID: 1, Version: 2}, {ID: 2}},
+			srvErrs:      []error{nil, nil},
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusOK, http.StatusOK},
+			wantIDs:      []int{1, 2},
+		},
+		{
+			name:         "check atomic invalid id",
+			url:          "/pet/batch",
+			body:         `{"items":[{"id":1},{"id":0}]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []int{http.StatusFailedDependency, http.StatusBadRequest},
+			wantIDs:      []int{1, 0},
+		},
+		{
+			name:         "check 207 best effort",
+			url:          "/pet/batch?mode=best_effort",
+			body:         `{"items":[{"id":1},{"id":2}]}`,
+			srvPets:      []*model.Pet{{ID: 1}, {ID: 2}},
+			srvErrs:      []error{nil, service.NewNotFoundError("pet 2 not found")},
+			wantStatus:   http.StatusMultiStatus,
+			wantStatuses: []int{http.StatusOK, http.StatusNotFound},
+			wantIDs:      []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+
+			if tt.srvPets != nil {
+				srvMock.EXPECT().DeletePets(gomock.Any(), tt.srvPets, tt.url == "/pet/batch").Return(tt.srvErrs, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, bytes.NewBufferString(tt.body))
+			res := httptest.NewRecorder()
+			h.DeletePets().ServeHTTP(res, req)
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			requireBatch(t, res, tt.wantStatuses, tt.wantIDs)
+		})
+	}
+}
+
+// requireBatch is used to check that given response is a responses.BatchResp with given item statuses and IDs
+func requireBatch(t *testing.T, res *httptest.ResponseRecorder, statuses []int, ids []int) {
+	resp := &responses.BatchResp{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(resp))
+	require.Len(t, resp.Items, len(statuses))
+
+	failed := 0
+
+	for i, item := range resp.Items {
+		require.Equal(t, i, item.Index)
+		require.Equal(t, statuses[i], item.Status)
+		require.Equal(t, ids[i], item.ID)
+
+		if item.Status >= http.StatusBadRequest {
+			failed++
+			require.NotNil(t, item.Error)
+			require.Equal(t, item.Status, item.Error.Status)
+		}
+	}
+
+	require.Equal(t, failed, resp.Failed)
+	require.Equal(t, len(statuses)-failed, resp.Succeeded)
+}
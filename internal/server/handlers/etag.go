@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"pets/internal/model"
+	"pets/internal/service"
+)
+
+// notModified is used to check if given entity tag matches the request If-None-Match header, so 304 status can be
+// returned instead of the entity. Tags are compared weakly as required for GET requests
+func notModified(request *http.Request, etag string) bool {
+	header := request.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	return etagMatches(header, etag, true)
+}
+
+// petETag is used to get the entity tag of given pet representation in the content type negotiated by Accept header.
+// Will return service.ErrNotAcceptable kind error if no content type is accepted
+func petETag(request *http.Request, pet *model.Pet) (string, error) {
+	c, err := negotiate(request, false)
+	if err != nil {
+		return "", err
+	}
+
+	return contentETag(pet, c.contentType), nil
+}
+
+// contentETag is used to get the entity tag of given pet representation in given content type. JSON representation
+// has the pet ETag, tags of other content types have the content subtype suffix, e.g. "3-xml", so the representations
+// are not mixed by caches
+func contentETag(pet *model.Pet, contentType string) string {
+	if contentType == JSONContentType {
+		return pet.ETag()
+	}
+
+	_, subtype, _ := strings.Cut(contentType, "/")
+
+	return fmt.Sprintf(`"%v-%v"`, pet.Version, subtype)
+}
+
+// ifMatchVersion is used to check the request If-Match header against given stored pet. Tags of any pet
+// representation match. Function will return the pet version to be used as a write condition, 0 if the header is not
+// given or is "*", so the write is unconditional. Will return service.ErrPreconditionFailed kind error if the header
+// does not match the pet entity tag
+func ifMatchVersion(request *http.Request, pet *model.Pet) (int, error) {
+	header := strings.TrimSpace(request.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	for _, c := range codecs {
+		if !c.listOnly && etagMatches(header, contentETag(pet, c.contentType), false) {
+			return pet.Version, nil
+		}
+	}
+
+	return 0, service.NewPreconditionFailedError(fmt.Sprintf("pet %v does not match If-Match, current ETag is %v", pet.ID, pet.ETag()))
+}
+
+// etagMatches is used to check if given If-Match or If-None-Match header value matches given entity tag. "*" matches
+// any tag. Weak tags match only if weak comparison is used
+func etagMatches(header string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"pets/internal/model"
+	"pets/pkg/logger"
+)
+
+// XLSXContentType is a content type of XLSX export
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// exportFlushRows is a number of exported rows written to the client by a single flush
+const exportFlushRows = 100
+
+// exportColumns are columns of CSV and XLSX pets export
+var exportColumns = []string{"id", "name", "species", "breed", "birth_date", "sex", "neutered", "weight", "color",
+	"description", "status", "owner_id", "tags", "version", "created_at", "updated_at", "deleted_at"}
+
+// petExporter is a writer of exported pets in an export format
+type petExporter interface {
+	// begin is used to write the export header
+	begin() error
+	// write is used to write a pet
+	write(pet *model.Pet) error
+	// end is used to write buffered pets and the export end
+	end() error
+	// close is used to release the export resources, it is called whether the export is ended or cut
+	close()
+}
+
+// ExportPets is a handler func for GET /pet/export route. Export format is taken from format query param: csv (by
+// default), ndjson or xlsx. Pets are filtered and sorted by GetPets query params, pagination params are ignored. CSV
+// and NDJSON pets are streamed from the DB and flushed to the client every exportFlushRows pets, XLSX file is written
+// when all pets are read
+// Will return 200 status with the export file and Content-Disposition header, the file has no pets if none match
+// Will return 400 status if format or query params are invalid
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred before the first pet is written,
+// the export is cut if the error occurred later
+func (h *Handlers) ExportPets() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		query, err := getPetsQuery(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ExportPets").Warningf("wrong query: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		query.Limit, query.Offset = 0, 0
+
+		format := request.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+
+		exp, contentType, err := newPetExporter(format, writer)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ExportPets").Warningf("wrong format: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+		defer exp.close()
+
+		started := false
+
+		start := func() error {
+			started = true
+
+			file := fmt.Sprintf("pets-%v.%v", time.Now().Format("20060102"), format)
+
+			writer.Header().Set("Content-Type", contentType)
+			writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file))
+			writer.WriteHeader(http.StatusOK)
+
+			return exp.begin()
+		}
+
+		err = h.srv.ExportPets(request.Context(), query, func(pet *model.Pet) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+
+			return exp.write(pet)
+		})
+
+		if err != nil && !started {
+			writeError(writer, request, err)
+			return
+		}
+
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ExportPets").Errorf("export cut: %v", err.Error())
+			return
+		}
+
+		if !started {
+			err = start()
+		}
+
+		if err == nil {
+			err = exp.end()
+		}
+
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ExportPets").Errorf("error write export %v", err.Error())
+		}
+	}
+}
+
+// newPetExporter is used to get petExporter of given format writing to given writer and the format content type.
+// Will return service.ErrValidation kind error if format is unknown
+func newPetExporter(format string, writer http.ResponseWriter) (petExporter, string, error) {
+	switch format {
+	case "csv":
+		return &csvExporter{w: writer, cw: csv.NewWriter(writer)}, CSVContentType, nil
+	case "ndjson":
+		return &ndjsonExporter{w: writer, enc: json.NewEncoder(writer)}, NDJSONContentType, nil
+	case "xlsx":
+		return &xlsxExporter{w: writer}, XLSXContentType, nil
+	}
+
+	return nil, "", invalidParam("format", fmt.Sprintf("unknown format %q, should be csv, ndjson or xlsx", format))
+}
+
+// flush is used to send written data to the client if the writer supports it
+func flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// exportRecord is used to get values of exportColumns of given pet. Blank values are nil, free text values are escaped
+// by exportText
+func exportRecord(pet *model.Pet) []interface{} {
+	record := []interface{}{pet.ID, exportText(pet.Name), string(pet.Species), exportText(pet.Breed), nil,
+		string(pet.Sex), pet.Neutered, nil, exportText(pet.Color), exportText(pet.Description), string(pet.Status), nil,
+		exportText(strings.Join(pet.Tags, ",")), pet.Version, pet.CreatedAt.Format(time.RFC3339), nil, nil}
+
+	if pet.BirthDate != nil {
+		record[4] = pet.BirthDate.String()
+	}
+
+	if pet.Weight != nil {
+		record[7] = *pet.Weight
+	}
+
+	if pet.OwnerID != nil {
+		record[11] = *pet.OwnerID
+	}
+
+	if pet.UpdatedAt != nil {
+		record[15] = pet.UpdatedAt.Format(time.RFC3339)
+	}
+
+	if pet.DeletedAt != nil {
+		record[16] = pet.DeletedAt.Format(time.RFC3339)
+	}
+
+	return record
+}
+
+// exportText is used to escape given text value of CSV or XLSX cell. Values starting with =, +, - or @ are prefixed
+// with ', so spreadsheets do not evaluate them as formulas
+func exportText(v string) string {
+	if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+		return "'" + v
+	}
+
+	return v
+}
+
+// csvExporter is a petExporter of CSV with a header row of exportColumns
+type csvExporter struct {
+	w    io.Writer
+	cw   *csv.Writer
+	rows int
+}
+
+// begin is implementing petExporter.begin function
+func (e *csvExporter) begin() error {
+	return e.cw.Write(exportColumns)
+}
+
+// write is implementing petExporter.write function
+func (e *csvExporter) write(pet *model.Pet) error {
+	record := exportRecord(pet)
+	values := make([]string, len(record))
+
+	for i, v := range record {
+		switch v := v.(type) {
+		case nil:
+		case float64:
+			values[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+
+	if err := e.cw.Write(values); err != nil {
+		return err
+	}
+
+	if e.rows++; e.rows%exportFlushRows == 0 {
+		e.cw.Flush()
+		flush(e.w)
+	}
+
+	return e.cw.Error()
+}
+
+// end is implementing petExporter.end function
+func (e *csvExporter) end() error {
+	e.cw.Flush()
+
+	return e.cw.Error()
+}
+
+// close is implementing petExporter.close function. CSV export has no resources
+func (e *csvExporter) close() {}
+
+// ndjsonExporter is a petExporter of newline delimited JSON with a pet object per line
+type ndjsonExporter struct {
+	w    io.Writer
+	enc  *json.Encoder
+	rows int
+}
+
+// begin is implementing petExporter.begin function. NDJSON has no header
+func (e *ndjsonExporter) begin() error {
+	return nil
+}
+
+// write is implementing petExporter.write function
+func (e *ndjsonExporter) write(pet *model.Pet) error {
+	if err := e.enc.Encode(pet); err != nil {
+		return err
+	}
+
+	if e.rows++; e.rows%exportFlushRows == 0 {
+		flush(e.w)
+	}
+
+	return nil
+}
+
+// end is implementing petExporter.end function
+func (e *ndjsonExporter) end() error {
+	return nil
+}
+
+// close is implementing petExporter.close function. NDJSON export has no resources
+func (e *ndjsonExporter) close() {}
+
+// xlsxExporter is a petExporter of XLSX workbook with a Pets sheet of exportColumns. Rows are kept by the excelize
+// stream writer in a temporary file above its memory limit, the workbook is written on end and the file is removed on
+// close
+type xlsxExporter struct {
+	w    io.Writer
+	f    *excelize.File
+	sw   *excelize.StreamWriter
+	rows int
+}
+
+// begin is implementing petExporter.begin function
+func (e *xlsxExporter) begin() error {
+	e.f = excelize.NewFile()
+
+	if err := e.f.SetSheetName("Sheet1", "Pets"); err != nil {
+		return err
+	}
+
+	sw, err := e.f.NewStreamWriter("Pets")
+	if err != nil {
+		return err
+	}
+
+	e.sw = sw
+
+	header := make([]interface{}, len(exportColumns))
+	for i, c := range exportColumns {
+		header[i] = c
+	}
+
+	return e.sw.SetRow("A1", header)
+}
+
+// write is implementing petExporter.write function
+func (e *xlsxExporter) write(pet *model.Pet) error {
+	e.rows++
+
+	cell, err := excelize.CoordinatesToCellName(1, e.rows+1)
+	if err != nil {
+		return err
+	}
+
+	return e.sw.SetRow(cell, exportRecord(pet))
+}
+
+// end is implementing petExporter.end function
+func (e *xlsxExporter) end() error {
+	if err := e.sw.Flush(); err != nil {
+		return err
+	}
+
+	return e.f.Write(e.w)
+}
+
+// close is implementing petExporter.close function. Workbook temporary files are removed
+func (e *xlsxExporter) close() {
+	if e.f == nil {
+		return
+	}
+
+	if err := e.f.Close(); err != nil {
+		logger.Log().WithField("layer", "Handlers-ExportPets").Errorf("error close xlsx %v", err.Error())
+	}
+}
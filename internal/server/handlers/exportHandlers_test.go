@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"pets/internal/model"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_ExportPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	created := time.Date(2023, time.November, 26, 10, 0, 0, 0, time.UTC)
+	birth, err := model.ParseDate("2020-01-02")
+	require.NoError(t, err)
+
+	weight := 12.5
+	pets := []*model.Pet{
+		{ID: 1, Name: "Velho", Species: model.SpeciesDog, BirthDate: &birth, Sex: model.SexMale, Neutered: true,
+			Weight: &weight, Status: model.StatusAvailable, Tags: []string{"good-with-kids", "senior"}, Version: 2, CreatedAt: created},
+		{ID: 2, Name: "Murka, the cat", Species: model.SpeciesCat, Sex: model.SexFemale, Status: model.StatusAvailable,
+			Version: 1, CreatedAt: created},
+	}
+
+	header := "id,name,species,breed,birth_date,sex,neutered,weight,color,description,status,owner_id,tags,version," +
+		"created_at,updated_at,deleted_at\n"
+
+	tests := []struct {
+		name string
+		url  string
+
+		query   *model.PetsQuery
+		srvPets []*model.Pet
+		srvErr  error
+
+		wantStatus      int
+		wantErr         string
+		wantContentType string
+		wantFile        string
+		wantBody        string
+	}{
+		{
+			name:            "check 200 csv",
+			url:             "/pet/export?species=dog,cat&sort=name&limit=1&offset=1",
+			query:           &model.PetsQuery{Filter: model.PetsFilter{Species: []model.Species{model.SpeciesDog, model.SpeciesCat}}, Sort: []model.SortField{{Field: model.SortName}}},
+			srvPets:         pets,
+			wantStatus:      http.StatusOK,
+			wantContentType: CSVContentType,
+			wantFile:        "csv",
+			wantBody: header +
+				"1,Velho,dog,,2020-01-02,male,true,12.5,,,available,,\"good-with-kids,senior\",2,2023-11-26T10:00:00Z,,\n" +
+				"2,\"Murka, the cat\",cat,,,female,false,,,,available,,,1,2023-11-26T10:00:00Z,,\n",
+		},
+		{
+			name:  "check 200 csv formulas",
+			url:   "/pet/export",
+			query: &model.PetsQuery{},
+			srvPets: []*model.Pet{{ID: 3, Name: "=HYPERLINK(\"http://example.com\")", Breed: "+1", Color: "@black",
+				Description: "-2 years", Status: model.StatusAvailable, Tags: []string{"-senior"}, Version: 1,
+				CreatedAt: created}},
+			wantStatus:      http.StatusOK,
+			wantContentType: CSVContentType,
+			wantFile:        "csv",
+			wantBody: header +
+				"3,\"'=HYPERLINK(\"\"http://example.com\"\")\",,'+1,,,false,,'@black,'-2 years,available,,'-senior,1," +
+				"2023-11-26T10:00:00Z,,\n",
+		},
+		{
+			name:            "check 200 ndjson",
+			url:             "/pet/export?format=ndjson",
+			query:           &model.PetsQuery{},
+			srvPets:         pets,
+			wantStatus:      http.StatusOK,
+			wantContentType: NDJSONContentType,
+			wantFile:        "ndjson",
+		},
+		{
+			name:            "check 200 xlsx",
+			url:             "/pet/export?format=xlsx",
+			query:           &model.PetsQuery{},
+			srvPets:         pets,
+			wantStatus:      http.StatusOK,
+			wantContentType: XLSXContentType,
+			wantFile:        "xlsx",
+		},
+		{
+			name:            "check 200 csv empty",
+			url:             "/pet/export?name=Bobik",
+			query:           &model.PetsQuery{Filter: model.PetsFilter{Name: "Bobik"}},
+			wantStatus:      http.StatusOK,
+			wantContentType: CSVContentType,
+			wantFile:        "csv",
+			wantBody:        header,
+		},
+		{
+			name:       "check 400 format",
+			url:        "/pet/export?format=pdf",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown format "pdf", should be csv, ndjson or xlsx`,
+		},
+		{
+			name:       "check 503",
+			url:        "/pet/export",
+			query:      &model.PetsQuery{},
+			srvErr:     service.NewUnavailableError(context.DeadlineExceeded),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErr:    "storage is unavailable, try again later",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+
+			if tt.query != nil {
+				srvMock.EXPECT().ExportPets(gomock.Any(), tt.query, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ *model.PetsQuery, fn func(*model.Pet) error) error {
+						if tt.srvErr != nil {
+							return tt.srvErr
+						}
+
+						for _, p := range tt.srvPets {
+							if err := fn(p); err != nil {
+								return err
+							}
+						}
+
+						return nil
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			res := httptest.NewRecorder()
+			h.ExportPets().ServeHTTP(res, req)
+
+			if tt.wantErr != "" {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, tt.wantContentType, res.Header().Get("Content-Type"))
+			require.Regexp(t, `^attachment; filename="pets-\d{8}\.`+tt.wantFile+`"$`, res.Header().Get("Content-Disposition"))
+
+			switch tt.wantFile {
+			case "csv":
+				require.Equal(t, tt.wantBody, res.Body.String())
+			case "ndjson":
+				lines := strings.Split(strings.TrimSpace(res.Body.String()), "\n")
+				require.Len(t, lines, len(pets))
+
+				got := &model.Pet{}
+				require.NoError(t, json.Unmarshal([]byte(lines[1]), got))
+				require.Equal(t, "Murka, the cat", got.Name)
+			case "xlsx":
+				f, err := excelize.OpenReader(res.Body)
+				require.NoError(t, err)
+				defer f.Close()
+
+				rows, err := f.GetRows("Pets")
+				require.NoError(t, err)
+				require.Len(t, rows, len(pets)+1)
+				require.Equal(t, exportColumns, rows[0])
+				require.Equal(t, []string{"1", "Velho", "dog", "", "2020-01-02", "male", "TRUE", "12.5"}, rows[1][:8])
+			}
+		})
+	}
+}
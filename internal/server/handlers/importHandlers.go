@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	"pets/pkg/logger"
+)
+
+// Content types of pets import request bodies
+const (
+	// CSVContentType is a content type of CSV import body with a header row
+	CSVContentType = "text/csv"
+	// NDJSONContentType is a content type of newline delimited JSON import body
+	NDJSONContentType = "application/x-ndjson"
+)
+
+// importReportFile is a file name of the rejected rows report
+const importReportFile = "pets-import-rejects.csv"
+
+// ImportPets is a handler func for POST /pet/import route. Pets are read from CSV or NDJSON body by Content-Type,
+// the body is streamed. Query params are dry_run to only validate rows, map with column:field mappings and report=csv
+// to get the rejected rows report as a CSV file
+// Will return 200 status with model.ImportReport, or CSV report with Content-Disposition header if report=csv
+// Will return 400 status if query params, CSV header or column mappings are invalid, invalid rows are rejected in the
+// report
+// Will return 413 status if NDJSON line is too long, 415 status if Content-Type is not CSV or NDJSON
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+// Errors occurred while rows are read are returned as responses.ImportProblem with the report of rows read before, pets
+// up to its committed_line are added
+func (h *Handlers) ImportPets() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		opts, err := getImportOptions(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ImportPets").Warningf("wrong import: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		report, err := h.srv.ImportPets(request.Context(), request.Body, opts)
+		if err != nil && report != nil {
+			logger.Log().WithField("layer", "Handlers-ImportPets").Errorf("error import pets after line %v: %v",
+				report.CommittedLine, err.Error())
+
+			problem := newProblem(request, err)
+			writeProblem(writer, problem.Status, &responses.ImportProblem{Problem: problem, Report: report})
+
+			return
+		}
+
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-ImportPets").Errorf("error import pets: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		logger.Log().WithField("layer", "Handlers-ImportPets").Infof("%v of %v rows imported, dry run %v",
+			report.Imported, report.Total, report.DryRun)
+
+		if request.URL.Query().Get("report") == "csv" {
+			writer.Header().Set("Content-Type", CSVContentType)
+			writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", importReportFile))
+			writer.WriteHeader(http.StatusOK)
+
+			if err = report.WriteRejectsCSV(writer); err != nil {
+				logger.Log().WithField("layer", "Handlers-ImportPets").Errorf("error write report %v", err.Error())
+			}
+
+			return
+		}
+
+		render(writer, request, http.StatusOK, report)
+	}
+}
+
+// getImportOptions is used to get model.ImportOptions from the request Content-Type and query params. Will return
+// service.ErrUnsupportedMedia kind error if Content-Type is not CSV or NDJSON, service.ErrValidation kind error if
+// query params are invalid
+func getImportOptions(request *http.Request) (*model.ImportOptions, error) {
+	opts := &model.ImportOptions{}
+
+	header := request.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(header)
+
+	switch mediaType {
+	case CSVContentType:
+		opts.Format = model.ImportCSV
+	case NDJSONContentType, "application/ndjson":
+		opts.Format = model.ImportNDJSON
+	default:
+		return nil, service.NewUnsupportedMediaError(fmt.Sprintf("content type %q is not supported, use %v or %v",
+			header, CSVContentType, NDJSONContentType))
+	}
+
+	if v := request.URL.Query().Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, invalidParam("dry_run", fmt.Sprintf("invalid dry_run %q", v))
+		}
+
+		opts.DryRun = dryRun
+	}
+
+	if report := request.URL.Query().Get("report"); report != "" && report != "csv" {
+		return nil, invalidParam("report", fmt.Sprintf("unknown report %q, should be csv", report))
+	}
+
+	columns, err := model.ParseImportColumns(queryList(request, "map"))
+	if err != nil {
+		return nil, invalidParam("map", err.Error())
+	}
+
+	opts.Columns = columns
+
+	return opts, nil
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_ImportPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	report := &model.ImportReport{
+		Format: model.ImportCSV, Total: 2, Imported: 1, Rejected: 1,
+		Rejects: []*model.ImportReject{{Line: 3, Field: "name", Reason: "cannot be blank"}},
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+
+		opts   *model.ImportOptions
+		srvErr error
+		// partial is true if the report is returned with srvErr
+		partial bool
+
+		wantStatus int
+		wantErr    string
+		wantBody   string
+	}{
+		{
+			name:        "check 200",
+			url:         "/pet/import",
+			contentType: "text/csv; charset=utf-8",
+			opts:        &model.ImportOptions{Format: model.ImportCSV, Columns: map[string]string{}},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "check 200 dry run with mapping",
+			url:         "/pet/import?dry_run=true&map=Pet%20name:name,Kind:species",
+			contentType: "application/x-ndjson",
+			opts: &model.ImportOptions{Format: model.ImportNDJSON, DryRun: true,
+				Columns: map[string]string{"Pet name": "name", "Kind": "species"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "check 200 csv report",
+			url:         "/pet/import?report=csv",
+			contentType: "text/csv",
+			opts:        &model.ImportOptions{Format: model.ImportCSV, Columns: map[string]string{}},
+			wantStatus:  http.StatusOK,
+			wantBody:    "line,field,reason\n3,name,cannot be blank\n",
+		},
+		{
+			name:        "check 415",
+			url:         "/pet/import",
+			contentType: "application/json",
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantErr:     `content type "application/json" is not supported, use text/csv or application/x-ndjson`,
+		},
+		{
+			name:        "check 400 dry_run",
+			url:         "/pet/import?dry_run=maybe",
+			contentType: "text/csv",
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     `invalid dry_run "maybe"`,
+		},
+		{
+			name:        "check 400 map",
+			url:         "/pet/import?map=name",
+			contentType: "text/csv",
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     `column mapping "name" should be column:field`,
+		},
+		{
+			name:        "check 400 header",
+			url:         "/pet/import",
+			contentType: "text/csv",
+			opts:        &model.ImportOptions{Format: model.ImportCSV, Columns: map[string]string{}},
+			srvErr:      service.NewValidationError("CSV header has no name column"),
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     "CSV header has no name column",
+		},
+		{
+			name:        "check 413 partial report",
+			url:         "/pet/import",
+			contentType: "application/x-ndjson",
+			opts:        &model.ImportOptions{Format: model.ImportNDJSON, Columns: map[string]string{}},
+			srvErr:      service.NewTooLargeError("line 4 is longer than 1048576 bytes"),
+			partial:     true,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantErr:     "line 4 is longer than 1048576 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+
+			if tt.opts != nil {
+				srvMock.EXPECT().ImportPets(gomock.Any(), gomock.Any(), tt.opts).
+					DoAndReturn(func(_ context.Context, r io.Reader, _ *model.ImportOptions) (*model.ImportReport, error) {
+						data, err := io.ReadAll(r)
+						require.NoError(t, err)
+						require.Equal(t, "name\nVelho\n\n", string(data))
+
+						if tt.srvErr != nil && !tt.partial {
+							return nil, tt.srvErr
+						}
+
+						return report, tt.srvErr
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString("name\nVelho\n\n"))
+			req.Header.Set("Content-Type", tt.contentType)
+			res := httptest.NewRecorder()
+			h.ImportPets().ServeHTTP(res, req)
+
+			if tt.partial {
+				problem := &responses.ImportProblem{}
+				require.NoError(t, json.Unmarshal(res.Body.Bytes(), problem))
+				require.Equal(t, report, problem.Report)
+			}
+
+			if tt.wantErr != "" {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+				return
+			}
+
+			require.Equal(t, tt.wantStatus, res.Code)
+
+			if tt.wantBody != "" {
+				require.Equal(t, CSVContentType, res.Header().Get("Content-Type"))
+				require.Equal(t, `attachment; filename="pets-import-rejects.csv"`, res.Header().Get("Content-Disposition"))
+				require.Equal(t, tt.wantBody, res.Body.String())
+				return
+			}
+
+			got := &model.ImportReport{}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(got))
+			require.Equal(t, report, got)
+		})
+	}
+}
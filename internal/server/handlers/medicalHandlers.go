@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/pkg/logger"
+)
+
+// defaultDueDays is a number of days from today used as GET /vaccinations/due "before" param if it is not given
+const defaultDueDays = 30
+
+// GetVaccinations is a handler func for GET /pet/{id}/vaccinations route
+// Will return pet vaccinations in responses.GetVaccinationsResp format, oldest shot first
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetVaccinations() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetVaccinations").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetVaccinations(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Vaccination{}
+		}
+
+		render(writer, request, http.StatusOK, &responses.GetVaccinationsResp{Vaccinations: res})
+	}
+}
+
+// GetVaccination is a handler func for GET /pet/{id}/vaccinations/{record_id} route
+// Will return vaccination in model.Vaccination format if vaccination found
+// Will return 400 status if IDs in path are not numbers or less than 0
+// Will return 404 status if vaccination of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetVaccination() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getRecordPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetVaccination").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetVaccination(request.Context(), petID, id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// CreateVaccination is a handler func for POST /pet/{id}/vaccinations route
+// Will return created vaccination ID in responses.AddRecordResp format
+// Will return 400 status if no request.Body provided, vaccination fields are invalid or ID in path is less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) CreateVaccination() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreateVaccination").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		v, err := getVaccinationReq(request, petID)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreateVaccination").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		id, err := h.srv.AddVaccination(request.Context(), v)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusCreated, &responses.AddRecordResp{ID: id})
+	}
+}
+
+// UpdateVaccination is a handler func for PUT /pet/{id}/vaccinations/{record_id} route. All vaccination fields are
+// replaced
+// Will return 200 if request is successful
+// Will return 400 status if no request.Body provided, vaccination fields or IDs in path are invalid
+// Will return 404 status if vaccination of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) UpdateVaccination() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getRecordPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateVaccination").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		v, err := getVaccinationReq(request, petID)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateVaccination").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		v.ID = id
+
+		if err = h.srv.UpdateVaccination(request.Context(), v); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// DeleteVaccination is a handler func for DELETE /pet/{id}/vaccinations/{record_id} route
+// Will return 200 if request is successful
+// Will return 400 status if IDs in path are less than 0
+// Will return 404 status if vaccination of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeleteVaccination() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getRecordPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeleteVaccination").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.srv.DeleteVaccination(request.Context(), &model.Vaccination{ID: id, PetID: petID}); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// GetDueVaccinations is a handler func for GET /vaccinations/due route. Query param "before" is a YYYY-MM-DD due date,
+// defaultDueDays from today by default
+// Will return latest vaccinations with the next shot due in responses.GetDueVaccinationsResp format, earliest due first
+// Will return 400 status if "before" is not a date
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetDueVaccinations() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		before, err := queryDate(request, "before")
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetDueVaccinations").Warningf("wrong query: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if before == nil {
+			now := time.Now()
+			d := model.NewDate(now.Year(), now.Month(), now.Day()+defaultDueDays)
+			before = &d
+		}
+
+		res, err := h.srv.GetDueVaccinations(request.Context(), *before)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.DueVaccination{}
+		}
+
+		resp := &responses.GetDueVaccinationsResp{Before: *before, Vaccinations: res}
+
+		render(writer, request, http.StatusOK, resp)
+	}
+}
+
+// GetTreatments is a handler func for GET /pet/{id}/treatments route
+// Will return pet treatments in responses.GetTreatmentsResp format, oldest first
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetTreatments() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetTreatments").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetTreatments(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Treatment{}
+		}
+
+		render(writer, request, http.StatusOK, &responses.GetTreatmentsResp{Treatments: res})
+	}
+}
+
+// GetTreatment is a handler func for GET /pet/{id}/treatments/{record_id} route
+// Will return treatment in model.Treatment format if treatment found
+// Will return 400 status if IDs in path are not numbers or less than 0
+// Will return 404 status if treatment of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetTreatment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getRecordPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetTreatment").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetTreatment(request.Context(), petID, id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// CreateTreatment is a handler func for POST /pet/{id}/treatments route
+// Will return created treatment ID in responses.AddRecordResp format
+// Will return 400 status if no request.Body provided, treatment fields are invalid or ID in path is less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) CreateTreatment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreateTreatment").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		t, err := getTreatmentReq(request, petID)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreateTreatment").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		id, err := h.srv.AddTreatment(request.Context(), t)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusCreated, &responses.AddRecordResp{ID: id})
+	}
+}
+
+// UpdateTreatment is a handler func for PUT /pet/{id}/treatments/{record_id} route. All treatment fields are replaced
+// Will return 200 if request is successful
+// Will return 400 status if no request.Body provided, treatment fields or IDs in path are invalid
+// Will return 404 status if treatment of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) UpdateTreatment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getRecordPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateTreatment").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		t, err := getTreatmentReq(request, petID)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateTreatment").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		t.ID = id
+
+		if err = h.srv.UpdateTreatment(request.Context(), t); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// DeleteTreatment is a handler func for DELETE /pet/{id}/treatments/{record_id} route
+// Will return 200 if request is successful
+// Will return 400 status if IDs in path are less than 0
+// Will return 404 status if treatment of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeleteTreatment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getRecordPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeleteTreatment").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.srv.DeleteTreatment(request.Context(), &model.Treatment{ID: id, PetID: petID}); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// getRecordPath is used to get pet ID and medical record ID from the {id} and {record_id} route params
+func getRecordPath(request *http.Request) (petID int, id int, err error) {
+	if petID, err = getPathID(request); err != nil {
+		return 0, 0, err
+	}
+
+	if id, err = getPathInt(request, "record_id"); err != nil {
+		return 0, 0, err
+	}
+
+	return petID, id, nil
+}
+
+// getVaccinationReq is used to get model.Vaccination of the pet with given ID from requests.VaccinationReq body
+func getVaccinationReq(request *http.Request, petID int) (*model.Vaccination, error) {
+	req := &requests.VaccinationReq{}
+
+	if err := decode(request, req); err != nil {
+		return nil, bodyError(err, `provide body params {"vaccine":string, "given_on":string}`)
+	}
+
+	v, err := model.GetVaccinationFromReq(petID, req)
+	if err != nil {
+		return nil, dateParam(err)
+	}
+
+	return v, nil
+}
+
+// getTreatmentReq is used to get model.Treatment of the pet with given ID from requests.TreatmentReq body
+func getTreatmentReq(request *http.Request, petID int) (*model.Treatment, error) {
+	req := &requests.TreatmentReq{}
+
+	if err := decode(request, req); err != nil {
+		return nil, bodyError(err, `provide body params {"name":string, "given_on":string}`)
+	}
+
+	t, err := model.GetTreatmentFromReq(petID, req)
+	if err != nil {
+		return nil, dateParam(err)
+	}
+
+	return t, nil
+}
+
+// dateParam is used to convert model.DateError to ErrValidation kind error of the invalid field
+func dateParam(err error) error {
+	var dateErr *model.DateError
+	if errors.As(err, &dateErr) {
+		return invalidParam(dateErr.Field, dateErr.Error())
+	}
+
+	return err
+}
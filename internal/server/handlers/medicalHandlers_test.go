@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_CreateVaccination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	due := model.NewDate(2024, time.March, 1)
+
+	tests := []struct {
+		name string
+		body string
+
+		goToSev     bool
+		vaccination *model.Vaccination
+		srvErr      error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:    "check 201",
+			body:    `{"vaccine":"rabies","given_on":"2023-03-01","due_on":"2024-03-01","vet":"Dr. Smith"}`,
+			goToSev: true,
+			vaccination: &model.Vaccination{PetID: 1, Vaccine: "rabies", GivenOn: model.NewDate(2023, time.March, 1),
+				DueOn: &due, Vet: "Dr. Smith"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 400 no body",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"vaccine":string, "given_on":string}`,
+		},
+		{
+			name:       "check 400 wrong date",
+			body:       `{"vaccine":"rabies","given_on":"2023-03-01","due_on":"next year"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid due_on: should be a date in YYYY-MM-DD format",
+		},
+		{
+			name:        "check 404 pet not found",
+			body:        `{"vaccine":"rabies","given_on":"2023-03-01"}`,
+			goToSev:     true,
+			vaccination: &model.Vaccination{PetID: 1, Vaccine: "rabies", GivenOn: model.NewDate(2023, time.March, 1)},
+			srvErr:      service.NewNotFoundError("pet 1 not found"),
+			wantStatus:  http.StatusNotFound,
+			wantErr:     "pet 1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			createVaccination := h.CreateVaccination()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/pet/1/vaccinations", bytes.NewBufferString(tt.body))
+			req = withPathID(req, "1")
+
+			if tt.goToSev {
+				srvMock.EXPECT().AddVaccination(gomock.Any(), tt.vaccination).Return(2, tt.srvErr)
+			}
+
+			createVaccination.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(&responses.AddRecordResp{ID: 2})
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_GetDueVaccinations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		url  string
+
+		goToSev bool
+		before  model.Date
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200 before",
+			url:        "/vaccinations/due?before=2024-03-01",
+			goToSev:    true,
+			before:     model.NewDate(2024, time.March, 1),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 default before",
+			url:        "/vaccinations/due",
+			goToSev:    true,
+			before:     model.NewDate(now.Year(), now.Month(), now.Day()+defaultDueDays),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong before",
+			url:        "/vaccinations/due?before=soon",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid before: should be a date in YYYY-MM-DD format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getDueVaccinations := h.GetDueVaccinations()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+
+			if tt.goToSev {
+				srvMock.EXPECT().GetDueVaccinations(gomock.Any(), tt.before).Return(nil, nil)
+			}
+
+			getDueVaccinations.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(&responses.GetDueVaccinationsResp{Before: tt.before, Vaccinations: []*model.DueVaccination{}})
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_DeleteTreatment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		recordID string
+
+		goToSev bool
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			recordID:   "2",
+			goToSev:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong record id",
+			recordID:   "x",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `record_id should be a number more than 0, got "x"`,
+		},
+		{
+			name:       "check 404 not found",
+			recordID:   "2",
+			goToSev:    true,
+			srvErr:     service.NewNotFoundError("treatment 2 of pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "treatment 2 of pet 1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			deleteTreatment := h.DeleteTreatment()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/pet/1/treatments/"+tt.recordID, nil)
+			req = withRecordPath(req, "1", tt.recordID)
+
+			if tt.goToSev {
+				srvMock.EXPECT().DeleteTreatment(gomock.Any(), &model.Treatment{ID: 2, PetID: 1}).Return(tt.srvErr)
+			}
+
+			deleteTreatment.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Empty(t, res.Body.String())
+			}
+		})
+	}
+}
+
+// withRecordPath is used to set {id} and {record_id} chi route params to given request
+func withRecordPath(req *http.Request, id string, recordID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	rctx.URLParams.Add("record_id", recordID)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
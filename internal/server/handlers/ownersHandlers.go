@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/pkg/logger"
+)
+
+// GetOwners is a handler func for GET /owners route
+// Will return owners in responses.GetOwnersResp format with pagination metadata and Link header
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetOwners() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		limit, offset := pageBounds(queryInt(request, "limit"), queryInt(request, "offset"))
+
+		res, total, err := h.srv.GetOwners(request.Context(), limit, offset)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Owner{}
+		}
+
+		resp := &responses.GetOwnersResp{
+			Owners: res,
+			Total:  total,
+		}
+
+		setOwnersPagination(writer, request, resp, limit, offset)
+
+		render(writer, request, http.StatusOK, resp)
+	}
+}
+
+// GetOwner is a handler func for GET /owners/{id} route
+// Will return owner in model.Owner format if owner found
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if owner not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetOwner() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetOwner").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetOwner(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// CreateOwner is a handler func for POST /owners route
+// Will return created owner ID in responses.AddOwnerResp format
+// Will return 400 status if no request.Body provided or owner fields are invalid
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) CreateOwner() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := &requests.OwnerReq{}
+
+		if err := decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-CreateOwner").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"name":string, "email":string}`))
+			return
+		}
+
+		id, err := h.srv.AddOwner(request.Context(), model.GetOwnerFromReq(req))
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		resp := &responses.AddOwnerResp{
+			ID: id,
+		}
+
+		render(writer, request, http.StatusCreated, resp)
+	}
+}
+
+// UpdateOwner is a handler func for PUT /owners/{id} route. All owner fields are replaced
+// Will return 200 if request is successful
+// Will return 400 status if no request.Body provided, owner fields are invalid or ID in path is less than 0
+// Will return 404 status if owner not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) UpdateOwner() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateOwner").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.OwnerReq{}
+
+		if err = decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateOwner").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"name":string, "email":string}`))
+			return
+		}
+
+		owner := model.GetOwnerFromReq(req)
+		owner.ID = id
+
+		if err = h.srv.UpdateOwner(request.Context(), owner); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// DeleteOwner is a handler func for DELETE /owners/{id} route
+// Will return 200 if request is successful
+// Will return 400 status if ID in path is less than 0
+// Will return 404 status if owner not found
+// Will return 409 status if owner has pets
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeleteOwner() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeleteOwner").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.srv.DeleteOwner(request.Context(), &model.Owner{ID: id}); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// GetOwnerPets is a handler func for GET /owners/{id}/pets route. It accepts the same query params as GET /pet
+// Will return owner pets in responses.GetPetsResp format with pagination metadata and Link header, empty list if owner
+// has no pets
+// Will return 400 status if ID in path or query params are invalid
+// Will return 404 status if owner not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetOwnerPets() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetOwnerPets").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		query, cur, err := getPetsPage(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetOwnerPets").Warningf("wrong query: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, total, next, err := h.srv.GetOwnerPets(request.Context(), id, query, cur)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writePetsPage(writer, request, query, cur, &responses.GetPetsResp{Pets: res, Total: total, NextCursor: next})
+	}
+}
+
+// TransferPet is a handler func for POST /pet/{id}/transfer route
+// Will return recorded transfer in model.Ownership format
+// Will return 400 status if no request.Body provided, ID in path is less than 0 or owner not exist
+// Will return 404 status if pet not found
+// Will return 409 status if pet already has given owner
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) TransferPet() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-TransferPet").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.TransferReq{}
+
+		if err = decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-TransferPet").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"owner_id":number}`))
+			return
+		}
+
+		transfer := &model.Ownership{PetID: id, ToOwnerID: req.OwnerID, Note: req.Note}
+
+		if err = h.srv.TransferPet(request.Context(), transfer); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, transfer)
+	}
+}
+
+// GetOwnership is a handler func for GET /pet/{id}/ownership route
+// Will return pet ownership history in responses.GetOwnershipResp format, oldest transfer first
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetOwnership() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetOwnership").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetOwnership(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Ownership{}
+		}
+
+		render(writer, request, http.StatusOK, &responses.GetOwnershipResp{History: res})
+	}
+}
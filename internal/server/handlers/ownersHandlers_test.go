@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_GetOwners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		url  string
+
+		srvLimit  int
+		srvOffset int
+		srvErr    error
+		owners    []*model.Owner
+		total     int
+
+		wantBody   *responses.GetOwnersResp
+		wantLink   string
+		wantStatus int
+	}{
+		{
+			name:      "check 200 has more",
+			url:       "/owners?limit=1&offset=1",
+			srvLimit:  1,
+			srvOffset: 1,
+			owners:    []*model.Owner{{ID: 2, Name: "John"}},
+			total:     3,
+			wantBody: &responses.GetOwnersResp{
+				Owners:  []*model.Owner{{ID: 2, Name: "John"}},
+				Total:   3,
+				Limit:   1,
+				Offset:  1,
+				HasMore: true,
+				Next:    "/owners?limit=1&offset=2",
+				Prev:    "/owners?limit=1&offset=0",
+			},
+			wantLink:   `</owners?limit=1&offset=2>; rel="next", </owners?limit=1&offset=0>; rel="prev"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 empty",
+			url:        "/owners",
+			wantBody:   &responses.GetOwnersResp{Owners: []*model.Owner{}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 500 db error",
+			url:        "/owners",
+			srvErr:     fmt.Errorf("db error occurred"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getOwners := h.GetOwners()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+
+			srvMock.EXPECT().GetOwners(gomock.Any(), tt.srvLimit, tt.srvOffset).Return(tt.owners, tt.total, tt.srvErr)
+
+			getOwners.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, "")
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+				require.Equal(t, tt.wantLink, res.Header().Get("Link"))
+			}
+		})
+	}
+}
+
+func TestHandlers_CreateOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		req  *requests.OwnerReq
+
+		goToSev bool
+		id      int
+		srvErr  error
+		owner   *model.Owner
+
+		wantBody   *responses.AddOwnerResp
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 201",
+			req:        &requests.OwnerReq{Name: "John", Email: "john@example.com", Address: "1 Main St"},
+			goToSev:    true,
+			owner:      &model.Owner{Name: "John", Email: "john@example.com", Address: "1 Main St"},
+			id:         1,
+			wantBody:   &responses.AddOwnerResp{ID: 1},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 400 no body",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"name":string, "email":string}`,
+		},
+		{
+			name:       "check 400 invalid owner",
+			req:        &requests.OwnerReq{Name: "John"},
+			goToSev:    true,
+			owner:      &model.Owner{Name: "John"},
+			srvErr:     service.NewValidationError("invalid owner", service.FieldError{Field: "email", Message: "email or phone should be given"}),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			createOwner := h.CreateOwner()
+
+			res := httptest.NewRecorder()
+			var b []byte
+
+			if tt.req != nil {
+				b, _ = json.Marshal(tt.req)
+			}
+
+			req, _ := http.NewRequest("POST", "/owners", bytes.NewReader(b))
+
+			if tt.goToSev {
+				srvMock.EXPECT().AddOwner(gomock.Any(), tt.owner).Return(tt.id, tt.srvErr)
+			}
+
+			createOwner.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_DeleteOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		id   string
+
+		goToSev bool
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			goToSev:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong id",
+			id:         "0",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 404 not found",
+			id:         "1",
+			goToSev:    true,
+			srvErr:     service.NewNotFoundError("owner 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "owner 1 not found",
+		},
+		{
+			name:       "check 409 has pets",
+			id:         "1",
+			goToSev:    true,
+			srvErr:     service.NewConflictError("owner 1 has 2 pets, transfer them first"),
+			wantStatus: http.StatusConflict,
+			wantErr:    "owner 1 has 2 pets, transfer them first",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			deleteOwner := h.DeleteOwner()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/owners/"+tt.id, nil)
+			req = withPathID(req, tt.id)
+
+			if tt.goToSev {
+				srvMock.EXPECT().DeleteOwner(gomock.Any(), &model.Owner{ID: 1}).Return(tt.srvErr)
+			}
+
+			deleteOwner.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Empty(t, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_GetOwnerPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		id   string
+		url  string
+
+		goToSev bool
+		query   *model.PetsQuery
+		srvErr  error
+		pets    []*model.Pet
+		total   int
+
+		wantBody   *responses.GetPetsResp
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			url:        "/owners/1/pets?species=cat",
+			goToSev:    true,
+			query:      &model.PetsQuery{Filter: model.PetsFilter{Species: []model.Species{model.SpeciesCat}}},
+			pets:       []*model.Pet{{ID: 1, Name: "Murka"}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Murka"}}, Total: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 no pets",
+			id:         "1",
+			url:        "/owners/1/pets",
+			goToSev:    true,
+			query:      &model.PetsQuery{},
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong query",
+			id:         "1",
+			url:        "/owners/1/pets?sex=none",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown sex "none"`,
+		},
+		{
+			name:       "check 404 owner not found",
+			id:         "1",
+			url:        "/owners/1/pets",
+			goToSev:    true,
+			query:      &model.PetsQuery{},
+			srvErr:     service.NewNotFoundError("owner 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "owner 1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getOwnerPets := h.GetOwnerPets()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			req = withPathID(req, tt.id)
+
+			if tt.goToSev {
+				srvMock.EXPECT().GetOwnerPets(gomock.Any(), 1, tt.query, "").Return(tt.pets, tt.total, "", tt.srvErr)
+			}
+
+			getOwnerPets.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_TransferPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	ownerID := 2
+
+	tests := []struct {
+		name string
+		id   string
+		req  *requests.TransferReq
+
+		goToSev  bool
+		transfer *model.Ownership
+		srvErr   error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			req:        &requests.TransferReq{OwnerID: &ownerID, Note: "adopted"},
+			goToSev:    true,
+			transfer:   &model.Ownership{PetID: 1, ToOwnerID: &ownerID, Note: "adopted"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 release",
+			id:         "1",
+			req:        &requests.TransferReq{},
+			goToSev:    true,
+			transfer:   &model.Ownership{PetID: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 no body",
+			id:         "1",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"owner_id":number}`,
+		},
+		{
+			name:       "check 404 pet not found",
+			id:         "1",
+			req:        &requests.TransferReq{OwnerID: &ownerID},
+			goToSev:    true,
+			transfer:   &model.Ownership{PetID: 1, ToOwnerID: &ownerID},
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+		{
+			name:       "check 409 same owner",
+			id:         "1",
+			req:        &requests.TransferReq{OwnerID: &ownerID},
+			goToSev:    true,
+			transfer:   &model.Ownership{PetID: 1, ToOwnerID: &ownerID},
+			srvErr:     service.NewConflictError("pet 1 already has this owner"),
+			wantStatus: http.StatusConflict,
+			wantErr:    "pet 1 already has this owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			transferPet := h.TransferPet()
+
+			res := httptest.NewRecorder()
+			var b []byte
+
+			if tt.req != nil {
+				b, _ = json.Marshal(tt.req)
+			}
+
+			req, _ := http.NewRequest("POST", "/pet/"+tt.id+"/transfer", bytes.NewReader(b))
+			req = withPathID(req, tt.id)
+
+			if tt.goToSev {
+				srvMock.EXPECT().TransferPet(gomock.Any(), tt.transfer).Return(tt.srvErr)
+			}
+
+			transferPet.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.transfer)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pets/internal/server/handlers/responses"
+)
+
+// setPagination is used to fill responses.GetPetsResp pagination fields for the page of given limit and offset and to
+// set RFC 8288 Link header with next and prev page links
+func setPagination(writer http.ResponseWriter, request *http.Request, resp *responses.GetPetsResp, limit int, offset int) {
+	resp.Limit, resp.Offset = pageBounds(limit, offset)
+	resp.HasMore, resp.Next, resp.Prev = pageLinks(request, len(resp.Pets), resp.Total, resp.Limit, resp.Offset)
+
+	setLinkHeader(writer, resp.Next, resp.Prev)
+}
+
+// setOwnersPagination is used to fill responses.GetOwnersResp pagination fields for the page of given limit and
+// offset and to set RFC 8288 Link header with next and prev page links
+func setOwnersPagination(writer http.ResponseWriter, request *http.Request, resp *responses.GetOwnersResp, limit int, offset int) {
+	resp.Limit, resp.Offset = pageBounds(limit, offset)
+	resp.HasMore, resp.Next, resp.Prev = pageLinks(request, len(resp.Owners), resp.Total, resp.Limit, resp.Offset)
+
+	setLinkHeader(writer, resp.Next, resp.Prev)
+}
+
+// setAuditPagination is used to fill responses.GetAuditResp pagination fields for the page of given limit and offset
+// and to set RFC 8288 Link header with next and prev page links
+func setAuditPagination(writer http.ResponseWriter, request *http.Request, resp *responses.GetAuditResp, limit int, offset int) {
+	resp.Limit, resp.Offset = pageBounds(limit, offset)
+	resp.HasMore, resp.Next, resp.Prev = pageLinks(request, len(resp.Entries), resp.Total, resp.Limit, resp.Offset)
+
+	setLinkHeader(writer, resp.Next, resp.Prev)
+}
+
+// pageBounds is used to get not negative limit and offset
+func pageBounds(limit int, offset int) (int, int) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+// pageLinks is used to get has more flag, next and prev page links for the page of n items of total with given limit
+// and offset
+func pageLinks(request *http.Request, n int, total int, limit int, offset int) (hasMore bool, next string, prev string) {
+	hasMore = limit > 0 && offset+n < total
+
+	if hasMore {
+		next = pageLink(request, offset+limit)
+	}
+
+	if offset > 0 {
+		p := 0
+		if limit > 0 && offset-limit > 0 {
+			p = offset - limit
+		}
+
+		prev = pageLink(request, p)
+	}
+
+	return hasMore, next, prev
+}
+
+// setCursorPagination is used to fill responses.GetPetsResp pagination fields for the keyset page of given limit and
+// to set RFC 8288 Link header with next page link. Keyset pages have no previous page link
+func setCursorPagination(writer http.ResponseWriter, request *http.Request, resp *responses.GetPetsResp, limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	resp.Limit = limit
+	resp.HasMore = resp.NextCursor != ""
+
+	if resp.HasMore {
+		resp.Next = cursorLink(request, resp.NextCursor)
+	}
+
+	setLinkHeader(writer, resp.Next, resp.Prev)
+}
+
+// setLinkHeader is used to set RFC 8288 Link header with given next and prev page links. Blank links are skipped
+func setLinkHeader(writer http.ResponseWriter, next string, prev string) {
+	var links []string
+
+	if next != "" {
+		links = append(links, fmt.Sprintf(`<%v>; rel="next"`, next))
+	}
+
+	if prev != "" {
+		links = append(links, fmt.Sprintf(`<%v>; rel="prev"`, prev))
+	}
+
+	if len(links) != 0 {
+		writer.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// pageLink is used to get request URI with given offset keeping all other query params
+func pageLink(request *http.Request, offset int) string {
+	u := *request.URL
+
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	u.RawQuery = q.Encode()
+
+	return u.RequestURI()
+}
+
+// cursorLink is used to get request URI with given cursor keeping all other query params except offset
+func cursorLink(request *http.Request, cursor string) string {
+	u := *request.URL
+
+	q := u.Query()
+	q.Del("offset")
+	q.Set("cursor", cursor)
+	u.RawQuery = q.Encode()
+
+	return u.RequestURI()
+}
+
+// queryInt is used to get int query param by given key. If param is not convertable will return 0
+func queryInt(request *http.Request, key string) int {
+	i, err := strconv.Atoi(request.URL.Query().Get(key))
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
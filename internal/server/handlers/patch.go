@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/service"
+)
+
+// Content types of PATCH request bodies
+const (
+	// JSONContentType is a content type of requests.UpdateByIDReq body, given fields are updated. The request can be of
+	// other content types decode supports too
+	JSONContentType = "application/json"
+	// MergePatchContentType is a content type of RFC 7396 JSON Merge Patch body
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is a content type of RFC 6902 JSON Patch body
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// patchContentType is used to get PATCH request body media type. Blank Content-Type is treated as JSONContentType.
+// Will return service.ErrUnsupportedMedia kind error if content type is not one of patch content types or request body
+// content types of decode
+func patchContentType(request *http.Request) (string, error) {
+	header := request.Header.Get("Content-Type")
+	if header == "" {
+		return JSONContentType, nil
+	}
+
+	var supported []string
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err == nil && (mediaType == MergePatchContentType || mediaType == JSONPatchContentType) {
+		return mediaType, nil
+	}
+
+	for _, c := range codecs {
+		if c.decode == nil {
+			continue
+		}
+
+		if err == nil && c.matches(mediaType) && !strings.HasSuffix(mediaType, "/*") {
+			return mediaType, nil
+		}
+
+		supported = append(supported, c.contentType)
+	}
+
+	supported = append(supported, MergePatchContentType, JSONPatchContentType)
+
+	return "", service.NewUnsupportedMediaError(fmt.Sprintf("content type %q is not supported, use %v", header,
+		strings.Join(supported, ", ")))
+}
+
+// applyPetPatch is used to apply given JSON Merge Patch or JSON Patch body to the pet fields changed by the client, see
+// model.Pet ToReq. Function will return a new pet with patched fields, read-only fields are not set. Will return
+// service.ErrValidation kind error if patch is malformed or patched pet is not a valid requests.AddPetReq,
+// service.ErrConflict kind error if JSON Patch test operation failed
+func applyPetPatch(contentType string, body []byte, pet *model.Pet) (*model.Pet, error) {
+	doc, err := json.Marshal(pet.ToReq())
+	if err != nil {
+		return nil, err
+	}
+
+	var res []byte
+
+	switch contentType {
+	case MergePatchContentType:
+		res, err = jsonpatch.MergePatch(doc, body)
+	case JSONPatchContentType:
+		var patch jsonpatch.Patch
+
+		if patch, err = jsonpatch.DecodePatch(body); err == nil {
+			res, err = patch.Apply(doc)
+		}
+	default:
+		return nil, fmt.Errorf("unknown patch content type %q", contentType)
+	}
+
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return nil, service.NewConflictError(fmt.Sprintf("patch cannot be applied: %v", err.Error()))
+	case err != nil:
+		return nil, invalidParam("body", fmt.Sprintf("invalid patch: %v", err.Error()))
+	}
+
+	req := &requests.AddPetReq{}
+
+	dec := json.NewDecoder(bytes.NewReader(res))
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(req); err != nil {
+		return nil, invalidParam("body", fmt.Sprintf("patched pet is invalid: %v", err.Error()))
+	}
+
+	patched, err := model.GetPetFromReq(req)
+	if err != nil {
+		return nil, invalidParam("birth_date", err.Error())
+	}
+
+	patched.ID = pet.ID
+
+	return patched, nil
+}
@@ -1,78 +1,146 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
 
 	"pets/internal/model"
 	"pets/internal/server/handlers/requests"
 	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
 	"pets/pkg/logger"
 )
 
 // GetPets is a handler func for GET /pet route
-// Will return pets in responses.GetPetsResp format if pets found
+// Will return pets in responses.GetPetsResp format with pagination metadata and Link header if pets found
+// Will return 400 status if query params are invalid, cursor is invalid or used together with offset
 // Will return 404 status if pets not found
-// Can return 500 if unexpected DB error or encoding error occurred
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
 func (h *Handlers) GetPets() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		l := request.URL.Query().Get("limit")
-		o := request.URL.Query().Get("offset")
-		ord := request.URL.Query().Get("order")
+		query, cur, err := getPetsPage(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetPets").Warningf("wrong query: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
 
-		res, total, err := h.srv.GetPets(l, o, ord)
+		res, total, next, err := h.srv.GetPets(request.Context(), query, cur)
 		if err != nil {
-			http.Error(writer, fmt.Sprintf("db error"), http.StatusInternalServerError)
+			writeError(writer, request, err)
 			return
 		}
 
 		if total == 0 {
 			logger.Log().WithField("layer", "Handlers-GetPets").Warningf("pets not found")
-			http.Error(writer, fmt.Sprintf("pets not found"), http.StatusNotFound)
+			writeError(writer, request, service.NewNotFoundError("pets not found"))
 			return
 		}
 
-		log.Println(res[0].CreatedAt.Local())
+		writePetsPage(writer, request, query, cur, &responses.GetPetsResp{Pets: res, Total: total, NextCursor: next})
+	}
+}
+
+// getPetsPage is used to get model.PetsQuery and cursor from pets list query params. Will return service.ErrValidation
+// kind error if params are invalid or cursor is used together with offset
+func getPetsPage(request *http.Request) (*model.PetsQuery, string, error) {
+	cur := request.URL.Query().Get("cursor")
+
+	if cur != "" && request.URL.Query().Get("offset") != "" {
+		return nil, "", invalidParam("cursor", "cursor and offset cannot be used together")
+	}
+
+	query, err := getPetsQuery(request)
+	if err != nil {
+		return nil, "", err
+	}
 
-		resp := &responses.GetPetsResp{
-			Pets:  res,
-			Total: total,
+	return query, cur, nil
+}
+
+// writePetsPage is used to write pets list page as responses.GetPetsResp with pagination metadata and Link header
+func writePetsPage(writer http.ResponseWriter, request *http.Request, query *model.PetsQuery, cur string, resp *responses.GetPetsResp) {
+	// page after the last one is empty, but pets exist
+	if resp.Pets == nil {
+		resp.Pets = []*model.Pet{}
+	}
+
+	if cur != "" {
+		setCursorPagination(writer, request, resp, query.Limit)
+	} else {
+		setPagination(writer, request, resp, query.Limit, query.Offset)
+	}
+
+	render(writer, request, http.StatusOK, resp)
+}
+
+// GetPet is a handler func for GET /pet/{id} route
+// Will return pet in model.Pet format with ETag header of the negotiated representation if pet found
+// Will return 304 status without body if If-None-Match header matches the pet ETag
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetPet() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetPet").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
 		}
 
-		writer.WriteHeader(http.StatusOK)
-		if err = json.NewEncoder(writer).Encode(resp); err != nil {
-			logger.Log().WithField("layer", "Handlers-GetPets").Errorf("error encode resp %v", err.Error())
-			http.Error(writer, fmt.Sprintf("decode error"), http.StatusInternalServerError)
+		res, err := h.srv.GetPet(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
 			return
 		}
+
+		etag, err := petETag(request, res)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.Header().Set("ETag", etag)
+
+		if notModified(request, etag) {
+			writer.Header().Add("Vary", "Accept")
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
 	}
 }
 
 // CreatePet is a handler func for POST /pet route
 // Will return created pet ID in responses.AddPetResp format
-// Will return 400 status if no request.Body provided or name in body is blank
-// Can return 500 if unexpected DB error or encoding error occurred
+// Will return 400 status if no request.Body provided, name in body is blank or pet fields are invalid
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
 func (h *Handlers) CreatePet() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		req := &requests.AddPetReq{}
 
-		if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		if err := decode(request, req); err != nil {
 			logger.Log().WithField("layer", "Handlers-CreatePet").Errorf("err decode body: %v", err.Error())
-			http.Error(writer, fmt.Sprintf(`provide body params {"name":string}`), http.StatusBadRequest)
+			writeError(writer, request, bodyError(err, `provide body params {"name":string}`))
 			return
 		}
 
-		if req.Name == "" {
-			logger.Log().WithField("layer", "Handlers-CreatePet").Errorf("received blank name")
-			http.Error(writer, fmt.Sprintf("name cannot be blank"), http.StatusBadRequest)
+		pet, err := model.GetPetFromReq(req)
+		if err != nil {
+			writeError(writer, request, invalidParam("birth_date", err.Error()))
 			return
 		}
 
-		id, err := h.srv.AddPet(model.GetPetFromReq(req))
+		id, err := h.srv.AddPet(request.Context(), pet)
 		if err != nil {
-			http.Error(writer, fmt.Sprintf("db error"), http.StatusInternalServerError)
+			writeError(writer, request, err)
 			return
 		}
 
@@ -80,49 +148,37 @@ func (h *Handlers) CreatePet() http.HandlerFunc {
 			ID: id,
 		}
 
-		writer.WriteHeader(http.StatusCreated)
-		if err = json.NewEncoder(writer).Encode(resp); err != nil {
-			logger.Log().WithField("layer", "Handlers-CreatePet").Errorf("error encode resp %v", err.Error())
-			http.Error(writer, fmt.Sprintf("decode error"), http.StatusInternalServerError)
-			return
-		}
+		render(writer, request, http.StatusCreated, resp)
 	}
 }
 
-// UpdatePet is a handler func for PUT /pet route
+// UpdatePet is a handler func for deprecated PUT /pet route, use UpdatePetByID instead. Link header is set to the
+// successor route of the pet
 // Will return 200 if request is successful
-// Will return 400 status if no request.Body provided or name in body is blank or ID in body is less than 0 or ID not found
-// Can return 500 if unexpected DB error occurred
+// Will return 400 status if no request.Body provided, name in body is blank, pet fields are invalid or ID in body is less than 0
+// Will return 404 status if pet not found
+// Will return 412 status if If-Match header does not match the pet ETag
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
 func (h *Handlers) UpdatePet() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		req := &requests.UpdateReq{}
 
-		if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		if err := decode(request, req); err != nil {
 			logger.Log().WithField("layer", "Handlers-UpdatePet").Warningf("err decode body: %v", err.Error())
-			http.Error(writer, fmt.Sprintf(`provide body params {"name":string, "id": number}`), http.StatusBadRequest)
-			return
-		}
-
-		if req.Name == "" {
-			logger.Log().WithField("layer", "Handlers-UpdatePet").Warningf("received blank name")
-			http.Error(writer, fmt.Sprintf("name cannot be blank"), http.StatusBadRequest)
+			writeError(writer, request, bodyError(err, `provide body params {"name":string, "id": number}`))
 			return
 		}
 
 		if req.ID <= 0 {
 			logger.Log().WithField("layer", "Handlers-UpdatePet").Warningf("received id less than 0: %v", req.ID)
-			http.Error(writer, fmt.Sprintf("id should be more than 0"), http.StatusBadRequest)
+			writeError(writer, request, invalidParam("id", "id should be more than 0"))
 			return
 		}
 
-		if !h.srv.IsExist(req.ID) {
-			logger.Log().WithField("layer", "Handlers-UpdatePet").Warningf("pet does not exist id %v", req.ID)
-			http.Error(writer, fmt.Sprintf("pet does not exist"), http.StatusBadRequest)
-			return
-		}
+		successorLink(writer, request, req.ID)
 
-		if err := h.srv.UpdatePet(&model.Pet{ID: req.ID, Name: req.Name}); err != nil {
-			http.Error(writer, fmt.Sprintf("db error"), http.StatusInternalServerError)
+		if err := h.updatePet(request, req.ID, &req.UpdateByIDReq); err != nil {
+			writeError(writer, request, err)
 			return
 		}
 
@@ -130,37 +186,268 @@ func (h *Handlers) UpdatePet() http.HandlerFunc {
 	}
 }
 
-// DeletePet is a handler func for DELETE /pet route
+// DeletePet is a handler func for deprecated DELETE /pet route, use DeletePetByID instead. Link header is set to the
+// successor route of the pet
 // Will return 200 if request is successful
-// Will return 400 status if no request.Body provided or ID in body is less than 0 or ID not found
-// Can return 500 if unexpected DB error occurred
+// Will return 400 status if no request.Body provided or ID in body is less than 0
+// Will return 404 status if pet not found
+// Will return 412 status if If-Match header does not match the pet ETag
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
 func (h *Handlers) DeletePet() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		req := &requests.DeleteReq{}
 
-		if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		if err := decode(request, req); err != nil {
 			logger.Log().WithField("layer", "Handlers-DeletePet").Warningf("err decode body: %v", err.Error())
-			http.Error(writer, fmt.Sprintf(`provide body params {"id": number}`), http.StatusBadRequest)
+			writeError(writer, request, bodyError(err, `provide body params {"id": number}`))
 			return
 		}
 
 		if req.ID <= 0 {
 			logger.Log().WithField("layer", "Handlers-DeletePet").Warningf("received id less than 0: %v", req.ID)
-			http.Error(writer, fmt.Sprintf("id should be more than 0"), http.StatusBadRequest)
+			writeError(writer, request, invalidParam("id", "id should be more than 0"))
+			return
+		}
+
+		successorLink(writer, request, req.ID)
+
+		if err := h.deletePet(request, req.ID); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// successorLink is used to set Link header of deprecated body-based pet route to its /pet/{id} successor route with
+// given pet ID
+func successorLink(writer http.ResponseWriter, request *http.Request, id int) {
+	writer.Header().Set("Link", fmt.Sprintf(`<%v/%v>; rel="successor-version"`, strings.TrimSuffix(request.URL.Path, "/"), id))
+}
+
+// UpdatePetByID is a handler func for PUT /pet/{id} route
+// Will return 200 if request is successful
+// Will return 400 status if no request.Body provided, name in body is blank, pet fields are invalid or ID in path is less than 0
+// Will return 404 status if pet not found
+// Will return 412 status if If-Match header does not match the pet ETag
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) UpdatePetByID() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdatePetByID").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.UpdateByIDReq{}
+
+		if err = decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdatePetByID").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"name":string}`))
+			return
+		}
+
+		if err = h.updatePet(request, id, req); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// PatchPet is a handler func for PATCH /pet/{id} route. Body is a requests.UpdateByIDReq for application/json or other
+// content types decode supports, RFC 7396 JSON Merge Patch or RFC 6902 JSON Patch of the pet fields for
+// application/merge-patch+json and application/json-patch+json. Only changed fields are updated
+// Will return 200 if request is successful
+// Will return 400 status if no request.Body provided, patch is malformed, patched pet is invalid or ID in path is less than 0
+// Will return 404 status if pet not found
+// Will return 409 status if status cannot be changed manually or JSON Patch test operation failed
+// Will return 412 status if If-Match header does not match the pet ETag
+// Will return 415 status if content type is not supported
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) PatchPet() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-PatchPet").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
 			return
 		}
 
-		if !h.srv.IsExist(req.ID) {
-			logger.Log().WithField("layer", "Handlers-DeletePet").Warningf("pet does not exist id %v", req.ID)
-			http.Error(writer, fmt.Sprintf("pet does not exist"), http.StatusBadRequest)
+		contentType, err := patchContentType(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-PatchPet").Warningf("wrong content type: %v", err.Error())
+			writeError(writer, request, err)
 			return
 		}
 
-		if err := h.srv.DeletePet(&model.Pet{ID: req.ID}); err != nil {
-			http.Error(writer, fmt.Sprintf("db error"), http.StatusInternalServerError)
+		var req *requests.UpdateByIDReq
+		var body []byte
+
+		switch contentType {
+		case MergePatchContentType, JSONPatchContentType:
+			body, err = io.ReadAll(request.Body)
+			if err != nil || len(body) == 0 {
+				logger.Log().WithField("layer", "Handlers-PatchPet").Warningf("err read body: %v", err)
+				writeError(writer, request, invalidParam("body", "provide patch body"))
+				return
+			}
+		default:
+			req = &requests.UpdateByIDReq{}
+
+			if err = decode(request, req); err != nil {
+				logger.Log().WithField("layer", "Handlers-PatchPet").Warningf("err decode body: %v", err.Error())
+				writeError(writer, request, bodyError(err, `provide body params {"name":string}`))
+				return
+			}
+		}
+
+		if err = h.patchPet(request, id, req, contentType, body); err != nil {
+			writeError(writer, request, err)
 			return
 		}
 
 		writer.WriteHeader(http.StatusOK)
 	}
 }
+
+// DeletePetByID is a handler func for DELETE /pet/{id} route. The pet is soft deleted and can be restored until it is
+// purged
+// Will return 200 if request is successful
+// Will return 400 status if ID in path is less than 0
+// Will return 404 status if pet not found
+// Will return 412 status if If-Match header does not match the pet ETag
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeletePetByID() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeletePetByID").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.deletePet(request, id); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// RestorePet is a handler func for POST /pet/{id}/restore route
+// Will return restored pet in model.Pet format
+// Will return 400 status if ID in path is less than 0
+// Will return 404 status if pet not found or purged
+// Will return 409 status if pet is not deleted
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) RestorePet() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-RestorePet").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.RestorePet(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// updatePet is used to replace the stored pet with given ID with given requests.UpdateByIDReq, so fields not given in
+// request are reset to defaults. Pet is updated only if it matches the request If-Match header
+func (h *Handlers) updatePet(request *http.Request, id int, req *requests.UpdateByIDReq) error {
+	pet, err := h.srv.GetPet(request.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if pet.Version, err = ifMatchVersion(request, pet); err != nil {
+		return err
+	}
+
+	if err = pet.ReplaceWithReq(req); err != nil {
+		return invalidParam("birth_date", err.Error())
+	}
+
+	return h.srv.UpdatePet(request.Context(), pet)
+}
+
+// patchPet is used to apply given update request or PATCH body of given patch content type if the request is nil to the
+// stored pet with given ID and save changed fields only. Pet is patched only if it matches the request If-Match header
+func (h *Handlers) patchPet(request *http.Request, id int, req *requests.UpdateByIDReq, contentType string, body []byte) error {
+	pet, err := h.srv.GetPet(request.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	version, err := ifMatchVersion(request, pet)
+	if err != nil {
+		return err
+	}
+
+	var patched *model.Pet
+
+	if req != nil {
+		p := *pet
+		patched = &p
+
+		if err = patched.ApplyUpdateReq(req); err != nil {
+			return invalidParam("birth_date", err.Error())
+		}
+	} else if patched, err = applyPetPatch(contentType, body, pet); err != nil {
+		return err
+	}
+
+	patched.Version = version
+
+	return h.srv.PatchPet(request.Context(), patched, pet.Changes(patched))
+}
+
+// deletePet is used to soft delete pet with given ID. If the request has If-Match header pet is deleted only if it
+// matches the header
+func (h *Handlers) deletePet(request *http.Request, id int) error {
+	pet := &model.Pet{ID: id}
+
+	if request.Header.Get("If-Match") != "" {
+		stored, err := h.srv.GetPet(request.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		if pet.Version, err = ifMatchVersion(request, stored); err != nil {
+			return err
+		}
+	}
+
+	return h.srv.DeletePet(request.Context(), pet)
+}
+
+// getPathID is used to get pet, owner or application ID from the {id} route param. Will return service.ErrValidation
+// kind error if param is not a number or less than 1
+func getPathID(request *http.Request) (int, error) {
+	return getPathInt(request, "id")
+}
+
+// getPathInt is used to get ID route param by given key. Will return service.ErrValidation kind error if param is not a
+// number or less than 1
+func getPathInt(request *http.Request, key string) (int, error) {
+	param := chi.URLParam(request, key)
+
+	id, err := strconv.Atoi(param)
+	if err != nil || id <= 0 {
+		return 0, invalidParam(key, fmt.Sprintf("%v should be a number more than 0, got %q", key, param))
+	}
+
+	return id, nil
+}
@@ -2,18 +2,22 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
 	"pets/internal/model"
 	"pets/internal/server/handlers/requests"
 	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
 	mock_service "pets/mocks/service"
 )
 
@@ -22,40 +26,289 @@ func TestHandlers_GetPets(t *testing.T) {
 	srvMock := mock_service.NewMockIService(ctrl)
 	defer ctrl.Finish()
 
+	createdAfter := time.Date(2023, 9, 17, 10, 0, 0, 0, time.UTC)
+	bornAfter := model.NewDate(2020, time.January, 1)
+	neutered := true
+	deletedAt := time.Date(2023, time.November, 12, 10, 0, 0, 0, time.UTC)
+	maxWeight := 10.5
+
 	tests := []struct {
 		name   string
 		url    string
-		limit  string
-		offset string
-		order  string
+		query  *model.PetsQuery
+		cursor string
 
 		goToSev bool
 		srvErr  error
 		pets    []*model.Pet
 		total   int
+		next    string
 
 		wantBody   *responses.GetPetsResp
+		wantLink   string
 		wantStatus int
 		wantErr    string
 	}{
 		{
-			name:       "check 200 query",
-			url:        "/pets?limit=1&offset=2&order=asc",
-			limit:      "1",
-			offset:     "2",
-			order:      "asc",
+			name:    "check 200 query",
+			url:     "/pets?limit=1&offset=2&order=asc",
+			query:   &model.PetsQuery{Limit: 1, Offset: 2},
+			goToSev: true,
+			pets:    []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}},
+			total:   2,
+			wantBody: &responses.GetPetsResp{
+				Pets:   []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}},
+				Total:  2,
+				Limit:  1,
+				Offset: 2,
+				Prev:   "/pets?limit=1&offset=1&order=asc",
+			},
+			wantLink:   `</pets?limit=1&offset=1&order=asc>; rel="prev"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "check 200 has more",
+			url:     "/pets?limit=2&offset=2",
+			query:   &model.PetsQuery{Limit: 2, Offset: 2},
+			goToSev: true,
+			pets:    []*model.Pet{{ID: 3, Name: "Velho"}, {ID: 4, Name: "Melho"}},
+			total:   5,
+			wantBody: &responses.GetPetsResp{
+				Pets:    []*model.Pet{{ID: 3, Name: "Velho"}, {ID: 4, Name: "Melho"}},
+				Total:   5,
+				Limit:   2,
+				Offset:  2,
+				HasMore: true,
+				Next:    "/pets?limit=2&offset=4",
+				Prev:    "/pets?limit=2&offset=0",
+			},
+			wantLink:   `</pets?limit=2&offset=4>; rel="next", </pets?limit=2&offset=0>; rel="prev"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "check 200 offset next cursor",
+			url:     "/pets?limit=2",
+			query:   &model.PetsQuery{Limit: 2},
+			goToSev: true,
+			pets:    []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}},
+			total:   3,
+			next:    "next.sig",
+			wantBody: &responses.GetPetsResp{
+				Pets:       []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}},
+				Total:      3,
+				Limit:      2,
+				HasMore:    true,
+				Next:       "/pets?limit=2&offset=2",
+				NextCursor: "next.sig",
+			},
+			wantLink:   `</pets?limit=2&offset=2>; rel="next"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "check 200 cursor",
+			url:     "/pets?limit=2&cursor=cur.sig",
+			query:   &model.PetsQuery{Limit: 2},
+			cursor:  "cur.sig",
+			goToSev: true,
+			pets:    []*model.Pet{{ID: 3, Name: "Velho"}, {ID: 4, Name: "Melho"}},
+			total:   5,
+			next:    "next.sig",
+			wantBody: &responses.GetPetsResp{
+				Pets:       []*model.Pet{{ID: 3, Name: "Velho"}, {ID: 4, Name: "Melho"}},
+				Total:      5,
+				Limit:      2,
+				HasMore:    true,
+				Next:       "/pets?cursor=next.sig&limit=2",
+				NextCursor: "next.sig",
+			},
+			wantLink:   `</pets?cursor=next.sig&limit=2>; rel="next"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "check 200 cursor last page",
+			url:     "/pets?limit=2&cursor=cur.sig",
+			query:   &model.PetsQuery{Limit: 2},
+			cursor:  "cur.sig",
+			goToSev: true,
+			pets:    []*model.Pet{{ID: 5, Name: "Velho"}},
+			total:   5,
+			wantBody: &responses.GetPetsResp{
+				Pets:  []*model.Pet{{ID: 5, Name: "Velho"}},
+				Total: 5,
+				Limit: 2,
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "check 200 filter and sort",
+			url:  "/pets?name=vel&name_match=prefix&created_after=2023-09-17T10:00:00Z&id=1,2&id=3&sort=name,-created_at",
+			query: &model.PetsQuery{
+				Filter: model.PetsFilter{Name: "vel", NameMatch: model.NamePrefix, CreatedAfter: &createdAfter, IDs: []int{1, 2, 3}},
+				Sort:   []model.SortField{{Field: model.SortName}, {Field: model.SortCreatedAt, Desc: true}},
+			},
 			goToSev:    true,
-			pets:       []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}},
-			total:      2,
-			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}}, Total: 2},
+			pets:       []*model.Pet{{ID: 1, Name: "Velho"}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho"}}, Total: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "check 200 pet details filter",
+			url:  "/pets?species=dog,cat&status=available&sex=female&neutered=true&breed=beagle&born_after=2020-01-01&weight_max=10.5",
+			query: &model.PetsQuery{
+				Filter: model.PetsFilter{
+					Species:   []model.Species{model.SpeciesDog, model.SpeciesCat},
+					Statuses:  []model.Status{model.StatusAvailable},
+					Sex:       model.SexFemale,
+					Neutered:  &neutered,
+					Breed:     "beagle",
+					BornAfter: &bornAfter,
+					MaxWeight: &maxWeight,
+				},
+			},
+			goToSev:    true,
+			pets:       []*model.Pet{{ID: 1, Name: "Velho"}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho"}}, Total: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "check 200 tags filter",
+			url:  "/pets?tag=senior&tag=special-needs,good-with-kids&tag_match=all",
+			query: &model.PetsQuery{
+				Filter: model.PetsFilter{
+					Tags:     []string{"senior", "special-needs", "good-with-kids"},
+					TagMatch: model.TagAll,
+				},
+			},
+			goToSev:    true,
+			pets:       []*model.Pet{{ID: 1, Name: "Velho", Tags: []string{"senior"}}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho", Tags: []string{"senior"}}}, Total: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 unknown tag_match",
+			url:        "/pets?tag=senior&tag_match=some",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown tag_match "some"`,
+		},
+		{
+			name:       "check 200 legacy desc order",
+			url:        "/pets?order=DESC",
+			query:      &model.PetsQuery{Sort: []model.SortField{{Field: model.SortID, Desc: true}}},
+			goToSev:    true,
+			pets:       []*model.Pet{{ID: 1, Name: "Velho"}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho"}}, Total: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 invalid limit ignored",
+			url:        "/pets?limit=notInt&offset=-1",
+			query:      &model.PetsQuery{},
+			goToSev:    true,
+			pets:       []*model.Pet{{ID: 1, Name: "Velho"}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho"}}, Total: 1},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "check 400 cursor and offset",
+			url:        "/pets?offset=2&cursor=cur.sig",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "cursor and offset cannot be used together",
+		},
+		{
+			name:       "check 400 invalid cursor",
+			url:        "/pets?cursor=bad",
+			query:      &model.PetsQuery{},
+			cursor:     "bad",
+			goToSev:    true,
+			srvErr:     fmt.Errorf("decode: %w", service.ErrInvalidCursor),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid cursor",
+		},
+		{
+			name:       "check 400 unknown sort field",
+			url:        "/pets?sort=name,-weight",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown sort field "weight"`,
+		},
+		{
+			name:       "check 400 repeated sort field",
+			url:        "/pets?sort=name,-name",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `repeated sort field "name"`,
+		},
+		{
+			name:       "check 400 sort and order",
+			url:        "/pets?sort=name&order=desc",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "sort and order cannot be used together",
+		},
+		{
+			name:       "check 400 name match",
+			url:        "/pets?name=vel&name_match=regex",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown name_match "regex"`,
+		},
+		{
+			name:       "check 400 time",
+			url:        "/pets?updated_since=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid updated_since: should be RFC 3339 time",
+		},
+		{
+			name:       "check 400 id",
+			url:        "/pets?id=1,a",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `invalid id "a"`,
+		},
+		{
+			name:       "check 400 species",
+			url:        "/pets?species=dog,dragon",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `unknown species "dragon"`,
+		},
+		{
+			name:       "check 400 neutered",
+			url:        "/pets?neutered=maybe",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid neutered: should be true or false",
+		},
+		{
+			name:       "check 200 include deleted",
+			url:        "/pets?include_deleted=true",
+			query:      &model.PetsQuery{Filter: model.PetsFilter{IncludeDeleted: true}},
+			goToSev:    true,
+			pets:       []*model.Pet{{ID: 1, Name: "Velho", DeletedAt: &deletedAt}},
+			total:      1,
+			wantBody:   &responses.GetPetsResp{Pets: []*model.Pet{{ID: 1, Name: "Velho", DeletedAt: &deletedAt}}, Total: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 include deleted",
+			url:        "/pets?include_deleted=yes",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid include_deleted: should be true or false",
+		},
+		{
+			name:       "check 400 born date",
+			url:        "/pets?born_before=2020",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid born_before: should be a date in YYYY-MM-DD format",
+		},
+		{
+			name:       "check 400 weight",
+			url:        "/pets?weight_min=heavy",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid weight_min: should be a number",
+		},
 		{
 			name:       "check 200 no query",
 			url:        "/pets",
-			limit:      "",
-			offset:     "",
-			order:      "",
+			query:      &model.PetsQuery{},
 			goToSev:    true,
 			pets:       []*model.Pet{{ID: 1, Name: "Velho"}, {ID: 2, Name: "Melho"}},
 			total:      2,
@@ -65,9 +318,7 @@ func TestHandlers_GetPets(t *testing.T) {
 		{
 			name:       "check 404 not found",
 			url:        "/pets",
-			limit:      "",
-			offset:     "",
-			order:      "",
+			query:      &model.PetsQuery{},
 			goToSev:    true,
 			total:      0,
 			wantStatus: http.StatusNotFound,
@@ -76,13 +327,10 @@ func TestHandlers_GetPets(t *testing.T) {
 		{
 			name:       "check 500 db error",
 			url:        "/pets",
-			limit:      "",
-			offset:     "",
-			order:      "",
+			query:      &model.PetsQuery{},
 			goToSev:    true,
 			srvErr:     fmt.Errorf("db error occurred"),
 			wantStatus: http.StatusInternalServerError,
-			wantErr:    "db error",
 		},
 	}
 	for _, tt := range tests {
@@ -97,20 +345,21 @@ func TestHandlers_GetPets(t *testing.T) {
 			req, _ := http.NewRequest("GET", tt.url, body)
 
 			if tt.goToSev {
-				srvMock.EXPECT().GetPets(tt.limit, tt.offset, tt.order).Return(tt.pets, tt.total, tt.srvErr)
+				srvMock.EXPECT().GetPets(gomock.Any(), tt.query, tt.cursor).Return(tt.pets, tt.total, tt.next, tt.srvErr)
 			}
 
 			getPets.ServeHTTP(res, req)
 
-			want := httptest.NewRecorder()
-			if tt.wantErr == "" {
-				json.NewEncoder(want).Encode(tt.wantBody)
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
 			} else {
-				http.Error(want, tt.wantErr, tt.wantStatus)
-			}
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
 
-			require.Equal(t, tt.wantStatus, res.Code)
-			require.Equal(t, want.Body.String(), res.Body.String())
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+			require.Equal(t, tt.wantLink, res.Header().Get("Link"))
 		})
 	}
 }
@@ -120,6 +369,9 @@ func TestHandlers_CreatePet(t *testing.T) {
 	srvMock := mock_service.NewMockIService(ctrl)
 	defer ctrl.Finish()
 
+	weight := 12.5
+	birthDate := model.NewDate(2020, time.May, 1)
+
 	tests := []struct {
 		name string
 		req  *requests.AddPetReq
@@ -142,6 +394,24 @@ func TestHandlers_CreatePet(t *testing.T) {
 			wantBody:   &responses.AddPetResp{ID: 1},
 			wantStatus: http.StatusCreated,
 		},
+		{
+			name: "check 201 all fields",
+			req: &requests.AddPetReq{Name: "Velho", Species: "dog", Breed: "beagle", BirthDate: "2020-05-01", Sex: "male",
+				Neutered: true, Weight: &weight, Color: "tricolor", Description: "friendly", Status: "pending"},
+			goToSev: true,
+			pet: &model.Pet{Name: "Velho", Species: model.SpeciesDog, Breed: "beagle", BirthDate: &birthDate,
+				Sex: model.SexMale, Neutered: true, Weight: &weight, Color: "tricolor", Description: "friendly",
+				Status: model.StatusPending},
+			id:         1,
+			wantBody:   &responses.AddPetResp{ID: 1},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 400 wrong birth date",
+			req:        &requests.AddPetReq{Name: "Velho", BirthDate: "2020/05/01"},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "should be a date in YYYY-MM-DD format",
+		},
 		{
 			name:       "check 400 no body",
 			wantStatus: http.StatusBadRequest,
@@ -150,8 +420,11 @@ func TestHandlers_CreatePet(t *testing.T) {
 		{
 			name:       "check 400 blank name",
 			req:        &requests.AddPetReq{Name: ""},
+			goToSev:    true,
+			pet:        &model.Pet{Name: ""},
+			srvErr:     service.NewValidationError("invalid pet", service.FieldError{Field: "name", Message: "cannot be blank"}),
 			wantStatus: http.StatusBadRequest,
-			wantErr:    "name cannot be blank",
+			wantErr:    "invalid pet",
 		},
 		{
 			name:       "check 500 db error",
@@ -160,7 +433,6 @@ func TestHandlers_CreatePet(t *testing.T) {
 			pet:        &model.Pet{Name: "Velho"},
 			srvErr:     fmt.Errorf("db error occurred"),
 			wantStatus: http.StatusInternalServerError,
-			wantErr:    "db error",
 		},
 	}
 	for _, tt := range tests {
@@ -179,20 +451,20 @@ func TestHandlers_CreatePet(t *testing.T) {
 			req, _ := http.NewRequest("POST", "/pet", body)
 
 			if tt.goToSev {
-				srvMock.EXPECT().AddPet(tt.pet).Return(tt.id, tt.srvErr)
+				srvMock.EXPECT().AddPet(gomock.Any(), tt.pet).Return(tt.id, tt.srvErr)
 			}
 
 			createPet.ServeHTTP(res, req)
 
-			want := httptest.NewRecorder()
-			if tt.wantErr == "" {
-				json.NewEncoder(want).Encode(tt.wantBody)
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
 			} else {
-				http.Error(want, tt.wantErr, tt.wantStatus)
-			}
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
 
-			require.Equal(t, tt.wantStatus, res.Code)
-			require.Equal(t, want.Body.String(), res.Body.String())
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
 		})
 	}
 }
@@ -202,27 +474,35 @@ func TestHandlers_UpdatePet(t *testing.T) {
 	srvMock := mock_service.NewMockIService(ctrl)
 	defer ctrl.Finish()
 
+	breed := "siamese"
+
 	tests := []struct {
 		name string
 		req  *requests.UpdateReq
 
-		goToExist bool
-		exist     bool
-
-		goToSev bool
-		srvErr  error
-		pet     *model.Pet
+		goToSev  bool
+		getErr   error
+		goUpdate bool
+		srvErr   error
+		pet      *model.Pet
 
 		wantStatus int
 		wantErr    string
 	}{
 		{
 			name:       "check 200",
-			req:        &requests.UpdateReq{Name: "Velho", ID: 1},
-			goToExist:  true,
-			exist:      true,
+			req:        &requests.UpdateReq{ID: 1, UpdateByIDReq: requests.UpdateByIDReq{Name: "Velho"}},
 			goToSev:    true,
-			pet:        &model.Pet{Name: "Velho", ID: 1},
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 given fields",
+			req:        &requests.UpdateReq{ID: 1, UpdateByIDReq: requests.UpdateByIDReq{Name: "Velho", Breed: &breed}},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved, Breed: breed},
 			wantStatus: http.StatusOK,
 		},
 		{
@@ -232,34 +512,36 @@ func TestHandlers_UpdatePet(t *testing.T) {
 		},
 		{
 			name:       "check 400 blank name",
-			req:        &requests.UpdateReq{Name: "", ID: 1},
+			req:        &requests.UpdateReq{ID: 1, UpdateByIDReq: requests.UpdateByIDReq{Name: ""}},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "", ID: 1, Status: model.StatusReserved},
+			srvErr:     service.NewValidationError("invalid pet", service.FieldError{Field: "name", Message: "cannot be blank"}),
 			wantStatus: http.StatusBadRequest,
-			wantErr:    "name cannot be blank",
+			wantErr:    "invalid pet",
 		},
 		{
 			name:       "check 400 0 id",
-			req:        &requests.UpdateReq{Name: "Velho", ID: 0},
+			req:        &requests.UpdateReq{ID: 0, UpdateByIDReq: requests.UpdateByIDReq{Name: "Velho"}},
 			wantStatus: http.StatusBadRequest,
 			wantErr:    "id should be more than 0",
 		},
 		{
-			name:       "check 400 not exist",
-			req:        &requests.UpdateReq{Name: "Velho", ID: 1},
-			goToExist:  true,
-			exist:      false,
-			wantStatus: http.StatusBadRequest,
-			wantErr:    "pet does not exist",
+			name:       "check 404 not exist",
+			req:        &requests.UpdateReq{ID: 1, UpdateByIDReq: requests.UpdateByIDReq{Name: "Velho"}},
+			goToSev:    true,
+			getErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
 		},
 		{
 			name:       "check 500 db error",
-			req:        &requests.UpdateReq{Name: "Velho", ID: 1},
-			goToExist:  true,
-			exist:      true,
+			req:        &requests.UpdateReq{ID: 1, UpdateByIDReq: requests.UpdateByIDReq{Name: "Velho"}},
 			goToSev:    true,
-			pet:        &model.Pet{Name: "Velho", ID: 1},
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved},
 			srvErr:     fmt.Errorf("db error occured"),
 			wantStatus: http.StatusInternalServerError,
-			wantErr:    "db error",
 		},
 	}
 	for _, tt := range tests {
@@ -277,23 +559,24 @@ func TestHandlers_UpdatePet(t *testing.T) {
 			body := bytes.NewReader(b)
 			req, _ := http.NewRequest("PUT", "/pet", body)
 
-			if tt.goToExist {
-				srvMock.EXPECT().IsExist(tt.req.ID).Return(tt.exist)
+			if tt.goToSev {
+				stored := &model.Pet{Name: "Murka", ID: 1, Species: model.SpeciesCat, Breed: "Siamese", Status: model.StatusReserved}
+				srvMock.EXPECT().GetPet(gomock.Any(), 1).Return(stored, tt.getErr)
 			}
 
-			if tt.goToSev {
-				srvMock.EXPECT().UpdatePet(tt.pet).Return(tt.srvErr)
+			if tt.goUpdate {
+				srvMock.EXPECT().UpdatePet(gomock.Any(), tt.pet).Return(tt.srvErr)
 			}
 
 			updatePet.ServeHTTP(res, req)
 
-			want := httptest.NewRecorder()
-			if tt.wantErr != "" {
-				http.Error(want, tt.wantErr, tt.wantStatus)
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, `</pet/1>; rel="successor-version"`, res.Header().Get("Link"))
+				require.Empty(t, res.Body.String())
 			}
-
-			require.Equal(t, tt.wantStatus, res.Code)
-			require.Equal(t, want.Body.String(), res.Body.String())
 		})
 	}
 }
@@ -307,9 +590,6 @@ func TestHandlers_DeletePet(t *testing.T) {
 		name string
 		req  *requests.DeleteReq
 
-		goToExist bool
-		exist     bool
-
 		goToSev bool
 		srvErr  error
 		pet     *model.Pet
@@ -320,8 +600,6 @@ func TestHandlers_DeletePet(t *testing.T) {
 		{
 			name:       "check 200",
 			req:        &requests.DeleteReq{ID: 1},
-			goToExist:  true,
-			exist:      true,
 			goToSev:    true,
 			pet:        &model.Pet{ID: 1},
 			wantStatus: http.StatusOK,
@@ -338,23 +616,21 @@ func TestHandlers_DeletePet(t *testing.T) {
 			wantErr:    "id should be more than 0",
 		},
 		{
-			name:       "check 400 not exist",
+			name:       "check 404 not exist",
 			req:        &requests.DeleteReq{ID: 1},
-			goToExist:  true,
-			exist:      false,
-			wantStatus: http.StatusBadRequest,
-			wantErr:    "pet does not exist",
+			goToSev:    true,
+			pet:        &model.Pet{ID: 1},
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
 		},
 		{
 			name:       "check 500 db error",
 			req:        &requests.DeleteReq{ID: 1},
-			goToExist:  true,
-			exist:      true,
 			goToSev:    true,
 			pet:        &model.Pet{ID: 1},
 			srvErr:     fmt.Errorf("db error occured"),
 			wantStatus: http.StatusInternalServerError,
-			wantErr:    "db error",
 		},
 	}
 	for _, tt := range tests {
@@ -372,23 +648,789 @@ func TestHandlers_DeletePet(t *testing.T) {
 			body := bytes.NewReader(b)
 			req, _ := http.NewRequest("DELETE", "/pet", body)
 
-			if tt.goToExist {
-				srvMock.EXPECT().IsExist(tt.req.ID).Return(tt.exist)
+			if tt.goToSev {
+				srvMock.EXPECT().DeletePet(gomock.Any(), tt.pet).Return(tt.srvErr)
+			}
+
+			deletePet.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, `</pet/1>; rel="successor-version"`, res.Header().Get("Link"))
+				require.Empty(t, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_GetPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name        string
+		id          string
+		accept      string
+		ifNoneMatch string
+
+		goToSev bool
+		srvID   int
+		srvErr  error
+		pet     *model.Pet
+
+		wantBody   *model.Pet
+		wantETag   string
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			goToSev:    true,
+			srvID:      1,
+			pet:        &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantBody:   &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:   `"3"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "check 200 If-None-Match not matched",
+			id:          "1",
+			ifNoneMatch: `"1", "2"`,
+			goToSev:     true,
+			srvID:       1,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantBody:    &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:    `"3"`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "check 304",
+			id:          "1",
+			ifNoneMatch: `"2", "3"`,
+			goToSev:     true,
+			srvID:       1,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:    `"3"`,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "check 304 weak tag",
+			id:          "1",
+			ifNoneMatch: `W/"3"`,
+			goToSev:     true,
+			srvID:       1,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:    `"3"`,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "check 200 xml not matched json tag",
+			id:          "1",
+			accept:      "application/xml",
+			ifNoneMatch: `"3"`,
+			goToSev:     true,
+			srvID:       1,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:    `"3-xml"`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "check 304 xml",
+			id:          "1",
+			accept:      "application/xml",
+			ifNoneMatch: `"3-xml"`,
+			goToSev:     true,
+			srvID:       1,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:    `"3-xml"`,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "check 304 any",
+			id:          "1",
+			ifNoneMatch: "*",
+			goToSev:     true,
+			srvID:       1,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Version: 3},
+			wantETag:    `"3"`,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:       "check 400 not number id",
+			id:         "velho",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "velho"`,
+		},
+		{
+			name:       "check 400 0 id",
+			id:         "0",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 404 not found",
+			id:         "1",
+			goToSev:    true,
+			srvID:      1,
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+		{
+			name:       "check 500 db error",
+			id:         "1",
+			goToSev:    true,
+			srvID:      1,
+			srvErr:     fmt.Errorf("db error occurred"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getPet := h.GetPet()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/pet/"+tt.id, nil)
+			req = withPathID(req, tt.id)
+
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			req.Header.Set("Accept", tt.accept)
+
+			if tt.goToSev {
+				srvMock.EXPECT().GetPet(gomock.Any(), tt.srvID).Return(tt.pet, tt.srvErr)
+			}
+
+			getPet.ServeHTTP(res, req)
+
+			switch {
+			case tt.wantStatus >= http.StatusBadRequest:
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			case tt.wantStatus == http.StatusNotModified:
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, tt.wantETag, res.Header().Get("ETag"))
+				require.Equal(t, "Accept", res.Header().Get("Vary"))
+				require.Empty(t, res.Body.String())
+			case tt.accept != "":
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, tt.wantETag, res.Header().Get("ETag"))
+				require.Equal(t, tt.accept, res.Header().Get("Content-Type"))
+			default:
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(tt.wantBody)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, tt.wantETag, res.Header().Get("ETag"))
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_UpdatePetByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	birthDate := "01.02.2020"
+	species := string(model.SpeciesDog)
+	status := string(model.StatusAvailable)
+
+	tests := []struct {
+		name    string
+		id      string
+		ifMatch string
+		req     *requests.UpdateByIDReq
+
+		goToSev  bool
+		getErr   error
+		goUpdate bool
+		srvErr   error
+		pet      *model.Pet
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 given species and status",
+			id:         "1",
+			req:        &requests.UpdateByIDReq{Name: "Velho", Species: &species, Status: &status},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Species: model.SpeciesDog, Status: model.StatusAvailable},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 If-Match",
+			id:         "1",
+			ifMatch:    `"2", "3"`,
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved, Version: 3},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 If-Match xml tag",
+			id:         "1",
+			ifMatch:    `"3-xml"`,
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved, Version: 3},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 If-Match any",
+			id:         "1",
+			ifMatch:    "*",
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong id",
+			id:         "-1",
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "-1"`,
+		},
+		{
+			name:       "check 400 wrong birth date",
+			id:         "1",
+			req:        &requests.UpdateByIDReq{Name: "Velho", BirthDate: &birthDate},
+			goToSev:    true,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "should be a date in YYYY-MM-DD format",
+		},
+		{
+			name:       "check 400 no body",
+			id:         "1",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"name":string}`,
+		},
+		{
+			name:       "check 400 blank name",
+			id:         "1",
+			req:        &requests.UpdateByIDReq{Name: ""},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "", ID: 1, Status: model.StatusReserved},
+			srvErr:     service.NewValidationError("invalid pet", service.FieldError{Field: "name", Message: "cannot be blank"}),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid pet",
+		},
+		{
+			name:       "check 404 not exist",
+			id:         "1",
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			getErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+		{
+			name:       "check 412 If-Match not matched",
+			id:         "1",
+			ifMatch:    `"2"`,
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			wantStatus: http.StatusPreconditionFailed,
+			wantErr:    `pet 1 does not match If-Match, current ETag is "3"`,
+		},
+		{
+			name:       "check 412 If-Match weak tag",
+			id:         "1",
+			ifMatch:    `W/"3"`,
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			wantStatus: http.StatusPreconditionFailed,
+			wantErr:    `pet 1 does not match If-Match, current ETag is "3"`,
+		},
+		{
+			name:       "check 412 changed concurrently",
+			id:         "1",
+			ifMatch:    `"3"`,
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved, Version: 3},
+			srvErr:     service.NewPreconditionFailedError("pet 1 was changed, get it again"),
+			wantStatus: http.StatusPreconditionFailed,
+			wantErr:    "pet 1 was changed, get it again",
+		},
+		{
+			name:       "check 500 db error",
+			id:         "1",
+			req:        &requests.UpdateByIDReq{Name: "Velho"},
+			goToSev:    true,
+			goUpdate:   true,
+			pet:        &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved},
+			srvErr:     fmt.Errorf("db error occured"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			updatePet := h.UpdatePetByID()
+
+			res := httptest.NewRecorder()
+			var b []byte
+
+			if tt.req != nil {
+				b, _ = json.Marshal(tt.req)
+			}
+
+			body := bytes.NewReader(b)
+			req, _ := http.NewRequest("PUT", "/pet/"+tt.id, body)
+			req = withPathID(req, tt.id)
+
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			if tt.goToSev {
+				stored := &model.Pet{Name: "Murka", ID: 1, Species: model.SpeciesCat, Breed: "Siamese", Status: model.StatusReserved, Version: 3}
+				srvMock.EXPECT().GetPet(gomock.Any(), 1).Return(stored, tt.getErr)
+			}
+
+			if tt.goUpdate {
+				srvMock.EXPECT().UpdatePet(gomock.Any(), tt.pet).Return(tt.srvErr)
+			}
+
+			updatePet.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Empty(t, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_PatchPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	weight := 4.5
+
+	tests := []struct {
+		name        string
+		id          string
+		contentType string
+		ifMatch     string
+		body        string
+
+		goToSev bool
+		getErr  error
+		goPatch bool
+		srvErr  error
+		pet     *model.Pet
+		fields  []string
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:        "check 200 merge patch",
+			id:          "1",
+			contentType: MergePatchContentType,
+			body:        `{"breed": "Siamese", "weight": null, "color": "white"}`,
+			goToSev:     true,
+			goPatch:     true,
+			pet:         &model.Pet{ID: 1, Name: "Murka", Species: model.SpeciesCat, Breed: "Siamese", Color: "white", Status: model.StatusAvailable},
+			fields:      []string{"breed", "weight"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "check 200 JSON patch",
+			id:          "1",
+			contentType: JSONPatchContentType + "; charset=utf-8",
+			body:        `[{"op": "test", "path": "/name", "value": "Murka"}, {"op": "replace", "path": "/name", "value": "Velho"}]`,
+			goToSev:     true,
+			goPatch:     true,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Species: model.SpeciesCat, Color: "white", Weight: &weight, Status: model.StatusAvailable},
+			fields:      []string{"name"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:       "check 200 JSON body",
+			id:         "1",
+			body:       `{"name": "Velho", "status": "archived"}`,
+			goToSev:    true,
+			goPatch:    true,
+			pet:        &model.Pet{ID: 1, Name: "Velho", Species: model.SpeciesCat, Color: "white", Weight: &weight, Status: model.StatusArchived},
+			fields:     []string{"name"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "check 200 XML body",
+			id:          "1",
+			contentType: "application/xml",
+			body:        `<request><name>Velho</name><weight>4.5</weight></request>`,
+			goToSev:     true,
+			goPatch:     true,
+			pet:         &model.Pet{ID: 1, Name: "Velho", Species: model.SpeciesCat, Color: "white", Weight: &weight, Status: model.StatusAvailable},
+			fields:      []string{"name"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "check 200 If-Match",
+			id:          "1",
+			contentType: MergePatchContentType,
+			ifMatch:     `"3"`,
+			body:        `{"color": "black"}`,
+			goToSev:     true,
+			goPatch:     true,
+			pet:         &model.Pet{ID: 1, Name: "Murka", Species: model.SpeciesCat, Color: "black", Weight: &weight, Status: model.StatusAvailable, Version: 3},
+			fields:      []string{"color"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "check 400 no body",
+			id:          "1",
+			contentType: MergePatchContentType,
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     "provide patch body",
+		},
+		{
+			name:       "check 400 no JSON body",
+			id:         "1",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"name":string}`,
+		},
+		{
+			name:        "check 400 read-only field",
+			id:          "1",
+			contentType: MergePatchContentType,
+			body:        `{"id": 2}`,
+			goToSev:     true,
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     `patched pet is invalid: json: unknown field "id"`,
+		},
+		{
+			name:        "check 400 wrong type",
+			id:          "1",
+			contentType: MergePatchContentType,
+			body:        `{"neutered": "yes"}`,
+			goToSev:     true,
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     "patched pet is invalid: json: cannot unmarshal string into Go struct field AddPetReq.neutered of type bool",
+		},
+		{
+			name:        "check 400 wrong birth date",
+			id:          "1",
+			contentType: MergePatchContentType,
+			body:        `{"birth_date": "01.02.2020"}`,
+			goToSev:     true,
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     "should be a date in YYYY-MM-DD format",
+		},
+		{
+			name:        "check 400 malformed JSON patch",
+			id:          "1",
+			contentType: JSONPatchContentType,
+			body:        `{"op": "replace"}`,
+			goToSev:     true,
+			wantStatus:  http.StatusBadRequest,
+			wantErr:     "invalid patch: json: cannot unmarshal object into Go value of type jsonpatch.Patch",
+		},
+		{
+			name:        "check 404 not exist",
+			id:          "1",
+			contentType: MergePatchContentType,
+			body:        `{"color": "black"}`,
+			goToSev:     true,
+			getErr:      service.NewNotFoundError("pet 1 not found"),
+			wantStatus:  http.StatusNotFound,
+			wantErr:     "pet 1 not found",
+		},
+		{
+			name:        "check 409 JSON patch test failed",
+			id:          "1",
+			contentType: JSONPatchContentType,
+			body:        `[{"op": "test", "path": "/name", "value": "Velho"}]`,
+			goToSev:     true,
+			wantStatus:  http.StatusConflict,
+			wantErr:     "patch cannot be applied: testing value /name failed: test failed",
+		},
+		{
+			name:        "check 412 If-Match not matched",
+			id:          "1",
+			contentType: MergePatchContentType,
+			ifMatch:     `"2"`,
+			body:        `{"color": "black"}`,
+			goToSev:     true,
+			wantStatus:  http.StatusPreconditionFailed,
+			wantErr:     `pet 1 does not match If-Match, current ETag is "3"`,
+		},
+		{
+			name:        "check 415 unsupported content type",
+			id:          "1",
+			contentType: "text/plain",
+			body:        "name=Velho",
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantErr: `content type "text/plain" is not supported, use application/json, application/xml, application/msgpack, ` +
+				`application/merge-patch+json, application/json-patch+json`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			patchPet := h.PatchPet()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", "/pet/"+tt.id, bytes.NewReader([]byte(tt.body)))
+			req = withPathID(req, tt.id)
+
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			if tt.goToSev {
+				stored := &model.Pet{ID: 1, Name: "Murka", Species: model.SpeciesCat, Color: "white", Weight: &weight,
+					Status: model.StatusAvailable, Version: 3}
+				srvMock.EXPECT().GetPet(gomock.Any(), 1).Return(stored, tt.getErr)
+			}
+
+			if tt.goPatch {
+				srvMock.EXPECT().PatchPet(gomock.Any(), tt.pet, tt.fields).Return(tt.srvErr)
+			}
+
+			patchPet.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Empty(t, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_DeletePetByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		id      string
+		ifMatch string
+
+		goGet   bool
+		goToSev bool
+		srvErr  error
+		pet     *model.Pet
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			goToSev:    true,
+			pet:        &model.Pet{ID: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 If-Match",
+			id:         "1",
+			ifMatch:    `"3"`,
+			goGet:      true,
+			goToSev:    true,
+			pet:        &model.Pet{ID: 1, Version: 3},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 0 id",
+			id:         "0",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 404 not exist",
+			id:         "1",
+			goToSev:    true,
+			pet:        &model.Pet{ID: 1},
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+		{
+			name:       "check 412 If-Match not matched",
+			id:         "1",
+			ifMatch:    `"2"`,
+			goGet:      true,
+			wantStatus: http.StatusPreconditionFailed,
+			wantErr:    `pet 1 does not match If-Match, current ETag is "3"`,
+		},
+		{
+			name:       "check 500 db error",
+			id:         "1",
+			goToSev:    true,
+			pet:        &model.Pet{ID: 1},
+			srvErr:     fmt.Errorf("db error occured"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			deletePet := h.DeletePetByID()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/pet/"+tt.id, nil)
+			req = withPathID(req, tt.id)
+
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			if tt.goGet {
+				srvMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1, Version: 3}, nil)
 			}
 
 			if tt.goToSev {
-				srvMock.EXPECT().DeletePet(tt.pet).Return(tt.srvErr)
+				srvMock.EXPECT().DeletePet(gomock.Any(), tt.pet).Return(tt.srvErr)
 			}
 
 			deletePet.ServeHTTP(res, req)
 
-			want := httptest.NewRecorder()
-			if tt.wantErr != "" {
-				http.Error(want, tt.wantErr, tt.wantStatus)
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Empty(t, res.Body.String())
 			}
+		})
+	}
+}
+
+func TestHandlers_RestorePet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	pet := &model.Pet{ID: 1, Name: "Velho", Tags: []string{}}
+
+	tests := []struct {
+		name string
+		id   string
 
-			require.Equal(t, tt.wantStatus, res.Code)
-			require.Equal(t, want.Body.String(), res.Body.String())
+		goToSev bool
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			goToSev:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 0 id",
+			id:         "0",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 404 not exist",
+			id:         "1",
+			goToSev:    true,
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+		{
+			name:       "check 409 not deleted",
+			id:         "1",
+			goToSev:    true,
+			srvErr:     service.NewConflictError("pet 1 is not deleted"),
+			wantStatus: http.StatusConflict,
+			wantErr:    "pet 1 is not deleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			restorePet := h.RestorePet()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/pet/"+tt.id+"/restore", nil)
+			req = withPathID(req, tt.id)
+
+			if tt.goToSev {
+				var restored *model.Pet
+				if tt.srvErr == nil {
+					restored = pet
+				}
+
+				srvMock.EXPECT().RestorePet(gomock.Any(), 1).Return(restored, tt.srvErr)
+			}
+
+			restorePet.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(pet)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
 		})
 	}
 }
+
+// withPathID is used to set {id} chi route param to given request
+func withPathID(req *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// requireProblem is used to check that given response is a responses.Problem with given status and detail
+func requireProblem(t *testing.T, res *httptest.ResponseRecorder, status int, detail string) {
+	require.Equal(t, status, res.Code)
+	require.Equal(t, ProblemContentType, res.Header().Get("Content-Type"))
+
+	problem := &responses.Problem{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(problem))
+
+	require.Equal(t, status, problem.Status)
+	require.Equal(t, detail, problem.Detail)
+	require.Equal(t, "urn:pets:problem:"+problem.Code, problem.Type)
+	require.NotEmpty(t, problem.Title)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pets/internal/model"
+)
+
+// getPetsQuery is used to get model.PetsQuery from GET /pet query params. Not convertable limit and offset are ignored
+// as before, other invalid params will return service.ErrValidation kind error
+func getPetsQuery(request *http.Request) (*model.PetsQuery, error) {
+	values := request.URL.Query()
+
+	q := &model.PetsQuery{
+		Limit:  queryInt(request, "limit"),
+		Offset: queryInt(request, "offset"),
+	}
+
+	if q.Limit < 0 {
+		q.Limit = 0
+	}
+
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	sort, order := values.Get("sort"), values.Get("order")
+	if sort != "" && order != "" {
+		return nil, invalidParam("sort", "sort and order cannot be used together")
+	}
+
+	var err error
+
+	q.Sort, err = model.ParseSort(sort)
+	if err != nil {
+		return nil, invalidParam("sort", err.Error())
+	}
+
+	// order is a legacy way to sort pets by ID
+	if strings.ToLower(order) == "desc" {
+		q.Sort = []model.SortField{{Field: model.SortID, Desc: true}}
+	}
+
+	q.Filter.Name = values.Get("name")
+
+	switch match := model.NameMatch(values.Get("name_match")); match {
+	case "", model.NameExact, model.NamePrefix, model.NameContains:
+		q.Filter.NameMatch = match
+	default:
+		return nil, invalidParam("name_match", fmt.Sprintf("unknown name_match %q", match))
+	}
+
+	if q.Filter.CreatedAfter, err = queryTime(request, "created_after"); err != nil {
+		return nil, err
+	}
+
+	if q.Filter.CreatedBefore, err = queryTime(request, "created_before"); err != nil {
+		return nil, err
+	}
+
+	if q.Filter.UpdatedSince, err = queryTime(request, "updated_since"); err != nil {
+		return nil, err
+	}
+
+	for _, v := range values["id"] {
+		for _, s := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || id < 1 {
+				return nil, invalidParam("id", fmt.Sprintf("invalid id %q", s))
+			}
+
+			q.Filter.IDs = append(q.Filter.IDs, id)
+		}
+	}
+
+	for _, s := range queryList(request, "species") {
+		if !model.Species(s).Valid() {
+			return nil, invalidParam("species", fmt.Sprintf("unknown species %q", s))
+		}
+
+		q.Filter.Species = append(q.Filter.Species, model.Species(s))
+	}
+
+	for _, s := range queryList(request, "status") {
+		if !model.Status(s).Valid() {
+			return nil, invalidParam("status", fmt.Sprintf("unknown status %q", s))
+		}
+
+		q.Filter.Statuses = append(q.Filter.Statuses, model.Status(s))
+	}
+
+	if sex := model.Sex(values.Get("sex")); sex != "" {
+		if !sex.Valid() {
+			return nil, invalidParam("sex", fmt.Sprintf("unknown sex %q", sex))
+		}
+
+		q.Filter.Sex = sex
+	}
+
+	if v := values.Get("neutered"); v != "" {
+		neutered, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, invalidParam("neutered", "invalid neutered: should be true or false")
+		}
+
+		q.Filter.Neutered = &neutered
+	}
+
+	if v := values.Get("include_deleted"); v != "" {
+		if q.Filter.IncludeDeleted, err = strconv.ParseBool(v); err != nil {
+			return nil, invalidParam("include_deleted", "invalid include_deleted: should be true or false")
+		}
+	}
+
+	q.Filter.Breed = values.Get("breed")
+	q.Filter.Color = values.Get("color")
+	q.Filter.Description = values.Get("description")
+
+	if q.Filter.BornAfter, err = queryDate(request, "born_after"); err != nil {
+		return nil, err
+	}
+
+	if q.Filter.BornBefore, err = queryDate(request, "born_before"); err != nil {
+		return nil, err
+	}
+
+	if q.Filter.MinWeight, err = queryFloat(request, "weight_min"); err != nil {
+		return nil, err
+	}
+
+	if q.Filter.MaxWeight, err = queryFloat(request, "weight_max"); err != nil {
+		return nil, err
+	}
+
+	q.Filter.Tags = queryList(request, "tag")
+
+	switch match := model.TagMatch(values.Get("tag_match")); match {
+	case "", model.TagAny, model.TagAll:
+		q.Filter.TagMatch = match
+	default:
+		return nil, invalidParam("tag_match", fmt.Sprintf("unknown tag_match %q", match))
+	}
+
+	return q, nil
+}
+
+// queryList is used to get list query param by given key. Param can be repeated or comma-separated
+func queryList(request *http.Request, key string) []string {
+	var res []string
+
+	for _, v := range request.URL.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+	}
+
+	return res
+}
+
+// queryDate is used to get YYYY-MM-DD date query param by given key. Will return nil if param is blank
+func queryDate(request *http.Request, key string) (*model.Date, error) {
+	v := request.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	d, err := model.ParseDate(v)
+	if err != nil {
+		return nil, invalidParam(key, fmt.Sprintf("invalid %v: %v", key, err.Error()))
+	}
+
+	return &d, nil
+}
+
+// queryFloat is used to get number query param by given key. Will return nil if param is blank
+func queryFloat(request *http.Request, key string) (*float64, error) {
+	v := request.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, invalidParam(key, fmt.Sprintf("invalid %v: should be a number", key))
+	}
+
+	return &f, nil
+}
+
+// queryTime is used to get RFC 3339 time query param by given key. Will return nil if param is blank
+func queryTime(request *http.Request, key string) (*time.Time, error) {
+	v := request.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, invalidParam(key, fmt.Sprintf("invalid %v: should be RFC 3339 time", key))
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/pkg/logger"
+)
+
+// photoField is a multipart form field name of uploaded photo
+const photoField = "photo"
+
+// GetPhotos is a handler func for GET /pet/{id}/photos route
+// Will return pet photos in responses.GetPhotosResp format, oldest first
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetPhotos() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetPhotos").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetPhotos(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Photo{}
+		}
+
+		render(writer, request, http.StatusOK, &responses.GetPhotosResp{Photos: res})
+	}
+}
+
+// CreatePhoto is a handler func for POST /pet/{id}/photos route. Photo is uploaded as "photo" field of
+// multipart/form-data body and streamed to the service without buffering the whole form. Query param "primary=true"
+// makes the photo the pet primary photo, the first photo of the pet is primary anyway
+// Will return created photo in model.Photo format
+// Will return 400 status if body is not a multipart form with "photo" field, photo is corrupted or ID in path is
+// invalid
+// Will return 404 status if pet not found
+// Will return 413 status if photo exceeds the size limit
+// Will return 415 status if photo is not a JPEG, PNG or GIF image
+// Can return 503 if DB or blob store is unavailable or 500 if unexpected error occurred
+func (h *Handlers) CreatePhoto() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreatePhoto").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		var primary bool
+		if v := request.URL.Query().Get("primary"); v != "" {
+			if primary, err = strconv.ParseBool(v); err != nil {
+				writeError(writer, request, invalidParam("primary", "invalid primary: should be true or false"))
+				return
+			}
+		}
+
+		part, err := getPhotoPart(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-CreatePhoto").Warningf("wrong body: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+		defer part.Close()
+
+		res, err := h.srv.AddPhoto(request.Context(), id, part, primary)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.Header().Set("Location", res.URL)
+		render(writer, request, http.StatusCreated, res)
+	}
+}
+
+// GetPhoto is a handler func for GET /pet/{id}/photos/{photo_id} route
+// Will return photo content with its sniffed content type
+// Will return 400 status if IDs in path are not numbers or less than 0
+// Will return 404 status if photo of the pet not found
+// Can return 503 if DB or blob store is unavailable or 500 if unexpected error occurred
+func (h *Handlers) GetPhoto() http.HandlerFunc {
+	return h.servePhoto("Handlers-GetPhoto", false)
+}
+
+// GetPhotoThumbnail is a handler func for GET /pet/{id}/photos/{photo_id}/thumbnail route
+// Will return photo thumbnail content as image/jpeg
+// Will return 400 status if IDs in path are not numbers or less than 0
+// Will return 404 status if photo of the pet not found
+// Can return 503 if DB or blob store is unavailable or 500 if unexpected error occurred
+func (h *Handlers) GetPhotoThumbnail() http.HandlerFunc {
+	return h.servePhoto("Handlers-GetPhotoThumbnail", true)
+}
+
+// DeletePhoto is a handler func for DELETE /pet/{id}/photos/{photo_id} route
+// Will return 200 if request is successful
+// Will return 400 status if IDs in path are less than 0
+// Will return 404 status if photo of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeletePhoto() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getPhotoPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeletePhoto").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.srv.DeletePhoto(request.Context(), petID, id); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// SetPrimaryPhoto is a handler func for PUT /pet/{id}/photos/{photo_id}/primary route
+// Will return 200 if request is successful
+// Will return 400 status if IDs in path are less than 0
+// Will return 404 status if photo of the pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) SetPrimaryPhoto() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getPhotoPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-SetPrimaryPhoto").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.srv.SetPrimaryPhoto(request.Context(), petID, id); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// servePhoto is used to get handler func writing content of the photo from the route path or its thumbnail
+func (h *Handlers) servePhoto(layer string, thumbnail bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		petID, id, err := getPhotoPath(request)
+		if err != nil {
+			logger.Log().WithField("layer", layer).Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		rc, contentType, err := h.srv.OpenPhoto(request.Context(), petID, id, thumbnail)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+		defer rc.Close()
+
+		writer.Header().Set("Content-Type", contentType)
+		writer.Header().Set("X-Content-Type-Options", "nosniff")
+		writer.Header().Set("Cache-Control", "private, max-age=86400")
+		writer.WriteHeader(http.StatusOK)
+
+		if _, err = io.Copy(writer, rc); err != nil {
+			logger.Log().WithField("layer", layer).Errorf("error write photo %v", err.Error())
+		}
+	}
+}
+
+// getPhotoPath is used to get pet ID and photo ID from the {id} and {photo_id} route params
+func getPhotoPath(request *http.Request) (petID int, id int, err error) {
+	if petID, err = getPathID(request); err != nil {
+		return 0, 0, err
+	}
+
+	if id, err = getPathInt(request, "photo_id"); err != nil {
+		return 0, 0, err
+	}
+
+	return petID, id, nil
+}
+
+// getPhotoPart is used to get "photo" part of multipart/form-data request body. Parts before it are skipped
+func getPhotoPart(request *http.Request) (*multipart.Part, error) {
+	mr, err := request.MultipartReader()
+	if err != nil {
+		return nil, invalidParam("body", `provide multipart/form-data body with "photo" file field`)
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, invalidParam(photoField, `provide multipart/form-data body with "photo" file field`)
+		}
+
+		if err != nil {
+			return nil, invalidParam("body", "invalid multipart body: "+err.Error())
+		}
+
+		if part.FormName() == photoField {
+			return part, nil
+		}
+
+		part.Close()
+	}
+}
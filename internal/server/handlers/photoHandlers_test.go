@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_CreatePhoto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	photo := &model.Photo{ID: 2, PetID: 1, ContentType: "image/png", URL: "/api/v1/pet/1/photos/2"}
+
+	tests := []struct {
+		name  string
+		url   string
+		field string
+		body  *bytes.Buffer
+
+		goToSev bool
+		primary bool
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 201",
+			url:        "/pet/1/photos",
+			field:      "photo",
+			goToSev:    true,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 201 primary",
+			url:        "/pet/1/photos?primary=true",
+			field:      "photo",
+			goToSev:    true,
+			primary:    true,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 400 wrong primary",
+			url:        "/pet/1/photos?primary=maybe",
+			field:      "photo",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid primary: should be true or false",
+		},
+		{
+			name:       "check 400 not multipart",
+			url:        "/pet/1/photos",
+			body:       bytes.NewBufferString(`{"photo":"data"}`),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide multipart/form-data body with "photo" file field`,
+		},
+		{
+			name:       "check 400 no photo field",
+			url:        "/pet/1/photos",
+			field:      "image",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide multipart/form-data body with "photo" file field`,
+		},
+		{
+			name:       "check 415",
+			url:        "/pet/1/photos",
+			field:      "photo",
+			goToSev:    true,
+			srvErr:     service.NewUnsupportedMediaError("photo should be JPEG, PNG or GIF image, got text/plain; charset=utf-8"),
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantErr:    "photo should be JPEG, PNG or GIF image, got text/plain; charset=utf-8",
+		},
+		{
+			name:       "check 413",
+			url:        "/pet/1/photos",
+			field:      "photo",
+			goToSev:    true,
+			srvErr:     service.NewTooLargeError("photo cannot be larger than 10485760 bytes"),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErr:    "photo cannot be larger than 10485760 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			createPhoto := h.CreatePhoto()
+
+			body, contentType := tt.body, "application/json"
+			if body == nil {
+				body, contentType = multipartBody(t, tt.field, "photo content")
+			}
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.url, body)
+			req.Header.Set("Content-Type", contentType)
+			req = withPathID(req, "1")
+
+			if tt.goToSev {
+				srvMock.EXPECT().AddPhoto(gomock.Any(), 1, gomock.Any(), tt.primary).DoAndReturn(
+					func(_ context.Context, _ int, r io.Reader, _ bool) (*model.Photo, error) {
+						data, err := io.ReadAll(r)
+						require.NoError(t, err)
+						require.Equal(t, "photo content", string(data))
+
+						if tt.srvErr != nil {
+							return nil, tt.srvErr
+						}
+
+						return photo, nil
+					})
+			}
+
+			createPhoto.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(photo)
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, photo.URL, res.Header().Get("Location"))
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_GetPhotoThumbnail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		photoID string
+
+		goToSev bool
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			photoID:    "2",
+			goToSev:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 wrong photo id",
+			photoID:    "0",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `photo_id should be a number more than 0, got "0"`,
+		},
+		{
+			name:       "check 404 not found",
+			photoID:    "2",
+			goToSev:    true,
+			srvErr:     service.NewNotFoundError("photo 2 of pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "photo 2 of pet 1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			getThumbnail := h.GetPhotoThumbnail()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/pet/1/photos/"+tt.photoID+"/thumbnail", nil)
+			req = withPhotoPath(req, "1", tt.photoID)
+
+			if tt.goToSev {
+				var rc io.ReadCloser
+				if tt.srvErr == nil {
+					rc = io.NopCloser(bytes.NewBufferString("thumbnail"))
+				}
+
+				srvMock.EXPECT().OpenPhoto(gomock.Any(), 1, 2, true).Return(rc, "image/jpeg", tt.srvErr)
+			}
+
+			getThumbnail.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, "image/jpeg", res.Header().Get("Content-Type"))
+				require.Equal(t, "thumbnail", res.Body.String())
+			}
+		})
+	}
+}
+
+// multipartBody is used to get multipart/form-data body with a single file field and its content type
+func multipartBody(t *testing.T, field string, content string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	part, err := w.CreateFormFile(field, "photo.png")
+	require.NoError(t, err)
+
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return body, w.FormDataContentType()
+}
+
+// withPhotoPath is used to set {id} and {photo_id} chi route params to given request
+func withPhotoPath(req *http.Request, id string, photoID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	rctx.URLParams.Add("photo_id", photoID)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
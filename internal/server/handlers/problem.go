@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	"pets/pkg/logger"
+)
+
+// ProblemContentType is a content type of responses.Problem responses
+const ProblemContentType = "application/problem+json"
+
+// Problem codes returned in responses.Problem Code field
+const (
+	CodeNotFound         = "not_found"
+	CodeValidation       = "validation_failed"
+	CodeConflict         = "conflict"
+	CodeUnavailable      = "unavailable"
+	CodeTooLarge         = "too_large"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeNotAcceptable    = "not_acceptable"
+	CodePrecondition     = "precondition_failed"
+	CodeNotApplied       = "not_applied"
+	CodeInternal         = "internal_error"
+)
+
+// problemType is a responses.Problem type of a service error kind
+type problemType struct {
+	kind   error
+	status int
+	code   string
+	title  string
+}
+
+// problemTypes is a list of service error kinds mapped to responses.Problem types
+var problemTypes = []problemType{
+	{kind: service.ErrNotFound, status: http.StatusNotFound, code: CodeNotFound, title: "Resource not found"},
+	{kind: service.ErrValidation, status: http.StatusBadRequest, code: CodeValidation, title: "Request is invalid"},
+	{kind: service.ErrConflict, status: http.StatusConflict, code: CodeConflict, title: "Request conflicts with resource state"},
+	{kind: service.ErrUnavailable, status: http.StatusServiceUnavailable, code: CodeUnavailable, title: "Service is unavailable"},
+	{kind: service.ErrTooLarge, status: http.StatusRequestEntityTooLarge, code: CodeTooLarge, title: "Request content is too large"},
+	{kind: service.ErrUnsupportedMedia, status: http.StatusUnsupportedMediaType, code: CodeUnsupportedMedia, title: "Request content type is not supported"},
+	{kind: service.ErrNotAcceptable, status: http.StatusNotAcceptable, code: CodeNotAcceptable, title: "Response content type is not acceptable"},
+	{kind: service.ErrPreconditionFailed, status: http.StatusPreconditionFailed, code: CodePrecondition, title: "Resource version does not match"},
+	{kind: service.ErrNotApplied, status: http.StatusFailedDependency, code: CodeNotApplied, title: "Batch item was not applied"},
+}
+
+// internalProblem is a responses.Problem type of all not typed errors
+var internalProblem = problemType{status: http.StatusInternalServerError, code: CodeInternal, title: "Internal server error"}
+
+// writeError is used to write given error as responses.Problem. service.Error is mapped by its kind, all other errors
+// are written as internal error without details
+func writeError(writer http.ResponseWriter, request *http.Request, err error) {
+	problem := newProblem(request, err)
+
+	writeProblem(writer, problem.Status, problem)
+}
+
+// writeProblem is used to write given responses.Problem or a struct embedding it with given status
+func writeProblem(writer http.ResponseWriter, status int, problem interface{}) {
+	writer.Header().Set("Content-Type", ProblemContentType)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.WriteHeader(status)
+
+	if err := json.NewEncoder(writer).Encode(problem); err != nil {
+		logger.Log().WithField("layer", "Handlers-Problem").Errorf("error encode problem %v", err.Error())
+	}
+}
+
+// newProblem is used to get responses.Problem of given error as writeError writes it. Internal errors are logged
+func newProblem(request *http.Request, err error) *responses.Problem {
+	pt := internalProblem
+
+	for _, t := range problemTypes {
+		if errors.Is(err, t.kind) {
+			pt = t
+			break
+		}
+	}
+
+	problem := &responses.Problem{
+		Type:     "urn:pets:problem:" + pt.code,
+		Title:    pt.title,
+		Status:   pt.status,
+		Instance: request.URL.Path,
+		Code:     pt.code,
+	}
+
+	var e *service.Error
+	if pt.status != http.StatusInternalServerError && errors.As(err, &e) {
+		problem.Detail = e.Detail
+
+		for _, f := range e.Fields {
+			problem.InvalidParams = append(problem.InvalidParams, responses.InvalidParam{Name: f.Field, Reason: f.Message})
+		}
+	}
+
+	if pt.status >= http.StatusInternalServerError {
+		logger.Log().WithField("layer", "Handlers-Problem").Errorf("%v %v: %v", request.Method, request.URL.Path, err.Error())
+	}
+
+	return problem
+}
+
+// invalidParam is used to get service.ErrValidation kind error for a single invalid request param
+func invalidParam(name string, reason string) error {
+	return service.NewValidationError(reason, service.FieldError{Field: name, Message: reason})
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+
+		wantProblem *responses.Problem
+	}{
+		{
+			name: "check not found",
+			err:  service.NewNotFoundError("pet 1 not found"),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:not_found",
+				Title:    "Resource not found",
+				Status:   http.StatusNotFound,
+				Detail:   "pet 1 not found",
+				Instance: "/api/v1/pet/1",
+				Code:     CodeNotFound,
+			},
+		},
+		{
+			name: "check validation with fields",
+			err: service.NewValidationError("invalid pet",
+				service.FieldError{Field: "name", Message: "cannot be blank"},
+				service.FieldError{Field: "weight", Message: "should be positive"}),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:validation_failed",
+				Title:    "Request is invalid",
+				Status:   http.StatusBadRequest,
+				Detail:   "invalid pet",
+				Instance: "/api/v1/pet/1",
+				Code:     CodeValidation,
+				InvalidParams: []responses.InvalidParam{
+					{Name: "name", Reason: "cannot be blank"},
+					{Name: "weight", Reason: "should be positive"},
+				},
+			},
+		},
+		{
+			name: "check wrapped conflict",
+			err:  fmt.Errorf("update: %w", service.NewConflictError("pet was changed")),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:conflict",
+				Title:    "Request conflicts with resource state",
+				Status:   http.StatusConflict,
+				Detail:   "pet was changed",
+				Instance: "/api/v1/pet/1",
+				Code:     CodeConflict,
+			},
+		},
+		{
+			name: "check unavailable hides cause",
+			err:  service.NewUnavailableError(context.DeadlineExceeded),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:unavailable",
+				Title:    "Service is unavailable",
+				Status:   http.StatusServiceUnavailable,
+				Detail:   "storage is unavailable, try again later",
+				Instance: "/api/v1/pet/1",
+				Code:     CodeUnavailable,
+			},
+		},
+		{
+			name: "check too large",
+			err:  service.NewTooLargeError("photo cannot be larger than 1024 bytes"),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:too_large",
+				Title:    "Request content is too large",
+				Status:   http.StatusRequestEntityTooLarge,
+				Detail:   "photo cannot be larger than 1024 bytes",
+				Instance: "/api/v1/pet/1",
+				Code:     CodeTooLarge,
+			},
+		},
+		{
+			name: "check precondition failed",
+			err:  service.NewPreconditionFailedError("pet 1 was changed, get it again"),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:precondition_failed",
+				Title:    "Resource version does not match",
+				Status:   http.StatusPreconditionFailed,
+				Detail:   "pet 1 was changed, get it again",
+				Instance: "/api/v1/pet/1",
+				Code:     CodePrecondition,
+			},
+		},
+		{
+			name: "check not applied",
+			err:  service.NewNotAppliedError(),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:not_applied",
+				Title:    "Batch item was not applied",
+				Status:   http.StatusFailedDependency,
+				Detail:   "not applied because other batch item failed",
+				Instance: "/api/v1/pet/1",
+				Code:     CodeNotApplied,
+			},
+		},
+		{
+			name: "check not acceptable",
+			err:  service.NewNotAcceptableError(`content type "text/html" is not acceptable, use application/json`),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:not_acceptable",
+				Title:    "Response content type is not acceptable",
+				Status:   http.StatusNotAcceptable,
+				Detail:   `content type "text/html" is not acceptable, use application/json`,
+				Instance: "/api/v1/pet/1",
+				Code:     CodeNotAcceptable,
+			},
+		},
+		{
+			name: "check internal hides error",
+			err:  fmt.Errorf("pq: relation pets does not exist"),
+			wantProblem: &responses.Problem{
+				Type:     "urn:pets:problem:internal_error",
+				Title:    "Internal server error",
+				Status:   http.StatusInternalServerError,
+				Instance: "/api/v1/pet/1",
+				Code:     CodeInternal,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/pet/1?limit=1", nil)
+
+			writeError(res, req, tt.err)
+
+			require.Equal(t, tt.wantProblem.Status, res.Code)
+			require.Equal(t, ProblemContentType, res.Header().Get("Content-Type"))
+
+			problem := &responses.Problem{}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(problem))
+			require.Equal(t, tt.wantProblem, problem)
+		})
+	}
+}
@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"pets/internal/service"
+	"pets/pkg/logger"
+)
+
+// Content types of responses and request bodies besides JSONContentType and CSVContentType
+const (
+	// XMLContentType is a content type of XML responses and request bodies
+	XMLContentType = "application/xml"
+	// MsgpackContentType is a content type of MessagePack responses and request bodies
+	MsgpackContentType = "application/msgpack"
+)
+
+// Element names of XML responses and request bodies
+const (
+	// xmlRoot is a name of the root element of XML responses
+	xmlRoot = "response"
+	// xmlItem is a name of list item elements
+	xmlItem = "item"
+)
+
+// codec is an encoder and a decoder of a content type. Values are encoded with their JSON field names and values in all
+// content types, so the content types are interchangeable
+type codec struct {
+	// contentType is a content type of encoded responses
+	contentType string
+	// aliases are other media types of the content type accepted in Accept and Content-Type headers
+	aliases []string
+	// listOnly is true if only list responses can be encoded, see listResponse
+	listOnly bool
+	// encode is used to write given value encoded
+	encode func(w io.Writer, v interface{}) error
+	// decode is used to read a value from given reader into v. Nil if request bodies cannot be of the content type
+	decode func(r io.Reader, v interface{}) error
+}
+
+// codecs are codecs of responses and request bodies in order of preference. The first one is used if the client
+// accepts any content type
+var codecs = []*codec{
+	{contentType: JSONContentType, encode: encodeJSON, decode: decodeJSON},
+	{contentType: XMLContentType, aliases: []string{"text/xml"}, encode: encodeXML, decode: decodeXML},
+	{contentType: MsgpackContentType, aliases: []string{"application/x-msgpack", "application/vnd.msgpack"},
+		encode: encodeMsgpack, decode: decodeMsgpack},
+	{contentType: CSVContentType, listOnly: true, encode: encodeCSV},
+}
+
+// listResponse is a response with a list of items. List responses can be encoded as CSV with a row per item, other
+// response fields are not encoded
+type listResponse interface {
+	// Items is used to get a slice of the listed items
+	Items() interface{}
+}
+
+// matches is used to check if given media range of Accept header matches the codec content type or its aliases
+func (c *codec) matches(mediaRange string) bool {
+	if mediaRange == "*/*" {
+		return true
+	}
+
+	for _, t := range append([]string{c.contentType}, c.aliases...) {
+		if t == mediaRange || strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(t, strings.TrimSuffix(mediaRange, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Negotiate is a middleware used to return 406 status before the route handler is called if Accept header has no
+// content type any response can be rendered in. Routes writing files or photos are not wrapped with it
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if _, err := negotiate(request, true); err != nil {
+			logger.Log().WithField("layer", "Handlers-Negotiate").Warningf("not acceptable: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// render is used to write given response with given status in the content type negotiated by Accept header. The
+// response is written as JSON if Accept header is blank. Will write 406 status if no accepted content type can encode
+// the response
+func render(writer http.ResponseWriter, request *http.Request, status int, v interface{}) {
+	_, list := v.(listResponse)
+
+	c, err := negotiate(request, list)
+	if err != nil {
+		logger.Log().WithField("layer", "Handlers-Render").Warningf("not acceptable: %v", err.Error())
+		writeError(writer, request, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", c.contentType)
+	writer.Header().Add("Vary", "Accept")
+	writer.WriteHeader(status)
+
+	if err = c.encode(writer, v); err != nil {
+		logger.Log().WithField("layer", "Handlers-Render").Errorf("error encode resp of %v %v: %v", request.Method,
+			request.URL.Path, err.Error())
+	}
+}
+
+// mediaRange is a media range of Accept header with its quality
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate is used to get a codec of the most preferred content type accepted by Accept header. Codecs of list only
+// content types are used if list is set. Media ranges of equal quality are preferred in the header order, JSON codec is
+// returned for blank Accept header. Will return service.ErrNotAcceptable kind error if no codec is accepted
+func negotiate(request *http.Request, list bool) (*codec, error) {
+	header := request.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return codecs[0], nil
+	}
+
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	// content types explicitly refused with zero quality are not used even if a wildcard range matches them
+	refused := func(c *codec) bool {
+		for _, r := range ranges {
+			if r.q == 0 && r.mediaType != "*/*" && !strings.HasSuffix(r.mediaType, "/*") && c.matches(r.mediaType) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var supported []string
+
+	for _, c := range codecs {
+		if list || !c.listOnly {
+			supported = append(supported, c.contentType)
+		}
+	}
+
+	for _, r := range ranges {
+		if r.q <= 0 {
+			continue
+		}
+
+		for _, c := range codecs {
+			if (list || !c.listOnly) && c.matches(r.mediaType) && !refused(c) {
+				return c, nil
+			}
+		}
+	}
+
+	return nil, service.NewNotAcceptableError(fmt.Sprintf("content type %q is not acceptable, use %v", header,
+		strings.Join(supported, ", ")))
+}
+
+// decode is used to decode request body into given request form by Content-Type header. Blank Content-Type is treated
+// as JSONContentType. Will return service.ErrUnsupportedMedia kind error if content type is not supported, io.EOF if
+// body is empty
+func decode(request *http.Request, v interface{}) error {
+	header := request.Header.Get("Content-Type")
+	if header == "" {
+		return decodeJSON(request.Body, v)
+	}
+
+	var supported []string
+
+	mediaType, _, err := mime.ParseMediaType(header)
+
+	for _, c := range codecs {
+		if c.decode == nil {
+			continue
+		}
+
+		if err == nil && mediaType != "*/*" && !strings.HasSuffix(mediaType, "/*") && c.matches(mediaType) {
+			return c.decode(request.Body, v)
+		}
+
+		supported = append(supported, c.contentType)
+	}
+
+	return service.NewUnsupportedMediaError(fmt.Sprintf("content type %q is not supported, use %v", header,
+		strings.Join(supported, ", ")))
+}
+
+// bodyError is used to get an error of request body decoding to write. service.ErrUnsupportedMedia kind error is
+// returned as is, other errors are converted to service.ErrValidation kind error of the body with given hint
+func bodyError(err error, hint string) error {
+	if errors.Is(err, service.ErrUnsupportedMedia) {
+		return err
+	}
+
+	return invalidParam("body", hint)
+}
+
+// encodeJSON is used to write given value as JSON
+func encodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// decodeJSON is used to read JSON value from given reader into v
+func decodeJSON(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// encodeXML is used to write given value as XML converted from its JSON. Objects are written as elements named by
+// their fields under the xmlRoot element, list values as xmlItem elements, null values are omitted
+func encodeXML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+
+	if err = writeXML(enc, dec, xmlRoot); err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
+
+// writeXML is used to write the next JSON value of given decoder as XML element with given name
+func writeXML(enc *xml.Encoder, dec *json.Decoder, name string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch t := tok.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err = enc.EncodeToken(start); err != nil {
+			return err
+		}
+
+		for dec.More() {
+			child := xmlItem
+
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				child = key.(string)
+			}
+
+			if err = writeXML(enc, dec, child); err != nil {
+				return err
+			}
+		}
+
+		// closing delimiter
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+
+		return enc.EncodeToken(start.End())
+	}
+
+	return enc.EncodeElement(fmt.Sprint(tok), start)
+}
+
+// xmlNode is an element of XML request body
+type xmlNode struct {
+	XMLName xml.Name
+	Text    string    `xml:",chardata"`
+	Nodes   []xmlNode `xml:",any"`
+}
+
+// jsonUnmarshaler is a type of json.Unmarshaler interface
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// rawMessage is a type of json.RawMessage
+var rawMessage = reflect.TypeOf(json.RawMessage{})
+
+// decodeXML is used to read XML value from given reader into v. XML elements are converted to JSON by the v type as
+// encodeXML writes them and decoded as JSON, so JSON field names are used as element names
+func decodeXML(r io.Reader, v interface{}) error {
+	root := &xmlNode{}
+	if err := xml.NewDecoder(r).Decode(root); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(xmlJSON(root, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// xmlJSON is used to get a JSON value of given type from XML node. Numbers and booleans are passed as raw JSON, so
+// malformed ones fail JSON decoding. Values of untyped fields are guessed by their text
+func xmlJSON(n *xmlNode, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == rawMessage || t.Kind() == reflect.Interface {
+		return xmlGuess(n)
+	}
+
+	// types with own JSON format like model.Date are decoded from text
+	if reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+		return n.Text
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := map[string]reflect.Type{}
+		for _, f := range jsonFields(t) {
+			fields[f.name] = f.typ
+		}
+
+		obj := map[string]interface{}{}
+
+		for i := range n.Nodes {
+			if ft, ok := fields[n.Nodes[i].XMLName.Local]; ok {
+				obj[n.Nodes[i].XMLName.Local] = xmlJSON(&n.Nodes[i], ft)
+			}
+		}
+
+		return obj
+	case reflect.Map:
+		obj := map[string]interface{}{}
+		for i := range n.Nodes {
+			obj[n.Nodes[i].XMLName.Local] = xmlJSON(&n.Nodes[i], t.Elem())
+		}
+
+		return obj
+	case reflect.Slice, reflect.Array:
+		items := []interface{}{}
+		for i := range n.Nodes {
+			items = append(items, xmlJSON(&n.Nodes[i], t.Elem()))
+		}
+
+		return items
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return json.RawMessage(strings.TrimSpace(n.Text))
+	}
+
+	return n.Text
+}
+
+// xmlGuess is used to get a JSON value of XML node of unknown type. Elements with xmlItem children are lists, other
+// elements with children are objects, text which is a valid JSON number, boolean or null is used as is
+func xmlGuess(n *xmlNode) interface{} {
+	if len(n.Nodes) != 0 {
+		if n.Nodes[0].XMLName.Local == xmlItem {
+			items := make([]interface{}, 0, len(n.Nodes))
+			for i := range n.Nodes {
+				items = append(items, xmlGuess(&n.Nodes[i]))
+			}
+
+			return items
+		}
+
+		obj := map[string]interface{}{}
+		for i := range n.Nodes {
+			obj[n.Nodes[i].XMLName.Local] = xmlGuess(&n.Nodes[i])
+		}
+
+		return obj
+	}
+
+	text := strings.TrimSpace(n.Text)
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err == nil {
+		if _, ok := v.(string); !ok {
+			return json.RawMessage(text)
+		}
+	}
+
+	return n.Text
+}
+
+// jsonField is a struct field encoded in JSON
+type jsonField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+}
+
+// jsonFields is used to get fields of given struct type encoded in JSON in their order. Fields of embedded structs
+// without JSON name are included as JSON encodes them
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if name == "-" || !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for _, ef := range jsonFields(f.Type) {
+				ef.index = append([]int{i}, ef.index...)
+				fields = append(fields, ef)
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, jsonField{name: name, index: []int{i}, typ: f.Type})
+	}
+
+	return fields
+}
+
+// encodeMsgpack is used to write given value as MessagePack converted from its JSON. Integer numbers are written as
+// integers, other numbers as floats
+func encodeMsgpack(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var value interface{}
+	if err = dec.Decode(&value); err != nil {
+		return err
+	}
+
+	enc := msgpack.NewEncoder(w)
+	enc.SetSortMapKeys(true)
+
+	return enc.Encode(msgpackValue(value))
+}
+
+// msgpackValue is used to convert json.Number values of given decoded JSON value to int64 or float64
+func msgpackValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := v.Float64()
+
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = msgpackValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = msgpackValue(e)
+		}
+	}
+
+	return v
+}
+
+// decodeMsgpack is used to read MessagePack value from given reader into v. The value is converted to JSON and decoded
+// as JSON, so JSON field names are used as map keys
+func decodeMsgpack(r io.Reader, v interface{}) error {
+	var value interface{}
+	if err := msgpack.NewDecoder(r).Decode(&value); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// encodeCSV is used to write items of given listResponse as CSV with a header row of the item JSON field names. Strings
+// are written as is, lists of scalars are joined with commas, objects as JSON, null values are blank
+func encodeCSV(w io.Writer, v interface{}) error {
+	list, ok := v.(listResponse)
+	if !ok {
+		return fmt.Errorf("%T is not a list response", v)
+	}
+
+	items := reflect.ValueOf(list.Items())
+	if items.Kind() != reflect.Slice {
+		return fmt.Errorf("%T items are not a slice", v)
+	}
+
+	t := items.Type().Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("%T items are not objects", v)
+	}
+
+	fields := jsonFields(t)
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < items.Len(); i++ {
+		item := reflect.Indirect(items.Index(i))
+		record := make([]string, len(fields))
+
+		for j, f := range fields {
+			value, err := csvValue(item.FieldByIndex(f.index).Interface())
+			if err != nil {
+				return err
+			}
+
+			record[j] = value
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// csvValue is used to get CSV cell of given field value by its JSON
+func csvValue(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var value interface{}
+	if err = dec.Decode(&value); err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case []interface{}:
+		values := make([]string, len(value))
+
+		for i, e := range value {
+			switch e.(type) {
+			case map[string]interface{}, []interface{}:
+				return string(b), nil
+			}
+
+			values[i] = fmt.Sprint(e)
+		}
+
+		return strings.Join(values, ","), nil
+	case map[string]interface{}:
+		return string(b), nil
+	}
+
+	return fmt.Sprint(value), nil
+}
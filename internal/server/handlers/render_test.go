@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		list   bool
+
+		wantContentType string
+		wantErr         string
+	}{
+		{
+			name:            "check blank",
+			wantContentType: JSONContentType,
+		},
+		{
+			name:            "check any",
+			accept:          "*/*",
+			wantContentType: JSONContentType,
+		},
+		{
+			name:            "check xml alias",
+			accept:          "text/xml",
+			wantContentType: XMLContentType,
+		},
+		{
+			name:            "check quality",
+			accept:          "application/json;q=0.5, application/msgpack",
+			wantContentType: MsgpackContentType,
+		},
+		{
+			name:            "check header order of equal quality",
+			accept:          "application/xml, application/json",
+			wantContentType: XMLContentType,
+		},
+		{
+			name:            "check type wildcard",
+			accept:          "text/html, application/*;q=0.8",
+			wantContentType: JSONContentType,
+		},
+		{
+			name:            "check refused",
+			accept:          "application/json;q=0, */*;q=0.1",
+			wantContentType: XMLContentType,
+		},
+		{
+			name:            "check csv list",
+			accept:          "text/csv",
+			list:            true,
+			wantContentType: CSVContentType,
+		},
+		{
+			name:    "check csv not list",
+			accept:  "text/csv",
+			wantErr: `content type "text/csv" is not acceptable, use application/json, application/xml, application/msgpack`,
+		},
+		{
+			name:   "check not acceptable",
+			accept: "text/html",
+			list:   true,
+			wantErr: `content type "text/html" is not acceptable, use application/json, application/xml, application/msgpack, ` +
+				`text/csv`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+			req.Header.Set("Accept", tt.accept)
+
+			c, err := negotiate(req, tt.list)
+
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, service.ErrNotAcceptable)
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantContentType, c.contentType)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	created := time.Date(2023, time.November, 26, 10, 0, 0, 0, time.UTC)
+	tags := &responses.GetTagsResp{Tags: []*model.Tag{
+		{ID: 1, Name: "senior", Category: "age", CreatedAt: created},
+		{ID: 2, Name: "good-with-kids", CreatedAt: created},
+	}}
+
+	tests := []struct {
+		name   string
+		accept string
+		resp   interface{}
+
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "check json",
+			resp:            &responses.AddTagResp{ID: 3},
+			wantStatus:      http.StatusCreated,
+			wantContentType: JSONContentType,
+			wantBody:        "{\"id\":3}\n",
+		},
+		{
+			name:            "check xml",
+			accept:          "application/xml",
+			resp:            tags,
+			wantStatus:      http.StatusOK,
+			wantContentType: XMLContentType,
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><tags>` +
+				`<item><id>1</id><name>senior</name><category>age</category><created_at>2023-11-26T10:00:00Z</created_at></item>` +
+				`<item><id>2</id><name>good-with-kids</name><category></category><created_at>2023-11-26T10:00:00Z</created_at></item>` +
+				`</tags></response>`,
+		},
+		{
+			name:            "check msgpack",
+			accept:          "application/msgpack",
+			resp:            tags,
+			wantStatus:      http.StatusOK,
+			wantContentType: MsgpackContentType,
+		},
+		{
+			name:            "check csv",
+			accept:          "text/csv",
+			resp:            tags,
+			wantStatus:      http.StatusOK,
+			wantContentType: CSVContentType,
+			wantBody: "id,name,category,created_at,updated_at\n" +
+				"1,senior,age,2023-11-26T10:00:00Z,\n" +
+				"2,good-with-kids,,2023-11-26T10:00:00Z,\n",
+		},
+		{
+			name:       "check 406",
+			accept:     "text/csv",
+			resp:       &responses.AddTagResp{ID: 3},
+			wantStatus: http.StatusNotAcceptable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+			req.Header.Set("Accept", tt.accept)
+			res := httptest.NewRecorder()
+
+			render(res, req, tt.wantStatus, tt.resp)
+
+			if tt.wantStatus == http.StatusNotAcceptable {
+				requireProblem(t, res, tt.wantStatus,
+					`content type "text/csv" is not acceptable, use application/json, application/xml, application/msgpack`)
+				return
+			}
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, tt.wantContentType, res.Header().Get("Content-Type"))
+			require.Equal(t, "Accept", res.Header().Get("Vary"))
+
+			if tt.wantContentType != MsgpackContentType {
+				require.Equal(t, tt.wantBody, res.Body.String())
+				return
+			}
+
+			got := map[string]interface{}{}
+			require.NoError(t, msgpack.Unmarshal(res.Body.Bytes(), &got))
+			require.Len(t, got["tags"], 2)
+
+			tag := got["tags"].([]interface{})[0].(map[string]interface{})
+			require.EqualValues(t, 1, tag["id"])
+			require.Equal(t, "senior", tag["name"])
+			require.Nil(t, tag["updated_at"])
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	weight := 12.5
+	species := "cat"
+
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"id": 2, "name": "Murka", "species": "cat", "weight": 12.5})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		req         interface{}
+
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name: "check blank json",
+			body: []byte(`{"name":"Velho","weight":12.5,"neutered":true}`),
+			req:  &requests.AddPetReq{},
+			want: &requests.AddPetReq{Name: "Velho", Weight: &weight, Neutered: true},
+		},
+		{
+			name:        "check xml",
+			contentType: "application/xml; charset=utf-8",
+			body: []byte(`<?xml version="1.0"?><request><id>2</id><name>Murka</name><species>cat</species>` +
+				`<weight> 12.5 </weight><unknown>1</unknown></request>`),
+			req:  &requests.UpdateReq{},
+			want: &requests.UpdateReq{ID: 2, UpdateByIDReq: requests.UpdateByIDReq{Name: "Murka", Species: &species, Weight: &weight}},
+		},
+		{
+			name:        "check xml list",
+			contentType: "text/xml",
+			body: []byte(`<request><items><item><id>1</id><version>2</version><patch><name>Velho</name>` +
+				`<weight>12.5</weight><color></color></patch></item></items></request>`),
+			req: &requests.BatchPatchPetsReq{},
+			want: &requests.BatchPatchPetsReq{Items: []*requests.BatchPatchItem{
+				{ID: 1, Version: 2, Patch: json.RawMessage(`{"color":"","name":"Velho","weight":12.5}`)},
+			}},
+		},
+		{
+			name:        "check xml malformed number",
+			contentType: "application/xml",
+			body:        []byte(`<request><id>two</id></request>`),
+			req:         &requests.DeleteReq{},
+			wantErr:     "invalid character 'w' in literal true",
+		},
+		{
+			name:        "check msgpack",
+			contentType: "application/x-msgpack",
+			body:        msgpackBody,
+			req:         &requests.UpdateReq{},
+			want:        &requests.UpdateReq{ID: 2, UpdateByIDReq: requests.UpdateByIDReq{Name: "Murka", Species: &species, Weight: &weight}},
+		},
+		{
+			name:        "check empty msgpack",
+			contentType: "application/msgpack",
+			req:         &requests.DecisionReq{},
+			wantErr:     io.EOF.Error(),
+		},
+		{
+			name:        "check 415",
+			contentType: "text/csv",
+			body:        []byte("name\nVelho\n"),
+			req:         &requests.AddPetReq{},
+			wantErr:     `content type "text/csv" is not supported, use application/json, application/xml, application/msgpack`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pet", bytes.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			err := decode(req, tt.req)
+
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tt.req)
+		})
+	}
+}
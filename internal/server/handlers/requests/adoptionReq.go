@@ -0,0 +1,18 @@
+package requests
+
+// ApplicationReq is a form of request accepted in POST /pet/{id}/applications route
+type ApplicationReq struct {
+	// Applicant is an applicant full name
+	Applicant string `json:"applicant"`
+	// Contact is an applicant email or phone
+	Contact string `json:"contact"`
+	// Notes is a free text applicant notes
+	Notes string `json:"notes"`
+}
+
+// DecisionReq is a form of request accepted in POST /applications/{id}/approve, /reject, /complete and
+// POST /pet/{id}/return routes. Body is optional
+type DecisionReq struct {
+	// Reason is a free text decision reason
+	Reason string `json:"reason"`
+}
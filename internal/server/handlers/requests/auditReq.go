@@ -0,0 +1,7 @@
+package requests
+
+// RevertReq is a form of request accepted in POST /pet/{id}/revert route
+type RevertReq struct {
+	// Version is a historical pet version to revert the pet to
+	Version int `json:"version"`
+}
@@ -0,0 +1,54 @@
+package requests
+
+import "encoding/json"
+
+// BatchAddPetsReq is a form of request accepted in POST /pet/batch route
+type BatchAddPetsReq struct {
+	// Items is a list of pets to add
+	Items []*AddPetReq `json:"items"`
+}
+
+// Len is used to get the number of batch items
+func (r *BatchAddPetsReq) Len() int {
+	return len(r.Items)
+}
+
+// BatchPatchPetsReq is a form of request accepted in PATCH /pet/batch route
+type BatchPatchPetsReq struct {
+	// Items is a list of pet patches
+	Items []*BatchPatchItem `json:"items"`
+}
+
+// Len is used to get the number of batch items
+func (r *BatchPatchPetsReq) Len() int {
+	return len(r.Items)
+}
+
+// BatchPatchItem is a patch of a single pet in BatchPatchPetsReq
+type BatchPatchItem struct {
+	// ID is a pet ID to patch
+	ID int `json:"id"`
+	// Version is a pet version the patch is applied to. 0 if version is not checked
+	Version int `json:"version"`
+	// Patch is an RFC 7396 JSON Merge Patch of the pet
+	Patch json.RawMessage `json:"patch"`
+}
+
+// BatchDeletePetsReq is a form of request accepted in DELETE /pet/batch route
+type BatchDeletePetsReq struct {
+	// Items is a list of pets to delete
+	Items []*BatchDeleteItem `json:"items"`
+}
+
+// Len is used to get the number of batch items
+func (r *BatchDeletePetsReq) Len() int {
+	return len(r.Items)
+}
+
+// BatchDeleteItem is a single pet to delete in BatchDeletePetsReq
+type BatchDeleteItem struct {
+	// ID is a pet ID to delete
+	ID int `json:"id"`
+	// Version is a pet version to delete. 0 if version is not checked
+	Version int `json:"version"`
+}
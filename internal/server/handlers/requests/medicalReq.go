@@ -0,0 +1,35 @@
+package requests
+
+// VaccinationReq is a form of request accepted in POST /pet/{id}/vaccinations and
+// PUT /pet/{id}/vaccinations/{record_id} routes
+type VaccinationReq struct {
+	// Vaccine is a vaccine name
+	Vaccine string `json:"vaccine"`
+	// GivenOn is a date the shot was given in YYYY-MM-DD format
+	GivenOn string `json:"given_on"`
+	// DueOn is a date the next shot is due in YYYY-MM-DD format. Blank if no next shot is needed
+	DueOn string `json:"due_on"`
+	// Vet is a veterinarian or clinic name
+	Vet string `json:"vet"`
+	// Notes is a free text notes
+	Notes string `json:"notes"`
+	// Attachment is a reference to an attached document, e.g. a certificate URL
+	Attachment string `json:"attachment"`
+}
+
+// TreatmentReq is a form of request accepted in POST /pet/{id}/treatments and PUT /pet/{id}/treatments/{record_id}
+// routes
+type TreatmentReq struct {
+	// Kind is a treatment kind: visit, medication, procedure or other
+	Kind string `json:"kind"`
+	// Name is a treatment, medication or visit reason name
+	Name string `json:"name"`
+	// GivenOn is a date of the treatment or visit in YYYY-MM-DD format
+	GivenOn string `json:"given_on"`
+	// Vet is a veterinarian or clinic name
+	Vet string `json:"vet"`
+	// Notes is a free text notes
+	Notes string `json:"notes"`
+	// Attachment is a reference to an attached document, e.g. a prescription URL
+	Attachment string `json:"attachment"`
+}
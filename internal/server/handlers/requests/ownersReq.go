@@ -0,0 +1,21 @@
+package requests
+
+// OwnerReq is a form of request accepted in POST /owners, PUT /owners/{id} and PATCH /owners/{id} routes
+type OwnerReq struct {
+	// Name is an owner full name
+	Name string `json:"name"`
+	// Email is an owner contact email
+	Email string `json:"email"`
+	// Phone is an owner contact phone
+	Phone string `json:"phone"`
+	// Address is an owner postal address
+	Address string `json:"address"`
+}
+
+// TransferReq is a form of request accepted in POST /pet/{id}/transfer route
+type TransferReq struct {
+	// OwnerID is a new pet owner ID. Null releases the pet from its current owner
+	OwnerID *int `json:"owner_id"`
+	// Note is a free text transfer note
+	Note string `json:"note"`
+}
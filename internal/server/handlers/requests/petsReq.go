@@ -4,14 +4,56 @@ package requests
 type AddPetReq struct {
 	// Name is a pet name to add
 	Name string `json:"name"`
+	// Species is a pet species: dog, cat, rabbit, bird, rodent, reptile or other. Other by default
+	Species string `json:"species"`
+	// Breed is a pet breed
+	Breed string `json:"breed"`
+	// BirthDate is a pet birth date in YYYY-MM-DD format
+	BirthDate string `json:"birth_date"`
+	// Sex is a pet sex: male, female or unknown. Unknown by default
+	Sex string `json:"sex"`
+	// Neutered is true if pet is neutered or spayed
+	Neutered bool `json:"neutered"`
+	// Weight is a pet weight in kilograms
+	Weight *float64 `json:"weight"`
+	// Color is a pet coat color
+	Color string `json:"color"`
+	// Description is a free text pet description
+	Description string `json:"description"`
+	// Status is a pet status: available, pending or archived. Available by default
+	Status string `json:"status"`
 }
 
 // UpdateReq is a form of request accepted in PUT /pet route
 type UpdateReq struct {
 	// ID is a pet ID to update
 	ID int `json:"id"`
+	UpdateByIDReq
+}
+
+// UpdateByIDReq is a form of request accepted in PUT /pet/{id} and PATCH /pet/{id} routes. Pet ID is taken from the
+// route path. Name is required, other fields are updated only if given, blank birth_date removes the birth date
+type UpdateByIDReq struct {
 	// Name is a new pet Name
 	Name string `json:"name"`
+	// Species is a new pet species
+	Species *string `json:"species"`
+	// Breed is a new pet breed
+	Breed *string `json:"breed"`
+	// BirthDate is a new pet birth date in YYYY-MM-DD format
+	BirthDate *string `json:"birth_date"`
+	// Sex is a new pet sex
+	Sex *string `json:"sex"`
+	// Neutered is a new pet neutered flag
+	Neutered *bool `json:"neutered"`
+	// Weight is a new pet weight in kilograms
+	Weight *float64 `json:"weight"`
+	// Color is a new pet coat color
+	Color *string `json:"color"`
+	// Description is a new pet description
+	Description *string `json:"description"`
+	// Status is a new pet status
+	Status *string `json:"status"`
 }
 
 // DeleteReq is a form of request accepted in DELETE /pet route
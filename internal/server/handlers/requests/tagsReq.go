@@ -0,0 +1,15 @@
+package requests
+
+// TagReq is a form of request accepted in POST /tags and PUT /tags/{id} routes
+type TagReq struct {
+	// Name is a tag name
+	Name string `json:"name"`
+	// Category is a tag category
+	Category string `json:"category"`
+}
+
+// PetTagsReq is a form of request accepted in PUT /pet/{id}/tags route
+type PetTagsReq struct {
+	// Tags is a full list of the pet tag names
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,31 @@
+package responses
+
+import "pets/internal/model"
+
+// AddApplicationResp is a form of response for POST /pet/{id}/applications route
+type AddApplicationResp struct {
+	// ID is a submitted application ID
+	ID int `json:"id"`
+}
+
+// GetApplicationsResp is a form of response for GET /applications and GET /pet/{id}/applications routes
+type GetApplicationsResp struct {
+	// Applications is a slice of model.Application found, oldest first
+	Applications []*model.Application `json:"applications"`
+}
+
+// Items is used to get the listed applications
+func (r *GetApplicationsResp) Items() interface{} {
+	return r.Applications
+}
+
+// GetTransitionsResp is a form of response for GET /pet/{id}/transitions route
+type GetTransitionsResp struct {
+	// Transitions is a slice of model.Transition of the pet, oldest first
+	Transitions []*model.Transition `json:"transitions"`
+}
+
+// Items is used to get the listed transitions
+func (r *GetTransitionsResp) Items() interface{} {
+	return r.Transitions
+}
@@ -0,0 +1,26 @@
+package responses
+
+import "pets/internal/model"
+
+// GetAuditResp is a form of response for GET /audit and GET /pet/{id}/history routes
+type GetAuditResp struct {
+	// Entries is a slice of model.AuditEntry found, newest first
+	Entries []*model.AuditEntry `json:"entries"`
+	// Total is a number of all matching entries regardless of limit and offset
+	Total int `json:"total"`
+	// Limit is a requested page size. 0 if page size is not limited
+	Limit int `json:"limit"`
+	// Offset is a requested number of entries to skip
+	Offset int `json:"offset"`
+	// HasMore is true if there are entries after the returned page
+	HasMore bool `json:"has_more"`
+	// Next is a link to the next page. Blank if there is no next page
+	Next string `json:"next,omitempty"`
+	// Prev is a link to the previous page. Blank if there is no previous page
+	Prev string `json:"prev,omitempty"`
+}
+
+// Items is used to get the listed audit entries
+func (r *GetAuditResp) Items() interface{} {
+	return r.Entries
+}
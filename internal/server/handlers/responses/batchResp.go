@@ -0,0 +1,25 @@
+package responses
+
+// BatchResp is a form of response for POST, PATCH and DELETE /pet/batch routes
+type BatchResp struct {
+	// Mode is a batch mode: atomic or best_effort
+	Mode string `json:"mode"`
+	// Succeeded is a number of applied items
+	Succeeded int `json:"succeeded"`
+	// Failed is a number of not applied items
+	Failed int `json:"failed"`
+	// Items is a list of item results in request items order
+	Items []*BatchItemResp `json:"items"`
+}
+
+// BatchItemResp is a result of a single batch item
+type BatchItemResp struct {
+	// Index is an item index in the request items
+	Index int `json:"index"`
+	// ID is a pet ID. Blank for not added pets
+	ID int `json:"id,omitempty"`
+	// Status is an HTTP status code of the item result
+	Status int `json:"status"`
+	// Error is a problem of not applied item. Nil for applied items
+	Error *Problem `json:"error,omitempty"`
+}
@@ -0,0 +1,44 @@
+package responses
+
+import "pets/internal/model"
+
+// AddRecordResp is a form of response for POST /pet/{id}/vaccinations and POST /pet/{id}/treatments routes
+type AddRecordResp struct {
+	// ID is a created record ID
+	ID int `json:"id"`
+}
+
+// GetVaccinationsResp is a form of response for GET /pet/{id}/vaccinations route
+type GetVaccinationsResp struct {
+	// Vaccinations is a slice of model.Vaccination of the pet, oldest shot first
+	Vaccinations []*model.Vaccination `json:"vaccinations"`
+}
+
+// Items is used to get the listed vaccinations
+func (r *GetVaccinationsResp) Items() interface{} {
+	return r.Vaccinations
+}
+
+// GetDueVaccinationsResp is a form of response for GET /vaccinations/due route
+type GetDueVaccinationsResp struct {
+	// Before is a due date the vaccinations are due by
+	Before model.Date `json:"before"`
+	// Vaccinations is a slice of model.DueVaccination, earliest due first
+	Vaccinations []*model.DueVaccination `json:"vaccinations"`
+}
+
+// Items is used to get the listed due vaccinations
+func (r *GetDueVaccinationsResp) Items() interface{} {
+	return r.Vaccinations
+}
+
+// GetTreatmentsResp is a form of response for GET /pet/{id}/treatments route
+type GetTreatmentsResp struct {
+	// Treatments is a slice of model.Treatment of the pet, oldest first
+	Treatments []*model.Treatment `json:"treatments"`
+}
+
+// Items is used to get the listed treatments
+func (r *GetTreatmentsResp) Items() interface{} {
+	return r.Treatments
+}
@@ -0,0 +1,43 @@
+package responses
+
+import "pets/internal/model"
+
+// AddOwnerResp is a form of response for POST /owners route
+type AddOwnerResp struct {
+	// ID is an added owner ID
+	ID int `json:"id"`
+}
+
+// GetOwnersResp is a form of response for GET /owners route
+type GetOwnersResp struct {
+	// Owners is a slice of model.Owner found
+	Owners []*model.Owner `json:"owners"`
+	// Total is a number of all owners regardless of limit and offset
+	Total int `json:"total"`
+	// Limit is a requested page size. 0 if page size is not limited
+	Limit int `json:"limit"`
+	// Offset is a requested number of owners to skip
+	Offset int `json:"offset"`
+	// HasMore is true if there are owners after the returned page
+	HasMore bool `json:"has_more"`
+	// Next is a link to the next page. Blank if there is no next page
+	Next string `json:"next,omitempty"`
+	// Prev is a link to the previous page. Blank if there is no previous page
+	Prev string `json:"prev,omitempty"`
+}
+
+// Items is used to get the listed owners
+func (r *GetOwnersResp) Items() interface{} {
+	return r.Owners
+}
+
+// GetOwnershipResp is a form of response for GET /pet/{id}/ownership route
+type GetOwnershipResp struct {
+	// History is a slice of model.Ownership transfers of the pet, oldest first
+	History []*model.Ownership `json:"history"`
+}
+
+// Items is used to get the listed ownership transfers
+func (r *GetOwnershipResp) Items() interface{} {
+	return r.History
+}
@@ -12,6 +12,24 @@ type AddPetResp struct {
 type GetPetsResp struct {
 	// Pets is a slice of model.Pet found
 	Pets []*model.Pet `json:"pets"`
-	// Total is a Pets length value
+	// Total is a number of all pets regardless of limit and offset
 	Total int `json:"total"`
+	// Limit is a requested page size. 0 if page size is not limited
+	Limit int `json:"limit"`
+	// Offset is a requested number of pets to skip
+	Offset int `json:"offset"`
+	// HasMore is true if there are pets after the returned page
+	HasMore bool `json:"has_more"`
+	// Next is a link to the next page. Blank if there is no next page
+	Next string `json:"next,omitempty"`
+	// Prev is a link to the previous page. Blank if there is no previous page
+	Prev string `json:"prev,omitempty"`
+	// NextCursor is an opaque cursor of the next page, can be passed as cursor query param. Blank if there is no
+	// next page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Items is used to get the listed pets
+func (r *GetPetsResp) Items() interface{} {
+	return r.Pets
 }
@@ -0,0 +1,14 @@
+package responses
+
+import "pets/internal/model"
+
+// GetPhotosResp is a form of response for GET /pet/{id}/photos route
+type GetPhotosResp struct {
+	// Photos is a slice of model.Photo of the pet, oldest first
+	Photos []*model.Photo `json:"photos"`
+}
+
+// Items is used to get the listed photos
+func (r *GetPhotosResp) Items() interface{} {
+	return r.Photos
+}
@@ -0,0 +1,37 @@
+package responses
+
+import "pets/internal/model"
+
+// Problem is an RFC 7807 problem details response sent with application/problem+json content type for all errors
+type Problem struct {
+	// Type is a URI identifying the problem type, stable for the Code
+	Type string `json:"type"`
+	// Title is a short human-readable summary of the problem type
+	Title string `json:"title"`
+	// Status is an HTTP status code of the response
+	Status int `json:"status"`
+	// Detail is a human-readable explanation of this problem occurrence
+	Detail string `json:"detail,omitempty"`
+	// Instance is a request path the problem occurred on
+	Instance string `json:"instance,omitempty"`
+	// Code is a stable machine-readable problem code
+	Code string `json:"code"`
+	// InvalidParams is a list of invalid request params. Set for validation problems only
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam is a Problem detail of a single invalid request param
+type InvalidParam struct {
+	// Name is an invalid param name
+	Name string `json:"name"`
+	// Reason is a human-readable explanation why the param is invalid
+	Reason string `json:"reason"`
+}
+
+// ImportProblem is a Problem of failed pets import with the report of rows read before the failure. Pets up to the
+// report CommittedLine are added
+type ImportProblem struct {
+	*Problem
+	// Report is a report of rows read before the failure
+	Report *model.ImportReport `json:"report"`
+}
@@ -0,0 +1,26 @@
+package responses
+
+import "pets/internal/model"
+
+// AddTagResp is a form of response for POST /tags route
+type AddTagResp struct {
+	// ID is an added tag ID
+	ID int `json:"id"`
+}
+
+// GetTagsResp is a form of response for GET /tags route
+type GetTagsResp struct {
+	// Tags is a slice of model.Tag found, ordered by name
+	Tags []*model.Tag `json:"tags"`
+}
+
+// Items is used to get the listed tags
+func (r *GetTagsResp) Items() interface{} {
+	return r.Tags
+}
+
+// PetTagsResp is a form of response for PUT /pet/{id}/tags route
+type PetTagsResp struct {
+	// Tags is a list of the pet tag names ordered by name
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/requests"
+	"pets/internal/server/handlers/responses"
+	"pets/pkg/logger"
+)
+
+// GetTags is a handler func for GET /tags route. Query param "category" is used to get tags of given category only
+// Will return tags in responses.GetTagsResp format ordered by name
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetTags() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		res, err := h.srv.GetTags(request.Context(), request.URL.Query().Get("category"))
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		if res == nil {
+			res = []*model.Tag{}
+		}
+
+		render(writer, request, http.StatusOK, &responses.GetTagsResp{Tags: res})
+	}
+}
+
+// GetTag is a handler func for GET /tags/{id} route
+// Will return tag in model.Tag format if tag found
+// Will return 400 status if ID in path is not a number or less than 0
+// Will return 404 status if tag not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) GetTag() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-GetTag").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		res, err := h.srv.GetTag(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, res)
+	}
+}
+
+// CreateTag is a handler func for POST /tags route
+// Will return created tag ID in responses.AddTagResp format
+// Will return 400 status if no request.Body provided or tag fields are invalid
+// Will return 409 status if tag with the same name exists
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) CreateTag() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := &requests.TagReq{}
+
+		if err := decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-CreateTag").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"name":string, "category":string}`))
+			return
+		}
+
+		id, err := h.srv.AddTag(request.Context(), model.GetTagFromReq(req))
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusCreated, &responses.AddTagResp{ID: id})
+	}
+}
+
+// UpdateTag is a handler func for PUT /tags/{id} route. All tag fields are replaced, pets keep the renamed tag
+// Will return 200 if request is successful
+// Will return 400 status if no request.Body provided, tag fields are invalid or ID in path is less than 0
+// Will return 404 status if tag not found
+// Will return 409 status if other tag with the same name exists
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) UpdateTag() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateTag").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.TagReq{}
+
+		if err = decode(request, req); err != nil {
+			logger.Log().WithField("layer", "Handlers-UpdateTag").Warningf("err decode body: %v", err.Error())
+			writeError(writer, request, bodyError(err, `provide body params {"name":string, "category":string}`))
+			return
+		}
+
+		tag := model.GetTagFromReq(req)
+		tag.ID = id
+
+		if err = h.srv.UpdateTag(request.Context(), tag); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// DeleteTag is a handler func for DELETE /tags/{id} route. The tag is removed from all pets
+// Will return 200 if request is successful
+// Will return 400 status if ID in path is less than 0
+// Will return 404 status if tag not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) DeleteTag() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-DeleteTag").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		if err = h.srv.DeleteTag(request.Context(), &model.Tag{ID: id}); err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// SetPetTags is a handler func for PUT /pet/{id}/tags route. The pet tag set is replaced atomically by given tag names,
+// empty list removes all tags
+// Will return the pet tag names in responses.PetTagsResp format ordered by name
+// Will return 400 status if no request.Body provided, any tag does not exist or ID in path is less than 0
+// Will return 404 status if pet not found
+// Can return 503 if DB is unavailable or 500 if unexpected DB error occurred
+func (h *Handlers) SetPetTags() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := getPathID(request)
+		if err != nil {
+			logger.Log().WithField("layer", "Handlers-SetPetTags").Warningf("wrong path id: %v", err.Error())
+			writeError(writer, request, err)
+			return
+		}
+
+		req := &requests.PetTagsReq{}
+
+		if err = decode(request, req); err != nil || req.Tags == nil {
+			logger.Log().WithField("layer", "Handlers-SetPetTags").Warningf("wrong body: %v", err)
+			writeError(writer, request, bodyError(err, `provide body params {"tags":[string]}`))
+			return
+		}
+
+		res, err := h.srv.SetPetTags(request.Context(), id, req.Tags)
+		if err != nil {
+			writeError(writer, request, err)
+			return
+		}
+
+		render(writer, request, http.StatusOK, &responses.PetTagsResp{Tags: res})
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/server/handlers/responses"
+	"pets/internal/service"
+	mock_service "pets/mocks/service"
+)
+
+func TestHandlers_CreateTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		body string
+
+		goToSev bool
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 201",
+			body:       `{"name":"senior","category":"age"}`,
+			goToSev:    true,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "check 400 no body",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"name":string, "category":string}`,
+		},
+		{
+			name:       "check 409 exists",
+			body:       `{"name":"senior","category":"age"}`,
+			goToSev:    true,
+			srvErr:     service.NewConflictError(`tag "senior" already exists`),
+			wantStatus: http.StatusConflict,
+			wantErr:    `tag "senior" already exists`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			createTag := h.CreateTag()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/tags", bytes.NewBufferString(tt.body))
+
+			if tt.goToSev {
+				srvMock.EXPECT().AddTag(gomock.Any(), &model.Tag{Name: "senior", Category: "age"}).Return(3, tt.srvErr)
+			}
+
+			createTag.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(&responses.AddTagResp{ID: 3})
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlers_SetPetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	srvMock := mock_service.NewMockIService(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+
+		goToSev bool
+		srvTags []string
+		srvErr  error
+
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "check 200",
+			id:         "1",
+			body:       `{"tags":["senior","good-with-kids"]}`,
+			goToSev:    true,
+			srvTags:    []string{"good-with-kids", "senior"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 200 clear",
+			id:         "1",
+			body:       `{"tags":[]}`,
+			goToSev:    true,
+			srvTags:    []string{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "check 400 no tags",
+			id:         "1",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `provide body params {"tags":[string]}`,
+		},
+		{
+			name:       "check 400 wrong id",
+			id:         "-1",
+			body:       `{"tags":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    `id should be a number more than 0, got "-1"`,
+		},
+		{
+			name:       "check 400 unknown tag",
+			id:         "1",
+			body:       `{"tags":["senior","good-with-kids"]}`,
+			goToSev:    true,
+			srvErr:     service.NewValidationError("invalid tags", service.FieldError{Field: "tags", Message: `unknown tag "senior"`}),
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid tags",
+		},
+		{
+			name:       "check 404 pet not found",
+			id:         "1",
+			body:       `{"tags":["senior","good-with-kids"]}`,
+			goToSev:    true,
+			srvErr:     service.NewNotFoundError("pet 1 not found"),
+			wantStatus: http.StatusNotFound,
+			wantErr:    "pet 1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(srvMock)
+			setPetTags := h.SetPetTags()
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/pet/"+tt.id+"/tags", bytes.NewBufferString(tt.body))
+			req = withPathID(req, tt.id)
+
+			if tt.goToSev {
+				var reqTags struct{ Tags []string }
+				require.NoError(t, json.Unmarshal([]byte(tt.body), &reqTags))
+
+				srvMock.EXPECT().SetPetTags(gomock.Any(), 1, reqTags.Tags).Return(tt.srvTags, tt.srvErr)
+			}
+
+			setPetTags.ServeHTTP(res, req)
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				requireProblem(t, res, tt.wantStatus, tt.wantErr)
+			} else {
+				want := httptest.NewRecorder()
+				json.NewEncoder(want).Encode(&responses.PetTagsResp{Tags: tt.srvTags})
+
+				require.Equal(t, tt.wantStatus, res.Code)
+				require.Equal(t, want.Body.String(), res.Body.String())
+			}
+		})
+	}
+}
@@ -30,8 +30,10 @@ func NewServer(conf *config.Http, srv service.IService) *HttpServer {
 	s.conf = conf
 
 	s.Router = chi.NewRouter()
+	s.Router.Use(middleware.RequestID)
 	s.Router.Use(middleware.Logger)
 	s.Router.Use(middleware.Recoverer)
+	s.Router.Use(handlers.Actor)
 
 	s.handlers = handlers.NewHandlers(srv)
 	s.registerRoutes()
@@ -52,9 +54,91 @@ func (s *HttpServer) ListenAndServ() {
 // registerRoutes is used to register routs in router
 func (s *HttpServer) registerRoutes() {
 	s.Router.Route("/api/v1", func(r chi.Router) {
-		r.Get("/pet", s.handlers.GetPets())
-		r.Post("/pet", s.handlers.CreatePet())
-		r.Put("/pet", s.handlers.UpdatePet())
-		r.Delete("/pet", s.handlers.DeletePet())
+		// routes writing files and photos have own content types
+		r.Get("/pet/export", s.handlers.ExportPets())
+		r.Get("/pet/{id}/photos/{photo_id}", s.handlers.GetPhoto())
+		r.Get("/pet/{id}/photos/{photo_id}/thumbnail", s.handlers.GetPhotoThumbnail())
+
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.Negotiate)
+
+			r.Get("/pet", s.handlers.GetPets())
+			r.Post("/pet", s.handlers.CreatePet())
+
+			r.Post("/pet/batch", s.handlers.CreatePets())
+			r.Patch("/pet/batch", s.handlers.PatchPets())
+			r.Delete("/pet/batch", s.handlers.DeletePets())
+			r.Post("/pet/import", s.handlers.ImportPets())
+
+			r.Get("/pet/{id}", s.handlers.GetPet())
+			r.Put("/pet/{id}", s.handlers.UpdatePetByID())
+			r.Patch("/pet/{id}", s.handlers.PatchPet())
+			r.Delete("/pet/{id}", s.handlers.DeletePetByID())
+			r.Post("/pet/{id}/restore", s.handlers.RestorePet())
+			r.Get("/pet/{id}/history", s.handlers.GetPetHistory())
+			r.Post("/pet/{id}/revert", s.handlers.RevertPet())
+
+			r.Post("/pet/{id}/transfer", s.handlers.TransferPet())
+			r.Get("/pet/{id}/ownership", s.handlers.GetOwnership())
+			r.Post("/pet/{id}/applications", s.handlers.SubmitApplication())
+			r.Get("/pet/{id}/applications", s.handlers.GetPetApplications())
+			r.Post("/pet/{id}/return", s.handlers.ReturnPet())
+			r.Get("/pet/{id}/transitions", s.handlers.GetTransitions())
+
+			r.Get("/pet/{id}/vaccinations", s.handlers.GetVaccinations())
+			r.Post("/pet/{id}/vaccinations", s.handlers.CreateVaccination())
+			r.Get("/pet/{id}/vaccinations/{record_id}", s.handlers.GetVaccination())
+			r.Put("/pet/{id}/vaccinations/{record_id}", s.handlers.UpdateVaccination())
+			r.Delete("/pet/{id}/vaccinations/{record_id}", s.handlers.DeleteVaccination())
+			r.Get("/vaccinations/due", s.handlers.GetDueVaccinations())
+
+			r.Get("/pet/{id}/treatments", s.handlers.GetTreatments())
+			r.Post("/pet/{id}/treatments", s.handlers.CreateTreatment())
+			r.Get("/pet/{id}/treatments/{record_id}", s.handlers.GetTreatment())
+			r.Put("/pet/{id}/treatments/{record_id}", s.handlers.UpdateTreatment())
+			r.Delete("/pet/{id}/treatments/{record_id}", s.handlers.DeleteTreatment())
+
+			r.Get("/pet/{id}/photos", s.handlers.GetPhotos())
+			r.Post("/pet/{id}/photos", s.handlers.CreatePhoto())
+			r.Delete("/pet/{id}/photos/{photo_id}", s.handlers.DeletePhoto())
+			r.Put("/pet/{id}/photos/{photo_id}/primary", s.handlers.SetPrimaryPhoto())
+			r.Put("/pet/{id}/tags", s.handlers.SetPetTags())
+
+			r.Get("/owners", s.handlers.GetOwners())
+			r.Post("/owners", s.handlers.CreateOwner())
+
+			r.Get("/owners/{id}", s.handlers.GetOwner())
+			r.Put("/owners/{id}", s.handlers.UpdateOwner())
+			r.Delete("/owners/{id}", s.handlers.DeleteOwner())
+			r.Get("/owners/{id}/pets", s.handlers.GetOwnerPets())
+
+			r.Get("/tags", s.handlers.GetTags())
+			r.Post("/tags", s.handlers.CreateTag())
+			r.Get("/tags/{id}", s.handlers.GetTag())
+			r.Put("/tags/{id}", s.handlers.UpdateTag())
+			r.Delete("/tags/{id}", s.handlers.DeleteTag())
+
+			r.Get("/audit", s.handlers.GetAudit())
+
+			r.Get("/applications", s.handlers.GetApplications())
+			r.Get("/applications/{id}", s.handlers.GetApplication())
+			r.Post("/applications/{id}/approve", s.handlers.ApproveApplication())
+			r.Post("/applications/{id}/reject", s.handlers.RejectApplication())
+			r.Post("/applications/{id}/complete", s.handlers.CompleteApplication())
+
+			// body-based routes are kept for existing callers, use /pet/{id} routes instead
+			r.With(deprecated).Put("/pet", s.handlers.UpdatePet())
+			r.With(deprecated).Delete("/pet", s.handlers.DeletePet())
+		})
+	})
+}
+
+// deprecated is a middleware used to mark route as deprecated with Deprecation header. Handlers of the route set Link
+// header to the successor route
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Deprecation", "true")
+
+		next.ServeHTTP(writer, request)
 	})
 }
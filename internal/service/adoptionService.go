@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pets/internal/model"
+)
+
+// applicationDecision is an allowed adoption application status change and pet status transition caused by it
+type applicationDecision struct {
+	from model.ApplicationStatus
+	to   model.ApplicationStatus
+	// petFrom and petTo are pet statuses before and after the decision. Blank if pet status is not changed
+	petFrom model.Status
+	petTo   model.Status
+}
+
+// applicationDecisions is a list of all allowed adoption application decisions
+var applicationDecisions = []applicationDecision{
+	{from: model.ApplicationSubmitted, to: model.ApplicationApproved, petFrom: model.StatusAvailable, petTo: model.StatusReserved},
+	{from: model.ApplicationSubmitted, to: model.ApplicationRejected},
+	{from: model.ApplicationApproved, to: model.ApplicationRejected, petFrom: model.StatusReserved, petTo: model.StatusAvailable},
+	{from: model.ApplicationApproved, to: model.ApplicationCompleted, petFrom: model.StatusReserved, petTo: model.StatusAdopted},
+}
+
+// SubmitApplication is implementing IService.SubmitApplication function
+func (s *Service) SubmitApplication(ctx context.Context, app *model.Application) (int, error) {
+	if err := validateApplication(app); err != nil {
+		return 0, err
+	}
+
+	pet, err := s.GetPet(ctx, app.PetID)
+	if err != nil {
+		return 0, err
+	}
+
+	if pet.Status != model.StatusAvailable {
+		return 0, NewConflictError(fmt.Sprintf("pet %v is %v, only available pets can be adopted", pet.ID, pet.Status))
+	}
+
+	app.Status = model.ApplicationSubmitted
+	app.Reason = ""
+
+	if err = s.repository.AddApplication(ctx, app); err != nil {
+		return 0, domainError(err, petNotFound(app.PetID))
+	}
+
+	return app.ID, nil
+}
+
+// GetApplications is implementing IService.GetApplications function
+func (s *Service) GetApplications(ctx context.Context, filter *model.ApplicationsFilter) ([]*model.Application, error) {
+	for _, st := range filter.Statuses {
+		if !st.Valid() {
+			return nil, NewValidationError("invalid filter", FieldError{Field: "status", Message: fmt.Sprintf("unknown status %q", st)})
+		}
+	}
+
+	res, err := s.repository.GetApplications(ctx, filter)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, a := range res {
+		a.SetLocal()
+	}
+
+	return res, nil
+}
+
+// GetPetApplications is implementing IService.GetPetApplications function
+func (s *Service) GetPetApplications(ctx context.Context, petID int) ([]*model.Application, error) {
+	if _, err := s.GetPet(ctx, petID); err != nil {
+		return nil, err
+	}
+
+	return s.GetApplications(ctx, &model.ApplicationsFilter{PetID: petID})
+}
+
+// GetApplication is implementing IService.GetApplication function
+func (s *Service) GetApplication(ctx context.Context, id int) (*model.Application, error) {
+	res, err := s.repository.GetApplication(ctx, id)
+	if err != nil {
+		return nil, domainError(err, applicationNotFound(id))
+	}
+
+	res.SetLocal()
+
+	return res, nil
+}
+
+// ApproveApplication is implementing IService.ApproveApplication function
+func (s *Service) ApproveApplication(ctx context.Context, id int, reason string) (*model.Application, error) {
+	return s.decideApplication(ctx, id, model.ApplicationApproved, reason)
+}
+
+// RejectApplication is implementing IService.RejectApplication function
+func (s *Service) RejectApplication(ctx context.Context, id int, reason string) (*model.Application, error) {
+	return s.decideApplication(ctx, id, model.ApplicationRejected, reason)
+}
+
+// CompleteApplication is implementing IService.CompleteApplication function
+func (s *Service) CompleteApplication(ctx context.Context, id int, reason string) (*model.Application, error) {
+	return s.decideApplication(ctx, id, model.ApplicationCompleted, reason)
+}
+
+// ReturnPet is implementing IService.ReturnPet function
+func (s *Service) ReturnPet(ctx context.Context, petID int, reason string) (*model.Transition, error) {
+	if err := validateReason(reason); err != nil {
+		return nil, err
+	}
+
+	pet, err := s.GetPet(ctx, petID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pet.Status != model.StatusAdopted {
+		return nil, NewConflictError(fmt.Sprintf("pet %v is %v, only adopted pets can be returned", pet.ID, pet.Status))
+	}
+
+	transition := &model.Transition{PetID: petID, From: pet.Status, To: model.StatusReturned, Reason: reason}
+
+	if err = s.repository.TransitionPet(ctx, transition); err != nil {
+		return nil, domainError(err, petNotFound(petID))
+	}
+
+	transition.SetLocal()
+
+	return transition, nil
+}
+
+// GetTransitions is implementing IService.GetTransitions function
+func (s *Service) GetTransitions(ctx context.Context, petID int) ([]*model.Transition, error) {
+	if _, err := s.GetPet(ctx, petID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.GetTransitions(ctx, petID)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, t := range res {
+		t.SetLocal()
+	}
+
+	return res, nil
+}
+
+// decideApplication is used to move application with given ID to given status with the pet status transition caused
+// by the decision. Will return ErrConflict kind error if decision is not allowed for current application or pet status
+func (s *Service) decideApplication(ctx context.Context, id int, to model.ApplicationStatus, reason string) (*model.Application, error) {
+	if err := validateReason(reason); err != nil {
+		return nil, err
+	}
+
+	app, err := s.GetApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := findDecision(app.Status, to)
+	if !ok {
+		return nil, NewConflictError(fmt.Sprintf("application %v is %v, cannot be %v", app.ID, app.Status, to))
+	}
+
+	var transition *model.Transition
+
+	if d.petTo != "" {
+		pet, err := s.GetPet(ctx, app.PetID)
+		if err != nil {
+			return nil, err
+		}
+
+		if pet.Status != d.petFrom {
+			return nil, NewConflictError(fmt.Sprintf("pet %v is %v, application %v cannot be %v", pet.ID, pet.Status, app.ID, to))
+		}
+
+		transition = &model.Transition{PetID: pet.ID, From: d.petFrom, To: d.petTo, Reason: reason, ApplicationID: &app.ID}
+	}
+
+	app.Status = to
+	app.Reason = reason
+
+	if err = s.repository.UpdateApplication(ctx, app, d.from, transition); err != nil {
+		return nil, domainError(err, applicationNotFound(id))
+	}
+
+	app.SetLocal()
+
+	return app, nil
+}
+
+// findDecision is used to get allowed decision moving application from given status to given one
+func findDecision(from model.ApplicationStatus, to model.ApplicationStatus) (applicationDecision, bool) {
+	for _, d := range applicationDecisions {
+		if d.from == from && d.to == to {
+			return d, true
+		}
+	}
+
+	return applicationDecision{}, false
+}
+
+// manualTransition is used to check that pet can be moved from given status to given one by pet update. Statuses of
+// the adoption workflow can be changed by applications and returns only
+func manualTransition(from model.Status, to model.Status) bool {
+	return from.CanTransition(to) && !to.Adoption() && from != model.StatusReserved
+}
+
+// validateApplication is used to check application fields given by user. Will return ErrValidation kind error with all
+// invalid fields
+func validateApplication(app *model.Application) error {
+	var fields []FieldError
+
+	if app.Applicant == "" {
+		fields = append(fields, FieldError{Field: "applicant", Message: "cannot be blank"})
+	}
+
+	if len(app.Applicant) > maxNameLen {
+		fields = append(fields, FieldError{Field: "applicant", Message: fmt.Sprintf("cannot be longer than %v", maxNameLen)})
+	}
+
+	if len(app.Contact) > maxShortLen {
+		fields = append(fields, FieldError{Field: "contact", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	if len(app.Notes) > maxDescriptionLen {
+		fields = append(fields, FieldError{Field: "notes", Message: fmt.Sprintf("cannot be longer than %v", maxDescriptionLen)})
+	}
+
+	if len(fields) != 0 {
+		return NewValidationError("invalid application", fields...)
+	}
+
+	return nil
+}
+
+// validateReason is used to check decision reason given by user
+func validateReason(reason string) error {
+	if len(reason) > maxDescriptionLen {
+		msg := fmt.Sprintf("cannot be longer than %v", maxDescriptionLen)
+		return NewValidationError("invalid decision", FieldError{Field: "reason", Message: msg})
+	}
+
+	return nil
+}
+
+// applicationNotFound is used to get not found error detail for application with given ID
+func applicationNotFound(id int) string {
+	return fmt.Sprintf("application %v not found", id)
+}
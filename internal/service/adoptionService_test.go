@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/repository"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_SubmitApplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		app      *model.Application
+		status   model.Status
+		goToRep  bool
+		wantKind error
+	}{
+		{
+			name:    "check submit",
+			app:     &model.Application{PetID: 1, Applicant: "John"},
+			status:  model.StatusAvailable,
+			goToRep: true,
+		},
+		{
+			name:     "check pet reserved",
+			app:      &model.Application{PetID: 1, Applicant: "John"},
+			status:   model.StatusReserved,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "check blank applicant",
+			app:      &model.Application{PetID: 1},
+			wantKind: ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.status != "" {
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1, Status: tt.status}, nil)
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().AddApplication(gomock.Any(), tt.app).DoAndReturn(func(_ context.Context, a *model.Application) error {
+					a.ID = 3
+					return nil
+				})
+			}
+
+			id, err := s.SubmitApplication(context.Background(), tt.app)
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+				require.Equal(t, 3, id)
+				require.Equal(t, model.ApplicationSubmitted, tt.app.Status)
+			} else {
+				require.ErrorIs(t, err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestService_DecideApplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name       string
+		from       model.ApplicationStatus
+		to         model.ApplicationStatus
+		pet        model.Status
+		repErr     error
+		goToRep    bool
+		transition *model.Transition
+		wantKind   error
+	}{
+		{
+			name:       "check approve",
+			from:       model.ApplicationSubmitted,
+			to:         model.ApplicationApproved,
+			pet:        model.StatusAvailable,
+			goToRep:    true,
+			transition: &model.Transition{From: model.StatusAvailable, To: model.StatusReserved},
+		},
+		{
+			name:     "check approve reserved pet",
+			from:     model.ApplicationSubmitted,
+			to:       model.ApplicationApproved,
+			pet:      model.StatusReserved,
+			wantKind: ErrConflict,
+		},
+		{
+			name:    "check reject submitted",
+			from:    model.ApplicationSubmitted,
+			to:      model.ApplicationRejected,
+			goToRep: true,
+		},
+		{
+			name:       "check reject approved",
+			from:       model.ApplicationApproved,
+			to:         model.ApplicationRejected,
+			pet:        model.StatusReserved,
+			goToRep:    true,
+			transition: &model.Transition{From: model.StatusReserved, To: model.StatusAvailable},
+		},
+		{
+			name:       "check complete",
+			from:       model.ApplicationApproved,
+			to:         model.ApplicationCompleted,
+			pet:        model.StatusReserved,
+			goToRep:    true,
+			transition: &model.Transition{From: model.StatusReserved, To: model.StatusAdopted},
+		},
+		{
+			name:     "check complete submitted",
+			from:     model.ApplicationSubmitted,
+			to:       model.ApplicationCompleted,
+			wantKind: ErrConflict,
+		},
+		{
+			name:       "check concurrent change",
+			from:       model.ApplicationSubmitted,
+			to:         model.ApplicationApproved,
+			pet:        model.StatusAvailable,
+			goToRep:    true,
+			repErr:     repository.ErrStale,
+			transition: &model.Transition{From: model.StatusAvailable, To: model.StatusReserved},
+			wantKind:   ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil).(*Service)
+
+			repMock.EXPECT().GetApplication(gomock.Any(), 2).Return(&model.Application{ID: 2, PetID: 1, Status: tt.from}, nil)
+
+			if tt.pet != "" {
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1, Status: tt.pet}, nil)
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().UpdateApplication(gomock.Any(), gomock.Any(), tt.from, gomock.Any()).DoAndReturn(
+					func(_ context.Context, a *model.Application, _ model.ApplicationStatus, tr *model.Transition) error {
+						require.Equal(t, tt.to, a.Status)
+
+						if tt.transition == nil {
+							require.Nil(t, tr)
+						} else {
+							require.Equal(t, tt.transition.From, tr.From)
+							require.Equal(t, tt.transition.To, tr.To)
+							require.Equal(t, 2, *tr.ApplicationID)
+						}
+
+						return tt.repErr
+					})
+			}
+
+			res, err := s.decideApplication(context.Background(), 2, tt.to, "reason")
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+				require.Equal(t, tt.to, res.Status)
+				require.Equal(t, "reason", res.Reason)
+			} else {
+				require.ErrorIs(t, err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestService_ReturnPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		status   model.Status
+		goToRep  bool
+		wantKind error
+	}{
+		{
+			name:    "check return",
+			status:  model.StatusAdopted,
+			goToRep: true,
+		},
+		{
+			name:     "check not adopted",
+			status:   model.StatusAvailable,
+			wantKind: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1, Status: tt.status}, nil)
+
+			if tt.goToRep {
+				want := &model.Transition{PetID: 1, From: model.StatusAdopted, To: model.StatusReturned}
+				repMock.EXPECT().TransitionPet(gomock.Any(), want).Return(nil)
+			}
+
+			_, err := s.ReturnPet(context.Background(), 1, "")
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantKind)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pets/internal/model"
+)
+
+// GetAudit is implementing IService.GetAudit function
+func (s *Service) GetAudit(ctx context.Context, filter *model.AuditFilter) ([]*model.AuditEntry, int, error) {
+	if filter.Action != "" && !filter.Action.Valid() {
+		msg := fmt.Sprintf("unknown action %q", filter.Action)
+		return nil, 0, NewValidationError("invalid audit filter", FieldError{Field: "action", Message: msg})
+	}
+
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		return nil, 0, NewValidationError("invalid audit filter", FieldError{Field: "until", Message: "should be after since"})
+	}
+
+	res, total, err := s.repository.GetAudit(ctx, filter)
+	if err != nil {
+		return nil, 0, domainError(err, "")
+	}
+
+	// history of the pet is empty only if the pet is not exist or filtered out
+	if filter.PetID != 0 && total == 0 {
+		if _, err = s.repository.GetPet(ctx, filter.PetID); err != nil {
+			return nil, 0, domainError(err, petNotFound(filter.PetID))
+		}
+	}
+
+	for _, e := range res {
+		e.SetLocal()
+	}
+
+	return res, total, nil
+}
+
+// RevertPet is implementing IService.RevertPet function. Fields changed by the client are replayed from the pet audit,
+// status, owner, tags and photos are kept as they are
+func (s *Service) RevertPet(ctx context.Context, pet *model.Pet, to int) (*model.Pet, error) {
+	var res *model.Pet
+
+	err := s.withTx(ctx, func(tx *Service) error {
+		stored, err := tx.GetPet(ctx, pet.ID)
+		if err != nil {
+			return err
+		}
+
+		if pet.Version != 0 && pet.Version != stored.Version {
+			return petChanged(pet.ID)
+		}
+
+		if to < 1 || to > stored.Version {
+			msg := fmt.Sprintf("should be from 1 to %v", stored.Version)
+			return NewValidationError("invalid version", FieldError{Field: "version", Message: msg})
+		}
+
+		entries, _, err := tx.repository.GetAudit(ctx, &model.AuditFilter{PetID: pet.ID})
+		if err != nil {
+			return domainError(err, "")
+		}
+
+		reverted, err := model.PetAtVersion(entries, to)
+		if err != nil {
+			return NewNotFoundError(fmt.Sprintf("pet %v version %v not found in history", pet.ID, to))
+		}
+
+		reverted.ID = pet.ID
+		reverted.Status = stored.Status
+		reverted.Version = pet.Version
+		reverted.SetDefaults()
+
+		if err = validatePet(reverted); err != nil {
+			return err
+		}
+
+		if fields := stored.Changes(reverted); len(fields) != 0 {
+			if err = tx.repository.RevertPet(ctx, reverted, fields); err != nil {
+				return petWriteError(err, pet.ID)
+			}
+		}
+
+		res, err = tx.GetPet(ctx, pet.ID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	"pets/internal/repository"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_GetAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		filter *model.AuditFilter
+
+		repEntries []*model.AuditEntry
+		repTotal   int
+		getErr     error
+
+		wantTotal int
+		wantKind  error
+	}{
+		{
+			name:       "check entries",
+			filter:     &model.AuditFilter{Actor: "alice", Action: model.AuditUpdate, Since: &hourAgo, Until: &now},
+			repEntries: []*model.AuditEntry{{ID: 2, PetID: 1, Version: 2}, {ID: 1, PetID: 1, Version: 1}},
+			repTotal:   2,
+			wantTotal:  2,
+		},
+		{
+			name:     "check unknown action",
+			filter:   &model.AuditFilter{Action: "rename"},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check until before since",
+			filter:   &model.AuditFilter{Since: &now, Until: &hourAgo},
+			wantKind: ErrValidation,
+		},
+		{
+			name:   "check pet without matching entries",
+			filter: &model.AuditFilter{PetID: 1, Action: model.AuditRevert},
+		},
+		{
+			name:     "check pet not found",
+			filter:   &model.AuditFilter{PetID: 1},
+			getErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.filter.Action == "" || tt.filter.Action.Valid() {
+				if tt.filter.Since == nil || tt.filter.Since.Before(*tt.filter.Until) {
+					repMock.EXPECT().GetAudit(gomock.Any(), tt.filter).Return(tt.repEntries, tt.repTotal, nil)
+				}
+			}
+
+			if tt.filter.PetID != 0 && tt.repTotal == 0 {
+				repMock.EXPECT().GetPet(gomock.Any(), tt.filter.PetID).Return(&model.Pet{ID: 1}, tt.getErr)
+			}
+
+			res, total, err := s.GetAudit(context.Background(), tt.filter)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTotal, total)
+			require.Len(t, res, len(tt.repEntries))
+		})
+	}
+}
+
+func TestService_RevertPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+	expectTx(repMock)
+
+	created := &model.Pet{ID: 1, Version: 1, Name: "Velho", Species: model.SpeciesDog, Sex: model.SexMale, Status: model.StatusAvailable}
+	renamed := &model.Pet{ID: 1, Version: 2, Name: "Bobik", Species: model.SpeciesDog, Sex: model.SexMale, Status: model.StatusAvailable}
+
+	createDiff, err := model.NewDiff(nil, created)
+	require.NoError(t, err)
+
+	renameDiff, err := model.NewDiff(created, renamed)
+	require.NoError(t, err)
+
+	entries := []*model.AuditEntry{
+		{ID: 2, PetID: 1, Version: 2, Action: model.AuditUpdate, Diff: renameDiff},
+		{ID: 1, PetID: 1, Version: 1, Action: model.AuditCreate, Diff: createDiff},
+	}
+
+	tests := []struct {
+		name    string
+		version int
+		to      int
+		stored  *model.Pet
+		entries []*model.AuditEntry
+		repErr  error
+
+		wantFields []string
+		wantKind   error
+	}{
+		{
+			name:       "check reverted",
+			to:         1,
+			stored:     renamed,
+			entries:    entries,
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "check reverted with version",
+			version:    2,
+			to:         1,
+			stored:     renamed,
+			entries:    entries,
+			wantFields: []string{"name"},
+		},
+		{
+			name:    "check current version",
+			to:      2,
+			stored:  renamed,
+			entries: entries,
+		},
+		{
+			name:     "check stale version",
+			version:  1,
+			to:       1,
+			stored:   renamed,
+			wantKind: ErrPreconditionFailed,
+		},
+		{
+			name:     "check version out of range",
+			to:       3,
+			stored:   renamed,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check no history",
+			to:       1,
+			stored:   renamed,
+			entries:  entries[:1],
+			wantKind: ErrNotFound,
+		},
+		{
+			name:       "check changed concurrently",
+			version:    2,
+			to:         1,
+			stored:     renamed,
+			entries:    entries,
+			repErr:     repository.ErrStale,
+			wantFields: []string{"name"},
+			wantKind:   ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			stored := *tt.stored
+			repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&stored, nil)
+
+			if tt.entries != nil {
+				repMock.EXPECT().GetAudit(gomock.Any(), &model.AuditFilter{PetID: 1}).Return(tt.entries, len(tt.entries), nil)
+			}
+
+			if tt.wantFields != nil {
+				repMock.EXPECT().RevertPet(gomock.Any(), gomock.Any(), tt.wantFields).
+					DoAndReturn(func(_ context.Context, pet *model.Pet, _ []string) error {
+						require.Equal(t, "Velho", pet.Name)
+						require.Equal(t, tt.version, pet.Version)
+						return tt.repErr
+					})
+			}
+
+			if tt.wantKind == nil {
+				reverted := *tt.stored
+				if tt.wantFields != nil {
+					reverted.Name = "Velho"
+					reverted.Version = 3
+				}
+
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&reverted, nil)
+			}
+
+			res, err := s.RevertPet(context.Background(), &model.Pet{ID: 1, Version: tt.version}, tt.to)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, model.StatusAvailable, res.Status)
+
+			if tt.wantFields != nil {
+				require.Equal(t, "Velho", res.Name)
+			}
+		})
+	}
+}
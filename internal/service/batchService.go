@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pets/internal/model"
+)
+
+// MaxBatchSize is a max number of items of a single batch
+const MaxBatchSize = 1000
+
+// errBatchFailed is returned by atomic batch transaction function to roll back the batch if any item failed
+var errBatchFailed = errors.New("batch item failed")
+
+// CheckBatchSize is used to check the number of batch items. Will return ErrValidation kind error if batch is empty,
+// ErrTooLarge kind error if it has more than MaxBatchSize items
+func CheckBatchSize(n int) error {
+	if n == 0 {
+		return NewValidationError("batch is empty", FieldError{Field: "items", Message: "cannot be empty"})
+	}
+
+	if n > MaxBatchSize {
+		return NewTooLargeError(fmt.Sprintf("batch cannot have more than %v items, got %v", MaxBatchSize, n))
+	}
+
+	return nil
+}
+
+// AddPets is implementing IService.AddPets function. Valid pets are added by a single repository call
+func (s *Service) AddPets(ctx context.Context, pets []*model.Pet, atomic bool) ([]error, error) {
+	if err := CheckBatchSize(len(pets)); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(pets))
+	valid := make([]*model.Pet, 0, len(pets))
+
+	for i, p := range pets {
+		if errs[i] = checkNewPet(p); errs[i] == nil {
+			valid = append(valid, p)
+		}
+	}
+
+	if len(valid) != len(pets) && atomic {
+		return notApplied(errs), nil
+	}
+
+	if len(valid) == 0 {
+		return errs, nil
+	}
+
+	if err := s.repository.AddPets(ctx, valid); err != nil {
+		return nil, domainError(err, "")
+	}
+
+	return errs, nil
+}
+
+// PatchPets is implementing IService.PatchPets function
+func (s *Service) PatchPets(ctx context.Context, patches []*model.PetPatch, atomic bool) ([]error, error) {
+	return s.runBatch(ctx, len(patches), atomic, func(tx *Service, i int) error {
+		return tx.PatchPet(ctx, patches[i].Pet, patches[i].Fields)
+	})
+}
+
+// DeletePets is implementing IService.DeletePets function
+func (s *Service) DeletePets(ctx context.Context, pets []*model.Pet, atomic bool) ([]error, error) {
+	return s.runBatch(ctx, len(pets), atomic, func(tx *Service, i int) error {
+		return tx.DeletePet(ctx, pets[i])
+	})
+}
+
+// runBatch is used to apply n batch items with given apply function. Atomic batch is applied in one transaction which
+// is rolled back on the first failed item, so other items get ErrNotApplied kind errors. Not domain errors fail the
+// whole atomic batch, so the transaction can be retried. Items of not atomic batch are applied one by one. Function
+// will return item errors in items order, nil for applied items
+func (s *Service) runBatch(ctx context.Context, n int, atomic bool, apply func(s *Service, i int) error) ([]error, error) {
+	if err := CheckBatchSize(n); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, n)
+
+	if !atomic {
+		for i := range errs {
+			errs[i] = apply(s, i)
+		}
+
+		return errs, nil
+	}
+
+	err := s.withTx(ctx, func(tx *Service) error {
+		for i := range errs {
+			if errs[i] = apply(tx, i); errs[i] != nil {
+				var e *Error
+				if !errors.As(errs[i], &e) {
+					return errs[i]
+				}
+
+				return errBatchFailed
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errBatchFailed) {
+		return notApplied(errs), nil
+	}
+
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	return errs, nil
+}
+
+// notApplied is used to set ErrNotApplied kind errors to items of a failed atomic batch which have no errors
+func notApplied(errs []error) []error {
+	for i, err := range errs {
+		if err == nil {
+			errs[i] = NewNotAppliedError()
+		}
+	}
+
+	return errs
+}
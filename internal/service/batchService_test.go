@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestCheckBatchSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		wantKind error
+	}{
+		{name: "check one", n: 1},
+		{name: "check max", n: MaxBatchSize},
+		{name: "check empty", n: 0, wantKind: ErrValidation},
+		{name: "check too many", n: MaxBatchSize + 1, wantKind: ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckBatchSize(tt.n)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_AddPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name   string
+		pets   []*model.Pet
+		atomic bool
+		repErr error
+
+		wantAdded int
+		wantKinds []error
+		wantErr   bool
+	}{
+		{
+			name:      "check atomic added",
+			pets:      []*model.Pet{{Name: "Velho"}, {Name: "Bobik"}},
+			atomic:    true,
+			wantAdded: 2,
+			wantKinds: []error{nil, nil},
+		},
+		{
+			name:      "check atomic invalid pet",
+			pets:      []*model.Pet{{Name: "Velho"}, {}},
+			atomic:    true,
+			wantKinds: []error{ErrNotApplied, ErrValidation},
+		},
+		{
+			name:      "check best effort invalid pet",
+			pets:      []*model.Pet{{Name: "Velho"}, {Name: "Bobik", Status: model.StatusAdopted}},
+			wantAdded: 1,
+			wantKinds: []error{nil, ErrValidation},
+		},
+		{
+			name:      "check best effort all invalid",
+			pets:      []*model.Pet{{}},
+			wantKinds: []error{ErrValidation},
+		},
+		{
+			name:      "check rep error",
+			pets:      []*model.Pet{{Name: "Velho"}},
+			atomic:    true,
+			repErr:    fmt.Errorf("rep error"),
+			wantAdded: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.wantAdded != 0 {
+				repMock.EXPECT().AddPets(gomock.Any(), gomock.Len(tt.wantAdded)).Return(tt.repErr)
+			}
+
+			errs, err := s.AddPets(context.Background(), tt.pets, tt.atomic)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, errs, len(tt.wantKinds))
+
+			for i, kind := range tt.wantKinds {
+				if kind == nil {
+					require.NoError(t, errs[i])
+					continue
+				}
+
+				require.ErrorIs(t, errs[i], kind)
+			}
+		})
+	}
+}
+
+func TestService_DeletePets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+	expectTx(repMock)
+
+	pets := []*model.Pet{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	tests := []struct {
+		name    string
+		atomic  bool
+		repErrs []error
+
+		wantKinds []error
+		wantErr   bool
+	}{
+		{
+			name:      "check atomic deleted",
+			atomic:    true,
+			repErrs:   []error{nil, nil, nil},
+			wantKinds: []error{nil, nil, nil},
+		},
+		{
+			name:      "check atomic not found",
+			atomic:    true,
+			repErrs:   []error{nil, sql.ErrNoRows},
+			wantKinds: []error{ErrNotApplied, ErrNotFound, ErrNotApplied},
+		},
+		{
+			name:      "check best effort not found",
+			repErrs:   []error{nil, sql.ErrNoRows, nil},
+			wantKinds: []error{nil, ErrNotFound, nil},
+		},
+		{
+			name:    "check atomic rep error",
+			atomic:  true,
+			repErrs: []error{fmt.Errorf("rep error")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			for i, repErr := range tt.repErrs {
+				repMock.EXPECT().DeletePet(gomock.Any(), pets[i]).Return(repErr)
+			}
+
+			errs, err := s.DeletePets(context.Background(), pets, tt.atomic)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, errs, len(tt.wantKinds))
+
+			for i, kind := range tt.wantKinds {
+				if kind == nil {
+					require.NoError(t, errs[i])
+					continue
+				}
+
+				require.ErrorIs(t, errs[i], kind)
+			}
+		})
+	}
+}
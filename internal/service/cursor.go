@@ -0,0 +1,62 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"pets/internal/model"
+)
+
+// cursor is a keyset pagination position. It is passed to clients as an opaque signed string
+type cursor struct {
+	// Sort is a pets sort order the cursor was issued for in model.ParseSort format
+	Sort string `json:"s,omitempty"`
+	// Key is the last seen pet position
+	Key model.PetsKey `json:"k"`
+}
+
+// encodeCursor is used to get opaque signed string of given cursor in "payload.signature" format
+func (s *Service) encodeCursor(c *cursor) string {
+	b, _ := json.Marshal(c)
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// decodeCursor is used to get cursor from given string made by encodeCursor. Will return ErrInvalidCursor if string is
+// malformed or signature is wrong
+func (s *Service) decodeCursor(str string) (*cursor, error) {
+	payload, sig, ok := strings.Cut(str, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(b, s.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	b, err = base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &cursor{}
+	if err = json.Unmarshal(b, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// sign is used to get HMAC-SHA256 signature of given payload with Service cursor secret
+func (s *Service) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.cursorSecret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"pets/internal/repository"
+)
+
+// Domain error kinds. Errors returned by IService can be checked with errors.Is against them and converted to *Error
+// with errors.As to get details
+var (
+	// ErrNotFound is a kind of errors returned if requested entity does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrValidation is a kind of errors returned if given input is invalid
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict is a kind of errors returned if request conflicts with the current entity state
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable is a kind of errors returned if storage is temporarily unavailable
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrTooLarge is a kind of errors returned if given content exceeds a size limit
+	ErrTooLarge = errors.New("content too large")
+	// ErrUnsupportedMedia is a kind of errors returned if given content type is not supported
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	// ErrNotAcceptable is a kind of errors returned if response cannot be given in any content type accepted by the
+	// client
+	ErrNotAcceptable = errors.New("not acceptable")
+	// ErrPreconditionFailed is a kind of errors returned if entity version given by the client does not match the
+	// current one
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrNotApplied is a kind of errors returned for items of an atomic batch which were not applied because other
+	// item failed
+	ErrNotApplied = errors.New("not applied")
+)
+
+// ErrInvalidCursor is returned if given pagination cursor is malformed or its signature is wrong
+var ErrInvalidCursor = NewValidationError("invalid cursor", FieldError{Field: "cursor", Message: "invalid cursor"})
+
+// Error is a typed domain error
+type Error struct {
+	// Kind is one of ErrNotFound, ErrValidation, ErrConflict, ErrUnavailable, ErrTooLarge, ErrUnsupportedMedia,
+	// ErrNotAcceptable, ErrPreconditionFailed or ErrNotApplied
+	Kind error
+	// Detail is a human-readable explanation of the error
+	Detail string
+	// Fields is a list of invalid fields of ErrValidation error
+	Fields []FieldError
+	// cause is an underlying error
+	cause error
+}
+
+// FieldError is a validation error of a single input field
+type FieldError struct {
+	// Field is an invalid field name
+	Field string
+	// Message is a human-readable explanation why the field is invalid
+	Message string
+}
+
+// NewNotFoundError is used to get new ErrNotFound kind error with given detail
+func NewNotFoundError(detail string) error {
+	return &Error{Kind: ErrNotFound, Detail: detail}
+}
+
+// NewValidationError is used to get new ErrValidation kind error with given detail and invalid fields
+func NewValidationError(detail string, fields ...FieldError) error {
+	return &Error{Kind: ErrValidation, Detail: detail, Fields: fields}
+}
+
+// NewConflictError is used to get new ErrConflict kind error with given detail
+func NewConflictError(detail string) error {
+	return &Error{Kind: ErrConflict, Detail: detail}
+}
+
+// NewTooLargeError is used to get new ErrTooLarge kind error with given detail
+func NewTooLargeError(detail string) error {
+	return &Error{Kind: ErrTooLarge, Detail: detail}
+}
+
+// NewUnsupportedMediaError is used to get new ErrUnsupportedMedia kind error with given detail
+func NewUnsupportedMediaError(detail string) error {
+	return &Error{Kind: ErrUnsupportedMedia, Detail: detail}
+}
+
+// NewNotAcceptableError is used to get new ErrNotAcceptable kind error with given detail
+func NewNotAcceptableError(detail string) error {
+	return &Error{Kind: ErrNotAcceptable, Detail: detail}
+}
+
+// NewPreconditionFailedError is used to get new ErrPreconditionFailed kind error with given detail
+func NewPreconditionFailedError(detail string) error {
+	return &Error{Kind: ErrPreconditionFailed, Detail: detail}
+}
+
+// NewNotAppliedError is used to get new ErrNotApplied kind error of a batch item not applied because other item failed
+func NewNotAppliedError() error {
+	return &Error{Kind: ErrNotApplied, Detail: "not applied because other batch item failed"}
+}
+
+// NewUnavailableError is used to get new ErrUnavailable kind error caused by given error
+func NewUnavailableError(cause error) error {
+	return &Error{Kind: ErrUnavailable, Detail: "storage is unavailable, try again later", cause: cause}
+}
+
+// Error is implementing error interface
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Detail + ": " + e.cause.Error()
+	}
+
+	return e.Detail
+}
+
+// Unwrap is used to match Error with its kind and cause in errors.Is and errors.As
+func (e *Error) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.Kind}
+	}
+
+	return []error{e.Kind, e.cause}
+}
+
+// domainError is used to convert given repository error to a domain error. sql.ErrNoRows is converted to ErrNotFound
+// kind error with given notFound detail, repository.ErrStale to ErrConflict, timeouts and connection errors to
+// ErrUnavailable. Other errors are returned as is
+func domainError(err error, notFound string) error {
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return NewNotFoundError(notFound)
+	case errors.Is(err, repository.ErrStale):
+		return &Error{Kind: ErrConflict, Detail: "entity was changed concurrently, try again", cause: err}
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone),
+		errors.As(err, &netErr):
+		return NewUnavailableError(err)
+	}
+
+	return err
+}
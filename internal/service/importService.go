@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pets/internal/model"
+)
+
+// importChunk is a number of valid import rows added by a single repository call
+const importChunk = 500
+
+// maxImportLine is a max length of NDJSON import line in bytes
+const maxImportLine = 1 << 20
+
+// importReader is a reader of import rows
+type importReader interface {
+	// next is used to read the next row as a map of model.ImportFields to their values. Will return io.EOF after the
+	// last row, *rowError if the row is invalid
+	next() (line int, row map[string]string, err error)
+	// ignored is used to get column names which are not mapped to pet fields
+	ignored() []string
+}
+
+// rowError is an error of an invalid import row, the row is rejected and reading continues
+type rowError struct {
+	line   int
+	reject *model.ImportReject
+}
+
+// Error is implementing error interface
+func (e *rowError) Error() string {
+	return fmt.Sprintf("line %v: %v", e.line, e.reject.Reason)
+}
+
+// ImportPets is implementing IService.ImportPets function
+func (s *Service) ImportPets(ctx context.Context, r io.Reader, opts *model.ImportOptions) (*model.ImportReport, error) {
+	for col, field := range opts.Columns {
+		if !slices.Contains(model.ImportFields, field) {
+			msg := fmt.Sprintf("column %q is mapped to unknown field %q", col, field)
+			return nil, NewValidationError(msg, FieldError{Field: "columns", Message: msg})
+		}
+	}
+
+	var rows importReader
+
+	switch opts.Format {
+	case model.ImportCSV:
+		cr, err := newCSVImport(r, opts.Columns)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = cr
+	case model.ImportNDJSON:
+		rows = newNDJSONImport(r, opts.Columns)
+	default:
+		msg := fmt.Sprintf("unknown format %q, should be %v or %v", opts.Format, model.ImportCSV, model.ImportNDJSON)
+		return nil, NewValidationError(msg, FieldError{Field: "format", Message: msg})
+	}
+
+	report := &model.ImportReport{Format: opts.Format, DryRun: opts.DryRun, Rejects: []*model.ImportReject{}}
+	chunk := make([]*model.Pet, 0, importChunk)
+	// chunkLine is a line of the last row of the chunk
+	chunkLine := 0
+
+	add := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		if !opts.DryRun {
+			if err := s.repository.AddPets(ctx, chunk); err != nil {
+				return domainError(err, "")
+			}
+		}
+
+		report.Imported += len(chunk)
+		report.CommittedLine = chunkLine
+		chunk = make([]*model.Pet, 0, importChunk)
+
+		return nil
+	}
+
+	for {
+		line, row, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *rowError
+		if errors.As(err, &rowErr) {
+			report.Total++
+			report.Reject(rowErr.line, rowErr.reject)
+
+			continue
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		report.Total++
+
+		pet, rejects := importPet(row)
+		if rejects != nil {
+			report.Reject(line, rejects...)
+			continue
+		}
+
+		chunkLine = line
+
+		if chunk = append(chunk, pet); len(chunk) == importChunk {
+			if err = add(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := add(); err != nil {
+		return report, err
+	}
+
+	report.IgnoredColumns = rows.ignored()
+
+	return report, nil
+}
+
+// importPet is used to get a new pet from given import row. Will return rejects of invalid row fields
+func importPet(row map[string]string) (*model.Pet, []*model.ImportReject) {
+	pet := &model.Pet{
+		Name:        row["name"],
+		Species:     model.Species(row["species"]),
+		Breed:       row["breed"],
+		Sex:         model.Sex(row["sex"]),
+		Color:       row["color"],
+		Description: row["description"],
+		Status:      model.Status(row["status"]),
+	}
+
+	var rejects []*model.ImportReject
+
+	if v := row["birth_date"]; v != "" {
+		d, err := model.ParseDate(v)
+		if err != nil {
+			rejects = append(rejects, &model.ImportReject{Field: "birth_date", Reason: err.Error()})
+		}
+
+		pet.BirthDate = &d
+	}
+
+	if v := row["neutered"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			rejects = append(rejects, &model.ImportReject{Field: "neutered", Reason: fmt.Sprintf("invalid boolean %q", v)})
+		}
+
+		pet.Neutered = b
+	}
+
+	if v := row["weight"]; v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			rejects = append(rejects, &model.ImportReject{Field: "weight", Reason: fmt.Sprintf("invalid number %q", v)})
+		}
+
+		pet.Weight = &f
+	}
+
+	if rejects != nil {
+		return nil, rejects
+	}
+
+	var e *Error
+	if err := checkNewPet(pet); errors.As(err, &e) {
+		for _, f := range e.Fields {
+			rejects = append(rejects, &model.ImportReject{Field: f.Field, Reason: f.Message})
+		}
+
+		if rejects == nil {
+			rejects = append(rejects, &model.ImportReject{Reason: e.Detail})
+		}
+
+		return nil, rejects
+	}
+
+	return pet, nil
+}
+
+// importField is used to get a pet field the import column with given name is mapped to. Columns named as
+// model.ImportFields are mapped to them if they are not in given columns map. Will return blank field if the column is
+// not mapped
+func importField(columns map[string]string, name string) string {
+	if field, ok := columns[name]; ok {
+		return field
+	}
+
+	if field := strings.ToLower(name); slices.Contains(model.ImportFields, field) {
+		return field
+	}
+
+	return ""
+}
+
+// csvImport is an importReader of CSV data with a header row
+type csvImport struct {
+	r *csv.Reader
+	// fields are pet fields of the columns, blank for ignored columns
+	fields []string
+	// skipped are names of ignored columns
+	skipped []string
+}
+
+// newCSVImport is used to get new csvImport reading given reader. Header row is read to map columns to pet fields.
+// Will return ErrValidation kind error if the header is invalid, has no name column or has several columns of a field
+func newCSVImport(r io.Reader, columns map[string]string) (*csvImport, error) {
+	c := &csvImport{r: csv.NewReader(r)}
+	c.r.ReuseRecord = true
+	// rows with wrong number of columns are rejected by next
+	c.r.FieldsPerRecord = -1
+
+	header, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, NewValidationError("import data is empty", FieldError{Field: "body", Message: "cannot be empty"})
+	}
+
+	if err != nil {
+		return nil, NewValidationError(fmt.Sprintf("invalid CSV header: %v", err.Error()), FieldError{Field: "body", Message: "invalid CSV header"})
+	}
+
+	c.fields = make([]string, len(header))
+
+	for i, name := range header {
+		if i == 0 {
+			// UTF-8 byte order mark of the files exported by spreadsheets
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+
+		name = strings.TrimSpace(name)
+
+		field := importField(columns, name)
+		if field == "" {
+			c.skipped = append(c.skipped, name)
+			continue
+		}
+
+		if slices.Contains(c.fields, field) {
+			msg := fmt.Sprintf("several columns are mapped to field %v", field)
+			return nil, NewValidationError(msg, FieldError{Field: field, Message: msg})
+		}
+
+		c.fields[i] = field
+	}
+
+	if !slices.Contains(c.fields, "name") {
+		return nil, NewValidationError("CSV header has no name column", FieldError{Field: "name", Message: "column is required"})
+	}
+
+	return c, nil
+}
+
+// next is implementing importReader.next function
+func (c *csvImport) next() (int, map[string]string, error) {
+	record, err := c.r.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return 0, nil, &rowError{line: parseErr.StartLine, reject: &model.ImportReject{Reason: parseErr.Err.Error()}}
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+
+	if len(record) != len(c.fields) {
+		reason := fmt.Sprintf("row has %v columns, header has %v", len(record), len(c.fields))
+		return 0, nil, &rowError{line: line, reject: &model.ImportReject{Reason: reason}}
+	}
+
+	row := make(map[string]string, len(c.fields))
+
+	for i, field := range c.fields {
+		if field != "" {
+			row[field] = strings.TrimSpace(record[i])
+		}
+	}
+
+	return line, row, nil
+}
+
+// ignored is implementing importReader.ignored function
+func (c *csvImport) ignored() []string {
+	return c.skipped
+}
+
+// ndjsonImport is an importReader of newline delimited JSON data with a pet object per line
+type ndjsonImport struct {
+	s       *bufio.Scanner
+	columns map[string]string
+	line    int
+	// skipped are names of ignored keys in the order they were read
+	skipped []string
+}
+
+// newNDJSONImport is used to get new ndjsonImport reading given reader. Keys are mapped to pet fields by given
+// columns map as CSV columns
+func newNDJSONImport(r io.Reader, columns map[string]string) *ndjsonImport {
+	n := &ndjsonImport{s: bufio.NewScanner(r), columns: columns}
+	n.s.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportLine)
+
+	return n
+}
+
+// next is implementing importReader.next function. Blank lines are skipped. Will return ErrTooLarge kind error if
+// the line is longer than maxImportLine
+func (n *ndjsonImport) next() (int, map[string]string, error) {
+	var data []byte
+
+	for len(data) == 0 {
+		if !n.s.Scan() {
+			if errors.Is(n.s.Err(), bufio.ErrTooLong) {
+				return 0, nil, NewTooLargeError(fmt.Sprintf("line %v is longer than %v bytes", n.line+1, maxImportLine))
+			}
+
+			if n.s.Err() != nil {
+				return 0, nil, n.s.Err()
+			}
+
+			return 0, nil, io.EOF
+		}
+
+		n.line++
+		data = bytes.TrimSpace(n.s.Bytes())
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var obj map[string]interface{}
+	if err := d.Decode(&obj); err != nil || obj == nil || d.More() {
+		return 0, nil, &rowError{line: n.line, reject: &model.ImportReject{Reason: "row should be a JSON object"}}
+	}
+
+	row := make(map[string]string, len(obj))
+
+	for key, v := range obj {
+		field := importField(n.columns, key)
+		if field == "" {
+			if !slices.Contains(n.skipped, key) {
+				n.skipped = append(n.skipped, key)
+			}
+
+			continue
+		}
+
+		switch v := v.(type) {
+		case nil:
+		case string:
+			row[field] = strings.TrimSpace(v)
+		case json.Number:
+			row[field] = v.String()
+		case bool:
+			row[field] = strconv.FormatBool(v)
+		default:
+			reason := fmt.Sprintf("%v should be a string, number or boolean", key)
+			return 0, nil, &rowError{line: n.line, reject: &model.ImportReject{Field: field, Reason: reason}}
+		}
+	}
+
+	return n.line, row, nil
+}
+
+// ignored is implementing importReader.ignored function
+func (n *ndjsonImport) ignored() []string {
+	return n.skipped
+}
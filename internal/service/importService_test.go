@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_ImportPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name   string
+		data   string
+		opts   *model.ImportOptions
+		repErr error
+
+		wantAdded  int
+		wantReport *model.ImportReport
+		wantKind   error
+		wantErr    bool
+	}{
+		{
+			name: "check csv",
+			data: "\ufeffName,Species,birth_date,neutered,weight,shelter\n" +
+				"Velho,dog,2020-01-02,yes,12.5,north\n" +
+				"Bobik,cat,,true,,south\n" +
+				",dog,,,,\n" +
+				"Murka,fish,2020-13-01,,heavy,east\n" +
+				"Short,dog\n" +
+				"\"Bad\"quote,dog,,,,\n",
+			opts:      &model.ImportOptions{Format: model.ImportCSV},
+			wantAdded: 1,
+			wantReport: &model.ImportReport{
+				Format: model.ImportCSV, Total: 6, Imported: 1, CommittedLine: 3, Rejected: 5, IgnoredColumns: []string{"shelter"},
+				Rejects: []*model.ImportReject{
+					{Line: 2, Field: "neutered", Reason: `invalid boolean "yes"`},
+					{Line: 4, Field: "name", Reason: "cannot be blank"},
+					{Line: 5, Field: "birth_date", Reason: "should be a date in YYYY-MM-DD format"},
+					{Line: 5, Field: "weight", Reason: `invalid number "heavy"`},
+					{Line: 6, Reason: "row has 2 columns, header has 6"},
+					{Line: 7, Reason: `extraneous or missing " in quoted-field`},
+				},
+			},
+		},
+		{
+			name:      "check csv column mapping",
+			data:      "Pet name,Kind\nVelho,dog\n",
+			opts:      &model.ImportOptions{Format: model.ImportCSV, Columns: map[string]string{"Pet name": "name", "Kind": "species"}},
+			wantAdded: 1,
+			wantReport: &model.ImportReport{
+				Format: model.ImportCSV, Total: 1, Imported: 1, CommittedLine: 2, Rejects: []*model.ImportReject{},
+			},
+		},
+		{
+			name: "check ndjson",
+			data: `{"name":"Velho","species":"dog","weight":12.5,"neutered":true,"shelter":"north"}` + "\n\n" +
+				`{"name":"Bobik","species":"parrot"}` + "\n" +
+				`["Murka"]` + "\n" +
+				`{"name":{"first":"Murka"}}` + "\n",
+			opts:      &model.ImportOptions{Format: model.ImportNDJSON},
+			wantAdded: 1,
+			wantReport: &model.ImportReport{
+				Format: model.ImportNDJSON, Total: 4, Imported: 1, CommittedLine: 1, Rejected: 3, IgnoredColumns: []string{"shelter"},
+				Rejects: []*model.ImportReject{
+					{Line: 3, Field: "species", Reason: `unknown species "parrot"`},
+					{Line: 4, Reason: "row should be a JSON object"},
+					{Line: 5, Field: "name", Reason: "name should be a string, number or boolean"},
+				},
+			},
+		},
+		{
+			name: "check dry run",
+			data: `{"name":"Velho"}`,
+			opts: &model.ImportOptions{Format: model.ImportNDJSON, DryRun: true},
+			wantReport: &model.ImportReport{
+				Format: model.ImportNDJSON, DryRun: true, Total: 1, Imported: 1, CommittedLine: 1, Rejects: []*model.ImportReject{},
+			},
+		},
+		{
+			name:     "check csv without name column",
+			data:     "species\ndog\n",
+			opts:     &model.ImportOptions{Format: model.ImportCSV},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check csv empty",
+			opts:     &model.ImportOptions{Format: model.ImportCSV},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check unknown mapped field",
+			data:     "name\nVelho\n",
+			opts:     &model.ImportOptions{Format: model.ImportCSV, Columns: map[string]string{"name": "nickname"}},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check ndjson line too long",
+			data:     `{"name":"` + strings.Repeat("a", maxImportLine) + `"}`,
+			opts:     &model.ImportOptions{Format: model.ImportNDJSON},
+			wantKind: ErrTooLarge,
+		},
+		{
+			name:      "check rep error",
+			data:      `{"name":"Velho"}`,
+			opts:      &model.ImportOptions{Format: model.ImportNDJSON},
+			repErr:    fmt.Errorf("rep error"),
+			wantAdded: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.wantAdded != 0 {
+				repMock.EXPECT().AddPets(gomock.Any(), gomock.Len(tt.wantAdded)).Return(tt.repErr)
+			}
+
+			report, err := s.ImportPets(context.Background(), strings.NewReader(tt.data), tt.opts)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReport, report)
+		})
+	}
+}
+
+func TestService_ImportPetsChunks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	data := "name\n" + strings.Repeat("Velho\n", importChunk+1)
+
+	tests := []struct {
+		name   string
+		repErr error
+
+		wantImported int
+		wantLine     int
+		wantErr      bool
+	}{
+		{
+			name:         "check chunks",
+			wantImported: importChunk + 1,
+			wantLine:     importChunk + 2,
+		},
+		{
+			name:         "check partial report",
+			repErr:       fmt.Errorf("rep error"),
+			wantImported: importChunk,
+			wantLine:     importChunk + 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			gomock.InOrder(
+				repMock.EXPECT().AddPets(gomock.Any(), gomock.Len(importChunk)).Return(nil),
+				repMock.EXPECT().AddPets(gomock.Any(), gomock.Len(1)).Return(tt.repErr),
+			)
+
+			report, err := s.ImportPets(context.Background(), strings.NewReader(data), &model.ImportOptions{Format: model.ImportCSV})
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, importChunk+1, report.Total)
+			require.Equal(t, tt.wantImported, report.Imported)
+			require.Equal(t, tt.wantLine, report.CommittedLine)
+		})
+	}
+}
+
+func TestImportReport_Reject(t *testing.T) {
+	report := &model.ImportReport{}
+
+	for i := 0; i < model.MaxImportRejects+1; i++ {
+		report.Reject(i+2, &model.ImportReject{Reason: "invalid"})
+	}
+
+	require.Equal(t, model.MaxImportRejects+1, report.Rejected)
+	require.Len(t, report.Rejects, model.MaxImportRejects)
+	require.True(t, report.RejectsTruncated)
+}
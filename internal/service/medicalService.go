@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pets/internal/model"
+)
+
+// maxReferenceLen is a max length of medical record attachment reference
+const maxReferenceLen = 500
+
+// GetVaccinations is implementing IService.GetVaccinations function
+func (s *Service) GetVaccinations(ctx context.Context, petID int) ([]*model.Vaccination, error) {
+	if _, err := s.GetPet(ctx, petID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.GetVaccinations(ctx, petID)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, v := range res {
+		v.SetLocal()
+	}
+
+	return res, nil
+}
+
+// GetVaccination is implementing IService.GetVaccination function
+func (s *Service) GetVaccination(ctx context.Context, petID int, id int) (*model.Vaccination, error) {
+	res, err := s.repository.GetVaccination(ctx, petID, id)
+	if err != nil {
+		return nil, domainError(err, recordNotFound("vaccination", petID, id))
+	}
+
+	res.SetLocal()
+
+	return res, nil
+}
+
+// AddVaccination is implementing IService.AddVaccination function
+func (s *Service) AddVaccination(ctx context.Context, vaccination *model.Vaccination) (int, error) {
+	if err := validateVaccination(vaccination); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.GetPet(ctx, vaccination.PetID); err != nil {
+		return 0, err
+	}
+
+	if err := s.repository.AddVaccination(ctx, vaccination); err != nil {
+		return 0, domainError(err, "")
+	}
+
+	return vaccination.ID, nil
+}
+
+// UpdateVaccination is implementing IService.UpdateVaccination function
+func (s *Service) UpdateVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	if err := validateVaccination(vaccination); err != nil {
+		return err
+	}
+
+	err := s.repository.UpdateVaccination(ctx, vaccination)
+
+	return domainError(err, recordNotFound("vaccination", vaccination.PetID, vaccination.ID))
+}
+
+// DeleteVaccination is implementing IService.DeleteVaccination function
+func (s *Service) DeleteVaccination(ctx context.Context, vaccination *model.Vaccination) error {
+	err := s.repository.DeleteVaccination(ctx, vaccination)
+
+	return domainError(err, recordNotFound("vaccination", vaccination.PetID, vaccination.ID))
+}
+
+// GetDueVaccinations is implementing IService.GetDueVaccinations function
+func (s *Service) GetDueVaccinations(ctx context.Context, before model.Date) ([]*model.DueVaccination, error) {
+	res, err := s.repository.GetDueVaccinations(ctx, before)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	today := today()
+
+	for _, v := range res {
+		v.SetLocal()
+		v.Overdue = v.DueOn.Before(today.Time)
+	}
+
+	return res, nil
+}
+
+// GetTreatments is implementing IService.GetTreatments function
+func (s *Service) GetTreatments(ctx context.Context, petID int) ([]*model.Treatment, error) {
+	if _, err := s.GetPet(ctx, petID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.GetTreatments(ctx, petID)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, t := range res {
+		t.SetLocal()
+	}
+
+	return res, nil
+}
+
+// GetTreatment is implementing IService.GetTreatment function
+func (s *Service) GetTreatment(ctx context.Context, petID int, id int) (*model.Treatment, error) {
+	res, err := s.repository.GetTreatment(ctx, petID, id)
+	if err != nil {
+		return nil, domainError(err, recordNotFound("treatment", petID, id))
+	}
+
+	res.SetLocal()
+
+	return res, nil
+}
+
+// AddTreatment is implementing IService.AddTreatment function
+func (s *Service) AddTreatment(ctx context.Context, treatment *model.Treatment) (int, error) {
+	if err := validateTreatment(treatment); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.GetPet(ctx, treatment.PetID); err != nil {
+		return 0, err
+	}
+
+	if err := s.repository.AddTreatment(ctx, treatment); err != nil {
+		return 0, domainError(err, "")
+	}
+
+	return treatment.ID, nil
+}
+
+// UpdateTreatment is implementing IService.UpdateTreatment function
+func (s *Service) UpdateTreatment(ctx context.Context, treatment *model.Treatment) error {
+	if err := validateTreatment(treatment); err != nil {
+		return err
+	}
+
+	err := s.repository.UpdateTreatment(ctx, treatment)
+
+	return domainError(err, recordNotFound("treatment", treatment.PetID, treatment.ID))
+}
+
+// DeleteTreatment is implementing IService.DeleteTreatment function
+func (s *Service) DeleteTreatment(ctx context.Context, treatment *model.Treatment) error {
+	err := s.repository.DeleteTreatment(ctx, treatment)
+
+	return domainError(err, recordNotFound("treatment", treatment.PetID, treatment.ID))
+}
+
+// validateVaccination is used to check vaccination fields given by user. Will return ErrValidation kind error with all
+// invalid fields
+func validateVaccination(v *model.Vaccination) error {
+	var fields []FieldError
+
+	if v.Vaccine == "" {
+		fields = append(fields, FieldError{Field: "vaccine", Message: "cannot be blank"})
+	}
+
+	if len(v.Vaccine) > maxShortLen {
+		fields = append(fields, FieldError{Field: "vaccine", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	fields = append(fields, validateGivenOn(v.GivenOn)...)
+
+	if v.DueOn != nil && !v.GivenOn.IsZero() && v.DueOn.Before(v.GivenOn.Time) {
+		fields = append(fields, FieldError{Field: "due_on", Message: "cannot be before given_on"})
+	}
+
+	fields = append(fields, validateRecordText(v.Vet, v.Notes, v.Attachment)...)
+
+	if len(fields) != 0 {
+		return NewValidationError("invalid vaccination", fields...)
+	}
+
+	return nil
+}
+
+// validateTreatment is used to check treatment fields given by user. Blank kind is set to model.TreatmentOther. Will
+// return ErrValidation kind error with all invalid fields
+func validateTreatment(t *model.Treatment) error {
+	var fields []FieldError
+
+	if t.Kind == "" {
+		t.Kind = model.TreatmentOther
+	}
+
+	if !t.Kind.Valid() {
+		fields = append(fields, FieldError{Field: "kind", Message: fmt.Sprintf("unknown kind %q", t.Kind)})
+	}
+
+	if t.Name == "" {
+		fields = append(fields, FieldError{Field: "name", Message: "cannot be blank"})
+	}
+
+	if len(t.Name) > maxShortLen {
+		fields = append(fields, FieldError{Field: "name", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	fields = append(fields, validateGivenOn(t.GivenOn)...)
+	fields = append(fields, validateRecordText(t.Vet, t.Notes, t.Attachment)...)
+
+	if len(fields) != 0 {
+		return NewValidationError("invalid treatment", fields...)
+	}
+
+	return nil
+}
+
+// validateGivenOn is used to check medical record date. It is required and cannot be in the future
+func validateGivenOn(givenOn model.Date) []FieldError {
+	if givenOn.IsZero() {
+		return []FieldError{{Field: "given_on", Message: "cannot be blank"}}
+	}
+
+	if givenOn.After(today().Time) {
+		return []FieldError{{Field: "given_on", Message: "cannot be in the future"}}
+	}
+
+	return nil
+}
+
+// validateRecordText is used to check medical record vet, notes and attachment lengths
+func validateRecordText(vet string, notes string, attachment string) []FieldError {
+	var fields []FieldError
+
+	if len(vet) > maxShortLen {
+		fields = append(fields, FieldError{Field: "vet", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	if len(notes) > maxDescriptionLen {
+		fields = append(fields, FieldError{Field: "notes", Message: fmt.Sprintf("cannot be longer than %v", maxDescriptionLen)})
+	}
+
+	if len(attachment) > maxReferenceLen {
+		fields = append(fields, FieldError{Field: "attachment", Message: fmt.Sprintf("cannot be longer than %v", maxReferenceLen)})
+	}
+
+	return fields
+}
+
+// today is used to get current local date
+func today() model.Date {
+	now := time.Now()
+	return model.NewDate(now.Year(), now.Month(), now.Day())
+}
+
+// recordNotFound is used to get not found error detail for pet medical record of given kind and ID
+func recordNotFound(kind string, petID int, id int) string {
+	return fmt.Sprintf("%v %v of pet %v not found", kind, id, petID)
+}
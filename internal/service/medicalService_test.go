@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_AddVaccination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	given := model.NewDate(2023, 3, 1)
+	due := model.NewDate(2024, 3, 1)
+	early := model.NewDate(2023, 1, 1)
+
+	tests := []struct {
+		name        string
+		vaccination *model.Vaccination
+		petErr      error
+		goToRep     bool
+		wantFields  []string
+		wantKind    error
+	}{
+		{
+			name:        "check add",
+			vaccination: &model.Vaccination{PetID: 1, Vaccine: "rabies", GivenOn: given, DueOn: &due},
+			goToRep:     true,
+		},
+		{
+			name:        "check pet not found",
+			vaccination: &model.Vaccination{PetID: 1, Vaccine: "rabies", GivenOn: given},
+			petErr:      sql.ErrNoRows,
+			wantKind:    ErrNotFound,
+		},
+		{
+			name:        "check blank",
+			vaccination: &model.Vaccination{PetID: 1},
+			wantFields:  []string{"vaccine", "given_on"},
+			wantKind:    ErrValidation,
+		},
+		{
+			name:        "check due before given",
+			vaccination: &model.Vaccination{PetID: 1, Vaccine: "rabies", GivenOn: given, DueOn: &early},
+			wantFields:  []string{"due_on"},
+			wantKind:    ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.wantFields == nil {
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1}, tt.petErr)
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().AddVaccination(gomock.Any(), tt.vaccination).DoAndReturn(func(_ context.Context, v *model.Vaccination) error {
+					v.ID = 2
+					return nil
+				})
+			}
+
+			id, err := s.AddVaccination(context.Background(), tt.vaccination)
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+				require.Equal(t, 2, id)
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantKind)
+
+			if tt.wantFields != nil {
+				var e *Error
+				require.ErrorAs(t, err, &e)
+
+				fields := make([]string, 0, len(e.Fields))
+				for _, f := range e.Fields {
+					fields = append(fields, f.Field)
+				}
+
+				require.Equal(t, tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestService_GetDueVaccinations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	s := NewService(testConf, repMock, nil)
+
+	now := today()
+	overdue := model.NewDate(now.Year(), now.Month(), now.Day()-1)
+	upcoming := model.NewDate(now.Year(), now.Month(), now.Day()+1)
+	before := model.NewDate(now.Year(), now.Month()+1, now.Day())
+
+	repMock.EXPECT().GetDueVaccinations(gomock.Any(), before).Return([]*model.DueVaccination{
+		{Vaccination: model.Vaccination{ID: 1, DueOn: &overdue}},
+		{Vaccination: model.Vaccination{ID: 2, DueOn: &now}},
+		{Vaccination: model.Vaccination{ID: 3, DueOn: &upcoming}},
+	}, nil)
+
+	res, err := s.GetDueVaccinations(context.Background(), before)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	require.True(t, res[0].Overdue)
+	require.False(t, res[1].Overdue)
+	require.False(t, res[2].Overdue)
+}
+
+func TestValidateTreatment(t *testing.T) {
+	given := model.NewDate(2023, 3, 1)
+	future := today()
+	future = model.NewDate(future.Year()+1, future.Month(), future.Day())
+
+	tests := []struct {
+		name       string
+		treatment  *model.Treatment
+		wantKind   model.TreatmentKind
+		wantFields []string
+	}{
+		{
+			name:      "check default kind",
+			treatment: &model.Treatment{Name: "checkup", GivenOn: given},
+			wantKind:  model.TreatmentOther,
+		},
+		{
+			name:      "check visit",
+			treatment: &model.Treatment{Kind: model.TreatmentVisit, Name: "checkup", GivenOn: given},
+			wantKind:  model.TreatmentVisit,
+		},
+		{
+			name:       "check invalid",
+			treatment:  &model.Treatment{Kind: "magic", GivenOn: future},
+			wantFields: []string{"kind", "name", "given_on"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTreatment(tt.treatment)
+
+			if tt.wantFields == nil {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantKind, tt.treatment.Kind)
+				return
+			}
+
+			var e *Error
+			require.ErrorAs(t, err, &e)
+
+			fields := make([]string, 0, len(e.Fields))
+			for _, f := range e.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			require.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	"pets/internal/model"
+)
+
+// GetOwners is implementing IService.GetOwners function
+func (s *Service) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, int, error) {
+	res, total, err := s.repository.GetOwners(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, domainError(err, "")
+	}
+
+	for _, o := range res {
+		o.SetLocal()
+	}
+
+	return res, total, nil
+}
+
+// GetOwner is implementing IService.GetOwner function
+func (s *Service) GetOwner(ctx context.Context, id int) (*model.Owner, error) {
+	res, err := s.repository.GetOwner(ctx, id)
+	if err != nil {
+		return nil, domainError(err, ownerNotFound(id))
+	}
+
+	res.SetLocal()
+
+	return res, nil
+}
+
+// AddOwner is implementing IService.AddOwner function
+func (s *Service) AddOwner(ctx context.Context, owner *model.Owner) (int, error) {
+	if err := validateOwner(owner); err != nil {
+		return 0, err
+	}
+
+	if err := s.repository.AddOwner(ctx, owner); err != nil {
+		return 0, domainError(err, "")
+	}
+
+	return owner.ID, nil
+}
+
+// UpdateOwner is implementing IService.UpdateOwner function
+func (s *Service) UpdateOwner(ctx context.Context, owner *model.Owner) error {
+	if err := validateOwner(owner); err != nil {
+		return err
+	}
+
+	return domainError(s.repository.UpdateOwner(ctx, owner), ownerNotFound(owner.ID))
+}
+
+// DeleteOwner is implementing IService.DeleteOwner function
+func (s *Service) DeleteOwner(ctx context.Context, owner *model.Owner) error {
+	return s.withTx(ctx, func(tx *Service) error {
+		_, total, err := tx.repository.GetPets(ctx, &model.PetsQuery{Filter: model.PetsFilter{OwnerID: &owner.ID}, Limit: 1})
+		if err != nil {
+			return domainError(err, "")
+		}
+
+		if total != 0 {
+			return NewConflictError(fmt.Sprintf("owner %v has %v pets, transfer them first", owner.ID, total))
+		}
+
+		return domainError(tx.repository.DeleteOwner(ctx, owner), ownerNotFound(owner.ID))
+	})
+}
+
+// GetOwnerPets is implementing IService.GetOwnerPets function
+func (s *Service) GetOwnerPets(ctx context.Context, ownerID int, query *model.PetsQuery, cursor string) ([]*model.Pet, int, string, error) {
+	if _, err := s.GetOwner(ctx, ownerID); err != nil {
+		return nil, 0, "", err
+	}
+
+	q := *query
+	q.Filter.OwnerID = &ownerID
+
+	return s.GetPets(ctx, &q, cursor)
+}
+
+// TransferPet is implementing IService.TransferPet function
+func (s *Service) TransferPet(ctx context.Context, transfer *model.Ownership) error {
+	if len(transfer.Note) > maxDescriptionLen {
+		msg := fmt.Sprintf("cannot be longer than %v", maxDescriptionLen)
+		return NewValidationError("invalid transfer", FieldError{Field: "note", Message: msg})
+	}
+
+	err := s.withTx(ctx, func(tx *Service) error {
+		pet, err := tx.GetPet(ctx, transfer.PetID)
+		if err != nil {
+			return err
+		}
+
+		if transfer.ToOwnerID != nil {
+			_, err = tx.GetOwner(ctx, *transfer.ToOwnerID)
+			if errors.Is(err, ErrNotFound) {
+				detail := ownerNotFound(*transfer.ToOwnerID)
+				return NewValidationError("invalid transfer", FieldError{Field: "owner_id", Message: detail})
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if sameOwner(pet.OwnerID, transfer.ToOwnerID) {
+			return NewConflictError(fmt.Sprintf("pet %v already has this owner", pet.ID))
+		}
+
+		return domainError(tx.repository.TransferPet(ctx, transfer), petNotFound(transfer.PetID))
+	})
+	if err != nil {
+		return err
+	}
+
+	transfer.SetLocal()
+
+	return nil
+}
+
+// GetOwnership is implementing IService.GetOwnership function
+func (s *Service) GetOwnership(ctx context.Context, petID int) ([]*model.Ownership, error) {
+	if _, err := s.GetPet(ctx, petID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.GetOwnership(ctx, petID)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, o := range res {
+		o.SetLocal()
+	}
+
+	return res, nil
+}
+
+// phonePattern is a pattern of owner phone: optional "+" and digits separated by spaces, dashes or parentheses
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{3,18}[0-9]$`)
+
+// maxAddressLen is a max length of owner address
+const maxAddressLen = 200
+
+// validateOwner is used to check owner fields given by user. Will return ErrValidation kind error with all invalid
+// fields
+func validateOwner(owner *model.Owner) error {
+	var fields []FieldError
+
+	if owner.Name == "" {
+		fields = append(fields, FieldError{Field: "name", Message: "cannot be blank"})
+	}
+
+	if len(owner.Name) > maxNameLen {
+		fields = append(fields, FieldError{Field: "name", Message: fmt.Sprintf("cannot be longer than %v", maxNameLen)})
+	}
+
+	if owner.Email == "" && owner.Phone == "" {
+		fields = append(fields, FieldError{Field: "email", Message: "email or phone should be given"})
+	}
+
+	if owner.Email != "" {
+		if a, err := mail.ParseAddress(owner.Email); err != nil || a.Address != owner.Email {
+			fields = append(fields, FieldError{Field: "email", Message: "should be an email address"})
+		}
+	}
+
+	if owner.Phone != "" && !phonePattern.MatchString(owner.Phone) {
+		fields = append(fields, FieldError{Field: "phone", Message: "should be a phone number"})
+	}
+
+	if len(owner.Address) > maxAddressLen {
+		fields = append(fields, FieldError{Field: "address", Message: fmt.Sprintf("cannot be longer than %v", maxAddressLen)})
+	}
+
+	if len(fields) != 0 {
+		return NewValidationError("invalid owner", fields...)
+	}
+
+	return nil
+}
+
+// ownerNotFound is used to get not found error detail for owner with given ID
+func ownerNotFound(id int) string {
+	return fmt.Sprintf("owner %v not found", id)
+}
+
+// sameOwner is used to check that given optional owner IDs are equal
+func sameOwner(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
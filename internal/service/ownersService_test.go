@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_DeleteOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+	expectTx(repMock)
+
+	tests := []struct {
+		name     string
+		pets     int
+		goToRep  bool
+		repErr   error
+		wantKind error
+	}{
+		{
+			name:    "check no pets",
+			goToRep: true,
+		},
+		{
+			name:     "check has pets",
+			pets:     2,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "check not found",
+			goToRep:  true,
+			repErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+			owner := &model.Owner{ID: 1}
+
+			ownerID := 1
+			query := &model.PetsQuery{Filter: model.PetsFilter{OwnerID: &ownerID}, Limit: 1}
+			repMock.EXPECT().GetPets(gomock.Any(), query).Return(nil, tt.pets, nil)
+
+			if tt.goToRep {
+				repMock.EXPECT().DeleteOwner(gomock.Any(), owner).Return(tt.repErr)
+			}
+
+			err := s.DeleteOwner(context.Background(), owner)
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestService_TransferPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+	expectTx(repMock)
+
+	ownerID, otherID := 1, 2
+
+	tests := []struct {
+		name     string
+		transfer *model.Ownership
+		petErr   error
+		owner    *int
+		ownerErr error
+		goToRep  bool
+		wantKind error
+	}{
+		{
+			name:     "check transfer",
+			transfer: &model.Ownership{PetID: 1, ToOwnerID: &otherID},
+			owner:    &ownerID,
+			goToRep:  true,
+		},
+		{
+			name:     "check release",
+			transfer: &model.Ownership{PetID: 1},
+			owner:    &ownerID,
+			goToRep:  true,
+		},
+		{
+			name:     "check pet not found",
+			transfer: &model.Ownership{PetID: 1, ToOwnerID: &otherID},
+			petErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "check owner not found",
+			transfer: &model.Ownership{PetID: 1, ToOwnerID: &otherID},
+			ownerErr: sql.ErrNoRows,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check same owner",
+			transfer: &model.Ownership{PetID: 1, ToOwnerID: &ownerID},
+			owner:    &ownerID,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "check release without owner",
+			transfer: &model.Ownership{PetID: 1},
+			wantKind: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.petErr != nil {
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(nil, tt.petErr)
+			} else {
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1, OwnerID: tt.owner}, nil)
+			}
+
+			if tt.petErr == nil && tt.transfer.ToOwnerID != nil {
+				if tt.ownerErr != nil {
+					repMock.EXPECT().GetOwner(gomock.Any(), *tt.transfer.ToOwnerID).Return(nil, tt.ownerErr)
+				} else {
+					repMock.EXPECT().GetOwner(gomock.Any(), *tt.transfer.ToOwnerID).Return(&model.Owner{ID: *tt.transfer.ToOwnerID}, nil)
+				}
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().TransferPet(gomock.Any(), tt.transfer).Return(nil)
+			}
+
+			err := s.TransferPet(context.Background(), tt.transfer)
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestValidateOwner(t *testing.T) {
+	tests := []struct {
+		name       string
+		owner      *model.Owner
+		wantFields []string
+	}{
+		{
+			name:  "check valid email",
+			owner: &model.Owner{Name: "John", Email: "john@example.com"},
+		},
+		{
+			name:  "check valid phone",
+			owner: &model.Owner{Name: "John", Phone: "+1 (555) 010-0100"},
+		},
+		{
+			name:       "check no contacts",
+			owner:      &model.Owner{Name: "John"},
+			wantFields: []string{"email"},
+		},
+		{
+			name:       "check invalid contacts",
+			owner:      &model.Owner{Email: "John <john@example.com>", Phone: "call me"},
+			wantFields: []string{"name", "email", "phone"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOwner(tt.owner)
+
+			if tt.wantFields == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var e *Error
+			require.ErrorAs(t, err, &e)
+
+			fields := make([]string, 0, len(e.Fields))
+			for _, f := range e.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			require.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
@@ -1,73 +1,365 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"strconv"
+	"fmt"
+	"slices"
+	"time"
 
 	"pets/internal/model"
+	"pets/internal/repository"
 )
 
 // GetPets is implementing IService.GetPets function
-func (s *Service) GetPets(limit string, offset string, order string) ([]*model.Pet, int, error) {
-	res, err := s.repository.GetPets(convertString(limit), convertString(offset), order)
+func (s *Service) GetPets(ctx context.Context, query *model.PetsQuery, cur string) ([]*model.Pet, int, string, error) {
+	q := *query
+	if len(query.Filter.Tags) != 0 {
+		q.Filter.Tags = normalizeTagNames(query.Filter.Tags)
+	}
+
+	if cur != "" {
+		c, err := s.decodeCursor(cur)
+		if err != nil {
+			return nil, 0, "", err
+		}
+
+		q.Sort, err = model.ParseSort(c.Sort)
+		if err != nil {
+			return nil, 0, "", ErrInvalidCursor
+		}
+
+		q.After = &c.Key
+		q.Offset = 0
+
+		// one more pet is requested to know if there is a next page
+		if q.Limit > 0 {
+			q.Limit++
+		}
+	}
+
+	res, total, err := s.repository.GetPets(ctx, &q)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, 0, nil
+		return nil, 0, "", nil
 	}
 
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", domainError(err, "")
 	}
 
-	if res == nil {
-		return nil, 0, nil
+	hasMore := query.Limit > 0 && q.Offset+len(res) < total
+	if cur != "" {
+		hasMore = query.Limit > 0 && len(res) > query.Limit
+		if hasMore {
+			res = res[:query.Limit]
+		}
+	}
+
+	var next string
+	if hasMore && len(res) != 0 {
+		next = s.encodeCursor(&cursor{Sort: model.SortString(q.Sort), Key: *res[len(res)-1].Key()})
 	}
 
 	setLocalTimePets(res)
 
-	return res, len(res), nil
+	return res, total, next, nil
+}
+
+// ExportPets is implementing IService.ExportPets function
+func (s *Service) ExportPets(ctx context.Context, query *model.PetsQuery, fn func(pet *model.Pet) error) error {
+	q := *query
+	if len(query.Filter.Tags) != 0 {
+		q.Filter.Tags = normalizeTagNames(query.Filter.Tags)
+	}
+
+	now := time.Now()
+
+	err := s.repository.ExportPets(ctx, &q, func(pet *model.Pet) error {
+		pet.SetLocal()
+		pet.SetAge(now)
+		pet.SetPhotoURL()
+		pet.SetTags(pet.Tags)
+
+		return fn(pet)
+	})
+
+	return domainError(err, "")
+}
+
+// GetPet is implementing IService.GetPet function
+func (s *Service) GetPet(ctx context.Context, id int) (*model.Pet, error) {
+	res, err := s.repository.GetPet(ctx, id)
+	if err != nil {
+		return nil, domainError(err, petNotFound(id))
+	}
+
+	res.SetLocal()
+	res.SetAge(time.Now())
+	res.SetPhotoURL()
+	res.SetTags(res.Tags)
+
+	return res, nil
 }
 
 // AddPet is implementing IService.AddPet function
-func (s *Service) AddPet(pet *model.Pet) (int, error) {
-	if err := s.repository.AddPet(pet); err != nil {
+func (s *Service) AddPet(ctx context.Context, pet *model.Pet) (int, error) {
+	if err := checkNewPet(pet); err != nil {
 		return 0, err
 	}
 
+	if err := s.repository.AddPet(ctx, pet); err != nil {
+		return 0, domainError(err, "")
+	}
+
 	return pet.ID, nil
 }
 
+// checkNewPet is used to set defaults of the added pet and to validate it. Pets cannot be added in adoption statuses
+func checkNewPet(pet *model.Pet) error {
+	pet.SetDefaults()
+
+	if err := validatePet(pet); err != nil {
+		return err
+	}
+
+	if pet.Status.Adoption() {
+		msg := fmt.Sprintf("pet cannot be added as %v", pet.Status)
+		return NewValidationError("invalid pet", FieldError{Field: "status", Message: msg})
+	}
+
+	return nil
+}
+
 // UpdatePet is implementing IService.UpdatePet function
-func (s *Service) UpdatePet(pet *model.Pet) error {
-	return s.repository.UpdatePet(pet)
+func (s *Service) UpdatePet(ctx context.Context, pet *model.Pet) error {
+	return s.savePet(ctx, pet, repository.IRepository.UpdatePet)
 }
 
-// DeletePet is implementing IService.DeletePet function
-func (s *Service) DeletePet(pet *model.Pet) error {
-	return s.repository.DeletePet(pet)
+// PatchPet is implementing IService.PatchPet function
+func (s *Service) PatchPet(ctx context.Context, pet *model.Pet, fields []string) error {
+	for _, f := range fields {
+		if !slices.Contains(model.PetFields, f) {
+			return NewValidationError(fmt.Sprintf("pet field %v cannot be changed", f), FieldError{Field: f, Message: "cannot be changed"})
+		}
+	}
+
+	return s.savePet(ctx, pet, func(rep repository.IRepository, ctx context.Context, pet *model.Pet) error {
+		// only status can be changed, it is changed by the transition
+		if len(fields) == 0 {
+			return nil
+		}
+
+		return rep.PatchPet(ctx, pet, fields)
+	})
 }
 
-// IsExist is implementing IService.IsExist function
-func (s *Service) IsExist(id int) bool {
-	res, _ := s.repository.GetPet(id)
+// savePet is used to validate changed pet, check its version and status change and save it with given save function
+// of the repository in one transaction. Status change is recorded as a transition after the pet is saved
+func (s *Service) savePet(ctx context.Context, pet *model.Pet, save func(repository.IRepository, context.Context, *model.Pet) error) error {
+	pet.SetDefaults()
+
+	if err := validatePet(pet); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *Service) error {
+		stored, err := tx.GetPet(ctx, pet.ID)
+		if err != nil {
+			return err
+		}
+
+		if pet.Version != 0 && pet.Version != stored.Version {
+			return petChanged(pet.ID)
+		}
+
+		if pet.Status != stored.Status && !manualTransition(stored.Status, pet.Status) {
+			return NewConflictError(fmt.Sprintf("pet %v cannot be moved from %v to %v", pet.ID, stored.Status, pet.Status))
+		}
+
+		if err = save(tx.repository, ctx, pet); err != nil {
+			return petWriteError(err, pet.ID)
+		}
+
+		if pet.Status == stored.Status {
+			return nil
+		}
+
+		transition := &model.Transition{PetID: pet.ID, From: stored.Status, To: pet.Status}
+
+		return domainError(tx.repository.TransitionPet(ctx, transition), petNotFound(pet.ID))
+	})
+}
+
+// DeletePet is implementing IService.DeletePet function. Pet is soft deleted, its records and photos are kept until
+// the pet is purged
+func (s *Service) DeletePet(ctx context.Context, pet *model.Pet) error {
+	return petWriteError(s.repository.DeletePet(ctx, pet), pet.ID)
+}
+
+// petWriteError is used to convert given pet write error to a domain error. repository.ErrStale is returned only if
+// pet version was given by the client, so it is converted to ErrPreconditionFailed kind error
+func petWriteError(err error, id int) error {
+	if errors.Is(err, repository.ErrStale) {
+		return petChanged(id)
+	}
+
+	return domainError(err, petNotFound(id))
+}
 
-	return res != nil && res.ID != 0
+// petChanged is used to get ErrPreconditionFailed kind error for pet with given ID changed since the client read it
+func petChanged(id int) error {
+	return NewPreconditionFailedError(fmt.Sprintf("pet %v was changed, get it again", id))
 }
 
-// convertString is used to convert string to int format. If string is not convertable will return 0.
-func convertString(s string) int {
-	i, err := strconv.Atoi(s)
+// RestorePet is implementing IService.RestorePet function
+func (s *Service) RestorePet(ctx context.Context, id int) (*model.Pet, error) {
+	var res *model.Pet
+
+	err := s.withTx(ctx, func(tx *Service) error {
+		err := tx.repository.RestorePet(ctx, &model.Pet{ID: id})
+
+		if errors.Is(err, sql.ErrNoRows) {
+			// pet is either not deleted or not exist
+			if _, err = tx.repository.GetPet(ctx, id); err == nil {
+				return NewConflictError(fmt.Sprintf("pet %v is not deleted", id))
+			}
+
+			return domainError(err, petNotFound(id))
+		}
+
+		if err != nil {
+			return domainError(err, "")
+		}
+
+		res, err = tx.GetPet(ctx, id)
+
+		return err
+	})
 	if err != nil {
-		return 0
-	} else {
-		return i
+		return nil, err
 	}
+
+	return res, nil
 }
 
-// setLocalTimePets is used to set local time in all given model.Pet objects
+// purgeBatch is a max number of pets purged by a single PurgePets query
+const purgeBatch = 100
+
+// PurgePets is implementing IService.PurgePets function. Pets are purged one by one, so a failure keeps already purged
+// pets purged. Photos content is deleted after the pet records, pets restored while purging are skipped
+func (s *Service) PurgePets(ctx context.Context, before time.Time) (int, error) {
+	query := &model.PetsQuery{Filter: model.PetsFilter{DeletedBefore: &before}, Limit: purgeBatch}
+	purged := 0
+
+	for {
+		pets, _, err := s.repository.GetPets(ctx, query)
+		if err != nil {
+			return purged, domainError(err, "")
+		}
+
+		if len(pets) == 0 {
+			return purged, nil
+		}
+
+		for _, p := range pets {
+			photos, err := s.repository.GetPhotos(ctx, p.ID)
+			if err != nil {
+				return purged, domainError(err, "")
+			}
+
+			err = s.repository.PurgePet(ctx, p)
+
+			// pet was restored after it was listed
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			if err != nil {
+				return purged, domainError(err, "")
+			}
+
+			for _, ph := range photos {
+				s.deleteBlobs(ctx, ph.BlobKey, ph.ThumbKey)
+			}
+
+			purged++
+		}
+	}
+}
+
+// Max lengths of pet text fields
+const (
+	maxNameLen        = 100
+	maxShortLen       = 100
+	maxDescriptionLen = 2000
+)
+
+// validatePet is used to check pet fields given by user. Will return ErrValidation kind error with all invalid fields
+func validatePet(pet *model.Pet) error {
+	var fields []FieldError
+
+	if pet.Name == "" {
+		fields = append(fields, FieldError{Field: "name", Message: "cannot be blank"})
+	}
+
+	if len(pet.Name) > maxNameLen {
+		fields = append(fields, FieldError{Field: "name", Message: fmt.Sprintf("cannot be longer than %v", maxNameLen)})
+	}
+
+	if !pet.Species.Valid() {
+		fields = append(fields, FieldError{Field: "species", Message: fmt.Sprintf("unknown species %q", pet.Species)})
+	}
+
+	if !pet.Sex.Valid() {
+		fields = append(fields, FieldError{Field: "sex", Message: fmt.Sprintf("unknown sex %q", pet.Sex)})
+	}
+
+	if !pet.Status.Valid() {
+		fields = append(fields, FieldError{Field: "status", Message: fmt.Sprintf("unknown status %q", pet.Status)})
+	}
+
+	if len(pet.Breed) > maxShortLen {
+		fields = append(fields, FieldError{Field: "breed", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	if len(pet.Color) > maxShortLen {
+		fields = append(fields, FieldError{Field: "color", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	if len(pet.Description) > maxDescriptionLen {
+		fields = append(fields, FieldError{Field: "description", Message: fmt.Sprintf("cannot be longer than %v", maxDescriptionLen)})
+	}
+
+	if pet.BirthDate != nil && pet.BirthDate.After(time.Now()) {
+		fields = append(fields, FieldError{Field: "birth_date", Message: "cannot be in the future"})
+	}
+
+	if pet.Weight != nil && *pet.Weight <= 0 {
+		fields = append(fields, FieldError{Field: "weight", Message: "should be more than 0"})
+	}
+
+	if len(fields) != 0 {
+		return NewValidationError("invalid pet", fields...)
+	}
+
+	return nil
+}
+
+// petNotFound is used to get not found error detail for pet with given ID
+func petNotFound(id int) string {
+	return fmt.Sprintf("pet %v not found", id)
+}
+
+// setLocalTimePets is used to set local time, age, photo URL and non-nil tags in all given model.Pet objects
 func setLocalTimePets(pets []*model.Pet) {
+	now := time.Now()
+
 	for _, p := range pets {
 		p.SetLocal()
+		p.SetAge(now)
+		p.SetPhotoURL()
+		p.SetTags(p.Tags)
 	}
 }
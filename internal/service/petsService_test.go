@@ -1,140 +1,122 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	"pets/internal/config"
 	"pets/internal/model"
+	"pets/internal/repository"
+	"pets/internal/storage"
 	mock_repository "pets/mocks/repository"
 )
 
+// testConf is a service config used in tests
+var testConf = &config.Service{CursorSecret: "secret"}
+
+// expectTx is used to expect any number of WithTx calls running given functions with the repository mock
+func expectTx(repMock *mock_repository.MockIRepository) {
+	repMock.EXPECT().WithTx(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(repository.IRepository) error) error {
+			return fn(repMock)
+		})
+}
+
 func TestService_GetPets(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	repMock := mock_repository.NewMockIRepository(ctrl)
 	defer ctrl.Finish()
 
-	type req struct {
-		limit  string
-		offset string
-		order  string
-	}
-	type repReq struct {
-		limit  int
-		offset int
-		order  string
-	}
 	tests := []struct {
-		name string
+		name  string
+		query *model.PetsQuery
 
-		req    *req
-		repReq *repReq
-
-		repPets []*model.Pet
-		repErr  error
+		repPets  []*model.Pet
+		repTotal int
+		repErr   error
 
 		wantTotal int
+		wantNext  bool
 		wantErr   bool
 	}{
 		{
 			name:      "check total 3",
-			req:       &req{},
-			repReq:    &repReq{},
+			query:     &model.PetsQuery{},
 			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}, {ID: 2, Name: "Pet2"}, {ID: 3, Name: "Pet3"}},
+			repTotal:  3,
 			wantTotal: 3,
 		},
-		{
-			name:      "check total 2",
-			req:       &req{},
-			repReq:    &repReq{},
-			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}, {ID: 2, Name: "Pet2"}},
-			wantTotal: 2,
-		},
-		{
-			name:      "check total 1",
-			req:       &req{},
-			repReq:    &repReq{},
-			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}},
-			wantTotal: 1,
-		},
 		{
 			name:      "check total 1",
-			req:       &req{},
-			repReq:    &repReq{},
+			query:     &model.PetsQuery{},
 			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}},
+			repTotal:  1,
 			wantTotal: 1,
 		},
 		{
 			name:      "check total 0",
-			req:       &req{},
-			repReq:    &repReq{},
+			query:     &model.PetsQuery{},
 			repPets:   []*model.Pet{},
 			wantTotal: 0,
 		},
 		{
 			name:      "check total nil-0",
-			req:       &req{},
-			repReq:    &repReq{},
+			query:     &model.PetsQuery{},
 			wantTotal: 0,
 		},
 		{
-			name:      "check convert limit",
-			req:       &req{limit: "1"},
-			repReq:    &repReq{limit: 1},
+			name: "check query passed",
+			query: &model.PetsQuery{
+				Filter: model.PetsFilter{Name: "Pet", NameMatch: model.NamePrefix, IDs: []int{1}},
+				Sort:   []model.SortField{{Field: model.SortName, Desc: true}},
+				Limit:  1,
+			},
 			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}},
+			repTotal:  1,
 			wantTotal: 1,
 		},
 		{
-			name:      "check convert offset",
-			req:       &req{limit: "2"},
-			repReq:    &repReq{limit: 2},
+			name:      "check total from repository",
+			query:     &model.PetsQuery{Limit: 1},
 			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}},
-			wantTotal: 1,
-		},
-		{
-			name:      "check convert invalid",
-			req:       &req{limit: "notInt"},
-			repReq:    &repReq{},
-			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}},
-			wantTotal: 1,
-		},
-		{
-			name:      "check forward order",
-			req:       &req{order: "desc"},
-			repReq:    &repReq{order: "desc"},
-			repPets:   []*model.Pet{{ID: 1, Name: "Pet1"}},
-			wantTotal: 1,
+			repTotal:  10,
+			wantTotal: 10,
+			wantNext:  true,
 		},
 		{
 			name:    "check rep error",
-			req:     &req{},
-			repReq:  &repReq{},
+			query:   &model.PetsQuery{},
 			repErr:  fmt.Errorf("rep error"),
 			wantErr: true,
 		},
 		{
 			name:      "check rep no rows",
-			req:       &req{},
-			repReq:    &repReq{},
+			query:     &model.PetsQuery{},
 			repErr:    sql.ErrNoRows,
 			wantTotal: 0,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewService(repMock)
+			s := NewService(testConf, repMock, nil)
 
-			repMock.EXPECT().GetPets(tt.repReq.limit, tt.repReq.offset, tt.repReq.order).Return(tt.repPets, tt.repErr)
+			repMock.EXPECT().GetPets(gomock.Any(), tt.query).Return(tt.repPets, tt.repTotal, tt.repErr)
 
-			res, resTotal, err := s.GetPets(tt.req.limit, tt.req.offset, tt.req.order)
+			res, resTotal, next, err := s.GetPets(context.Background(), tt.query, "")
 
 			if !tt.wantErr {
 				require.NoError(t, err)
 				require.Equal(t, tt.wantTotal, resTotal)
 				require.Equal(t, tt.repPets, res)
+				require.Equal(t, tt.wantNext, next != "")
 			} else {
 				require.Error(t, err)
 			}
@@ -142,58 +124,231 @@ func TestService_GetPets(t *testing.T) {
 	}
 }
 
+func TestService_GetPetsCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	s := NewService(testConf, repMock, nil)
+
+	created := time.Date(2023, 9, 17, 10, 0, 0, 0, time.UTC)
+	byName := []model.SortField{{Field: model.SortName}, {Field: model.SortCreatedAt, Desc: true}}
+	filter := model.PetsFilter{Name: "pet", NameMatch: model.NameContains}
+
+	// first page in offset mode returns cursor of the last pet
+	repMock.EXPECT().GetPets(gomock.Any(), &model.PetsQuery{Filter: filter, Sort: byName, Limit: 2}).
+		Return([]*model.Pet{{ID: 5, Name: "Pet1", CreatedAt: created}, {ID: 4, Name: "Pet2", CreatedAt: created}}, 5, nil)
+
+	res, total, next, err := s.GetPets(context.Background(), &model.PetsQuery{Filter: filter, Sort: byName, Limit: 2}, "")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, 5, total)
+	require.NotEmpty(t, next)
+
+	// next page is requested with one more pet to know if there is a page after it, sort is taken from cursor
+	repMock.EXPECT().GetPets(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, q *model.PetsQuery) ([]*model.Pet, int, error) {
+			require.Equal(t, filter, q.Filter)
+			require.Equal(t, byName, q.Sort)
+			require.Equal(t, 3, q.Limit)
+			require.Equal(t, 0, q.Offset)
+			require.Equal(t, 4, q.After.ID)
+			require.Equal(t, "Pet2", q.After.Name)
+			require.True(t, created.Equal(q.After.CreatedAt))
+
+			return []*model.Pet{{ID: 3}, {ID: 2}, {ID: 1}}, 5, nil
+		})
+
+	res, total, next, err = s.GetPets(context.Background(), &model.PetsQuery{Filter: filter, Limit: 2}, next)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2}, petIDs(res))
+	require.Equal(t, 5, total)
+	require.NotEmpty(t, next)
+
+	// last page has no next cursor
+	repMock.EXPECT().GetPets(gomock.Any(), gomock.Any()).Return([]*model.Pet{{ID: 1}}, 5, nil)
+
+	res, _, next, err = s.GetPets(context.Background(), &model.PetsQuery{Limit: 2}, next)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, petIDs(res))
+	require.Empty(t, next)
+
+	// last offset page has no next cursor
+	repMock.EXPECT().GetPets(gomock.Any(), &model.PetsQuery{Limit: 2, Offset: 4}).Return([]*model.Pet{{ID: 5}}, 5, nil)
+
+	_, _, next, err = s.GetPets(context.Background(), &model.PetsQuery{Limit: 2, Offset: 4}, "")
+	require.NoError(t, err)
+	require.Empty(t, next)
+
+	// cursor of a service with other secret is rejected
+	other := NewService(&config.Service{CursorSecret: "other"}, repMock, nil)
+	repMock.EXPECT().GetPets(gomock.Any(), gomock.Any()).Return([]*model.Pet{{ID: 1}}, 5, nil)
+
+	_, _, next, err = other.GetPets(context.Background(), &model.PetsQuery{Limit: 1}, "")
+	require.NoError(t, err)
+
+	_, _, _, err = s.GetPets(context.Background(), &model.PetsQuery{Limit: 1}, next)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, _, _, err = s.GetPets(context.Background(), &model.PetsQuery{Limit: 1}, "notCursor")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestService_GetPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		id       int
+		repPet   *model.Pet
+		repErr   error
+		wantErr  bool
+		wantKind error
+	}{
+		{
+			name:   "check found",
+			id:     1,
+			repPet: &model.Pet{ID: 1, Name: "Velho"},
+		},
+		{
+			name:     "check no rows",
+			id:       1,
+			repErr:   sql.ErrNoRows,
+			wantErr:  true,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "check timeout",
+			id:       1,
+			repErr:   fmt.Errorf("query: %w", context.DeadlineExceeded),
+			wantErr:  true,
+			wantKind: ErrUnavailable,
+		},
+		{
+			name:    "check rep error",
+			id:      1,
+			repErr:  fmt.Errorf("rep error"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			repMock.EXPECT().GetPet(gomock.Any(), tt.id).Return(tt.repPet, tt.repErr)
+
+			res, err := s.GetPet(context.Background(), tt.id)
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				require.Equal(t, tt.repPet, res)
+			} else {
+				require.Error(t, err)
+				require.Nil(t, res)
+
+				if tt.wantKind != nil {
+					require.ErrorIs(t, err, tt.wantKind)
+				} else {
+					require.ErrorIs(t, err, tt.repErr)
+				}
+			}
+		})
+	}
+}
+
 func TestService_AddPet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	repMock := mock_repository.NewMockIRepository(ctrl)
 	defer ctrl.Finish()
 
 	tests := []struct {
-		name    string
-		pet     *model.Pet
-		wantId  int
-		repErr  error
-		wantErr bool
+		name     string
+		pet      *model.Pet
+		wantId   int
+		goToRep  bool
+		repErr   error
+		wantErr  bool
+		wantKind error
 	}{
 		{
-			name:   "check id return 1",
-			pet:    &model.Pet{Name: "Velho"},
-			wantId: 1,
+			name:    "check id return 1",
+			pet:     &model.Pet{Name: "Velho"},
+			goToRep: true,
+			wantId:  1,
 		},
 		{
-			name:   "check id return 2",
-			pet:    &model.Pet{Name: "Velho"},
-			wantId: 2,
+			name:    "check id return 2",
+			pet:     &model.Pet{Name: "Velho"},
+			goToRep: true,
+			wantId:  2,
 		},
 		{
-			name:   "check id return 3",
-			pet:    &model.Pet{Name: "Velho"},
-			wantId: 3,
+			name:    "check id return 3",
+			pet:     &model.Pet{Name: "Velho"},
+			goToRep: true,
+			wantId:  3,
 		},
 		{
 			name:    "check id return 0 with err",
 			pet:     &model.Pet{Name: "Velho"},
+			goToRep: true,
 			repErr:  fmt.Errorf("rep error"),
 			wantErr: true,
 		},
+		{
+			name:    "check defaults",
+			pet:     &model.Pet{Name: "Velho"},
+			goToRep: true,
+			wantId:  4,
+		},
+		{
+			name:     "check blank name",
+			pet:      &model.Pet{},
+			wantErr:  true,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check unknown species",
+			pet:      &model.Pet{Name: "Velho", Species: "dragon"},
+			wantErr:  true,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check adoption status",
+			pet:      &model.Pet{Name: "Velho", Status: model.StatusAdopted},
+			wantErr:  true,
+			wantKind: ErrValidation,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewService(repMock)
+			s := NewService(testConf, repMock, nil)
 
-			repMock.EXPECT().AddPet(tt.pet).DoAndReturn(func(p *model.Pet) {
-				if tt.repErr == nil {
-					p.ID = tt.wantId
-				}
-			}).Return(tt.repErr)
+			if tt.goToRep {
+				repMock.EXPECT().AddPet(gomock.Any(), tt.pet).DoAndReturn(func(_ context.Context, p *model.Pet) {
+					if tt.repErr == nil {
+						p.ID = tt.wantId
+					}
+				}).Return(tt.repErr)
+			}
 
-			id, err := s.AddPet(tt.pet)
+			id, err := s.AddPet(context.Background(), tt.pet)
 
 			if !tt.wantErr {
 				require.NoError(t, err)
 				require.Equal(t, tt.wantId, id)
+				require.Equal(t, model.SpeciesOther, tt.pet.Species)
+				require.Equal(t, model.SexUnknown, tt.pet.Sex)
+				require.Equal(t, model.StatusAvailable, tt.pet.Status)
 			} else {
 				require.Equal(t, 0, id)
 				require.Error(t, err)
+
+				if tt.wantKind != nil {
+					require.ErrorIs(t, err, tt.wantKind)
+				}
 			}
 		})
 	}
@@ -203,36 +358,227 @@ func TestService_UpdatePet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	repMock := mock_repository.NewMockIRepository(ctrl)
 	defer ctrl.Finish()
+	expectTx(repMock)
 
 	tests := []struct {
-		name    string
-		pet     *model.Pet
-		repErr  error
-		wantErr bool
+		name         string
+		pet          *model.Pet
+		stored       model.Status
+		version      int
+		goToRep      bool
+		repErr       error
+		goTransition bool
+		wantErr      bool
+		wantKind     error
 	}{
 		{
-			name: "no error",
-			pet:  &model.Pet{Name: "Velho", ID: 1},
+			name:    "no error",
+			pet:     &model.Pet{Name: "Velho", ID: 1},
+			goToRep: true,
 		},
 		{
 			name:    "error",
 			pet:     &model.Pet{Name: "Velho", ID: 1},
+			goToRep: true,
 			repErr:  fmt.Errorf("rep error"),
 			wantErr: true,
 		},
+		{
+			name:     "not found",
+			pet:      &model.Pet{Name: "Velho", ID: 1},
+			goToRep:  true,
+			repErr:   sql.ErrNoRows,
+			wantErr:  true,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:    "version matches",
+			pet:     &model.Pet{Name: "Velho", ID: 1, Version: 2},
+			version: 2,
+			goToRep: true,
+		},
+		{
+			name:     "version mismatch",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Version: 2},
+			version:  3,
+			wantErr:  true,
+			wantKind: ErrPreconditionFailed,
+		},
+		{
+			name:     "stale version",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Version: 2},
+			version:  2,
+			goToRep:  true,
+			repErr:   repository.ErrStale,
+			wantErr:  true,
+			wantKind: ErrPreconditionFailed,
+		},
+		{
+			name:     "blank name",
+			pet:      &model.Pet{ID: 1},
+			wantErr:  true,
+			wantKind: ErrValidation,
+		},
+		{
+			name:         "status change",
+			pet:          &model.Pet{Name: "Velho", ID: 1, Status: model.StatusArchived},
+			goToRep:      true,
+			goTransition: true,
+		},
+		{
+			name:         "status change from returned",
+			pet:          &model.Pet{Name: "Velho", ID: 1, Status: model.StatusAvailable},
+			stored:       model.StatusReturned,
+			goToRep:      true,
+			goTransition: true,
+		},
+		{
+			name:     "adoption status",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Status: model.StatusReserved},
+			wantErr:  true,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "reserved status",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Status: model.StatusAvailable},
+			stored:   model.StatusReserved,
+			wantErr:  true,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "not allowed transition",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Status: model.StatusPending},
+			stored:   model.StatusArchived,
+			wantErr:  true,
+			wantKind: ErrConflict,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewService(repMock)
+			s := NewService(testConf, repMock, nil)
+
+			if tt.pet.Name != "" {
+				stored := tt.stored
+				if stored == "" {
+					stored = model.StatusAvailable
+				}
 
-			repMock.EXPECT().UpdatePet(tt.pet).Return(tt.repErr)
+				repMock.EXPECT().GetPet(gomock.Any(), tt.pet.ID).Return(&model.Pet{ID: tt.pet.ID, Status: stored, Version: tt.version}, nil)
+			}
 
-			err := s.UpdatePet(tt.pet)
+			if tt.goToRep {
+				repMock.EXPECT().UpdatePet(gomock.Any(), tt.pet).Return(tt.repErr)
+			}
+
+			if tt.goTransition {
+				repMock.EXPECT().TransitionPet(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tr *model.Transition) error {
+					require.Equal(t, tt.pet.Status, tr.To)
+					return nil
+				})
+			}
+
+			err := s.UpdatePet(context.Background(), tt.pet)
 
 			if !tt.wantErr {
 				require.NoError(t, err)
 			} else {
 				require.Error(t, err)
+
+				if tt.wantKind != nil {
+					require.ErrorIs(t, err, tt.wantKind)
+				}
+			}
+		})
+	}
+}
+
+func TestService_PatchPet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+	expectTx(repMock)
+
+	tests := []struct {
+		name         string
+		pet          *model.Pet
+		fields       []string
+		goToSev      bool
+		goToRep      bool
+		repErr       error
+		goTransition bool
+		wantErr      bool
+		wantKind     error
+	}{
+		{
+			name:    "no error",
+			pet:     &model.Pet{Name: "Velho", ID: 1, Breed: "Beagle"},
+			fields:  []string{"breed"},
+			goToSev: true,
+			goToRep: true,
+		},
+		{
+			name:    "no changes",
+			pet:     &model.Pet{Name: "Velho", ID: 1},
+			goToSev: true,
+		},
+		{
+			name:         "status change only",
+			pet:          &model.Pet{Name: "Velho", ID: 1, Status: model.StatusArchived},
+			goToSev:      true,
+			goTransition: true,
+		},
+		{
+			name:     "read-only field",
+			pet:      &model.Pet{Name: "Velho", ID: 1},
+			fields:   []string{"owner_id"},
+			wantErr:  true,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "invalid field",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Species: "dragon"},
+			fields:   []string{"species"},
+			wantErr:  true,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "stale version",
+			pet:      &model.Pet{Name: "Velho", ID: 1, Breed: "Beagle", Version: 2},
+			fields:   []string{"breed"},
+			goToSev:  true,
+			goToRep:  true,
+			repErr:   repository.ErrStale,
+			wantErr:  true,
+			wantKind: ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.goToSev {
+				stored := &model.Pet{ID: tt.pet.ID, Status: model.StatusAvailable, Version: tt.pet.Version}
+				repMock.EXPECT().GetPet(gomock.Any(), tt.pet.ID).Return(stored, nil)
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().PatchPet(gomock.Any(), tt.pet, tt.fields).Return(tt.repErr)
+			}
+
+			if tt.goTransition {
+				repMock.EXPECT().TransitionPet(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			err := s.PatchPet(context.Background(), tt.pet, tt.fields)
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+
+				if tt.wantKind != nil {
+					require.ErrorIs(t, err, tt.wantKind)
+				}
 			}
 		})
 	}
@@ -244,10 +590,11 @@ func TestService_DeletePet(t *testing.T) {
 	defer ctrl.Finish()
 
 	tests := []struct {
-		name    string
-		pet     *model.Pet
-		repErr  error
-		wantErr bool
+		name     string
+		pet      *model.Pet
+		repErr   error
+		wantErr  bool
+		wantKind error
 	}{
 		{
 			name: "no error",
@@ -259,75 +606,270 @@ func TestService_DeletePet(t *testing.T) {
 			repErr:  fmt.Errorf("rep error"),
 			wantErr: true,
 		},
+		{
+			name:     "not found",
+			pet:      &model.Pet{ID: 1},
+			repErr:   sql.ErrNoRows,
+			wantErr:  true,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "stale version",
+			pet:      &model.Pet{ID: 1, Version: 2},
+			repErr:   repository.ErrStale,
+			wantErr:  true,
+			wantKind: ErrPreconditionFailed,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewService(repMock)
+			s := NewService(testConf, repMock, nil)
 
-			repMock.EXPECT().DeletePet(tt.pet).Return(tt.repErr)
+			// photos are kept until the pet is purged
+			repMock.EXPECT().DeletePet(gomock.Any(), tt.pet).Return(tt.repErr)
 
-			err := s.DeletePet(tt.pet)
+			err := s.DeletePet(context.Background(), tt.pet)
 
 			if !tt.wantErr {
 				require.NoError(t, err)
 			} else {
 				require.Error(t, err)
+
+				if tt.wantKind != nil {
+					require.ErrorIs(t, err, tt.wantKind)
+				}
+			}
+		})
+	}
+}
+
+func TestService_RestorePet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+	expectTx(repMock)
+
+	tests := []struct {
+		name     string
+		repErr   error
+		getErr   error
+		wantKind error
+	}{
+		{
+			name: "check restored",
+		},
+		{
+			name:     "check not deleted",
+			repErr:   sql.ErrNoRows,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "check not found",
+			repErr:   sql.ErrNoRows,
+			getErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "check unavailable",
+			repErr:   context.DeadlineExceeded,
+			wantKind: ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			repMock.EXPECT().RestorePet(gomock.Any(), &model.Pet{ID: 1}).Return(tt.repErr)
+
+			if tt.repErr == nil || errors.Is(tt.repErr, sql.ErrNoRows) {
+				var pet *model.Pet
+				if tt.getErr == nil {
+					pet = &model.Pet{ID: 1, Name: "Velho"}
+				}
+
+				repMock.EXPECT().GetPet(gomock.Any(), 1).Return(pet, tt.getErr)
 			}
+
+			res, err := s.RestorePet(context.Background(), 1)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "Velho", res.Name)
+			require.Equal(t, []string{}, res.Tags)
 		})
 	}
 }
 
-func TestService_IsExist(t *testing.T) {
+func TestService_PurgePets(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	repMock := mock_repository.NewMockIRepository(ctrl)
 	defer ctrl.Finish()
 
+	store := storage.NewMemoryStore()
+	s := NewService(testConf, repMock, store)
+
+	before := time.Now()
+	query := &model.PetsQuery{Filter: model.PetsFilter{DeletedBefore: &before}, Limit: purgeBatch}
+	photo := &model.Photo{ID: 1, PetID: 1, BlobKey: "pets/1/a", ThumbKey: "pets/1/a_thumb"}
+
+	require.NoError(t, store.Put(context.Background(), photo.BlobKey, strings.NewReader("photo"), "image/png"))
+	require.NoError(t, store.Put(context.Background(), photo.ThumbKey, strings.NewReader("thumb"), "image/jpeg"))
+
+	gomock.InOrder(
+		repMock.EXPECT().GetPets(gomock.Any(), query).Return([]*model.Pet{{ID: 1}, {ID: 2}}, 2, nil),
+		repMock.EXPECT().GetPhotos(gomock.Any(), 1).Return([]*model.Photo{photo}, nil),
+		repMock.EXPECT().PurgePet(gomock.Any(), &model.Pet{ID: 1}).Return(nil),
+		// the second pet is restored while purging
+		repMock.EXPECT().GetPhotos(gomock.Any(), 2).Return(nil, nil),
+		repMock.EXPECT().PurgePet(gomock.Any(), &model.Pet{ID: 2}).Return(sql.ErrNoRows),
+		repMock.EXPECT().GetPets(gomock.Any(), query).Return(nil, 0, nil),
+	)
+
+	n, err := s.PurgePets(context.Background(), before)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// photos content is deleted with the purged pet
+	_, err = store.Get(context.Background(), photo.BlobKey)
+	require.ErrorIs(t, err, storage.ErrNotExist)
+
+	_, err = store.Get(context.Background(), photo.ThumbKey)
+	require.ErrorIs(t, err, storage.ErrNotExist)
+
+	// failed purge stops the job
+	repMock.EXPECT().GetPets(gomock.Any(), query).Return([]*model.Pet{{ID: 3}}, 1, nil)
+	repMock.EXPECT().GetPhotos(gomock.Any(), 3).Return(nil, nil)
+	repMock.EXPECT().PurgePet(gomock.Any(), &model.Pet{ID: 3}).Return(context.DeadlineExceeded)
+
+	n, err = s.PurgePets(context.Background(), before)
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.Zero(t, n)
+}
+
+// petIDs is used to get IDs of given pets
+func petIDs(pets []*model.Pet) []int {
+	ids := make([]int, 0, len(pets))
+
+	for _, p := range pets {
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
+
+func TestValidatePet(t *testing.T) {
+	weight := -1.0
+	future := model.NewDate(time.Now().Year()+1, time.January, 1)
+
 	tests := []struct {
-		name   string
-		id     int
-		pet    *model.Pet
-		res    bool
-		repErr error
+		name       string
+		pet        *model.Pet
+		wantFields []string
 	}{
 		{
-			name: "check good id 1",
-			id:   1,
-			pet:  &model.Pet{ID: 1},
-			res:  true,
+			name: "check valid",
+			pet:  &model.Pet{Name: "Velho", Species: model.SpeciesDog, Sex: model.SexMale, Status: model.StatusAvailable},
 		},
 		{
-			name: "check good id 2",
-			id:   2,
-			pet:  &model.Pet{ID: 2},
-			res:  true,
+			name:       "check blank enums",
+			pet:        &model.Pet{Name: "Velho"},
+			wantFields: []string{"species", "sex", "status"},
 		},
 		{
-			name: "check good id 3",
-			id:   3,
-			pet:  &model.Pet{ID: 3},
-			res:  true,
+			name: "check invalid details",
+			pet: &model.Pet{Name: "Velho", Species: model.SpeciesDog, Sex: model.SexMale, Status: "lost",
+				Breed: strings.Repeat("b", 101), BirthDate: &future, Weight: &weight},
+			wantFields: []string{"status", "breed", "birth_date", "weight"},
 		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePet(tt.pet)
+
+			if tt.wantFields == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var e *Error
+			require.ErrorAs(t, err, &e)
+			require.ErrorIs(t, err, ErrValidation)
+
+			fields := make([]string, 0, len(e.Fields))
+			for _, f := range e.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			require.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestService_ExportPets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	birth := model.Date{Time: time.Now().AddDate(-2, 0, 0)}
+
+	tests := []struct {
+		name     string
+		query    *model.PetsQuery
+		repPets  []*model.Pet
+		repErr   error
+		wantTags []string
+		wantKind error
+	}{
 		{
-			name: "check return nil",
-			id:   3,
-			res:  false,
+			name:     "check exported",
+			query:    &model.PetsQuery{Filter: model.PetsFilter{Tags: []string{"Senior", "senior"}}},
+			repPets:  []*model.Pet{{ID: 1, BirthDate: &birth}, {ID: 2}},
+			wantTags: []string{"senior"},
 		},
 		{
-			name: "check return 0 id",
-			id:   3,
-			pet:  &model.Pet{ID: 0},
-			res:  false,
+			name:     "check unavailable",
+			query:    &model.PetsQuery{},
+			repErr:   context.DeadlineExceeded,
+			wantKind: ErrUnavailable,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewService(repMock)
+			s := NewService(testConf, repMock, nil)
+
+			repMock.EXPECT().ExportPets(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, query *model.PetsQuery, fn func(*model.Pet) error) error {
+					require.Equal(t, tt.wantTags, query.Filter.Tags)
+
+					for _, p := range tt.repPets {
+						if err := fn(p); err != nil {
+							return err
+						}
+					}
 
-			repMock.EXPECT().GetPet(tt.id).Return(tt.pet, tt.repErr)
+					return tt.repErr
+				})
 
-			res := s.IsExist(tt.id)
+			var res []*model.Pet
+
+			err := s.ExportPets(context.Background(), tt.query, func(pet *model.Pet) error {
+				res = append(res, pet)
+				return nil
+			})
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
 
-			require.Equal(t, tt.res, res)
+			require.NoError(t, err)
+			require.Len(t, res, len(tt.repPets))
+			require.Equal(t, 2, res[0].Age.Years)
+			require.Nil(t, res[1].Age)
 		})
 	}
 }
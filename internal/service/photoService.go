@@ -0,0 +1,270 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+
+	"pets/internal/model"
+	"pets/internal/storage"
+	"pets/pkg/logger"
+)
+
+// Pet photo limits
+const (
+	// defaultMaxPhotoSize is a max size of uploaded photo in bytes used if it is not set in config
+	defaultMaxPhotoSize = 10 << 20
+	// maxPhotoPixels is a max number of photo pixels, so small files can not be decoded to huge images
+	maxPhotoPixels = 50_000_000
+	// thumbSize is a max width and height of photo thumbnails in pixels
+	thumbSize = 256
+)
+
+// thumbContentType is a content type of photo thumbnails
+const thumbContentType = "image/jpeg"
+
+// photoTypes is a set of sniffed content types accepted as pet photos
+var photoTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true}
+
+// GetPhotos is implementing IService.GetPhotos function
+func (s *Service) GetPhotos(ctx context.Context, petID int) ([]*model.Photo, error) {
+	pet, err := s.GetPet(ctx, petID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.GetPhotos(ctx, petID)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, p := range res {
+		p.SetLocal()
+		p.SetURLs(pet.PrimaryPhotoID)
+	}
+
+	return res, nil
+}
+
+// GetPhoto is implementing IService.GetPhoto function
+func (s *Service) GetPhoto(ctx context.Context, petID int, id int) (*model.Photo, error) {
+	pet, err := s.GetPet(ctx, petID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.GetPhoto(ctx, petID, id)
+	if err != nil {
+		return nil, domainError(err, photoNotFound(petID, id))
+	}
+
+	res.SetLocal()
+	res.SetURLs(pet.PrimaryPhotoID)
+
+	return res, nil
+}
+
+// AddPhoto is implementing IService.AddPhoto function. Photo is read into memory up to the size limit, its content
+// type is sniffed from the content, so client given content type is ignored
+func (s *Service) AddPhoto(ctx context.Context, petID int, r io.Reader, primary bool) (*model.Photo, error) {
+	pet, err := s.GetPet(ctx, petID)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more byte is read to know if the photo exceeds the limit
+	data, err := io.ReadAll(io.LimitReader(r, s.maxPhotoSize+1))
+	if err != nil {
+		return nil, NewValidationError(fmt.Sprintf("cannot read photo: %v", err.Error()))
+	}
+
+	if int64(len(data)) > s.maxPhotoSize {
+		return nil, NewTooLargeError(fmt.Sprintf("photo cannot be larger than %v bytes", s.maxPhotoSize))
+	}
+
+	photo, err := decodePhoto(data)
+	if err != nil {
+		return nil, err
+	}
+
+	thumb, err := thumbnail(data)
+	if err != nil {
+		return nil, err
+	}
+
+	photo.PetID = petID
+	photo.BlobKey, err = blobKey(petID)
+	if err != nil {
+		return nil, err
+	}
+
+	photo.ThumbKey = photo.BlobKey + "_thumb"
+
+	if err = s.store.Put(ctx, photo.BlobKey, bytes.NewReader(data), photo.ContentType); err != nil {
+		return nil, NewUnavailableError(err)
+	}
+
+	if err = s.store.Put(ctx, photo.ThumbKey, thumb, thumbContentType); err != nil {
+		s.deleteBlobs(ctx, photo.BlobKey)
+		return nil, NewUnavailableError(err)
+	}
+
+	if err = s.repository.AddPhoto(ctx, photo, primary); err != nil {
+		s.deleteBlobs(ctx, photo.BlobKey, photo.ThumbKey)
+		return nil, domainError(err, "")
+	}
+
+	primaryID := pet.PrimaryPhotoID
+	if primary || primaryID == nil {
+		primaryID = &photo.ID
+	}
+
+	photo.SetLocal()
+	photo.SetURLs(primaryID)
+
+	return photo, nil
+}
+
+// OpenPhoto is implementing IService.OpenPhoto function
+func (s *Service) OpenPhoto(ctx context.Context, petID int, id int, thumbnail bool) (io.ReadCloser, string, error) {
+	if _, err := s.GetPet(ctx, petID); err != nil {
+		return nil, "", err
+	}
+
+	photo, err := s.repository.GetPhoto(ctx, petID, id)
+	if err != nil {
+		return nil, "", domainError(err, photoNotFound(petID, id))
+	}
+
+	key, contentType := photo.BlobKey, photo.ContentType
+	if thumbnail {
+		key, contentType = photo.ThumbKey, thumbContentType
+	}
+
+	rc, err := s.store.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotExist) {
+		logger.Log().WithField("layer", "Service-OpenPhoto").Errorf("blob %v of photo %v not found", key, id)
+		return nil, "", NewNotFoundError(photoNotFound(petID, id))
+	}
+
+	if err != nil {
+		return nil, "", NewUnavailableError(err)
+	}
+
+	return rc, contentType, nil
+}
+
+// DeletePhoto is implementing IService.DeletePhoto function. Photo content is deleted after the photo record, so
+// content of a deleted photo is never referenced
+func (s *Service) DeletePhoto(ctx context.Context, petID int, id int) error {
+	photo, err := s.repository.GetPhoto(ctx, petID, id)
+	if err != nil {
+		return domainError(err, photoNotFound(petID, id))
+	}
+
+	if err = s.repository.DeletePhoto(ctx, photo); err != nil {
+		return domainError(err, photoNotFound(petID, id))
+	}
+
+	s.deleteBlobs(ctx, photo.BlobKey, photo.ThumbKey)
+
+	return nil
+}
+
+// SetPrimaryPhoto is implementing IService.SetPrimaryPhoto function
+func (s *Service) SetPrimaryPhoto(ctx context.Context, petID int, id int) error {
+	err := s.repository.SetPrimaryPhoto(ctx, &model.Photo{ID: id, PetID: petID})
+
+	return domainError(err, photoNotFound(petID, id))
+}
+
+// deleteBlobs is used to delete blobs with given keys. Errors are logged only, as not deleted blobs are not referenced
+// anymore
+func (s *Service) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, k := range keys {
+		if err := s.store.Delete(ctx, k); err != nil {
+			logger.Log().WithField("layer", "Service-DeleteBlobs").Errorf("err delete blob %v: %v", k, err.Error())
+		}
+	}
+}
+
+// decodePhoto is used to check given photo content and to get photo model with its content type, size and
+// dimensions. Will return ErrUnsupportedMedia kind error if content type is not one of photoTypes, ErrTooLarge kind
+// error if photo has more than maxPhotoPixels pixels, ErrValidation kind error if content is empty or corrupted
+func decodePhoto(data []byte) (*model.Photo, error) {
+	if len(data) == 0 {
+		return nil, NewValidationError("photo cannot be empty")
+	}
+
+	contentType := http.DetectContentType(data)
+	if !photoTypes[contentType] {
+		return nil, NewUnsupportedMediaError(fmt.Sprintf("photo should be JPEG, PNG or GIF image, got %v", contentType))
+	}
+
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, NewValidationError(fmt.Sprintf("photo is corrupted: %v", err.Error()))
+	}
+
+	if conf.Width*conf.Height > maxPhotoPixels {
+		return nil, NewTooLargeError(fmt.Sprintf("photo cannot have more than %v pixels", maxPhotoPixels))
+	}
+
+	return &model.Photo{ContentType: contentType, Size: int64(len(data)), Width: conf.Width, Height: conf.Height}, nil
+}
+
+// thumbnail is used to get JPEG thumbnail of given photo content fitting into thumbSize square with photo aspect ratio
+// kept. Photos smaller than the square are not scaled up
+func thumbnail(data []byte) (io.Reader, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, NewValidationError(fmt.Sprintf("photo is corrupted: %v", err.Error()))
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	if w > thumbSize || h > thumbSize {
+		if w >= h {
+			w, h = thumbSize, max(1, h*thumbSize/w)
+		} else {
+			w, h = max(1, w*thumbSize/h), thumbSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+
+	buf := &bytes.Buffer{}
+	if err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// blobKey is used to get new random blob store key of the pet with given ID photo
+func blobKey(petID int) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("pets/%v/%v", petID, hex.EncodeToString(b)), nil
+}
+
+// photoNotFound is used to get not found error detail for pet photo with given pet ID and ID
+func photoNotFound(petID int, id int) string {
+	return fmt.Sprintf("photo %v of pet %v not found", id, petID)
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/config"
+	"pets/internal/model"
+	"pets/internal/storage"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_AddPhoto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	primaryID := 5
+
+	tests := []struct {
+		name    string
+		photo   []byte
+		pet     *model.Pet
+		primary bool
+		petErr  error
+		goToRep bool
+
+		wantPrimary bool
+		wantWidth   int
+		wantHeight  int
+		wantKind    error
+	}{
+		{
+			name:        "check first photo",
+			photo:       testPNG(t, 1024, 512),
+			pet:         &model.Pet{ID: 1},
+			goToRep:     true,
+			wantPrimary: true,
+			wantWidth:   1024,
+			wantHeight:  512,
+		},
+		{
+			name:       "check not primary",
+			photo:      testPNG(t, 10, 20),
+			pet:        &model.Pet{ID: 1, PrimaryPhotoID: &primaryID},
+			goToRep:    true,
+			wantWidth:  10,
+			wantHeight: 20,
+		},
+		{
+			name:        "check primary",
+			photo:       testPNG(t, 10, 20),
+			pet:         &model.Pet{ID: 1, PrimaryPhotoID: &primaryID},
+			primary:     true,
+			goToRep:     true,
+			wantPrimary: true,
+			wantWidth:   10,
+			wantHeight:  20,
+		},
+		{
+			name:     "check pet not found",
+			photo:    testPNG(t, 10, 20),
+			pet:      &model.Pet{ID: 1},
+			petErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "check too large",
+			photo:    make([]byte, 1025),
+			pet:      &model.Pet{ID: 1},
+			wantKind: ErrTooLarge,
+		},
+		{
+			name:     "check not image",
+			photo:    []byte("just some text"),
+			pet:      &model.Pet{ID: 1},
+			wantKind: ErrUnsupportedMedia,
+		},
+		{
+			name:     "check corrupted",
+			photo:    testPNG(t, 10, 20)[:60],
+			pet:      &model.Pet{ID: 1},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check empty",
+			pet:      &model.Pet{ID: 1},
+			wantKind: ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			conf := &config.Service{CursorSecret: "secret", MaxPhotoSize: 1 << 20}
+
+			if tt.wantKind == ErrTooLarge {
+				conf.MaxPhotoSize = 1024
+			}
+
+			s := NewService(conf, repMock, store)
+
+			repMock.EXPECT().GetPet(gomock.Any(), 1).Return(tt.pet, tt.petErr)
+
+			if tt.goToRep {
+				repMock.EXPECT().AddPhoto(gomock.Any(), gomock.Any(), tt.primary).DoAndReturn(func(_ context.Context, p *model.Photo, _ bool) error {
+					p.ID = 7
+					return nil
+				})
+			}
+
+			res, err := s.AddPhoto(context.Background(), 1, bytes.NewReader(tt.photo), tt.primary)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, 7, res.ID)
+			require.Equal(t, "image/png", res.ContentType)
+			require.Equal(t, int64(len(tt.photo)), res.Size)
+			require.Equal(t, tt.wantWidth, res.Width)
+			require.Equal(t, tt.wantHeight, res.Height)
+			require.Equal(t, tt.wantPrimary, res.Primary)
+			require.Equal(t, "/api/v1/pet/1/photos/7", res.URL)
+			require.Equal(t, "/api/v1/pet/1/photos/7/thumbnail", res.ThumbnailURL)
+
+			rc, err := store.Get(context.Background(), res.BlobKey)
+			require.NoError(t, err)
+
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.Equal(t, tt.photo, data)
+
+			rc, err = store.Get(context.Background(), res.ThumbKey)
+			require.NoError(t, err)
+
+			thumb, err := jpeg.DecodeConfig(rc)
+			require.NoError(t, err)
+			require.LessOrEqual(t, thumb.Width, thumbSize)
+			require.LessOrEqual(t, thumb.Height, thumbSize)
+		})
+	}
+}
+
+func TestService_AddPhotoRepError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	store := storage.NewMemoryStore()
+	s := NewService(testConf, repMock, store)
+
+	var stored *model.Photo
+
+	repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1}, nil)
+	repMock.EXPECT().AddPhoto(gomock.Any(), gomock.Any(), false).DoAndReturn(func(_ context.Context, p *model.Photo, _ bool) error {
+		stored = p
+		return sql.ErrConnDone
+	})
+
+	_, err := s.AddPhoto(context.Background(), 1, bytes.NewReader(testPNG(t, 10, 10)), false)
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	// uploaded blobs are deleted if the photo is not added
+	_, err = store.Get(context.Background(), stored.BlobKey)
+	require.ErrorIs(t, err, storage.ErrNotExist)
+
+	_, err = store.Get(context.Background(), stored.ThumbKey)
+	require.ErrorIs(t, err, storage.ErrNotExist)
+}
+
+func TestService_OpenPhoto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	store := storage.NewMemoryStore()
+	require.NoError(t, store.Put(context.Background(), "photo", bytes.NewReader([]byte("photo")), "image/png"))
+	require.NoError(t, store.Put(context.Background(), "photo_thumb", bytes.NewReader([]byte("thumb")), thumbContentType))
+
+	photo := &model.Photo{ID: 2, PetID: 1, BlobKey: "photo", ThumbKey: "photo_thumb", ContentType: "image/png"}
+
+	tests := []struct {
+		name      string
+		thumbnail bool
+		petErr    error
+		photo     *model.Photo
+
+		wantContent     string
+		wantContentType string
+		wantKind        error
+	}{
+		{
+			name:            "check photo",
+			photo:           photo,
+			wantContent:     "photo",
+			wantContentType: "image/png",
+		},
+		{
+			name:            "check thumbnail",
+			thumbnail:       true,
+			photo:           photo,
+			wantContent:     "thumb",
+			wantContentType: thumbContentType,
+		},
+		{
+			name:     "check deleted pet",
+			petErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "check blob not found",
+			photo:    &model.Photo{ID: 2, PetID: 1, BlobKey: "lost", ThumbKey: "lost_thumb"},
+			wantKind: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, store)
+
+			repMock.EXPECT().GetPet(gomock.Any(), 1).Return(&model.Pet{ID: 1}, tt.petErr)
+
+			if tt.photo != nil {
+				repMock.EXPECT().GetPhoto(gomock.Any(), 1, 2).Return(tt.photo, nil)
+			}
+
+			rc, contentType, err := s.OpenPhoto(context.Background(), 1, 2, tt.thumbnail)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			defer rc.Close()
+
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantContent, string(data))
+			require.Equal(t, tt.wantContentType, contentType)
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "check landscape", width: 1024, height: 512, wantWidth: thumbSize, wantHeight: thumbSize / 2},
+		{name: "check portrait", width: 300, height: 600, wantWidth: thumbSize / 2, wantHeight: thumbSize},
+		{name: "check small", width: 100, height: 50, wantWidth: 100, wantHeight: 50},
+		{name: "check thin", width: 2000, height: 1, wantWidth: thumbSize, wantHeight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := thumbnail(testPNG(t, tt.width, tt.height))
+			require.NoError(t, err)
+
+			conf, err := jpeg.DecodeConfig(r)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantWidth, conf.Width)
+			require.Equal(t, tt.wantHeight, conf.Height)
+		})
+	}
+}
+
+// testPNG is used to get PNG image content of given size
+func testPNG(t *testing.T, width int, height int) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, width, height))))
+
+	return buf.Bytes()
+}
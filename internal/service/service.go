@@ -1,46 +1,277 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"io"
+	"time"
+
+	"pets/internal/config"
 	"pets/internal/model"
 	"pets/internal/repository"
+	"pets/internal/storage"
 	"pets/pkg/logger"
 )
 
-// IService is an app service layer interface
+// IService is an app service layer interface. Expected failures are returned as *Error domain errors, see ErrNotFound,
+// ErrValidation, ErrConflict, ErrUnavailable, ErrTooLarge and ErrUnsupportedMedia. ErrUnavailable kind error is
+// returned if storage is not reachable
 type IService interface {
-	// GetPets is used to get pets. Limit and offset can be used for pagination. 0 limit and 0 offset will return
-	// all existing pets. Not convertable values limit and offset will be ignored. For order arg can be used "asc" and "desc"
-	// string value to order pets by ID.
-	// Function will return slice of pets model, total found pets or error
-	GetPets(limit string, offset string, order string) ([]*model.Pet, int, error)
+	// GetPets is used to get pets with their tags matching given query, soft deleted pets are included if query filter
+	// IncludeDeleted is set. Pagination can be used by setting query limit and offset. Cursor can be used instead of
+	// offset for keyset pagination, query offset and sort are ignored then, sort is taken from the cursor. Cursor is
+	// returned as nextCursor if there are pets after the returned page. ErrInvalidCursor is returned for malformed
+	// cursor.
+	// Function will return slice of pets model, total number of pets matching query filter regardless of pagination,
+	// next page cursor or error
+	GetPets(ctx context.Context, query *model.PetsQuery, cursor string) (pets []*model.Pet, total int, nextCursor string, err error)
+	// GetPet is used to get pet by given ID. If pet with given ID not exist or deleted, will return ErrNotFound kind
+	// error.
+	GetPet(ctx context.Context, id int) (*model.Pet, error)
+	// ExportPets is used to call given function for every pet with its tags matching given query filter in query sort
+	// order as GetPets does, pagination is ignored. Pets are read one by one, so all matching pets can be exported.
+	// Iteration is stopped if the function returns error, the error is returned
+	ExportPets(ctx context.Context, query *model.PetsQuery, fn func(pet *model.Pet) error) error
+
+	// AddPet is used to add new pet to the DB. Blank species, sex and status are set to defaults, owner is ignored.
+	// Will return ErrValidation kind error if name is blank, any field is invalid or status is one of the adoption
+	// workflow statuses.
+	AddPet(ctx context.Context, pet *model.Pet) (int, error)
+
+	// UpdatePet is used to update existing pet by "id" field, owner is ignored. Status change is recorded in pet status
+	// history. Will return ErrValidation kind error as AddPet, ErrNotFound kind error if pet with given ID not exist,
+	// ErrConflict kind error if status cannot be changed manually, see model.Status.CanTransition. If "version" field is
+	// not 0 pet is updated only if it matches the stored version, ErrPreconditionFailed kind error is returned otherwise.
+	UpdatePet(ctx context.Context, pet *model.Pet) error
+	// PatchPet is used to update only given fields of existing pet, named as in model.PetFields. Pet must have all
+	// fields set as UpdatePet, status change is applied as in UpdatePet. Nothing is written if fields are empty and
+	// status is not changed. Will return errors as UpdatePet, ErrValidation kind error if any field cannot be changed.
+	PatchPet(ctx context.Context, pet *model.Pet, fields []string) error
+
+	// DeletePet is used to soft delete existing pet. Deleted pet is hidden, its records and photos are kept until the
+	// pet is restored or purged. Only "id" and "version" fields will be used, version is checked as in UpdatePet. Will
+	// return ErrNotFound kind error if pet with given ID not exist or already deleted.
+	DeletePet(ctx context.Context, pet *model.Pet) error
+	// RestorePet is used to restore soft deleted pet with given ID. Function will return the restored pet. Will return
+	// ErrConflict kind error if pet is not deleted, ErrNotFound kind error if pet with given ID not exist.
+	RestorePet(ctx context.Context, id int) (*model.Pet, error)
+	// PurgePets is used to hard delete pets soft deleted before given time with their records and photos. Function
+	// will return number of purged pets, it is set on error too.
+	PurgePets(ctx context.Context, before time.Time) (int, error)
+	// AddPets is used to add given pets as AddPet does with a single repository call. If atomic is set, no pet is added
+	// if any pet is invalid. Function will return pet errors in pets order, nil for added pets which IDs are set. Will
+	// return ErrValidation kind error if pets are empty, ErrTooLarge kind error if there are more than MaxBatchSize
+	// pets
+	AddPets(ctx context.Context, pets []*model.Pet, atomic bool) ([]error, error)
+	// PatchPets is used to save given pet patches as PatchPet does. If atomic is set, patches are saved in one
+	// transaction and no patch is saved if any patch fails, otherwise patches are saved one by one. Function will
+	// return patch errors in patches order, nil for saved patches, ErrNotApplied kind error for patches of a failed
+	// atomic batch. Will return batch size errors as AddPets
+	PatchPets(ctx context.Context, patches []*model.PetPatch, atomic bool) ([]error, error)
+	// DeletePets is used to delete given pets as DeletePet does. Atomic batch and errors are handled as in PatchPets
+	DeletePets(ctx context.Context, pets []*model.Pet, atomic bool) ([]error, error)
+	// ImportPets is used to add pets read from given CSV or NDJSON reader. Rows are read one by one and valid pets are
+	// added by chunks, so the data is not buffered. Invalid rows are rejected and listed in the report, other rows are
+	// imported. If opts DryRun is set, rows are only validated. Will return ErrValidation kind error if options or CSV
+	// header are invalid, ErrTooLarge kind error if NDJSON line is too long. Pets of chunks added before the error are
+	// kept, errors occurred while rows are read are returned with the report of the rows read before
+	ImportPets(ctx context.Context, r io.Reader, opts *model.ImportOptions) (*model.ImportReport, error)
+	// RevertPet is used to set pet fields changed by the client back to their values at given historical version of
+	// the pet with given ID. Status, owner, tags and photos are not reverted. If version field is set, pet is reverted
+	// only if it is the stored version. Function will return the reverted pet. Will return ErrValidation kind error if
+	// the version is out of range, ErrPreconditionFailed kind error if the stored version differs, ErrNotFound kind
+	// error if pet with given ID or its version history not exist
+	RevertPet(ctx context.Context, pet *model.Pet, to int) (*model.Pet, error)
+
+	// GetAudit is used to get pet audit entries matching given filter, newest first. Pagination can be used by setting
+	// filter limit and offset. Function will return slice of audit entries, total number of matching entries
+	// regardless of pagination or error. Will return ErrNotFound kind error if filter pet ID is set and the pet has no
+	// matching entries and not exist
+	GetAudit(ctx context.Context, filter *model.AuditFilter) (entries []*model.AuditEntry, total int, err error)
+
+	// GetOwners is used to get owners ordered by ID. Pagination can be used by setting limit and offset. Function will
+	// return slice of owners model, total number of owners regardless of pagination or error
+	GetOwners(ctx context.Context, limit int, offset int) (owners []*model.Owner, total int, err error)
+	// GetOwner is used to get owner by given ID. If owner with given ID not exist, will return ErrNotFound kind error.
+	GetOwner(ctx context.Context, id int) (*model.Owner, error)
+	// AddOwner is used to add new owner. Will return ErrValidation kind error if name is blank, both email and phone
+	// are blank or contacts are malformed.
+	AddOwner(ctx context.Context, owner *model.Owner) (int, error)
+	// UpdateOwner is used to update existing owner by "id" field. Will return ErrValidation kind error as AddOwner,
+	// ErrNotFound kind error if owner with given ID not exist.
+	UpdateOwner(ctx context.Context, owner *model.Owner) error
+	// DeleteOwner is used to delete existing owner. Only "id" field will be used. Will return ErrConflict kind error if
+	// owner has pets, ErrNotFound kind error if owner with given ID not exist.
+	DeleteOwner(ctx context.Context, owner *model.Owner) error
+	// GetOwnerPets is used to get pets of the owner with given ID matching given query as GetPets does. Will return
+	// ErrNotFound kind error if owner with given ID not exist.
+	GetOwnerPets(ctx context.Context, ownerID int, query *model.PetsQuery, cursor string) (pets []*model.Pet, total int, nextCursor string, err error)
+	// TransferPet is used to transfer pet with transfer "pet_id" to the owner with "to_owner_id" and record it in pet
+	// ownership history. Nil "to_owner_id" releases the pet from its owner. Will return ErrNotFound kind error if pet
+	// not exist, ErrValidation kind error if owner not exist, ErrConflict kind error if pet already has this owner.
+	TransferPet(ctx context.Context, transfer *model.Ownership) error
+	// GetOwnership is used to get ownership history of the pet with given ID, oldest transfer first. Will return
+	// ErrNotFound kind error if pet with given ID not exist.
+	GetOwnership(ctx context.Context, petID int) ([]*model.Ownership, error)
+
+	// SubmitApplication is used to submit adoption application for the pet with application "pet_id". Will return
+	// ErrValidation kind error if applicant is blank or any field is invalid, ErrNotFound kind error if pet not exist,
+	// ErrConflict kind error if pet is not available.
+	SubmitApplication(ctx context.Context, app *model.Application) (int, error)
+	// GetApplications is used to get adoption applications matching given filter ordered by ID. Will return
+	// ErrValidation kind error if filter status is unknown.
+	GetApplications(ctx context.Context, filter *model.ApplicationsFilter) ([]*model.Application, error)
+	// GetPetApplications is used to get adoption applications for the pet with given ID ordered by ID. Will return
+	// ErrNotFound kind error if pet with given ID not exist.
+	GetPetApplications(ctx context.Context, petID int) ([]*model.Application, error)
+	// GetApplication is used to get adoption application by given ID. If application with given ID not exist, will
+	// return ErrNotFound kind error.
+	GetApplication(ctx context.Context, id int) (*model.Application, error)
+	// ApproveApplication is used to approve submitted application with given ID and reserve its pet. Will return
+	// ErrNotFound kind error if application not exist, ErrConflict kind error if application is not submitted or pet
+	// is not available.
+	ApproveApplication(ctx context.Context, id int, reason string) (*model.Application, error)
+	// RejectApplication is used to reject submitted or approved application with given ID. Pet reserved by approved
+	// application is made available again. Will return ErrNotFound kind error if application not exist, ErrConflict
+	// kind error if application is already rejected or completed.
+	RejectApplication(ctx context.Context, id int, reason string) (*model.Application, error)
+	// CompleteApplication is used to complete approved application with given ID and mark its pet adopted. Will return
+	// ErrNotFound kind error if application not exist, ErrConflict kind error if application is not approved.
+	CompleteApplication(ctx context.Context, id int, reason string) (*model.Application, error)
+	// ReturnPet is used to mark adopted pet with given ID returned to the shelter. Will return ErrNotFound kind error if
+	// pet not exist, ErrConflict kind error if pet is not adopted.
+	ReturnPet(ctx context.Context, petID int, reason string) (*model.Transition, error)
+	// GetTransitions is used to get status history of the pet with given ID, oldest transition first. Will return
+	// ErrNotFound kind error if pet with given ID not exist.
+	GetTransitions(ctx context.Context, petID int) ([]*model.Transition, error)
 
-	// AddPet is used to add new pet to the DB. Only "name" field will be used.
-	AddPet(pet *model.Pet) (int, error)
+	// GetVaccinations is used to get vaccinations of the pet with given ID, oldest shot first. Will return ErrNotFound
+	// kind error if pet with given ID not exist.
+	GetVaccinations(ctx context.Context, petID int) ([]*model.Vaccination, error)
+	// GetVaccination is used to get vaccination of the pet with given pet ID by given ID. Will return ErrNotFound kind
+	// error if vaccination not exist.
+	GetVaccination(ctx context.Context, petID int, id int) (*model.Vaccination, error)
+	// AddVaccination is used to add new vaccination of the pet with "pet_id". Will return ErrValidation kind error if
+	// vaccine or given_on is blank or any field is invalid, ErrNotFound kind error if pet not exist.
+	AddVaccination(ctx context.Context, vaccination *model.Vaccination) (int, error)
+	// UpdateVaccination is used to update existing vaccination by "id" and "pet_id" fields. Will return ErrValidation
+	// kind error as AddVaccination, ErrNotFound kind error if vaccination not exist.
+	UpdateVaccination(ctx context.Context, vaccination *model.Vaccination) error
+	// DeleteVaccination is used to delete existing vaccination by "id" and "pet_id" fields. Will return ErrNotFound
+	// kind error if vaccination not exist.
+	DeleteVaccination(ctx context.Context, vaccination *model.Vaccination) error
+	// GetDueVaccinations is used to get latest vaccinations of every pet and vaccine with the next shot due on or
+	// before given date, earliest due first. Overdue is set for shots due before today.
+	GetDueVaccinations(ctx context.Context, before model.Date) ([]*model.DueVaccination, error)
 
-	// UpdatePet is used to update existing pet. If pet with given ID not exist, will return error. Only "name" and "id"
-	// fields will be used.
-	UpdatePet(pet *model.Pet) error
+	// GetTreatments is used to get treatments of the pet with given ID, oldest first. Will return ErrNotFound kind
+	// error if pet with given ID not exist.
+	GetTreatments(ctx context.Context, petID int) ([]*model.Treatment, error)
+	// GetTreatment is used to get treatment of the pet with given pet ID by given ID. Will return ErrNotFound kind error
+	// if treatment not exist.
+	GetTreatment(ctx context.Context, petID int, id int) (*model.Treatment, error)
+	// AddTreatment is used to add new treatment of the pet with "pet_id". Blank kind is set to other. Will return
+	// ErrValidation kind error if name or given_on is blank or any field is invalid, ErrNotFound kind error if pet not
+	// exist.
+	AddTreatment(ctx context.Context, treatment *model.Treatment) (int, error)
+	// UpdateTreatment is used to update existing treatment by "id" and "pet_id" fields. Will return ErrValidation kind
+	// error as AddTreatment, ErrNotFound kind error if treatment not exist.
+	UpdateTreatment(ctx context.Context, treatment *model.Treatment) error
+	// DeleteTreatment is used to delete existing treatment by "id" and "pet_id" fields. Will return ErrNotFound kind
+	// error if treatment not exist.
+	DeleteTreatment(ctx context.Context, treatment *model.Treatment) error
 
-	// DeletePet is used to delete existing pet. If pet with given ID not exist, will return error. Only "id"
-	// field will be used.
-	DeletePet(pet *model.Pet) error
+	// GetPhotos is used to get photos of the pet with given ID, oldest first. Will return ErrNotFound kind error if pet
+	// with given ID not exist.
+	GetPhotos(ctx context.Context, petID int) ([]*model.Photo, error)
+	// GetPhoto is used to get photo of the pet with given pet ID by given ID. Will return ErrNotFound kind error if
+	// photo not exist.
+	GetPhoto(ctx context.Context, petID int, id int) (*model.Photo, error)
+	// AddPhoto is used to add photo read from given reader to the pet with given ID and to generate its thumbnail.
+	// The photo is made the pet primary photo if primary is true or the pet has no photos. Will return ErrNotFound
+	// kind error if pet not exist, ErrTooLarge kind error if photo exceeds the size limit, ErrUnsupportedMedia kind
+	// error if photo is not a JPEG, PNG or GIF image, ErrValidation kind error if photo is empty or corrupted.
+	AddPhoto(ctx context.Context, petID int, r io.Reader, primary bool) (*model.Photo, error)
+	// OpenPhoto is used to open content of the photo of the pet with given pet ID by given ID or its thumbnail.
+	// Caller should close returned reader. Will return ErrNotFound kind error if pet or photo not exist or pet is
+	// deleted.
+	OpenPhoto(ctx context.Context, petID int, id int, thumbnail bool) (rc io.ReadCloser, contentType string, err error)
+	// DeletePhoto is used to delete photo of the pet with given pet ID by given ID with its content. If it was the pet
+	// primary photo, the latest remaining photo becomes primary. Will return ErrNotFound kind error if photo not exist.
+	DeletePhoto(ctx context.Context, petID int, id int) error
+	// SetPrimaryPhoto is used to make photo of the pet with given pet ID by given ID the pet primary photo. Will return
+	// ErrNotFound kind error if photo not exist.
+	SetPrimaryPhoto(ctx context.Context, petID int, id int) error
 
-	// IsExist is used to check if Pet exists by given ID. If DB returns error function will return false.
-	IsExist(id int) bool
+	// GetTags is used to get tags ordered by name. Blank category will be ignored, otherwise only tags of given
+	// category are returned.
+	GetTags(ctx context.Context, category string) ([]*model.Tag, error)
+	// GetTag is used to get tag by given ID. If tag with given ID not exist, will return ErrNotFound kind error.
+	GetTag(ctx context.Context, id int) (*model.Tag, error)
+	// AddTag is used to add new tag. Name is converted to lower case. Will return ErrValidation kind error if name is
+	// blank or invalid, ErrConflict kind error if tag with this name already exists.
+	AddTag(ctx context.Context, tag *model.Tag) (int, error)
+	// UpdateTag is used to update existing tag by "id" field, pets keep the renamed tag. Will return errors as AddTag,
+	// ErrNotFound kind error if tag with given ID not exist.
+	UpdateTag(ctx context.Context, tag *model.Tag) error
+	// DeleteTag is used to delete existing tag and to remove it from all pets. Only "id" field will be used. Will
+	// return ErrNotFound kind error if tag with given ID not exist.
+	DeleteTag(ctx context.Context, tag *model.Tag) error
+	// SetPetTags is used to replace tags of the pet with given ID by tags with given names in one transaction. Names
+	// are converted to lower case, repeated names are ignored. Function will return the pet tag names ordered by name.
+	// Will return ErrValidation kind error if any tag does not exist, ErrNotFound kind error if pet not exist.
+	SetPetTags(ctx context.Context, petID int, names []string) ([]string, error)
 }
 
 // Service is a service struct implementing IService interface
 type Service struct {
 	repository repository.IRepository
+	// store is a blob store of pet photos content
+	store storage.BlobStore
+	// cursorSecret is a key used to sign pagination cursors
+	cursorSecret []byte
+	// maxPhotoSize is a max size of uploaded pet photo in bytes
+	maxPhotoSize int64
 }
 
-// NewService is used to get new Service instance
-func NewService(rep repository.IRepository) IService {
+// NewService is used to get new Service instance keeping data in given repository and photos content in given store
+func NewService(conf *config.Service, rep repository.IRepository, store storage.BlobStore) IService {
+	if conf == nil {
+		logger.Log().WithField("layer", "Service-Init").Fatalf("config is nil")
+	}
+
 	s := &Service{}
 
 	s.repository = rep
+	s.store = store
+	s.cursorSecret = []byte(conf.CursorSecret)
+	s.maxPhotoSize = conf.MaxPhotoSize
+
+	if s.maxPhotoSize <= 0 {
+		s.maxPhotoSize = defaultMaxPhotoSize
+	}
+
+	if len(s.cursorSecret) == 0 {
+		s.cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(s.cursorSecret); err != nil {
+			logger.Log().WithField("layer", "Service-Init").Fatalf("err generate cursor secret: %v", err.Error())
+		}
+
+		logger.Log().WithField("layer", "Service-Init").Warningf("cursor secret is not set, random one is used")
+	}
 
 	logger.Log().WithField("layer", "Service-Init").Infof("service created")
 
 	return s
 }
+
+// withTx is used to run given function in a repository transaction with a copy of Service bound to the transaction, so
+// checks made by the function hold until its changes are committed. See repository.IRepository WithTx
+func (s *Service) withTx(ctx context.Context, fn func(tx *Service) error) error {
+	return s.repository.WithTx(ctx, func(rep repository.IRepository) error {
+		tx := *s
+		tx.repository = rep
+
+		return fn(&tx)
+	})
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pets/internal/model"
+)
+
+// maxTagLen is a max length of tag name
+const maxTagLen = 50
+
+// tagNamePattern is a pattern of valid tag names: lower case words of letters and digits separated by "-"
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// GetTags is implementing IService.GetTags function
+func (s *Service) GetTags(ctx context.Context, category string) ([]*model.Tag, error) {
+	res, err := s.repository.GetTags(ctx, strings.TrimSpace(category))
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	for _, t := range res {
+		t.SetLocal()
+	}
+
+	return res, nil
+}
+
+// GetTag is implementing IService.GetTag function
+func (s *Service) GetTag(ctx context.Context, id int) (*model.Tag, error) {
+	res, err := s.repository.GetTag(ctx, id)
+	if err != nil {
+		return nil, domainError(err, tagNotFound(id))
+	}
+
+	res.SetLocal()
+
+	return res, nil
+}
+
+// AddTag is implementing IService.AddTag function
+func (s *Service) AddTag(ctx context.Context, tag *model.Tag) (int, error) {
+	if err := validateTag(tag); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkTagName(ctx, tag); err != nil {
+		return 0, err
+	}
+
+	if err := s.repository.AddTag(ctx, tag); err != nil {
+		return 0, domainError(err, "")
+	}
+
+	return tag.ID, nil
+}
+
+// UpdateTag is implementing IService.UpdateTag function
+func (s *Service) UpdateTag(ctx context.Context, tag *model.Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	if err := s.checkTagName(ctx, tag); err != nil {
+		return err
+	}
+
+	return domainError(s.repository.UpdateTag(ctx, tag), tagNotFound(tag.ID))
+}
+
+// DeleteTag is implementing IService.DeleteTag function
+func (s *Service) DeleteTag(ctx context.Context, tag *model.Tag) error {
+	return domainError(s.repository.DeleteTag(ctx, tag), tagNotFound(tag.ID))
+}
+
+// SetPetTags is implementing IService.SetPetTags function
+func (s *Service) SetPetTags(ctx context.Context, petID int, names []string) ([]string, error) {
+	names = normalizeTagNames(names)
+
+	var fields []FieldError
+
+	for _, n := range names {
+		if !tagNamePattern.MatchString(n) || len(n) > maxTagLen {
+			fields = append(fields, FieldError{Field: "tags", Message: fmt.Sprintf("invalid tag %q", n)})
+		}
+	}
+
+	if len(fields) != 0 {
+		return nil, NewValidationError("invalid tags", fields...)
+	}
+
+	tags, err := s.repository.GetTagsByNames(ctx, names)
+	if err != nil {
+		return nil, domainError(err, "")
+	}
+
+	known := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		known[t.Name] = true
+	}
+
+	for _, n := range names {
+		if !known[n] {
+			fields = append(fields, FieldError{Field: "tags", Message: fmt.Sprintf("unknown tag %q", n)})
+		}
+	}
+
+	if len(fields) != 0 {
+		return nil, NewValidationError("invalid tags", fields...)
+	}
+
+	if err = s.repository.SetPetTags(ctx, petID, names); err != nil {
+		return nil, domainError(err, petNotFound(petID))
+	}
+
+	return names, nil
+}
+
+// checkTagName is used to check that there is no other tag with the tag name. Will return ErrConflict kind error if
+// it exists
+func (s *Service) checkTagName(ctx context.Context, tag *model.Tag) error {
+	tags, err := s.repository.GetTagsByNames(ctx, []string{tag.Name})
+	if err != nil {
+		return domainError(err, "")
+	}
+
+	for _, t := range tags {
+		if t.ID != tag.ID {
+			return NewConflictError(fmt.Sprintf("tag %q already exists", tag.Name))
+		}
+	}
+
+	return nil
+}
+
+// validateTag is used to normalize and check tag fields given by user. Name is converted to lower case. Will return
+// ErrValidation kind error with all invalid fields
+func validateTag(tag *model.Tag) error {
+	var fields []FieldError
+
+	tag.Name = strings.ToLower(strings.TrimSpace(tag.Name))
+	tag.Category = strings.TrimSpace(tag.Category)
+
+	switch {
+	case tag.Name == "":
+		fields = append(fields, FieldError{Field: "name", Message: "cannot be blank"})
+	case len(tag.Name) > maxTagLen:
+		fields = append(fields, FieldError{Field: "name", Message: fmt.Sprintf("cannot be longer than %v", maxTagLen)})
+	case !tagNamePattern.MatchString(tag.Name):
+		fields = append(fields, FieldError{Field: "name", Message: "should be lower case letters and digits separated by \"-\""})
+	}
+
+	if len(tag.Category) > maxShortLen {
+		fields = append(fields, FieldError{Field: "category", Message: fmt.Sprintf("cannot be longer than %v", maxShortLen)})
+	}
+
+	if len(fields) != 0 {
+		return NewValidationError("invalid tag", fields...)
+	}
+
+	return nil
+}
+
+// normalizeTagNames is used to get given tag names in lower case without blank and repeated names ordered by name
+func normalizeTagNames(names []string) []string {
+	res := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+
+		if n != "" && !seen[n] {
+			seen[n] = true
+			res = append(res, n)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// tagNotFound is used to get not found error detail for tag with given ID
+func tagNotFound(id int) string {
+	return fmt.Sprintf("tag %v not found", id)
+}
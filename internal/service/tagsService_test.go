@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"pets/internal/model"
+	mock_repository "pets/mocks/repository"
+)
+
+func TestService_AddTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		tag      *model.Tag
+		existing []*model.Tag
+		goToRep  bool
+		wantName string
+		wantKind error
+	}{
+		{
+			name:     "check add",
+			tag:      &model.Tag{Name: " Good-With-Kids ", Category: "behavior"},
+			goToRep:  true,
+			wantName: "good-with-kids",
+		},
+		{
+			name:     "check blank",
+			tag:      &model.Tag{Name: "  "},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check invalid name",
+			tag:      &model.Tag{Name: "special needs"},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check exists",
+			tag:      &model.Tag{Name: "senior"},
+			existing: []*model.Tag{{ID: 3, Name: "senior"}},
+			wantKind: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.goToRep || tt.existing != nil {
+				repMock.EXPECT().GetTagsByNames(gomock.Any(), gomock.Any()).Return(tt.existing, nil)
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().AddTag(gomock.Any(), tt.tag).DoAndReturn(func(_ context.Context, tag *model.Tag) error {
+					tag.ID = 7
+					return nil
+				})
+			}
+
+			id, err := s.AddTag(context.Background(), tt.tag)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, 7, id)
+			require.Equal(t, tt.wantName, tt.tag.Name)
+		})
+	}
+}
+
+func TestService_SetPetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repMock := mock_repository.NewMockIRepository(ctrl)
+	defer ctrl.Finish()
+
+	known := []*model.Tag{{ID: 1, Name: "senior"}, {ID: 2, Name: "special-needs"}}
+
+	tests := []struct {
+		name     string
+		names    []string
+		goToTags bool
+		tags     []*model.Tag
+		goToRep  bool
+		repErr   error
+		want     []string
+		wantKind error
+	}{
+		{
+			name:     "check set",
+			names:    []string{"Special-Needs", "senior", "senior "},
+			goToTags: true,
+			tags:     known,
+			goToRep:  true,
+			want:     []string{"senior", "special-needs"},
+		},
+		{
+			name:    "check clear",
+			names:   nil,
+			goToRep: true,
+			want:    []string{},
+		},
+		{
+			name:     "check invalid",
+			names:    []string{"senior", "special needs"},
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check unknown",
+			names:    []string{"senior", "unknown"},
+			goToTags: true,
+			tags:     known[:1],
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "check pet not found",
+			names:    []string{"senior"},
+			goToTags: true,
+			tags:     known[:1],
+			goToRep:  true,
+			repErr:   sql.ErrNoRows,
+			wantKind: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(testConf, repMock, nil)
+
+			if tt.goToTags {
+				repMock.EXPECT().GetTagsByNames(gomock.Any(), gomock.Any()).Return(tt.tags, nil)
+			} else if tt.goToRep {
+				repMock.EXPECT().GetTagsByNames(gomock.Any(), []string{}).Return(nil, nil)
+			}
+
+			if tt.goToRep {
+				repMock.EXPECT().SetPetTags(gomock.Any(), 1, gomock.Any()).Return(tt.repErr)
+			}
+
+			res, err := s.SetPetTags(context.Background(), 1, tt.names)
+
+			if tt.wantKind != nil {
+				require.ErrorIs(t, err, tt.wantKind)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, res)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"pets/pkg/logger"
+)
+
+// FSDriver is a config.Storage Driver value used to select FSStore
+const FSDriver = "fs"
+
+// FSStore is a local filesystem blob store, implements BlobStore interface. Blobs are saved as files under the root
+// directory, key path segments are used as subdirectories
+type FSStore struct {
+	root string
+}
+
+// NewFSStore is used to get new FSStore instance keeping blobs in given root directory. Directory is created if it
+// does not exist
+func NewFSStore(root string) BlobStore {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		logger.Log().WithField("layer", "FSStore-Init").Fatalf("err create root %v: %v", root, err.Error())
+	}
+
+	logger.Log().WithField("layer", "FSStore-Init").Infof("filesystem blob store created at %v", root)
+
+	return &FSStore{root: root}
+}
+
+// Put is implementing BlobStore.Put function. Blob is written to a temporary file first and renamed then, so readers
+// never see a partially written blob
+func (s *FSStore) Put(ctx context.Context, key string, r io.Reader, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		logger.Log().WithField("layer", "FSStore-Put").Errorf("err create dir: %v", err.Error())
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		logger.Log().WithField("layer", "FSStore-Put").Errorf("err create file: %v", err.Error())
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		logger.Log().WithField("layer", "FSStore-Put").Errorf("err write file: %v", err.Error())
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		logger.Log().WithField("layer", "FSStore-Put").Errorf("err close file: %v", err.Error())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// Get is implementing BlobStore.Get function
+func (s *FSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+
+	return f, err
+}
+
+// Delete is implementing BlobStore.Delete function
+func (s *FSStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Log().WithField("layer", "FSStore-Delete").Errorf("err remove file: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// path is used to get file path of the blob with given key. Will return error if key is blank or points outside the
+// root directory
+func (s *FSStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+
+	if key == "" || clean == "/" || clean != "/"+key || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewFSStore(t.TempDir())
+
+	require.NoError(t, s.Put(ctx, "pets/1/photo", bytes.NewBufferString("first"), "image/png"))
+	require.NoError(t, s.Put(ctx, "pets/1/photo", bytes.NewBufferString("second"), "image/png"))
+
+	rc, err := s.Get(ctx, "pets/1/photo")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, "second", string(data))
+
+	require.NoError(t, s.Delete(ctx, "pets/1/photo"))
+	require.NoError(t, s.Delete(ctx, "pets/1/photo"))
+
+	_, err = s.Get(ctx, "pets/1/photo")
+	require.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestFSStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	s := NewFSStore(t.TempDir())
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "check blank", key: ""},
+		{name: "check parent", key: "../photo"},
+		{name: "check nested parent", key: "pets/../../photo"},
+		{name: "check absolute", key: "/photo"},
+		{name: "check trailing slash", key: "pets/"},
+		{name: "check backslash", key: `pets\photo`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, s.Put(ctx, tt.key, bytes.NewBufferString("data"), "image/png"))
+
+			_, err := s.Get(ctx, tt.key)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, ErrNotExist)
+
+			require.Error(t, s.Delete(ctx, tt.key))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"pets/pkg/logger"
+)
+
+// MemoryStore is an in-memory blob store, implements BlobStore interface. It is safe for concurrent use and can be
+// used for demos and tests without filesystem
+type MemoryStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemoryStore is used to get new empty MemoryStore instance
+func NewMemoryStore() BlobStore {
+	logger.Log().WithField("layer", "MemoryStore-Init").Infof("in-memory blob store created")
+
+	return &MemoryStore{blobs: make(map[string][]byte)}
+}
+
+// Put is implementing BlobStore.Put function
+func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = data
+
+	return nil
+}
+
+// Get is implementing BlobStore.Get function
+func (s *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, ErrNotExist
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete is implementing BlobStore.Delete function
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blobs, key)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"pets/internal/config"
+	"pets/pkg/logger"
+)
+
+// ErrNotExist is returned if blob with given key does not exist
+var ErrNotExist = errors.New("blob does not exist")
+
+// BlobStore is a binary objects storage interface. Keys are slash separated paths like "pets/1/photo"
+type BlobStore interface {
+	// Put is used to save blob read from given reader by given key. Existing blob with the same key is replaced
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get is used to open blob by given key. Caller should close returned reader. Will return ErrNotExist if blob not
+	// found
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete is used to delete blob by given key. Deleting not existing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryDriver is a config.Storage Driver value used to select MemoryStore
+const MemoryDriver = "memory"
+
+// NewBlobStore is used to get new BlobStore instance selected by config driver: "fs" or "memory"
+func NewBlobStore(conf *config.Storage) BlobStore {
+	if conf == nil {
+		logger.Log().WithField("layer", "Storage-Init").Fatalf("config is nil")
+	}
+
+	if conf.Driver == MemoryDriver {
+		return NewMemoryStore()
+	}
+
+	return NewFSStore(conf.Root)
+}
@@ -0,0 +1,10 @@
+// Package migrations contains DB schema migrations for every supported SQL driver. Migrations are embedded into the app
+// binary and applied by repository.Migrator
+package migrations
+
+import "embed"
+
+// FS contains migrations for Postgres in "postgres" dir and for SQLite in "sqlite" dir
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS pets_status_idx;
+DROP INDEX IF EXISTS pets_species_idx;
+
+ALTER TABLE pets
+  DROP COLUMN status,
+  DROP COLUMN description,
+  DROP COLUMN color,
+  DROP COLUMN weight,
+  DROP COLUMN neutered,
+  DROP COLUMN sex,
+  DROP COLUMN birth_date,
+  DROP COLUMN breed,
+  DROP COLUMN species;
@@ -0,0 +1,13 @@
+ALTER TABLE pets
+  ADD COLUMN species varchar not null default 'other',
+  ADD COLUMN breed varchar not null default '',
+  ADD COLUMN birth_date date,
+  ADD COLUMN sex varchar not null default 'unknown',
+  ADD COLUMN neutered boolean not null default false,
+  ADD COLUMN weight double precision,
+  ADD COLUMN color varchar not null default '',
+  ADD COLUMN description text not null default '',
+  ADD COLUMN status varchar not null default 'available';
+
+CREATE INDEX pets_species_idx ON pets (species);
+CREATE INDEX pets_status_idx ON pets (status);
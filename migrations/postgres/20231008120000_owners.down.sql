@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS pet_ownership;
+
+DROP INDEX IF EXISTS pets_owner_id_idx;
+ALTER TABLE pets DROP COLUMN owner_id;
+
+DROP TABLE IF EXISTS owners;
@@ -0,0 +1,24 @@
+CREATE TABLE owners (
+  id bigserial not null primary key,
+  name varchar not null,
+  email varchar not null default '',
+  phone varchar not null default '',
+  address varchar not null default '',
+  created_at timestamp not null,
+  updated_at timestamp
+);
+
+ALTER TABLE pets ADD COLUMN owner_id bigint REFERENCES owners (id) ON DELETE SET NULL;
+
+CREATE INDEX pets_owner_id_idx ON pets (owner_id);
+
+CREATE TABLE pet_ownership (
+  id bigserial not null primary key,
+  pet_id bigint not null REFERENCES pets (id) ON DELETE CASCADE,
+  from_owner_id bigint REFERENCES owners (id) ON DELETE SET NULL,
+  to_owner_id bigint REFERENCES owners (id) ON DELETE SET NULL,
+  note text not null default '',
+  transferred_at timestamp not null
+);
+
+CREATE INDEX pet_ownership_pet_id_idx ON pet_ownership (pet_id);
@@ -0,0 +1,30 @@
+CREATE TABLE vaccinations (
+  id bigserial not null primary key,
+  pet_id bigint not null REFERENCES pets (id) ON DELETE CASCADE,
+  vaccine varchar not null,
+  given_on date not null,
+  due_on date,
+  vet varchar not null default '',
+  notes text not null default '',
+  attachment varchar not null default '',
+  created_at timestamp not null,
+  updated_at timestamp
+);
+
+CREATE INDEX vaccinations_pet_id_idx ON vaccinations (pet_id);
+CREATE INDEX vaccinations_due_on_idx ON vaccinations (due_on);
+
+CREATE TABLE treatments (
+  id bigserial not null primary key,
+  pet_id bigint not null REFERENCES pets (id) ON DELETE CASCADE,
+  kind varchar not null,
+  name varchar not null,
+  given_on date not null,
+  vet varchar not null default '',
+  notes text not null default '',
+  attachment varchar not null default '',
+  created_at timestamp not null,
+  updated_at timestamp
+);
+
+CREATE INDEX treatments_pet_id_idx ON treatments (pet_id);
@@ -0,0 +1,15 @@
+CREATE TABLE pet_photos (
+  id bigserial not null primary key,
+  pet_id bigint not null REFERENCES pets (id) ON DELETE CASCADE,
+  blob_key varchar not null,
+  thumb_key varchar not null,
+  content_type varchar not null,
+  size bigint not null,
+  width integer not null,
+  height integer not null,
+  created_at timestamp not null
+);
+
+CREATE INDEX pet_photos_pet_id_idx ON pet_photos (pet_id);
+
+ALTER TABLE pets ADD COLUMN primary_photo_id bigint REFERENCES pet_photos (id) ON DELETE SET NULL;
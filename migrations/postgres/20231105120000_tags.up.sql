@@ -0,0 +1,15 @@
+CREATE TABLE tags (
+  id bigserial not null primary key,
+  name varchar not null UNIQUE,
+  category varchar not null default '',
+  created_at timestamp not null,
+  updated_at timestamp
+);
+
+CREATE TABLE pet_tags (
+  pet_id bigint not null REFERENCES pets (id) ON DELETE CASCADE,
+  tag_id bigint not null REFERENCES tags (id) ON DELETE CASCADE,
+  PRIMARY KEY (pet_id, tag_id)
+);
+
+CREATE INDEX pet_tags_tag_id_idx ON pet_tags (tag_id);
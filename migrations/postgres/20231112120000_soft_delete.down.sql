@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS pets_deleted_at_idx;
+
+ALTER TABLE pets DROP COLUMN deleted_at;
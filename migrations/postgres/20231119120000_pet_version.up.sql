@@ -0,0 +1 @@
+ALTER TABLE pets ADD COLUMN version integer not null default 1;
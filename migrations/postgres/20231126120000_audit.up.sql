@@ -0,0 +1,14 @@
+-- audit rows are kept after the pet is purged, so pet_id has no foreign key
+CREATE TABLE pet_audit (
+  id bigserial not null primary key,
+  pet_id bigint not null,
+  version integer not null,
+  action varchar not null,
+  actor varchar not null default '',
+  request_id varchar not null default '',
+  diff text not null,
+  created_at timestamp not null
+);
+
+CREATE INDEX pet_audit_pet_id_idx ON pet_audit (pet_id, version);
+CREATE INDEX pet_audit_created_at_idx ON pet_audit (created_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pets;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE pets (
+  id integer not null primary key autoincrement,
+  name varchar,
+  created_at timestamp,
+  updated_at timestamp
+);
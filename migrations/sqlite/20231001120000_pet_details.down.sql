@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS pets_status_idx;
+DROP INDEX IF EXISTS pets_species_idx;
+
+ALTER TABLE pets DROP COLUMN status;
+ALTER TABLE pets DROP COLUMN description;
+ALTER TABLE pets DROP COLUMN color;
+ALTER TABLE pets DROP COLUMN weight;
+ALTER TABLE pets DROP COLUMN neutered;
+ALTER TABLE pets DROP COLUMN sex;
+ALTER TABLE pets DROP COLUMN birth_date;
+ALTER TABLE pets DROP COLUMN breed;
+ALTER TABLE pets DROP COLUMN species;
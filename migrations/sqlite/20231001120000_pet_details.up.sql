@@ -0,0 +1,12 @@
+ALTER TABLE pets ADD COLUMN species varchar not null default 'other';
+ALTER TABLE pets ADD COLUMN breed varchar not null default '';
+ALTER TABLE pets ADD COLUMN birth_date date;
+ALTER TABLE pets ADD COLUMN sex varchar not null default 'unknown';
+ALTER TABLE pets ADD COLUMN neutered boolean not null default false;
+ALTER TABLE pets ADD COLUMN weight real;
+ALTER TABLE pets ADD COLUMN color varchar not null default '';
+ALTER TABLE pets ADD COLUMN description text not null default '';
+ALTER TABLE pets ADD COLUMN status varchar not null default 'available';
+
+CREATE INDEX pets_species_idx ON pets (species);
+CREATE INDEX pets_status_idx ON pets (status);
@@ -0,0 +1,25 @@
+CREATE TABLE owners (
+  id integer not null primary key autoincrement,
+  name varchar not null,
+  email varchar not null default '',
+  phone varchar not null default '',
+  address varchar not null default '',
+  created_at timestamp not null,
+  updated_at timestamp
+);
+
+-- SQLite can not drop a column used in a foreign key, so pets.owner_id is not declared as one
+ALTER TABLE pets ADD COLUMN owner_id integer;
+
+CREATE INDEX pets_owner_id_idx ON pets (owner_id);
+
+CREATE TABLE pet_ownership (
+  id integer not null primary key autoincrement,
+  pet_id integer not null REFERENCES pets (id) ON DELETE CASCADE,
+  from_owner_id integer REFERENCES owners (id) ON DELETE SET NULL,
+  to_owner_id integer REFERENCES owners (id) ON DELETE SET NULL,
+  note text not null default '',
+  transferred_at timestamp not null
+);
+
+CREATE INDEX pet_ownership_pet_id_idx ON pet_ownership (pet_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pet_status_history;
+DROP TABLE IF EXISTS adoption_applications;
@@ -0,0 +1,26 @@
+CREATE TABLE adoption_applications (
+  id integer not null primary key autoincrement,
+  pet_id integer not null REFERENCES pets (id) ON DELETE CASCADE,
+  applicant varchar not null,
+  contact varchar not null default '',
+  notes text not null default '',
+  status varchar not null,
+  reason text not null default '',
+  created_at timestamp not null,
+  updated_at timestamp
+);
+
+CREATE INDEX adoption_applications_pet_id_idx ON adoption_applications (pet_id);
+CREATE INDEX adoption_applications_status_idx ON adoption_applications (status);
+
+CREATE TABLE pet_status_history (
+  id integer not null primary key autoincrement,
+  pet_id integer not null REFERENCES pets (id) ON DELETE CASCADE,
+  from_status varchar not null,
+  to_status varchar not null,
+  reason text not null default '',
+  application_id integer REFERENCES adoption_applications (id) ON DELETE SET NULL,
+  created_at timestamp not null
+);
+
+CREATE INDEX pet_status_history_pet_id_idx ON pet_status_history (pet_id);
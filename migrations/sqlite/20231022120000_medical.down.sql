@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS treatments;
+DROP TABLE IF EXISTS vaccinations;
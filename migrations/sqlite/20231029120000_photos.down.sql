@@ -0,0 +1,3 @@
+ALTER TABLE pets DROP COLUMN primary_photo_id;
+
+DROP TABLE IF EXISTS pet_photos;
@@ -0,0 +1,16 @@
+CREATE TABLE pet_photos (
+  id integer not null primary key autoincrement,
+  pet_id integer not null REFERENCES pets (id) ON DELETE CASCADE,
+  blob_key varchar not null,
+  thumb_key varchar not null,
+  content_type varchar not null,
+  size integer not null,
+  width integer not null,
+  height integer not null,
+  created_at timestamp not null
+);
+
+CREATE INDEX pet_photos_pet_id_idx ON pet_photos (pet_id);
+
+-- SQLite can not drop a column used in a foreign key, so pets.primary_photo_id is not declared as one
+ALTER TABLE pets ADD COLUMN primary_photo_id integer;
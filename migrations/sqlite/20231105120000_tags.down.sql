@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pet_tags;
+DROP TABLE IF EXISTS tags;
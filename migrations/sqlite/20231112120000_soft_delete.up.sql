@@ -0,0 +1,3 @@
+ALTER TABLE pets ADD COLUMN deleted_at timestamp;
+
+CREATE INDEX pets_deleted_at_idx ON pets (deleted_at);